@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// roleListEnvelope is the body of the role listing routes
+type roleListEnvelope struct {
+	Data []*Role `json:"data"`
+}
+
+// ListRoles handles GET /api/v1/admin/roles
+func (c *Client) ListRoles(ctx context.Context) ([]*Role, error) {
+	var out roleListEnvelope
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/admin/roles"}, &out); err != nil {
+		return nil, err
+	}
+	return out.Data, nil
+}
+
+// GetUserRoles handles GET /api/v1/admin/users/:id/roles
+func (c *Client) GetUserRoles(ctx context.Context, userID uint) ([]*Role, error) {
+	var out roleListEnvelope
+	if err := c.do(ctx, request{method: http.MethodGet, path: adminUserPath(userID) + "/roles"}, &out); err != nil {
+		return nil, err
+	}
+	return out.Data, nil
+}
+
+// GrantRole handles POST /api/v1/admin/users/:id/roles
+func (c *Client) GrantRole(ctx context.Context, userID uint, role string) error {
+	body := struct {
+		Role string `json:"role"`
+	}{Role: role}
+	return c.do(ctx, request{method: http.MethodPost, path: adminUserPath(userID) + "/roles", body: body}, nil)
+}
+
+// RevokeRole handles DELETE /api/v1/admin/users/:id/roles/:role
+func (c *Client) RevokeRole(ctx context.Context, userID uint, role string) error {
+	path := adminUserPath(userID) + "/roles/" + url.PathEscape(role)
+	return c.do(ctx, request{method: http.MethodDelete, path: path}, nil)
+}
+
+// RestoreUser handles POST /api/v1/admin/users/:id/restore
+func (c *Client) RestoreUser(ctx context.Context, userID uint) (*User, error) {
+	var out userEnvelope
+	if err := c.do(ctx, request{method: http.MethodPost, path: adminUserPath(userID) + "/restore"}, &out); err != nil {
+		return nil, err
+	}
+	return out.Data, nil
+}
+
+// ListAuditEntries handles GET /api/v1/admin/audit
+func (c *Client) ListAuditEntries(ctx context.Context, filter AuditFilter) (*AuditPage, error) {
+	query := url.Values{}
+	if filter.ActorID != 0 {
+		query.Set("actor_id", strconv.FormatUint(uint64(filter.ActorID), 10))
+	}
+	setString(query, "action", filter.Action)
+	setString(query, "target_type", filter.TargetType)
+	setString(query, "target_id", filter.TargetID)
+	setString(query, "request_id", filter.RequestID)
+	setTime(query, "from", filter.From)
+	setTime(query, "to", filter.To)
+	setInt(query, "limit", filter.Limit)
+	setInt(query, "offset", filter.Offset)
+
+	var page AuditPage
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/admin/audit", query: query}, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// VerifyAuditChain handles GET /api/v1/admin/audit/verify
+func (c *Client) VerifyAuditChain(ctx context.Context) (*AuditChainReport, error) {
+	var out struct {
+		Data *AuditChainReport `json:"data"`
+	}
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/admin/audit/verify"}, &out); err != nil {
+		return nil, err
+	}
+	return out.Data, nil
+}
+
+// adminUserPath returns the admin path of one user
+func adminUserPath(userID uint) string {
+	return "/api/v1/admin/users/" + strconv.FormatUint(uint64(userID), 10)
+}
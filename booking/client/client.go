@@ -0,0 +1,359 @@
+// Package client is a typed Go client for the booking REST API.
+//
+// It covers the routes of the OpenAPI document served at /openapi.json:
+// users, self-service GDPR requests, and the admin role and audit routes.
+// Runs, items and the wallet are not part of the API yet; their methods
+// will be added here together with their routes.
+//
+//	c := client.New("http://localhost:8080", client.WithTokenSource(tokens))
+//	user, err := c.GetUser(ctx, 7)
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	mathrand "math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Header names shared with the server
+const (
+	defaultUserIDHeader  = "X-User-ID"
+	idempotencyKeyHeader = "Idempotency-Key"
+	requestIDHeader      = "X-Request-ID"
+)
+
+// TokenSource supplies bearer tokens for the Authorization header
+// Token is called with refresh=true after the API answered 401 with the
+// current token; it should then return a new one (e.g. via a refresh token).
+type TokenSource interface {
+	Token(ctx context.Context, refresh bool) (string, error)
+}
+
+// Client calls the booking API
+// It is safe for concurrent use.
+type Client struct {
+	baseURL      *url.URL
+	httpClient   *http.Client
+	userIDHeader string
+	userID       uint
+	tokens       TokenSource
+	maxAttempts  int
+	baseDelay    time.Duration
+	maxDelay     time.Duration
+}
+
+// Option configures a Client
+// Functional Options Pattern, as used by the server's use cases
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client, e.g. one with a timeout or custom transport
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithUserID authenticates as the user, for callers trusted to set the gateway's user header
+func WithUserID(userID uint) Option {
+	return func(c *Client) {
+		c.userID = userID
+	}
+}
+
+// WithUserIDHeader sets the header WithUserID uses, if the server's AUTH_USER_HEADER is not X-User-ID
+func WithUserIDHeader(header string) Option {
+	return func(c *Client) {
+		c.userIDHeader = header
+	}
+}
+
+// WithTokenSource sends a bearer token from tokens with every request, refreshing it once on 401
+func WithTokenSource(tokens TokenSource) Option {
+	return func(c *Client) {
+		c.tokens = tokens
+	}
+}
+
+// WithRetry retries idempotent calls up to maxAttempts times in total with exponential backoff from baseDelay
+// POST requests are retried too, because the client sends them with an
+// Idempotency-Key. maxAttempts of 1 disables retries.
+func WithRetry(maxAttempts int, baseDelay time.Duration) Option {
+	return func(c *Client) {
+		c.maxAttempts = max(maxAttempts, 1)
+		c.baseDelay = baseDelay
+	}
+}
+
+// New creates a client for the API at baseURL, e.g. http://localhost:8080
+func New(baseURL string, opts ...Option) *Client {
+	parsed, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		// Surface the mistake on the first call instead of panicking here
+		parsed = &url.URL{Opaque: baseURL}
+	}
+
+	c := &Client{
+		baseURL:      parsed,
+		httpClient:   &http.Client{Timeout: 30 * time.Second},
+		userIDHeader: defaultUserIDHeader,
+		maxAttempts:  3,
+		baseDelay:    200 * time.Millisecond,
+		maxDelay:     5 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// request describes one API call
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	body   interface{}
+}
+
+// do sends req and decodes a JSON response into out (which may be nil)
+func (c *Client) do(ctx context.Context, req request, out interface{}) error {
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode %s %s response: %w", req.method, req.path, err)
+	}
+	return nil
+}
+
+// send performs req with authentication and retries and returns a 2xx response
+// The caller closes the response body.
+func (c *Client) send(ctx context.Context, req request) (*http.Response, error) {
+	var body []byte
+	if req.body != nil {
+		var err error
+		if body, err = json.Marshal(req.body); err != nil {
+			return nil, fmt.Errorf("encode %s %s request: %w", req.method, req.path, err)
+		}
+	}
+
+	// One key for every attempt, so the server replays instead of repeating the call
+	var idempotencyKey string
+	if req.method == http.MethodPost {
+		idempotencyKey = newKey()
+	}
+
+	refreshed := false
+	for attempt := 1; ; attempt++ {
+		resp, err := c.attempt(ctx, req, body, idempotencyKey, false)
+		if err == nil && resp.StatusCode == http.StatusUnauthorized && c.tokens != nil && !refreshed {
+			// The token may have expired; refresh once and repeat without counting an attempt
+			drain(resp)
+			refreshed = true
+			resp, err = c.attempt(ctx, req, body, idempotencyKey, true)
+		}
+
+		if err == nil && resp.StatusCode < 300 {
+			return resp, nil
+		}
+
+		var apiErr error
+		if err == nil {
+			apiErr = newAPIError(resp)
+		} else {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			apiErr = err
+		}
+
+		if attempt >= c.maxAttempts || !c.retryable(req.method, resp, err) {
+			if resp != nil {
+				drain(resp)
+			}
+			return nil, apiErr
+		}
+
+		delay := c.backoff(attempt, resp)
+		if resp != nil {
+			drain(resp)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// attempt sends one HTTP request
+func (c *Client) attempt(ctx context.Context, req request, body []byte, idempotencyKey string, refreshToken bool) (*http.Response, error) {
+	target := c.baseURL.JoinPath(req.path)
+	if len(req.query) > 0 {
+		target.RawQuery = req.query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, target.String(), reader)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	if idempotencyKey != "" {
+		httpReq.Header.Set(idempotencyKeyHeader, idempotencyKey)
+	}
+	if c.userID != 0 {
+		httpReq.Header.Set(c.userIDHeader, strconv.FormatUint(uint64(c.userID), 10))
+	}
+	if c.tokens != nil {
+		token, err := c.tokens.Token(ctx, refreshToken)
+		if err != nil {
+			return nil, fmt.Errorf("get token: %w", err)
+		}
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	return c.httpClient.Do(httpReq)
+}
+
+// retryable reports whether a failed attempt may be repeated
+// Only idempotent methods are retried; POST counts because of its Idempotency-Key.
+func (c *Client) retryable(method string, resp *http.Response, err error) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodPost:
+	default:
+		return false
+	}
+	if err != nil {
+		// Network errors; the context was checked by the caller
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	case http.StatusConflict:
+		// The first request with the same Idempotency-Key is still running
+		return resp.Header.Get("Retry-After") != ""
+	default:
+		return false
+	}
+}
+
+// backoff returns the wait before the next attempt, honouring Retry-After
+func (c *Client) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return min(time.Duration(seconds)*time.Second, c.maxDelay)
+		}
+	}
+	delay := float64(c.baseDelay) * math.Pow(2, float64(attempt-1))
+	// Full jitter keeps clients that failed together from retrying together
+	return min(time.Duration(mathrand.Float64()*delay), c.maxDelay)
+}
+
+// drain discards the rest of a body so the connection can be reused
+func drain(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+}
+
+// newKey returns a random Idempotency-Key
+func newKey() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b)
+}
+
+// APIError is a non-2xx response of the API
+type APIError struct {
+	StatusCode int
+	// Message is the "error" field of the body
+	Message string
+	// Fields lists invalid parameters or body fields when request validation failed
+	Fields []FieldError
+	// RequestID identifies the request in the server logs
+	RequestID string
+	// RetryAfter is how long the server asked to wait before retrying, e.g. on 429; zero if it did not say
+	RetryAfter time.Duration
+}
+
+// FieldError is one invalid parameter or body field
+type FieldError struct {
+	In      string `json:"in"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error implements error
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("booking API: %d %s", e.StatusCode, e.Message)
+	for _, field := range e.Fields {
+		msg += fmt.Sprintf("; %s %s %s", field.In, field.Field, field.Message)
+	}
+	return msg
+}
+
+// newAPIError reads the error body of resp
+func newAPIError(resp *http.Response) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Message:    http.StatusText(resp.StatusCode),
+		RequestID:  resp.Header.Get(requestIDHeader),
+	}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		apiErr.RetryAfter = time.Duration(seconds) * time.Second
+	}
+
+	var body struct {
+		Error  string       `json:"error"`
+		Fields []FieldError `json:"fields"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if json.Unmarshal(data, &body) == nil && body.Error != "" {
+		apiErr.Message = body.Error
+		apiErr.Fields = body.Fields
+	}
+	return apiErr
+}
+
+// IsNotFound reports whether err is a 404 from the API
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsConflict reports whether err is a 409 from the API
+func IsConflict(err error) bool {
+	return hasStatus(err, http.StatusConflict)
+}
+
+// hasStatus reports whether err is an APIError with the status code
+func hasStatus(err error, status int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == status
+}
@@ -0,0 +1,503 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"booking/config"
+	server "booking/delivery/http"
+	"booking/delivery/http/handler"
+	"booking/delivery/http/middleware"
+	"booking/domain/entity"
+	"booking/infrastructure/auth"
+	"booking/infrastructure/ratelimit"
+	"booking/usecase/idempotency"
+	"booking/usecase/role"
+	"booking/usecase/user"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// testSecret signs the bearer tokens of the test server
+const testSecret = "client-test-secret-0123456789abcdef"
+
+// adminID holds users:write; any other user holds nothing
+const adminID uint = 1
+
+// fakeUsers keeps users in memory; methods the tests don't call panic through the nil interface
+type fakeUsers struct {
+	user.UserUseCase
+
+	mu      sync.Mutex
+	users   map[uint]*entity.User
+	creates int
+}
+
+func (f *fakeUsers) CreateUser(ctx context.Context, u *entity.User) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, existing := range f.users {
+		if existing.Email == u.Email {
+			return user.ErrEmailTaken
+		}
+	}
+	f.creates++
+	u.ID = uint(len(f.users) + 100)
+	u.CreatedAt = time.Now()
+	u.UpdatedAt = u.CreatedAt
+	copied := *u
+	f.users[u.ID] = &copied
+	return nil
+}
+
+func (f *fakeUsers) RegisterUser(ctx context.Context, u *entity.User) error {
+	u.IsActive = true
+	return f.CreateUser(ctx, u)
+}
+
+func (f *fakeUsers) GetUserByID(ctx context.Context, id uint) (*entity.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	u, ok := f.users[id]
+	if !ok {
+		return nil, user.ErrUserNotFound
+	}
+	copied := *u
+	return &copied, nil
+}
+
+// fakeRoles grants every permission to adminID
+type fakeRoles struct {
+	role.RoleUseCase
+}
+
+func (fakeRoles) HasPermission(ctx context.Context, userID uint, permission string) (bool, error) {
+	return userID == adminID, nil
+}
+
+// memoryIdempotency is an in-memory repository.IdempotencyRepository
+type memoryIdempotency struct {
+	mu      sync.Mutex
+	nextID  uint
+	records map[string]*entity.IdempotencyRecord
+}
+
+func (m *memoryIdempotency) Reserve(ctx context.Context, record *entity.IdempotencyRecord) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, taken := m.records[record.Scope+"\x00"+record.Key]; taken {
+		return false, nil
+	}
+	m.nextID++
+	record.ID = m.nextID
+	record.CreatedAt = time.Now()
+	record.UpdatedAt = record.CreatedAt
+	copied := *record
+	m.records[record.Scope+"\x00"+record.Key] = &copied
+	return true, nil
+}
+
+func (m *memoryIdempotency) Get(ctx context.Context, scope, key string) (*entity.IdempotencyRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	record, ok := m.records[scope+"\x00"+key]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *record
+	return &copied, nil
+}
+
+func (m *memoryIdempotency) Complete(ctx context.Context, record *entity.IdempotencyRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	copied := *record
+	copied.UpdatedAt = time.Now()
+	m.records[record.Scope+"\x00"+record.Key] = &copied
+	return nil
+}
+
+func (m *memoryIdempotency) Delete(ctx context.Context, id uint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key, record := range m.records {
+		if record.ID == id {
+			delete(m.records, key)
+		}
+	}
+	return nil
+}
+
+func (m *memoryIdempotency) DeleteExpired(ctx context.Context, cutoff time.Time) (int64, error) {
+	return 0, nil
+}
+
+// testServer is the real router over fake use cases
+type testServer struct {
+	*httptest.Server
+	users  *fakeUsers
+	tokens *auth.Tokens
+}
+
+// newTestServer serves the API routes with bearer authentication and response validation on
+func newTestServer(t *testing.T, policies ...ratelimit.Policy) *testServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	cfg, err := config.Load(nil)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	cfg.Auth.TokenSecret = testSecret
+	cfg.Auth.TrustUserHeader = false
+	cfg.OpenAPI.ValidateRequests = true
+	// Every response the client decodes must match the document it was written from
+	cfg.OpenAPI.ValidateResponses = true
+
+	users := &fakeUsers{users: map[uint]*entity.User{}}
+	idempotencyUseCase := idempotency.NewIdempotencyUseCase(&memoryIdempotency{records: map[string]*entity.IdempotencyRecord{}})
+	factory := handler.NewHandlerFactory(users, fakeRoles{}, nil, nil, idempotencyUseCase, nil)
+
+	store := ratelimit.NewMemoryStore()
+	t.Cleanup(func() { store.Close() })
+
+	router := server.NewRouter(factory, cfg, middleware.NewRateLimiter(store, policies...), nil, nil)
+	router.SetupRoutes()
+
+	ts := httptest.NewServer(router.GetEngine())
+	t.Cleanup(ts.Close)
+	return &testServer{Server: ts, users: users, tokens: auth.NewTokens(testSecret)}
+}
+
+// tokenSource signs tokens for a user; the first token is already expired when expireFirst is set
+type tokenSource struct {
+	tokens      *auth.Tokens
+	userID      uint
+	expireFirst bool
+
+	mu       sync.Mutex
+	requests []bool
+}
+
+func (s *tokenSource) Token(ctx context.Context, refresh bool) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, refresh)
+	issuedAt := time.Now()
+	if s.expireFirst && len(s.requests) == 1 {
+		issuedAt = issuedAt.Add(-2 * time.Hour)
+	}
+	return s.tokens.Sign(s.userID, time.Hour, issuedAt)
+}
+
+// recordingTransport records every request and can turn responses into failures
+// fail is called with the 1-based attempt number and the real response; a
+// non-nil return replaces it, e.g. to lose a response the server did send.
+type recordingTransport struct {
+	fail func(attempt int, resp *http.Response) *http.Response
+
+	mu       sync.Mutex
+	requests []*http.Request
+	replayed []string
+}
+
+func (rt *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.mu.Lock()
+	rt.requests = append(rt.requests, req)
+	attempt := len(rt.requests)
+	rt.mu.Unlock()
+
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	rt.mu.Lock()
+	rt.replayed = append(rt.replayed, resp.Header.Get(middleware.IdempotentReplayedHeader))
+	rt.mu.Unlock()
+
+	if rt.fail != nil {
+		if replacement := rt.fail(attempt, resp); replacement != nil {
+			drain(resp)
+			replacement.Request = req
+			return replacement, nil
+		}
+	}
+	return resp, nil
+}
+
+// headers returns the value of header in every recorded request
+func (rt *recordingTransport) headers(header string) []string {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	values := make([]string, len(rt.requests))
+	for i, req := range rt.requests {
+		values[i] = req.Header.Get(header)
+	}
+	return values
+}
+
+// failure builds a bodiless error response
+func failure(status int, retryAfter string) *http.Response {
+	header := http.Header{}
+	if retryAfter != "" {
+		header.Set("Retry-After", retryAfter)
+	}
+	return &http.Response{
+		StatusCode: status,
+		Status:     fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader("")),
+	}
+}
+
+func newUserInput(name string) CreateUserInput {
+	return CreateUserInput{Email: name + "@example.com", Username: name, Password: "secret123", FullName: "Test " + name}
+}
+
+func TestClientSendsBearerToken(t *testing.T) {
+	ts := newTestServer(t)
+	ctx := context.Background()
+
+	transport := &recordingTransport{}
+	tokens := &tokenSource{tokens: ts.tokens, userID: adminID}
+	c := New(ts.URL, WithTokenSource(tokens), WithHTTPClient(&http.Client{Transport: transport}))
+
+	created, err := c.CreateUser(ctx, newUserInput("alice"))
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	if created.ID == 0 || created.Email != "alice@example.com" || created.FullName != "Test alice" {
+		t.Errorf("CreateUser = %+v", created)
+	}
+	if got := transport.headers("Authorization")[0]; !strings.HasPrefix(got, "Bearer ") {
+		t.Errorf("Authorization = %q, want a bearer token", got)
+	}
+
+	fetched, err := c.GetUser(ctx, created.ID)
+	if err != nil || fetched.Username != "alice" {
+		t.Errorf("GetUser = %+v, %v", fetched, err)
+	}
+
+	// Without credentials the write route answers 401, with another user's 403
+	_, err = New(ts.URL).CreateUser(ctx, newUserInput("anonymous"))
+	if !hasStatus(err, http.StatusUnauthorized) {
+		t.Errorf("anonymous CreateUser error = %v, want 401", err)
+	}
+	other := New(ts.URL, WithTokenSource(&tokenSource{tokens: ts.tokens, userID: 2}))
+	if _, err := other.CreateUser(ctx, newUserInput("mallory")); !hasStatus(err, http.StatusForbidden) {
+		t.Errorf("CreateUser without users:write error = %v, want 403", err)
+	}
+
+	// The user header is not trusted by default, so it proves nothing
+	spoofed := New(ts.URL, WithUserID(adminID))
+	if _, err := spoofed.CreateUser(ctx, newUserInput("spoofed")); !hasStatus(err, http.StatusUnauthorized) {
+		t.Errorf("CreateUser with only X-User-ID error = %v, want 401", err)
+	}
+
+	// Reads need users:read too
+	if _, err := New(ts.URL).GetUser(ctx, created.ID); !hasStatus(err, http.StatusUnauthorized) {
+		t.Errorf("anonymous GetUser error = %v, want 401", err)
+	}
+	if _, err := other.ListUsers(ctx, UserFilter{}); !hasStatus(err, http.StatusForbidden) {
+		t.Errorf("ListUsers without users:read error = %v, want 403", err)
+	}
+}
+
+func TestClientSignup(t *testing.T) {
+	ts := newTestServer(t)
+	ctx := context.Background()
+
+	created, err := New(ts.URL).Signup(ctx, newUserInput("frank"))
+	if err != nil {
+		t.Fatalf("anonymous Signup: %v", err)
+	}
+	if created.ID == 0 || !created.IsActive {
+		t.Errorf("Signup = %+v, want an active user", created)
+	}
+	if _, err := New(ts.URL).Signup(ctx, newUserInput("frank")); !hasStatus(err, http.StatusConflict) {
+		t.Errorf("Signup with a taken email error = %v, want 409", err)
+	}
+}
+
+func TestClientRefreshesTokenOnce(t *testing.T) {
+	ts := newTestServer(t)
+	ctx := context.Background()
+
+	tokens := &tokenSource{tokens: ts.tokens, userID: adminID, expireFirst: true}
+	c := New(ts.URL, WithTokenSource(tokens), WithRetry(1, time.Millisecond))
+
+	if _, err := c.CreateUser(ctx, newUserInput("bob")); err != nil {
+		t.Fatalf("CreateUser with an expired token: %v", err)
+	}
+	if fmt.Sprint(tokens.requests) != "[false true]" {
+		t.Errorf("token requests (refresh) = %v, want [false true]", tokens.requests)
+	}
+	if ts.users.creates != 1 {
+		t.Errorf("users created = %d, want 1", ts.users.creates)
+	}
+
+	// A token that stays invalid is refreshed once, then the 401 is returned
+	bad := &tokenSource{tokens: auth.NewTokens("another-secret-0123456789abcdefghij"), userID: adminID}
+	_, err := New(ts.URL, WithTokenSource(bad)).CreateUser(ctx, newUserInput("carol"))
+	if !hasStatus(err, http.StatusUnauthorized) {
+		t.Errorf("error = %v, want 401", err)
+	}
+	if fmt.Sprint(bad.requests) != "[false true]" {
+		t.Errorf("token requests (refresh) = %v, want one refresh", bad.requests)
+	}
+}
+
+func TestClientRetriesPostWithSameIdempotencyKey(t *testing.T) {
+	ts := newTestServer(t)
+	ctx := context.Background()
+
+	// The first response is lost after the server created the user
+	transport := &recordingTransport{fail: func(attempt int, resp *http.Response) *http.Response {
+		if attempt == 1 {
+			return failure(http.StatusBadGateway, "")
+		}
+		return nil
+	}}
+	c := New(ts.URL,
+		WithTokenSource(&tokenSource{tokens: ts.tokens, userID: adminID}),
+		WithHTTPClient(&http.Client{Transport: transport}),
+		WithRetry(3, time.Millisecond),
+	)
+
+	created, err := c.CreateUser(ctx, newUserInput("dave"))
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	if created.Username != "dave" {
+		t.Errorf("CreateUser = %+v", created)
+	}
+
+	keys := transport.headers(idempotencyKeyHeader)
+	if len(keys) != 2 || keys[0] == "" || keys[0] != keys[1] {
+		t.Fatalf("Idempotency-Key per attempt = %q, want the same key twice", keys)
+	}
+	// The retry was answered from the stored response instead of creating the user again
+	if ts.users.creates != 1 {
+		t.Errorf("users created = %d, want 1", ts.users.creates)
+	}
+	if transport.replayed[1] != "true" {
+		t.Errorf("retry %s = %q, want a replayed response", middleware.IdempotentReplayedHeader, transport.replayed[1])
+	}
+
+	// Each call gets a key of its own
+	if _, err := c.CreateUser(ctx, newUserInput("erin")); err != nil {
+		t.Fatal(err)
+	}
+	keys = transport.headers(idempotencyKeyHeader)
+	if len(keys) != 3 || keys[2] == keys[0] {
+		t.Errorf("Idempotency-Key per attempt = %q, want a new key for the new call", keys)
+	}
+}
+
+func TestClientStopsRetryingAtMaxAttempts(t *testing.T) {
+	ts := newTestServer(t)
+	ctx := context.Background()
+
+	transport := &recordingTransport{fail: func(int, *http.Response) *http.Response {
+		return failure(http.StatusServiceUnavailable, "")
+	}}
+	c := New(ts.URL, WithHTTPClient(&http.Client{Transport: transport}), WithRetry(3, time.Millisecond))
+
+	_, err := c.GetUser(ctx, 1)
+	if !hasStatus(err, http.StatusServiceUnavailable) {
+		t.Errorf("error = %v, want 503", err)
+	}
+	if n := len(transport.headers("Accept")); n != 3 {
+		t.Errorf("attempts = %d, want 3", n)
+	}
+
+	// Client errors are final
+	transport = &recordingTransport{}
+	c = New(ts.URL, WithTokenSource(&tokenSource{tokens: ts.tokens, userID: adminID}), WithHTTPClient(&http.Client{Transport: transport}), WithRetry(3, time.Millisecond))
+	if _, err := c.GetUser(ctx, 999); !IsNotFound(err) {
+		t.Errorf("error = %v, want 404", err)
+	}
+	if n := len(transport.headers("Accept")); n != 1 {
+		t.Errorf("attempts for a 404 = %d, want 1", n)
+	}
+}
+
+func TestClientHonoursRetryAfter(t *testing.T) {
+	ts := newTestServer(t)
+
+	// Retry-After: 0 must win over an hour of backoff
+	transport := &recordingTransport{fail: func(attempt int, resp *http.Response) *http.Response {
+		if attempt == 1 {
+			return failure(http.StatusServiceUnavailable, "0")
+		}
+		return nil
+	}}
+	c := New(ts.URL, WithTokenSource(&tokenSource{tokens: ts.tokens, userID: adminID}), WithHTTPClient(&http.Client{Transport: transport}), WithRetry(2, time.Hour))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_, err := c.GetUser(ctx, 999)
+	if !IsNotFound(err) {
+		t.Fatalf("error = %v, want the 404 of the second attempt", err)
+	}
+}
+
+func TestAPIErrorDecodesResponse(t *testing.T) {
+	ts := newTestServer(t, ratelimit.Policy{Name: "api", Limit: 2, Period: time.Hour})
+	ctx := context.Background()
+	c := New(ts.URL, WithTokenSource(&tokenSource{tokens: ts.tokens, userID: adminID}), WithRetry(1, time.Millisecond))
+
+	// Request validation failures list the fields
+	_, err := c.ListUsers(ctx, UserFilter{Sort: "shoe_size", Limit: -1})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("error = %v, want *APIError", err)
+	}
+	if apiErr.StatusCode != http.StatusBadRequest || apiErr.Message != "Request validation failed" {
+		t.Errorf("APIError = %+v, want 400 Request validation failed", apiErr)
+	}
+	if len(apiErr.Fields) != 2 || apiErr.Fields[0] != (FieldError{In: "query", Field: "sort", Message: apiErr.Fields[0].Message}) || apiErr.Fields[1].Field != "limit" {
+		t.Errorf("Fields = %+v, want query sort and limit", apiErr.Fields)
+	}
+	if apiErr.RequestID == "" {
+		t.Error("RequestID is empty")
+	}
+	if !strings.Contains(apiErr.Error(), "query sort must be one of") {
+		t.Errorf("Error() = %q, want the invalid fields", apiErr.Error())
+	}
+
+	// Not found is reported as such
+	if _, err := c.GetUser(ctx, 999); !IsNotFound(err) {
+		t.Errorf("GetUser(999) error = %v, want 404", err)
+	}
+
+	// The bucket is empty now; the 429 says how long to wait
+	_, err = c.GetUser(ctx, 999)
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("error = %v, want 429", err)
+	}
+	if apiErr.RetryAfter != 30*time.Minute {
+		t.Errorf("RetryAfter = %v, want 30m", apiErr.RetryAfter)
+	}
+	if apiErr.Message != "Rate limit exceeded, retry later" {
+		t.Errorf("Message = %q", apiErr.Message)
+	}
+}
@@ -0,0 +1,29 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Ready calls the readiness probe GET /readyz
+// A not-ready API answers 503 with the same report, so that is returned
+// without an error; check Status (ok, fail or starting). The probe is not
+// retried, since callers poll it themselves.
+func (c *Client) Ready(ctx context.Context) (*HealthReport, error) {
+	resp, err := c.attempt(ctx, request{method: http.MethodGet, path: "/readyz"}, nil, "", false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusServiceUnavailable {
+		return nil, newAPIError(resp)
+	}
+	var report HealthReport
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		return nil, fmt.Errorf("decode readiness report: %w", err)
+	}
+	return &report, nil
+}
@@ -0,0 +1,188 @@
+package client
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Models mirror the JSON of the API as described by /openapi.json
+// They are declared here rather than imported from the server packages, so
+// the client has no dependency on GORM, gin or the database drivers.
+
+// User is a user account
+type User struct {
+	ID        uint       `json:"id"`
+	Email     string     `json:"email"`
+	Username  string     `json:"username"`
+	FullName  string     `json:"full_name"`
+	Phone     string     `json:"phone"`
+	IsActive  bool       `json:"is_active"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at"`
+	// PurgedAt is set once personal data has been anonymized
+	PurgedAt *time.Time `json:"purged_at,omitempty"`
+}
+
+// CreateUserInput is the body of CreateUser
+type CreateUserInput struct {
+	Email    string `json:"email"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	FullName string `json:"full_name,omitempty"`
+	Phone    string `json:"phone,omitempty"`
+}
+
+// UpdateUserInput is the body of UpdateUser; empty fields are left unchanged
+type UpdateUserInput struct {
+	Email    string `json:"email,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	FullName string `json:"full_name,omitempty"`
+	Phone    string `json:"phone,omitempty"`
+	IsActive *bool  `json:"is_active,omitempty"`
+}
+
+// Sort orders of ListUsers
+const (
+	SortCreatedAt     = "created_at"
+	SortCreatedAtDesc = "-created_at"
+	SortUsername      = "username"
+	SortUsernameDesc  = "-username"
+	SortEmail         = "email"
+	SortEmailDesc     = "-email"
+)
+
+// UserFilter narrows ListUsers; zero fields are not sent
+type UserFilter struct {
+	Email    string
+	Username string
+	FullName string
+	Phone    string
+	IsActive *bool
+	// Search matches a prefix of username or email, or the whole full name
+	Search string
+	// CreatedFrom (inclusive) and CreatedTo (exclusive) bound created_at
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	// Sort is one of the Sort* constants
+	Sort string
+	// Cursor is the NextCursor of the previous page
+	Cursor string
+	Limit  int
+}
+
+// UserPage is one page of ListUsers
+type UserPage struct {
+	Data  []*User `json:"data"`
+	Total int64   `json:"total"`
+	Limit int     `json:"limit"`
+	// NextCursor fetches the next page; empty on the last page
+	NextCursor string `json:"next_cursor"`
+	HasMore    bool   `json:"has_more"`
+}
+
+// Permission is a capability granted through a role
+type Permission struct {
+	ID          uint   `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Role groups permissions
+type Role struct {
+	ID          uint         `json:"id"`
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Permissions []Permission `json:"permissions"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// Types and statuses of a PrivacyRequest
+const (
+	PrivacyExport  = "export"
+	PrivacyErasure = "erasure"
+
+	PrivacyPending    = "pending"
+	PrivacyProcessing = "processing"
+	PrivacyAnonymized = "anonymized"
+	PrivacyCompleted  = "completed"
+	PrivacyFailed     = "failed"
+	PrivacyExpired    = "expired"
+)
+
+// PrivacyRequest is a GDPR export or erasure request of the caller
+type PrivacyRequest struct {
+	ID          uint       `json:"id"`
+	UserID      uint       `json:"user_id"`
+	Type        string     `json:"type"`
+	Status      string     `json:"status"`
+	Error       string     `json:"error,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// AuditEntry is one record of the audit log
+type AuditEntry struct {
+	ID          uint            `json:"id"`
+	Sequence    uint64          `json:"sequence"`
+	ActorID     uint            `json:"actor_id"`
+	Action      string          `json:"action"`
+	TargetType  string          `json:"target_type"`
+	TargetID    string          `json:"target_id"`
+	RequestID   string          `json:"request_id"`
+	Before      json.RawMessage `json:"before,omitempty"`
+	After       json.RawMessage `json:"after,omitempty"`
+	Changes     json.RawMessage `json:"changes,omitempty"`
+	PayloadHash string          `json:"payload_hash"`
+	Redacted    bool            `json:"redacted"`
+	PrevHash    string          `json:"prev_hash"`
+	Hash        string          `json:"hash"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// AuditFilter narrows ListAuditEntries; zero fields are not sent
+type AuditFilter struct {
+	ActorID    uint
+	Action     string
+	TargetType string
+	TargetID   string
+	RequestID  string
+	From       time.Time
+	To         time.Time
+	Limit      int
+	Offset     int
+}
+
+// AuditPage is one page of ListAuditEntries
+type AuditPage struct {
+	Data   []*AuditEntry `json:"data"`
+	Total  int64         `json:"total"`
+	Limit  int           `json:"limit"`
+	Offset int           `json:"offset"`
+}
+
+// AuditChainReport is the result of VerifyAuditChain
+type AuditChainReport struct {
+	Valid          bool   `json:"valid"`
+	EntriesChecked int64  `json:"entries_checked"`
+	FirstBroken    uint64 `json:"first_broken_sequence,omitempty"`
+	Reason         string `json:"reason,omitempty"`
+}
+
+// HealthCheck is the result of one readiness check
+type HealthCheck struct {
+	Status     string  `json:"status"`
+	Error      string  `json:"error,omitempty"`
+	DurationMs float64 `json:"duration_ms"`
+}
+
+// HealthReport is the body of the readiness probe
+type HealthReport struct {
+	Status    string                 `json:"status"`
+	Checks    map[string]HealthCheck `json:"checks"`
+	CheckedAt time.Time              `json:"checked_at"`
+}
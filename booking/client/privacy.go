@@ -0,0 +1,76 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// privacyRequestEnvelope is the body of the single-request routes
+type privacyRequestEnvelope struct {
+	Data *PrivacyRequest `json:"data"`
+}
+
+// RequestExport handles POST /api/v1/me/exports
+// The export is built in the background; poll GetPrivacyRequest until it is
+// completed, then call DownloadExport.
+func (c *Client) RequestExport(ctx context.Context) (*PrivacyRequest, error) {
+	var out privacyRequestEnvelope
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/api/v1/me/exports"}, &out); err != nil {
+		return nil, err
+	}
+	return out.Data, nil
+}
+
+// DownloadExport handles GET /api/v1/me/exports/:id/download, copying the ZIP archive to w
+func (c *Client) DownloadExport(ctx context.Context, id uint, w io.Writer) (int64, error) {
+	path := privacyPath(id, "/api/v1/me/exports/") + "/download"
+	resp, err := c.send(ctx, request{method: http.MethodGet, path: path})
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	n, err := io.Copy(w, resp.Body)
+	if err != nil {
+		return n, fmt.Errorf("download export %d: %w", id, err)
+	}
+	return n, nil
+}
+
+// RequestErasure handles POST /api/v1/me/erasure
+// The account is anonymized once the grace period has passed.
+func (c *Client) RequestErasure(ctx context.Context) (*PrivacyRequest, error) {
+	var out privacyRequestEnvelope
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/api/v1/me/erasure"}, &out); err != nil {
+		return nil, err
+	}
+	return out.Data, nil
+}
+
+// ListPrivacyRequests handles GET /api/v1/me/privacy-requests
+func (c *Client) ListPrivacyRequests(ctx context.Context) ([]*PrivacyRequest, error) {
+	var out struct {
+		Data []*PrivacyRequest `json:"data"`
+	}
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/me/privacy-requests"}, &out); err != nil {
+		return nil, err
+	}
+	return out.Data, nil
+}
+
+// GetPrivacyRequest handles GET /api/v1/me/privacy-requests/:id
+func (c *Client) GetPrivacyRequest(ctx context.Context, id uint) (*PrivacyRequest, error) {
+	var out privacyRequestEnvelope
+	if err := c.do(ctx, request{method: http.MethodGet, path: privacyPath(id, "/api/v1/me/privacy-requests/")}, &out); err != nil {
+		return nil, err
+	}
+	return out.Data, nil
+}
+
+// privacyPath appends a request ID to prefix
+func privacyPath(id uint, prefix string) string {
+	return prefix + strconv.FormatUint(uint64(id), 10)
+}
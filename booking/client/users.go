@@ -0,0 +1,107 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// userEnvelope is the body of the single-user routes
+type userEnvelope struct {
+	Data *User `json:"data"`
+}
+
+// CreateUser handles POST /api/v1/users
+func (c *Client) CreateUser(ctx context.Context, input CreateUserInput) (*User, error) {
+	var out userEnvelope
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/api/v1/users", body: input}, &out); err != nil {
+		return nil, err
+	}
+	return out.Data, nil
+}
+
+// Signup handles POST /api/v1/signup
+// It needs no credentials; the new account gets the runner role.
+func (c *Client) Signup(ctx context.Context, input CreateUserInput) (*User, error) {
+	var out userEnvelope
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/api/v1/signup", body: input}, &out); err != nil {
+		return nil, err
+	}
+	return out.Data, nil
+}
+
+// GetUser handles GET /api/v1/users/:id
+func (c *Client) GetUser(ctx context.Context, id uint) (*User, error) {
+	var out userEnvelope
+	if err := c.do(ctx, request{method: http.MethodGet, path: userPath(id)}, &out); err != nil {
+		return nil, err
+	}
+	return out.Data, nil
+}
+
+// ListUsers handles GET /api/v1/users
+// Pass the NextCursor of the returned page as filter.Cursor to fetch the next one.
+func (c *Client) ListUsers(ctx context.Context, filter UserFilter) (*UserPage, error) {
+	query := url.Values{}
+	setString(query, "email", filter.Email)
+	setString(query, "username", filter.Username)
+	setString(query, "full_name", filter.FullName)
+	setString(query, "phone", filter.Phone)
+	if filter.IsActive != nil {
+		query.Set("is_active", strconv.FormatBool(*filter.IsActive))
+	}
+	setString(query, "q", filter.Search)
+	setTime(query, "created_from", filter.CreatedFrom)
+	setTime(query, "created_to", filter.CreatedTo)
+	setString(query, "sort", filter.Sort)
+	setString(query, "cursor", filter.Cursor)
+	setInt(query, "limit", filter.Limit)
+
+	var page UserPage
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/users", query: query}, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// UpdateUser handles PUT /api/v1/users/:id
+func (c *Client) UpdateUser(ctx context.Context, id uint, input UpdateUserInput) (*User, error) {
+	var out userEnvelope
+	if err := c.do(ctx, request{method: http.MethodPut, path: userPath(id), body: input}, &out); err != nil {
+		return nil, err
+	}
+	return out.Data, nil
+}
+
+// DeleteUser handles DELETE /api/v1/users/:id
+func (c *Client) DeleteUser(ctx context.Context, id uint) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: userPath(id)}, nil)
+}
+
+// userPath returns the path of one user
+func userPath(id uint) string {
+	return "/api/v1/users/" + strconv.FormatUint(uint64(id), 10)
+}
+
+// setString adds a query parameter unless value is empty
+func setString(query url.Values, name, value string) {
+	if value != "" {
+		query.Set(name, value)
+	}
+}
+
+// setInt adds a query parameter unless value is zero
+func setInt(query url.Values, name string, value int) {
+	if value != 0 {
+		query.Set(name, strconv.Itoa(value))
+	}
+}
+
+// setTime adds an RFC 3339 query parameter unless value is zero
+func setTime(query url.Values, name string, value time.Time) {
+	if !value.IsZero() {
+		query.Set(name, value.Format(time.RFC3339))
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"booking/delivery/userfile"
+	"booking/domain/entity"
+)
+
+// importUsers handles: admin import -file [-format] [-dry-run]
+func importUsers(args []string) (action, error) {
+	fs := newFlagSet("import")
+	file := fs.String("file", "", "CSV or NDJSON file to import, - for stdin")
+	formatName := fs.String("format", "", "csv or ndjson; by default taken from the file extension")
+	dryRun := fs.Bool("dry-run", false, "only validate the rows")
+	if err := parse(fs, args, "file"); err != nil {
+		return nil, err
+	}
+	format, err := fileFormat(fs, *formatName, *file)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, e *env) (*result, error) {
+		in := io.Reader(os.Stdin)
+		if *file != "-" {
+			f, err := os.Open(*file)
+			if err != nil {
+				return nil, err
+			}
+			defer f.Close()
+			in = f
+		}
+
+		source, err := userfile.NewReader(in, format)
+		if err != nil {
+			return nil, err
+		}
+		report, err := e.users.ImportUsers(ctx, source, *dryRun)
+		if err != nil {
+			return nil, fmt.Errorf("import: %w", err)
+		}
+
+		errs := make(lines, 0, len(report.Errors))
+		for _, rowErr := range report.Errors {
+			errs = append(errs, fmt.Sprintf("row %d: %s", rowErr.Row, rowErr.Error))
+		}
+		return &result{fields: []field{
+			{name: "dry_run", value: report.DryRun},
+			{name: "rows", value: report.Rows},
+			{name: "valid", value: report.Valid},
+			{name: "created", value: report.Created},
+			{name: "failed", value: report.Failed},
+			{name: "errors", value: errs},
+		}}, nil
+	}, nil
+}
+
+// exportUsers handles: admin export [-file] [-format] [-active] [-q] [-sort]
+func exportUsers(args []string) (action, error) {
+	fs := newFlagSet("export")
+	file := fs.String("file", "-", "file to write, - for stdout")
+	formatName := fs.String("format", "", "csv or ndjson; by default taken from the file extension, csv for stdout")
+	active := fs.String("active", "", "only active (true) or deactivated (false) users")
+	search := fs.String("q", "", "prefix of username or email, or the whole full name")
+	sort := fs.String("sort", entity.UserSortCreatedAt, "sort field; a leading - sorts descending")
+	if err := parse(fs, args); err != nil {
+		return nil, err
+	}
+	format, err := fileFormat(fs, *formatName, *file)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := &entity.UserFilter{
+		SortBy:   strings.TrimPrefix(*sort, "-"),
+		SortDesc: strings.HasPrefix(*sort, "-"),
+	}
+	if !entity.IsUserSortField(filter.SortBy) {
+		return nil, usageError(fs, "-sort must be one of created_at, username, email")
+	}
+	if *active != "" {
+		isActive, err := strconv.ParseBool(*active)
+		if err != nil {
+			return nil, usageError(fs, "-active must be true or false")
+		}
+		filter.IsActive = &isActive
+	}
+	if *search != "" {
+		filter.Search = search
+	}
+
+	return func(ctx context.Context, e *env) (*result, error) {
+		out := io.Writer(os.Stdout)
+		if *file != "-" {
+			f, err := os.Create(*file)
+			if err != nil {
+				return nil, err
+			}
+			defer f.Close()
+			out = f
+		}
+
+		writer := userfile.NewWriter(out, format)
+		exported, err := e.users.ExportUsers(ctx, filter, writer.Write)
+		if err == nil {
+			err = writer.Flush()
+		}
+		if err != nil {
+			if *file != "-" {
+				os.Remove(*file)
+			}
+			return nil, fmt.Errorf("export: %w", err)
+		}
+
+		// The users themselves are the output on stdout
+		if *file == "-" {
+			return nil, nil
+		}
+		return &result{fields: []field{
+			{name: "file", value: *file},
+			{name: "exported", value: exported},
+		}}, nil
+	}, nil
+}
+
+// fileFormat is the -format flag, or the format matching the file extension
+func fileFormat(fs *flag.FlagSet, name, file string) (userfile.Format, error) {
+	if name == "" {
+		switch strings.ToLower(filepath.Ext(file)) {
+		case ".ndjson", ".jsonl":
+			return userfile.NDJSON, nil
+		case ".csv", "":
+			return userfile.CSV, nil
+		}
+		return "", usageError(fs, "-format is required for "+file)
+	}
+	format, err := userfile.ParseFormat(name)
+	if err != nil {
+		return "", usageError(fs, err.Error())
+	}
+	return format, nil
+}
@@ -0,0 +1,296 @@
+// Command admin runs operational tasks directly against the database.
+//
+// It loads the same configuration as the API and goes through the same use
+// cases, so validation, password hashing and the audit log behave as they do
+// for HTTP requests. Changes are audited with actor 0 and a request ID of
+// the form cli-<operator>-<random>.
+//
+//	admin [config flags] [-output table|json] [-operator name] <command> [command flags]
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/user"
+	"sort"
+	"strings"
+	"time"
+
+	"booking/config"
+	"booking/domain/identity"
+	"booking/infrastructure/auth"
+	"booking/infrastructure/cache"
+	"booking/infrastructure/database"
+	"booking/infrastructure/logging"
+	"booking/infrastructure/observer"
+	"booking/usecase/audit"
+	"booking/usecase/role"
+	userusecase "booking/usecase/user"
+)
+
+// env holds what commands need
+type env struct {
+	users userusecase.UserUseCase
+	roles role.RoleUseCase
+	audit audit.AuditUseCase
+	// tokens signs bearer tokens; nil when AUTH_TOKEN_SECRET is unset
+	tokens *auth.Tokens
+}
+
+// command is one subcommand
+type command struct {
+	summary string
+	// parse checks the arguments before anything connects to the database
+	parse func(args []string) (action, error)
+	// writes reports that the command changes users, which the API may have cached
+	writes bool
+}
+
+// action does the work of a parsed command and returns the result to print
+type action func(ctx context.Context, e *env) (*result, error)
+
+// commands are the available subcommands by name
+var commands = map[string]command{
+	"create-admin":   {summary: "create a user with the admin role", parse: createAdmin, writes: true},
+	"reset-password": {summary: "set or generate a new password for a user", parse: resetPassword, writes: true},
+	"lock":           {summary: "deactivate a user, with a reason for the audit log", parse: lockUser, writes: true},
+	"unlock":         {summary: "reactivate a user, with a reason for the audit log", parse: unlockUser, writes: true},
+	"show":           {summary: "show a user and their roles", parse: showUser},
+	"import":         {summary: "create users from a CSV or NDJSON file", parse: importUsers, writes: true},
+	"export":         {summary: "write users to a CSV or NDJSON file", parse: exportUsers},
+	"issue-token":    {summary: "sign a bearer token for a user with AUTH_TOKEN_SECRET", parse: issueToken},
+}
+
+// unavailable are commands asked for but not built yet, with what they wait on
+// This service has no wallet, runs or outbox, so there is nothing for them to go through.
+var unavailable = map[string]string{
+	"adjust-wallet":  "adjust a wallet balance with a reason; needs the wallet use case",
+	"reprocess-runs": "reprocess held runs; needs the runs use case",
+	"outbox":         "list and replay failed outbox events; needs the event outbox",
+}
+
+// errUsage reports invalid arguments; main exits with 2
+var errUsage = errors.New("usage")
+
+func main() {
+	output := flag.String("output", "table", "output format: table or json")
+	operator := flag.String("operator", currentUser(), "who is running the command, recorded in the audit log request ID")
+	configFlags := config.RegisterFlags(flag.CommandLine)
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[flag.Arg(0)]
+	if !ok {
+		if missing, planned := unavailable[flag.Arg(0)]; planned {
+			fmt.Fprintf(os.Stderr, "command %q is not available yet: %s\n", flag.Arg(0), missing)
+			os.Exit(2)
+		}
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+	if *output != "table" && *output != "json" {
+		fmt.Fprintf(os.Stderr, "invalid -output %q, expected table or json\n", *output)
+		os.Exit(2)
+	}
+
+	act, err := cmd.parse(flag.Args()[1:])
+	if err != nil {
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load(configFlags)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	// Logs go to stderr so stdout carries only the result
+	if _, err := logging.SetupWriter(os.Stderr, cfg.Logging); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	logger := logging.For("admin")
+
+	code := run(cfg, cmd, act, *output, *operator, logger)
+	os.Exit(code)
+}
+
+// run wires the use cases, runs act and prints its result; it returns the exit code
+func run(cfg *config.Config, cmd command, act action, output, operator string, logger *slog.Logger) int {
+	// Repository events are audited like those of the API
+	subject := observer.NewSubject()
+	dbFactory := database.NewDatabaseFactory(cfg, subject)
+	defer dbFactory.Close()
+
+	userRepo, err := dbFactory.CreateUserRepository()
+	if err != nil {
+		logger.Error("failed to create user repository", slog.Any("error", err))
+		return 1
+	}
+	roleRepo, err := dbFactory.CreateRoleRepository()
+	if err != nil {
+		logger.Error("failed to create role repository", slog.Any("error", err))
+		return 1
+	}
+	auditRepo, err := dbFactory.CreateAuditRepository()
+	if err != nil {
+		logger.Error("failed to create audit repository", slog.Any("error", err))
+		return 1
+	}
+	subject.Attach(observer.NewAuditObserver(auditRepo))
+
+	// Instances sharing a Redis cache would keep serving users changed here until the TTL expires
+	switch cache.StoreType(cfg.Cache.Store) {
+	case cache.RedisStoreType:
+		cacheStore, err := cache.NewStore(cfg.Cache)
+		if err != nil {
+			logger.Error("failed to create cache store", slog.Any("error", err))
+			return 1
+		}
+		defer cacheStore.Close()
+		encryptor, err := dbFactory.FieldEncryptor()
+		if err != nil {
+			logger.Error("failed to load PII encryption keys", slog.Any("error", err))
+			return 1
+		}
+		cachedUsers := cache.NewUserRepository(userRepo, cacheStore, encryptor,
+			cache.WithTTL(cfg.Cache.TTL),
+			cache.WithNegativeTTL(cfg.Cache.NegativeTTL),
+			cache.WithNotFoundError(dbFactory.NotFoundError()),
+		)
+		subject.Attach(cachedUsers.Invalidator())
+		userRepo = cachedUsers
+	case cache.MemoryStoreType:
+		// Each API instance caches users in its own memory, out of reach of this process
+		if cmd.writes {
+			logger.Warn("running API instances may serve the previous state of changed users until their cache entries expire",
+				slog.String("cache_store", cfg.Cache.Store),
+				slog.Duration("cache_ttl", cfg.Cache.TTL),
+			)
+		}
+	}
+
+	auditUseCase := audit.NewAuditUseCase(auditRepo)
+	e := &env{
+		users: userusecase.NewUserUseCase(
+			userRepo,
+			userusecase.NewBcryptHasher(10),
+			userusecase.WithEmailValidation(true),
+			userusecase.WithPasswordValidation(true),
+			userusecase.WithPasswordLength(8, 72),
+			userusecase.WithAuditRecorder(auditUseCase),
+			// Files too large for one HTTP request are imported here
+			userusecase.WithImportLimits(cfg.Users.ImportBatchSize, 0),
+		),
+		roles: role.NewRoleUseCase(roleRepo, userRepo),
+		audit: auditUseCase,
+	}
+	if cfg.Auth.TokenSecret != "" {
+		e.tokens = auth.NewTokens(cfg.Auth.TokenSecret)
+	}
+
+	ctx := identity.WithRequestID(context.Background(), requestID(operator))
+	res, err := act(ctx, e)
+
+	// Audit entries are written by observers; wait for them before exiting
+	closeCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if closeErr := subject.Close(closeCtx); closeErr != nil {
+		logger.Warn("audit entries may be missing", slog.Any("error", closeErr))
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	if res == nil {
+		return 0
+	}
+	if err := res.write(os.Stdout, output); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	return 0
+}
+
+// usage prints the commands and global flags
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintln(out, "usage: admin [flags] <command> [command flags]")
+	fmt.Fprintln(out, "\ncommands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(out, "  %-16s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintln(out, "\nnot available yet:")
+	names = names[:0]
+	for name := range unavailable {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(out, "  %-16s %s\n", name, unavailable[name])
+	}
+	fmt.Fprintln(out, "\nRun admin <command> -h for the flags of a command. Global flags:")
+	flag.PrintDefaults()
+}
+
+// newFlagSet creates the flag set of a command, printing its usage on errors
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: admin %s [flags]\n", name)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parse parses the flags of a command and checks the required ones are set
+func parse(fs *flag.FlagSet, args []string, required ...string) error {
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	for _, name := range required {
+		if strings.TrimSpace(fs.Lookup(name).Value.String()) == "" {
+			return usageError(fs, "-"+name+" is required")
+		}
+	}
+	return nil
+}
+
+// usageError prints why the flags of a command are invalid, then its usage
+func usageError(fs *flag.FlagSet, message string) error {
+	fmt.Fprintln(fs.Output(), message)
+	fs.Usage()
+	return errUsage
+}
+
+// requestID identifies the changes of one run in the audit log
+func requestID(operator string) string {
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return fmt.Sprintf("cli-%s-%s", operator, hex.EncodeToString(suffix))
+}
+
+// currentUser is the default operator name
+func currentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return "unknown"
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// field is one named value of a result, in display order
+type field struct {
+	name  string
+	value interface{}
+}
+
+// lines is a list shown one item per line in tables, e.g. the errors of an import
+type lines []string
+
+// result is what a command prints: one record of fields
+type result struct {
+	fields []field
+}
+
+// write prints the result as a two-column table or as a JSON object
+func (r *result) write(w io.Writer, format string) error {
+	if format == "json" {
+		data, err := r.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", data)
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, f := range r.fields {
+		if items, ok := f.value.(lines); ok && len(items) > 0 {
+			for i, item := range items {
+				name := ""
+				if i == 0 {
+					name = strings.ToUpper(f.name)
+				}
+				fmt.Fprintf(tw, "%s\t%s\n", name, item)
+			}
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", strings.ToUpper(f.name), formatValue(f.value))
+	}
+	return tw.Flush()
+}
+
+// MarshalJSON writes the fields as an object, keeping their order
+func (r *result) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, f := range r.fields {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		name, _ := json.Marshal(f.name)
+		value, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, fmt.Errorf("encode %s: %w", f.name, err)
+		}
+		b.Write(name)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// formatValue renders a value for the table
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	case lines:
+		return formatValue([]string(v))
+	case []string:
+		if len(v) == 0 {
+			return "-"
+		}
+		return strings.Join(v, ", ")
+	case string:
+		if v == "" {
+			return "-"
+		}
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"booking/domain/entity"
+)
+
+// createAdmin handles: admin create-admin -email -username [-full-name] [-password-stdin]
+func createAdmin(args []string) (action, error) {
+	fs := newFlagSet("create-admin")
+	email := fs.String("email", "", "email of the new user")
+	username := fs.String("username", "", "username of the new user")
+	fullName := fs.String("full-name", "", "full name of the new user")
+	passwordStdin := fs.Bool("password-stdin", false, "read the password from the first line of stdin instead of generating one")
+	if err := parse(fs, args, "email", "username"); err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, e *env) (*result, error) {
+		password, generated, err := newPassword(*passwordStdin)
+		if err != nil {
+			return nil, err
+		}
+
+		// The API seeds the roles on startup, but this may run before it ever did
+		if err := e.roles.SeedDefaultRoles(ctx); err != nil {
+			return nil, fmt.Errorf("seed roles: %w", err)
+		}
+
+		u := &entity.User{
+			Email:    *email,
+			Username: *username,
+			Password: password,
+			FullName: *fullName,
+			IsActive: true,
+		}
+		if err := e.users.CreateUser(ctx, u); err != nil {
+			return nil, fmt.Errorf("create user: %w", err)
+		}
+		if err := e.roles.BootstrapAdmin(ctx, u.Email); err != nil {
+			return nil, fmt.Errorf("user %d was created but not granted the admin role: %w", u.ID, err)
+		}
+
+		return userResult(ctx, e, u, generatedPassword(generated, password))
+	}, nil
+}
+
+// resetPassword handles: admin reset-password -user [-password-stdin]
+func resetPassword(args []string) (action, error) {
+	fs := newFlagSet("reset-password")
+	ref := fs.String("user", "", "user ID, email or username")
+	passwordStdin := fs.Bool("password-stdin", false, "read the password from the first line of stdin instead of generating one")
+	if err := parse(fs, args, "user"); err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, e *env) (*result, error) {
+		u, err := findUser(ctx, e, *ref)
+		if err != nil {
+			return nil, err
+		}
+		password, generated, err := newPassword(*passwordStdin)
+		if err != nil {
+			return nil, err
+		}
+
+		u.Password = password
+		if err := e.users.UpdateUser(ctx, u); err != nil {
+			return nil, fmt.Errorf("update user: %w", err)
+		}
+		return userResult(ctx, e, u, generatedPassword(generated, password))
+	}, nil
+}
+
+// lockUser handles: admin lock -user -reason
+func lockUser(args []string) (action, error) {
+	return setActive("lock", false, args)
+}
+
+// unlockUser handles: admin unlock -user -reason
+func unlockUser(args []string) (action, error) {
+	return setActive("unlock", true, args)
+}
+
+// setActive deactivates or reactivates a user and records why in the audit log
+func setActive(name string, active bool, args []string) (action, error) {
+	fs := newFlagSet(name)
+	ref := fs.String("user", "", "user ID, email or username")
+	reason := fs.String("reason", "", "why, recorded in the audit log")
+	if err := parse(fs, args, "user", "reason"); err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, e *env) (*result, error) {
+		u, err := findUser(ctx, e, *ref)
+		if err != nil {
+			return nil, err
+		}
+		if u.IsActive != active {
+			u.IsActive = active
+			if err := e.users.UpdateUser(ctx, u); err != nil {
+				return nil, fmt.Errorf("update user: %w", err)
+			}
+		}
+
+		// user.updated shows the change; this entry adds the reason
+		action := "user." + name + "ed"
+		targetID := strconv.FormatUint(uint64(u.ID), 10)
+		if err := e.audit.Record(ctx, action, "user", targetID, nil, map[string]string{"reason": *reason}); err != nil {
+			return nil, fmt.Errorf("record %s: %w", action, err)
+		}
+		return userResult(ctx, e, u)
+	}, nil
+}
+
+// showUser handles: admin show -user
+func showUser(args []string) (action, error) {
+	fs := newFlagSet("show")
+	ref := fs.String("user", "", "user ID, email or username")
+	if err := parse(fs, args, "user"); err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, e *env) (*result, error) {
+		u, err := findUser(ctx, e, *ref)
+		if err != nil {
+			return nil, err
+		}
+		return userResult(ctx, e, u)
+	}, nil
+}
+
+// findUser looks a user up by ID, email (contains @) or username
+func findUser(ctx context.Context, e *env, ref string) (*entity.User, error) {
+	var (
+		u   *entity.User
+		err error
+	)
+	if id, parseErr := strconv.ParseUint(ref, 10, 32); parseErr == nil {
+		u, err = e.users.GetUserByID(ctx, uint(id))
+	} else if strings.Contains(ref, "@") {
+		u, err = e.users.GetUserByEmail(ctx, ref)
+	} else {
+		u, err = e.users.GetUserByUsername(ctx, ref)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find user %q: %w", ref, err)
+	}
+	return u, nil
+}
+
+// newPassword reads a password from stdin or generates one
+func newPassword(fromStdin bool) (password string, generated bool, err error) {
+	if fromStdin {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", false, fmt.Errorf("read password from stdin: %w", err)
+		}
+		return strings.TrimRight(line, "\r\n"), false, nil
+	}
+
+	// 18 random bytes are 24 URL-safe characters
+	raw := make([]byte, 18)
+	if _, err := rand.Read(raw); err != nil {
+		return "", false, fmt.Errorf("generate password: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), true, nil
+}
+
+// generatedPassword is the extra output field showing a generated password, once
+func generatedPassword(generated bool, password string) []field {
+	if !generated {
+		return nil
+	}
+	return []field{{name: "password", value: password}}
+}
+
+// userResult renders a user with their role names and any extra fields
+func userResult(ctx context.Context, e *env, u *entity.User, extra ...[]field) (*result, error) {
+	roles, err := e.roles.GetUserRoles(ctx, u.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get roles: %w", err)
+	}
+	names := make([]string, 0, len(roles))
+	for _, r := range roles {
+		names = append(names, r.Name)
+	}
+
+	fields := []field{
+		{name: "id", value: u.ID},
+		{name: "email", value: u.Email},
+		{name: "username", value: u.Username},
+		{name: "full_name", value: u.FullName},
+		{name: "is_active", value: u.IsActive},
+		{name: "roles", value: names},
+		{name: "created_at", value: u.CreatedAt},
+	}
+	for _, more := range extra {
+		fields = append(fields, more...)
+	}
+	return &result{fields: fields}, nil
+}
@@ -1,52 +1,201 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"flag"
 	"fmt"
-	"log"
+	"log/slog"
+	"os"
+	"time"
 
 	"booking/config"
+	"booking/delivery/consistency"
+	"booking/delivery/graphql"
+	"booking/delivery/grpc"
 	"booking/delivery/http"
 	"booking/delivery/http/handler"
+	"booking/delivery/http/middleware"
+	"booking/infrastructure/auth"
+	"booking/infrastructure/cache"
 	"booking/infrastructure/database"
+	"booking/infrastructure/health"
+	"booking/infrastructure/lifecycle"
+	"booking/infrastructure/logging"
+	"booking/infrastructure/metrics"
 	"booking/infrastructure/observer"
+	"booking/infrastructure/ratelimit"
+	"booking/infrastructure/tracing"
+	"booking/usecase/audit"
+	"booking/usecase/idempotency"
+	"booking/usecase/privacy"
+	"booking/usecase/role"
 	"booking/usecase/user"
+
+	"github.com/gin-gonic/gin"
 )
 
 func main() {
-	// Load configuration
-	cfg, err := config.Load()
+	// Subcommands that print something and exit:
+	//   config print  the effective configuration, secrets redacted
+	//   openapi       the OpenAPI document, after checking it covers every route
+	var command string
+	switch {
+	case len(os.Args) > 2 && os.Args[1] == "config" && os.Args[2] == "print":
+		command = "config print"
+		os.Args = append(os.Args[:1], os.Args[3:]...)
+	case len(os.Args) > 1 && os.Args[1] == "openapi":
+		command = "openapi"
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+	}
+
+	// Load configuration: defaults < config file < environment < flags
+	configFlags := config.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+	cfg, err := config.Load(configFlags)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	switch command {
+	case "config print":
+		if err := config.WriteYAML(os.Stdout, cfg); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	case "openapi":
+		if err := writeOpenAPI(cfg); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Structured JSON logs with per-component levels
+	if _, err := logging.Setup(cfg.Logging); err != nil {
+		slog.Error("failed to configure logging", slog.Any("error", err))
+		os.Exit(1)
+	}
+	logger := logging.For("main")
+
+	logger.Info("starting", slog.String("database_type", string(cfg.DatabaseType)))
+
+	// The lifecycle stops what is registered below in reverse order on SIGINT/SIGTERM
+	app := lifecycle.New(cfg.Server.ShutdownTimeout, logger)
+	fatal := func(msg string, err error) {
+		logger.Error(msg, slog.Any("error", err))
+		_ = app.Shutdown()
+		os.Exit(1)
+	}
+
+	// OpenTelemetry tracing with W3C trace context propagation
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
 	if err != nil {
-		log.Fatal("Failed to load config:", err)
+		fatal("failed to configure tracing", err)
 	}
+	// Registered first so spans of the other shutdown steps are still flushed
+	app.OnStop("tracing", shutdownTracing)
 
-	fmt.Printf("🔧 Database Type: %s\n", cfg.DatabaseType)
+	// Prometheus metrics; nil disables instrumentation
+	var appMetrics *metrics.Metrics
+	if cfg.Metrics.Enabled {
+		appMetrics = metrics.New(metrics.NewRegistry())
+	}
 
 	// Initialize Observer Pattern
 	subject := observer.NewSubject()
+	if err := appMetrics.ObserveQueue("observer", subject); err != nil {
+		fatal("failed to register observer queue metric", err)
+	}
 
 	// Attach observers
-	logger := observer.NewUserEventLogger()
+	eventLogger := observer.NewUserEventLogger()
 	notifier := observer.NewUserEventNotifier()
-	subject.Attach(logger)
+	subject.Attach(eventLogger)
 	subject.Attach(notifier)
+	subject.Attach(observer.NewMetricsObserver(appMetrics))
+
+	logger.Info("observers attached")
 
-	fmt.Println("✅ Observers attached")
+	// User lookup cache; created before the database so it is closed after observers stop invalidating
+	var cacheStore cache.Store
+	if cfg.Cache.Store != string(cache.OffStoreType) {
+		cacheStore, err = cache.NewStore(cfg.Cache)
+		if err != nil {
+			fatal("failed to create cache store", err)
+		}
+		app.OnStop("cache store", func(context.Context) error {
+			return cacheStore.Close()
+		})
+	}
 
 	// Initialize Database Factory (Factory Pattern for Database Selection)
-	dbFactory := database.NewDatabaseFactory(cfg, subject)
-	defer dbFactory.Close()
+	dbFactory := database.NewDatabaseFactory(cfg, subject, database.WithMetrics(appMetrics))
+	app.OnStop("database", func(context.Context) error {
+		return dbFactory.Close()
+	})
+	// Observers write to the database, so they are drained before it closes
+	app.OnStop("observers", subject.Close)
 
 	// Create user repository using factory
 	userRepo, err := dbFactory.CreateUserRepository()
 	if err != nil {
-		log.Fatal("Failed to create user repository:", err)
+		fatal("failed to create user repository", err)
+	}
+
+	roleRepo, err := dbFactory.CreateRoleRepository()
+	if err != nil {
+		fatal("failed to create role repository", err)
+	}
+
+	auditRepo, err := dbFactory.CreateAuditRepository()
+	if err != nil {
+		fatal("failed to create audit repository", err)
+	}
+
+	privacyRepo, err := dbFactory.CreatePrivacyRequestRepository()
+	if err != nil {
+		fatal("failed to create privacy request repository", err)
+	}
+
+	idempotencyRepo, err := dbFactory.CreateIdempotencyRepository()
+	if err != nil {
+		fatal("failed to create idempotency repository", err)
 	}
 
-	fmt.Printf("✅ Database connected successfully (%s)\n", dbFactory.GetDatabaseType())
+	// Every repository event is appended to the audit log
+	subject.Attach(observer.NewAuditObserver(auditRepo))
+
+	logger.Info("database connected", slog.String("database_type", string(dbFactory.GetDatabaseType())))
+
+	// Decorator Pattern: user lookups go through the cache; writes and user events invalidate it
+	if cacheStore != nil {
+		// Cached PII is sealed with the same keys as the database columns
+		encryptor, err := dbFactory.FieldEncryptor()
+		if err != nil {
+			fatal("failed to load PII encryption keys", err)
+		}
+		cachedUsers := cache.NewUserRepository(userRepo, cacheStore, encryptor,
+			cache.WithTTL(cfg.Cache.TTL),
+			cache.WithNegativeTTL(cfg.Cache.NegativeTTL),
+			cache.WithNotFoundError(dbFactory.NotFoundError()),
+			cache.WithMetrics(appMetrics),
+		)
+		subject.Attach(cachedUsers.Invalidator())
+		userRepo = cachedUsers
+
+		logger.Info("user cache configured", slog.String("store", cfg.Cache.Store))
+	}
 
 	// Initialize password hasher (Strategy Pattern)
 	passwordHasher := user.NewBcryptHasher(10)
 
+	auditUseCase := audit.NewAuditUseCase(auditRepo)
+
+	roleUseCase := role.NewRoleUseCase(roleRepo, userRepo)
+
 	// Initialize use cases with Functional Options Pattern
 	userUseCase := user.NewUserUseCase(
 		userRepo,
@@ -54,31 +203,179 @@ func main() {
 		user.WithEmailValidation(true),
 		user.WithPasswordValidation(true),
 		user.WithPasswordLength(8, 72),
+		user.WithAuditRecorder(auditUseCase),
+		user.WithImportLimits(cfg.Users.ImportBatchSize, cfg.Users.ImportMaxRows),
+		// Users who sign up themselves become runners
+		user.WithRoleGranter(roleUseCase),
+	)
+
+	// Seed default roles (admin, support, runner)
+	if err := roleUseCase.SeedDefaultRoles(context.Background()); err != nil {
+		fatal("failed to seed default roles", err)
+	}
+	if cfg.Auth.BootstrapAdminEmail != "" {
+		if err := roleUseCase.BootstrapAdmin(context.Background(), cfg.Auth.BootstrapAdminEmail); err != nil {
+			logger.Warn("could not grant bootstrap admin role", slog.Any("error", err))
+		}
+	}
+
+	// Without credentials every caller is anonymous and protected routes answer 401
+	if !auth.NewAuthenticator(cfg.Auth).Enabled() {
+		logger.Warn("no credentials are accepted: set AUTH_TOKEN_SECRET, or AUTH_TRUST_USER_HEADER behind a trusted proxy")
+	} else if cfg.Auth.TrustUserHeader {
+		logger.Info("trusting the user ID header; the proxy in front must strip it from client requests",
+			slog.String("header", cfg.Auth.UserIDHeader))
+	}
+
+	privacyUseCase := privacy.NewPrivacyUseCase(
+		privacyRepo,
+		userRepo,
+		roleRepo,
+		auditRepo,
+		privacy.WithExportDir(cfg.Privacy.ExportDir),
+		privacy.WithExportTTL(cfg.Privacy.ExportTTL),
+		privacy.WithErasureSettleDelay(cfg.Privacy.ErasureSettleDelay),
+	)
+
+	idempotencyUseCase := idempotency.NewIdempotencyUseCase(
+		idempotencyRepo,
+		idempotency.WithTTL(cfg.Idempotency.TTL),
+		idempotency.WithLockTimeout(cfg.Idempotency.LockTimeout),
 	)
 
-	fmt.Println("✅ Use cases initialized")
+	logger.Info("use cases initialized")
+
+	// Anonymize soft-deleted users once their grace period has expired
+	purgeWorker := user.NewPurgeWorker(userUseCase, cfg.Users.DeletionGracePeriod, cfg.Users.PurgeInterval)
+	app.Go("purge", purgeWorker.Run)
+
+	// Generate GDPR exports and carry out erasures asynchronously
+	privacyWorker := privacy.NewWorker(privacyUseCase, cfg.Privacy.WorkerInterval)
+	app.Go("privacy", privacyWorker.Run)
+
+	// Remove expired idempotency keys
+	idempotencyWorker := idempotency.NewCleanupWorker(idempotencyUseCase, cfg.Idempotency.CleanupInterval)
+	app.Go("idempotency", idempotencyWorker.Run)
+
+	// Readiness checks; /readyz stays 503 until migrations are verified
+	healthChecker := health.New(cfg.Health.CheckTimeout, cfg.Health.CacheTTL)
+	healthChecker.Register("database", dbFactory.Ping, 0)
+	healthChecker.Register("observer_backlog", health.BacklogCheck(subject, cfg.Health.MaxObserverBacklog), 0)
+	// Events leave the service through the observers, so their queue is the outbox
+	healthChecker.Register("outbox_lag", health.LagCheck(subject, cfg.Health.MaxOutboxLag), 0)
+	healthChecker.Register("exports_disk", health.DiskCheck(cfg.Privacy.ExportDir, uint64(cfg.Health.MinFreeDiskMB)<<20), 0)
+	app.Go("startup gate", func(ctx context.Context) {
+		waitForMigrations(ctx, dbFactory, healthChecker, logger)
+	})
+
+	// GraphQL limits and persisted queries; the queries of the mobile build are pinned from file
+	persistedQueries := graphql.NewPersistedQueryStore(cfg.GraphQL.PersistedCacheSize)
+	if cfg.GraphQL.PersistedQueriesFile != "" {
+		if err := persistedQueries.LoadFile(cfg.GraphQL.PersistedQueriesFile); err != nil {
+			fatal("failed to load persisted GraphQL queries", err)
+		}
+	}
 
 	// Initialize handler factory (Factory Pattern)
-	handlerFactory := handler.NewHandlerFactory(userUseCase)
+	handlerFactory := handler.NewHandlerFactory(
+		userUseCase, roleUseCase, auditUseCase, privacyUseCase, idempotencyUseCase, healthChecker,
+		handler.WithGraphQLOptions(
+			graphql.WithMaxDepth(cfg.GraphQL.MaxDepth),
+			graphql.WithMaxComplexity(cfg.GraphQL.MaxComplexity),
+			graphql.WithPersistedQueries(persistedQueries, cfg.GraphQL.PersistedOnly),
+		),
+	)
+
+	// Rate limiting: one token bucket policy per route group
+	rateLimitStore, err := ratelimit.NewStore(cfg.RateLimit)
+	if err != nil {
+		fatal("failed to create rate limit store", err)
+	}
+	app.OnStop("rate limit store", func(context.Context) error {
+		return rateLimitStore.Close()
+	})
+
+	var policies []ratelimit.Policy
+	for name, spec := range map[string]string{
+		"api":     cfg.RateLimit.API,
+		"admin":   cfg.RateLimit.Admin,
+		"privacy": cfg.RateLimit.Privacy,
+		"signup":  cfg.RateLimit.Signup,
+	} {
+		policy, err := ratelimit.ParsePolicy(name, spec)
+		if err != nil {
+			fatal("invalid rate limit policy", err)
+		}
+		policies = append(policies, policy)
+	}
+	rateLimiter := middleware.NewRateLimiter(rateLimitStore, policies...)
+
+	logger.Info("rate limiting configured", slog.String("store", cfg.RateLimit.Store))
+
+	// Actors read their own writes for a while, over HTTP and gRPC alike
+	writes := consistency.NewTracker(cfg.Database.ReadYourWritesWindow)
 
 	// Initialize router
-	router := http.NewRouter(handlerFactory)
+	router := http.NewRouter(handlerFactory, cfg, rateLimiter, appMetrics, writes)
 	router.SetupRoutes()
+	if err := router.VerifyOpenAPI(); err != nil {
+		fatal("routes and OpenAPI document disagree", err)
+	}
+
+	logger.Info("routes configured")
 
-	fmt.Println("✅ Routes configured")
+	// gRPC server for other services, backed by the same use cases
+	if cfg.GRPC.Enabled {
+		grpcServer := grpc.NewServer(userUseCase, roleUseCase, cfg, writes)
+		grpcAddr := fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.GRPC.Port)
+		app.Serve("grpc", func() error {
+			return grpcServer.ListenAndServe(grpcAddr)
+		}, grpcServer.Drain)
+
+		logger.Info("gRPC configured", slog.String("addr", grpcAddr), slog.Bool("reflection", cfg.GRPC.Reflection))
+	}
 
-	// Start server
+	// Serve until SIGINT/SIGTERM, then drain requests and stop everything in order
 	addr := fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.Server.Port)
-	fmt.Printf("🚀 Server starting on %s\n", addr)
-	fmt.Println("📚 API Documentation:")
-	fmt.Println("   - Health Check: GET /health")
-	fmt.Println("   - Create User:  POST /api/v1/users")
-	fmt.Println("   - List Users:   GET /api/v1/users")
-	fmt.Println("   - Get User:     GET /api/v1/users/:id")
-	fmt.Println("   - Update User:  PUT /api/v1/users/:id")
-	fmt.Println("   - Delete User:  DELETE /api/v1/users/:id")
-
-	if err := router.Run(addr); err != nil {
-		log.Fatal("Failed to start server:", err)
+	if err := app.Run(router.Server(addr)); err != nil {
+		logger.Error("shutdown incomplete", slog.Any("error", err))
+		os.Exit(1)
 	}
+	logger.Info("shutdown complete")
+}
+
+// waitForMigrations opens the readiness gate once the schema is verified, retrying until ctx is done
+func waitForMigrations(ctx context.Context, dbFactory *database.DatabaseFactory, healthChecker *health.Health, logger *slog.Logger) {
+	for {
+		err := dbFactory.VerifyMigrations(ctx)
+		if err == nil {
+			healthChecker.MarkStarted()
+			logger.Info("migrations verified, ready for traffic")
+			return
+		}
+		logger.Warn("migrations not verified yet", slog.Any("error", err))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+// writeOpenAPI prints the OpenAPI document without connecting to any dependency
+// The routes are registered with nil use cases, which is enough to compare
+// them with the document; CI runs this to catch undocumented routes.
+func writeOpenAPI(cfg *config.Config) error {
+	// Gin's debug mode prints every route to stdout, ahead of the document
+	gin.SetMode(gin.ReleaseMode)
+	router := http.NewRouter(handler.NewHandlerFactory(nil, nil, nil, nil, nil, nil), cfg, middleware.NewRateLimiter(nil), nil, nil)
+	router.SetupRoutes()
+	if err := router.VerifyOpenAPI(); err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(router.OpenAPI())
 }
@@ -0,0 +1,70 @@
+// Command pii-reencrypt rewrites encrypted user PII under the active master key.
+//
+// Key rotation: add the new key to PII_MASTER_KEYS (keeping the old one),
+// point PII_ACTIVE_KEY_ID at it, restart the API, run this command, then
+// remove the old key. Run with -all after changing PII_BLIND_INDEX_KEY.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"booking/config"
+	"booking/infrastructure/database"
+	"booking/infrastructure/logging"
+	"booking/infrastructure/observer"
+)
+
+func main() {
+	all := flag.Bool("all", false, "rewrite every user, not only those under an older key")
+	configFlags := config.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
+	cfg, err := config.Load(configFlags)
+	if err != nil {
+		slog.Error("failed to load config", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	if _, err := logging.Setup(cfg.Logging); err != nil {
+		slog.Error("failed to configure logging", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	if err := run(cfg, *all); err != nil {
+		logging.For("pii-reencrypt").Error("re-encryption failed", slog.Any("error", err))
+		os.Exit(1)
+	}
+}
+
+// run re-encrypts the users' PII; it returns instead of exiting so the database is closed
+func run(cfg *config.Config, all bool) error {
+	logger := logging.For("pii-reencrypt")
+
+	// No observers: rotation is not a change to any user
+	dbFactory := database.NewDatabaseFactory(cfg, observer.NewSubject())
+	defer dbFactory.Close()
+
+	userRepo, err := dbFactory.CreateUserRepository()
+	if err != nil {
+		return fmt.Errorf("create user repository: %w", err)
+	}
+
+	rotator, ok := userRepo.(database.PIIRotator)
+	if !ok {
+		return fmt.Errorf("the %s user repository does not support PII re-encryption", cfg.DatabaseType)
+	}
+
+	logger.Info("re-encrypting user PII", slog.String("key_id", cfg.Encryption.ActiveKeyID), slog.Bool("all", all))
+
+	count, err := rotator.ReencryptPII(context.Background(), all)
+	if err != nil {
+		return fmt.Errorf("stopped after %d users: %w", count, err)
+	}
+
+	logger.Info("re-encryption finished", slog.Int("reencrypted", count))
+	return nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+
+	"booking/domain/entity"
+)
+
+// profile sets how much data a seed run creates
+type profile struct {
+	users   int
+	admins  int
+	support int
+	// batchSize users are inserted per transaction
+	batchSize int
+}
+
+// profiles are the volumes selectable with -profile
+var profiles = map[string]profile{
+	"minimal":   {users: 10, admins: 1, support: 1, batchSize: 10},
+	"demo":      {users: 200, admins: 2, support: 5, batchSize: 100},
+	"load-test": {users: 20000, admins: 2, support: 20, batchSize: 500},
+}
+
+// name is a name part as displayed and as used in usernames
+type name struct {
+	display string
+	ascii   string
+}
+
+// Name parts and domains generated users are made of
+var (
+	familyNames = []name{
+		{"Nguyễn", "nguyen"}, {"Trần", "tran"}, {"Lê", "le"}, {"Phạm", "pham"}, {"Hoàng", "hoang"},
+		{"Huỳnh", "huynh"}, {"Phan", "phan"}, {"Vũ", "vu"}, {"Võ", "vo"}, {"Đặng", "dang"},
+		{"Bùi", "bui"}, {"Đỗ", "do"}, {"Hồ", "ho"}, {"Ngô", "ngo"}, {"Dương", "duong"}, {"Lý", "ly"},
+	}
+	middleNames = []name{
+		{"Văn", "van"}, {"Thị", "thi"}, {"Minh", "minh"}, {"Thanh", "thanh"}, {"Ngọc", "ngoc"},
+		{"Đức", "duc"}, {"Hữu", "huu"}, {"Quốc", "quoc"}, {"Gia", "gia"}, {"Bảo", "bao"},
+	}
+	givenNames = []name{
+		{"An", "an"}, {"Bình", "binh"}, {"Châu", "chau"}, {"Dũng", "dung"}, {"Giang", "giang"},
+		{"Hà", "ha"}, {"Hải", "hai"}, {"Hạnh", "hanh"}, {"Hiếu", "hieu"}, {"Hoa", "hoa"},
+		{"Huy", "huy"}, {"Khánh", "khanh"}, {"Lan", "lan"}, {"Linh", "linh"}, {"Long", "long"},
+		{"Mai", "mai"}, {"Nam", "nam"}, {"Nhung", "nhung"}, {"Phong", "phong"}, {"Phúc", "phuc"},
+		{"Quân", "quan"}, {"Sơn", "son"}, {"Tâm", "tam"}, {"Thảo", "thao"}, {"Trang", "trang"},
+		{"Trung", "trung"}, {"Tú", "tu"}, {"Tuấn", "tuan"}, {"Vy", "vy"}, {"Yến", "yen"},
+	}
+	// Reserved example domains, so seeded addresses never reach anyone
+	emailDomains = []string{"example.com", "example.org", "example.net"}
+	// Mobile prefixes after +84
+	phonePrefixes = []string{"32", "33", "34", "35", "36", "37", "38", "39", "70", "76", "77", "78", "79", "81", "83", "84", "85", "86", "88", "89", "90", "91", "93", "94", "96", "97", "98"}
+)
+
+// seededUser is a generated user with the role it is granted
+type seededUser struct {
+	user *entity.User
+	role string
+}
+
+// generate returns the users of a profile; the same seed always gives the same users
+// Admins and support staff come first with fixed usernames (admin1, support1, ...),
+// so there are known accounts to sign in with. Everyone gets passwordHash.
+func generate(p profile, seed uint64, passwordHash string) []seededUser {
+	rng := rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15))
+
+	users := make([]seededUser, 0, p.users)
+	for i := 0; i < p.users; i++ {
+		family := familyNames[rng.IntN(len(familyNames))]
+		middle := middleNames[rng.IntN(len(middleNames))]
+		given := givenNames[rng.IntN(len(givenNames))]
+		phone := fmt.Sprintf("+84%s%07d", phonePrefixes[rng.IntN(len(phonePrefixes))], rng.IntN(10_000_000))
+		domain := emailDomains[rng.IntN(len(emailDomains))]
+		// One in twenty runners has deactivated their account
+		active := rng.IntN(20) != 0
+
+		role := entity.RoleRunner
+		username := fmt.Sprintf("%s.%s%d", given.ascii, family.ascii, i+1)
+		switch {
+		case i < p.admins:
+			role, username, domain, active = entity.RoleAdmin, fmt.Sprintf("admin%d", i+1), "example.com", true
+		case i < p.admins+p.support:
+			role, username, domain, active = entity.RoleSupport, fmt.Sprintf("support%d", i-p.admins+1), "example.com", true
+		}
+
+		users = append(users, seededUser{
+			user: &entity.User{
+				Email:    username + "@" + domain,
+				Username: username,
+				Password: passwordHash,
+				FullName: family.display + " " + middle.display + " " + given.display,
+				Phone:    phone,
+				IsActive: active,
+			},
+			role: role,
+		})
+	}
+	return users
+}
@@ -0,0 +1,189 @@
+// Command seed fills a development database with generated users.
+//
+// The data is derived from -seed, so every developer running the same
+// profile and seed gets the same users. Users are written through the
+// repositories, so observers such as the audit log see them like any other
+// new user. Users whose email or username already exists are skipped, which
+// makes it safe to run again, e.g. after an interrupted run.
+//
+//	seed [config flags] [-profile minimal|demo|load-test] [-seed n] [-password p]
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"booking/config"
+	"booking/domain/entity"
+	"booking/domain/identity"
+	"booking/domain/repository"
+	"booking/infrastructure/database"
+	"booking/infrastructure/logging"
+	"booking/infrastructure/observer"
+	"booking/usecase/role"
+	"booking/usecase/user"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"gorm.io/gorm"
+)
+
+func main() {
+	profileName := flag.String("profile", "demo", "how much data to create: "+strings.Join(profileNames(), ", "))
+	seed := flag.Uint64("seed", 1, "seed of the generated data")
+	password := flag.String("password", "password123", "password of every seeded user")
+	configFlags := config.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
+	p, ok := profiles[*profileName]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown profile %q, expected one of %s\n", *profileName, strings.Join(profileNames(), ", "))
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load(configFlags)
+	if err != nil {
+		slog.Error("failed to load config", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	if _, err := logging.Setup(cfg.Logging); err != nil {
+		slog.Error("failed to configure logging", slog.Any("error", err))
+		os.Exit(1)
+	}
+	logger := logging.For("seed")
+
+	// Repository events are audited like those of the API
+	subject := observer.NewSubject()
+	dbFactory := database.NewDatabaseFactory(cfg, subject)
+	defer dbFactory.Close()
+
+	userRepo, err := dbFactory.CreateUserRepository()
+	if err != nil {
+		logger.Error("failed to create user repository", slog.Any("error", err))
+		os.Exit(1)
+	}
+	roleRepo, err := dbFactory.CreateRoleRepository()
+	if err != nil {
+		logger.Error("failed to create role repository", slog.Any("error", err))
+		os.Exit(1)
+	}
+	auditRepo, err := dbFactory.CreateAuditRepository()
+	if err != nil {
+		logger.Error("failed to create audit repository", slog.Any("error", err))
+		os.Exit(1)
+	}
+	subject.Attach(observer.NewAuditObserver(auditRepo))
+
+	ctx := identity.WithRequestID(context.Background(), fmt.Sprintf("seed-%s-%d", *profileName, *seed))
+	if err := role.NewRoleUseCase(roleRepo, userRepo).SeedDefaultRoles(ctx); err != nil {
+		logger.Error("failed to seed roles", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	// Every user gets the same password, so it is hashed once
+	passwordHash, err := user.NewBcryptHasher(10).Hash(*password)
+	if err != nil {
+		logger.Error("failed to hash password", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	logger.Info("seeding", slog.String("profile", *profileName), slog.Uint64("seed", *seed), slog.Int("users", p.users))
+	start := time.Now()
+	created, skipped, err := seedUsers(ctx, userRepo, roleRepo, generate(p, *seed, passwordHash), p.batchSize, logger)
+
+	// Audit entries are written by observers; wait for them before exiting
+	closeCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if closeErr := subject.Close(closeCtx); closeErr != nil {
+		logger.Warn("audit entries may be missing", slog.Any("error", closeErr))
+	}
+
+	if err != nil {
+		logger.Error("seeding stopped", slog.Int("created", created), slog.Int("skipped", skipped), slog.Any("error", err))
+		os.Exit(1)
+	}
+	logger.Info("seeding finished",
+		slog.Int("created", created),
+		slog.Int("skipped", skipped),
+		slog.Duration("took", time.Since(start)),
+		slog.String("sign_in", "admin1@example.com / "+*password),
+	)
+}
+
+// seedUsers creates the users missing from the database, batchSize per transaction, and grants their roles
+// It returns how many users were created and how many already existed.
+func seedUsers(ctx context.Context, userRepo repository.UserRepository, roleRepo repository.RoleRepository, users []seededUser, batchSize int, logger *slog.Logger) (created, skipped int, err error) {
+	for start := 0; start < len(users); start += batchSize {
+		batch := users[start:min(start+batchSize, len(users))]
+
+		var missing []seededUser
+		for _, s := range batch {
+			exists, err := userExists(ctx, userRepo, s.user.Email, s.user.Username)
+			if err != nil {
+				return created, skipped, err
+			}
+			if exists {
+				skipped++
+				continue
+			}
+			missing = append(missing, s)
+		}
+		if len(missing) == 0 {
+			continue
+		}
+
+		toCreate := make([]*entity.User, len(missing))
+		for i, s := range missing {
+			toCreate[i] = s.user
+		}
+		if err := userRepo.CreateBatch(ctx, toCreate); err != nil {
+			return created, skipped, fmt.Errorf("create users: %w", err)
+		}
+		created += len(toCreate)
+
+		for _, s := range missing {
+			if err := roleRepo.AssignToUser(ctx, s.user.ID, s.role, 0); err != nil {
+				return created, skipped, fmt.Errorf("grant %s to %s: %w", s.role, s.user.Username, err)
+			}
+		}
+		logger.Info("seeded users", slog.Int("created", created), slog.Int("skipped", skipped), slog.Int("total", len(users)))
+	}
+	return created, skipped, nil
+}
+
+// userExists reports whether a user already has the email or username
+func userExists(ctx context.Context, userRepo repository.UserRepository, email, username string) (bool, error) {
+	if _, err := userRepo.GetByEmail(ctx, email); err == nil {
+		return true, nil
+	} else if !isNotFound(err) {
+		return false, err
+	}
+	if _, err := userRepo.GetByUsername(ctx, username); err == nil {
+		return true, nil
+	} else if !isNotFound(err) {
+		return false, err
+	}
+	return false, nil
+}
+
+// isNotFound reports whether err is a not-found error from either database backend
+func isNotFound(err error) bool {
+	return errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, mongo.ErrNoDocuments)
+}
+
+// profileNames lists the profiles in a stable order
+func profileNames() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -1,10 +1,7 @@
 package config
 
 import (
-	"os"
-	"strconv"
-
-	"github.com/joho/godotenv"
+	"time"
 )
 
 // DatabaseType represents the type of database to use
@@ -16,88 +13,246 @@ const (
 )
 
 // Config holds application configuration
+// Every field is loaded, in increasing precedence, from its `default` tag,
+// the config file (`yaml` path), the `env` variable (or <env>_FILE) and the
+// command-line flag named after the dotted yaml path. Fields tagged `secret`
+// are redacted when printed.
 type Config struct {
-	Server       ServerConfig
-	Database     DatabaseConfig
-	DatabaseType DatabaseType
+	Server       ServerConfig      `yaml:"server"`
+	GRPC         GRPCConfig        `yaml:"grpc"`
+	Database     DatabaseConfig    `yaml:"database"`
+	DatabaseType DatabaseType      `yaml:"database_type" env:"DB_TYPE" default:"postgres" validate:"required,oneof=postgres mongodb"`
+	Auth         AuthConfig        `yaml:"auth"`
+	Users        UsersConfig       `yaml:"users"`
+	Privacy      PrivacyConfig     `yaml:"privacy"`
+	Encryption   EncryptionConfig  `yaml:"encryption"`
+	Idempotency  IdempotencyConfig `yaml:"idempotency"`
+	RateLimit    RateLimitConfig   `yaml:"rate_limit"`
+	Cache        CacheConfig       `yaml:"cache"`
+	CORS         CORSConfig        `yaml:"cors"`
+	OpenAPI      OpenAPIConfig     `yaml:"openapi"`
+	GraphQL      GraphQLConfig     `yaml:"graphql"`
+	Logging      LoggingConfig     `yaml:"logging"`
+	Metrics      MetricsConfig     `yaml:"metrics"`
+	Tracing      TracingConfig     `yaml:"tracing"`
+	Health       HealthConfig      `yaml:"health"`
 }
 
 // ServerConfig holds server configuration
 type ServerConfig struct {
-	Port string
-	Host string
+	Port string `yaml:"port" env:"SERVER_PORT" default:"8080" validate:"required,numeric"`
+	Host string `yaml:"host" env:"SERVER_HOST" default:"0.0.0.0"`
+	// ShutdownTimeout bounds draining requests and stopping workers on SIGTERM
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT" default:"30s" validate:"gt=0"`
+}
+
+// GRPCConfig holds the gRPC server configuration; it listens on Server.Host
+type GRPCConfig struct {
+	Enabled bool   `yaml:"enabled" env:"GRPC_ENABLED" default:"true"`
+	Port    string `yaml:"port" env:"GRPC_PORT" default:"9090" validate:"required,numeric"`
+	// Reflection lets tools such as grpcurl list services without the .proto files
+	Reflection bool `yaml:"reflection" env:"GRPC_REFLECTION" default:"true"`
+}
+
+// AuthConfig holds authentication and authorization configuration
+type AuthConfig struct {
+	// UserIDHeader is the header carrying the authenticated user ID, read only when TrustUserHeader is set
+	UserIDHeader string `yaml:"user_id_header" env:"AUTH_USER_HEADER" default:"X-User-ID" validate:"required"`
+	// TrustUserHeader accepts UserIDHeader as proof of identity. Enable it only
+	// behind a trusted proxy that strips the header from client requests.
+	TrustUserHeader bool `yaml:"trust_user_header" env:"AUTH_TRUST_USER_HEADER" default:"false"`
+	// TokenSecret verifies HS256 bearer tokens whose sub is the user ID; empty disables tokens
+	TokenSecret string `yaml:"token_secret" env:"AUTH_TOKEN_SECRET" secret:"true" validate:"omitempty,min=32"`
+	// BootstrapAdminEmail, if set, is granted the admin role on startup
+	BootstrapAdminEmail string `yaml:"bootstrap_admin_email" env:"BOOTSTRAP_ADMIN_EMAIL" validate:"omitempty,email"`
+}
+
+// UsersConfig holds user lifecycle configuration
+type UsersConfig struct {
+	// DeletionGracePeriod is how long a soft-deleted user can be restored before being purged
+	DeletionGracePeriod time.Duration `yaml:"deletion_grace_period" env:"USER_DELETION_GRACE_PERIOD" default:"720h" validate:"gt=0"`
+	// PurgeInterval is how often the purge job looks for expired users
+	PurgeInterval time.Duration `yaml:"purge_interval" env:"USER_PURGE_INTERVAL" default:"1h" validate:"gt=0"`
+	// ImportBatchSize is how many imported users are inserted per transaction
+	ImportBatchSize int `yaml:"import_batch_size" env:"USER_IMPORT_BATCH_SIZE" default:"100" validate:"gt=0"`
+	// ImportMaxRows bounds the rows of one HTTP import; the admin CLI has no limit
+	ImportMaxRows int `yaml:"import_max_rows" env:"USER_IMPORT_MAX_ROWS" default:"1000" validate:"gt=0"`
+}
+
+// PrivacyConfig holds GDPR export and erasure configuration
+type PrivacyConfig struct {
+	// ExportDir is where export ZIP files are written
+	ExportDir string `yaml:"export_dir" env:"PRIVACY_EXPORT_DIR" default:"exports" validate:"required"`
+	// ExportTTL is how long a finished export can be downloaded before it is deleted
+	ExportTTL time.Duration `yaml:"export_ttl" env:"PRIVACY_EXPORT_TTL" default:"168h" validate:"gt=0"`
+	// ErasureSettleDelay is the wait between anonymizing records and redacting the audit log
+	ErasureSettleDelay time.Duration `yaml:"erasure_settle_delay" env:"PRIVACY_ERASURE_SETTLE_DELAY" default:"1m" validate:"gte=0"`
+	// WorkerInterval is how often the privacy worker looks for new requests
+	WorkerInterval time.Duration `yaml:"worker_interval" env:"PRIVACY_WORKER_INTERVAL" default:"10s" validate:"gt=0"`
+}
+
+// EncryptionConfig holds field-level encryption configuration for PII columns
+type EncryptionConfig struct {
+	// MasterKeys are comma separated "id:base64key" pairs of 32-byte master keys
+	MasterKeys string `yaml:"master_keys" env:"PII_MASTER_KEYS" secret:"true"`
+	// MasterKeyFile is an optional file with one "id:base64key" pair per line
+	MasterKeyFile string `yaml:"master_key_file" env:"PII_MASTER_KEY_FILE"`
+	// ActiveKeyID selects the master key that wraps new data keys
+	ActiveKeyID string `yaml:"active_key_id" env:"PII_ACTIVE_KEY_ID" validate:"required_with=MasterKeys MasterKeyFile"`
+	// BlindIndexKey is the base64 32-byte HMAC key for equality lookups on encrypted columns
+	BlindIndexKey string `yaml:"blind_index_key" env:"PII_BLIND_INDEX_KEY" secret:"true" validate:"required_with=MasterKeys MasterKeyFile"`
+}
+
+// IdempotencyConfig holds Idempotency-Key handling configuration
+type IdempotencyConfig struct {
+	// TTL is how long the first response to a key is replayed
+	TTL time.Duration `yaml:"ttl" env:"IDEMPOTENCY_TTL" default:"24h" validate:"gt=0"`
+	// LockTimeout is after how long an unfinished request stops blocking retries
+	LockTimeout time.Duration `yaml:"lock_timeout" env:"IDEMPOTENCY_LOCK_TIMEOUT" default:"1m" validate:"gt=0"`
+	// CleanupInterval is how often expired keys are removed
+	CleanupInterval time.Duration `yaml:"cleanup_interval" env:"IDEMPOTENCY_CLEANUP_INTERVAL" default:"1h" validate:"gt=0"`
+	// MaxBodyMB bounds the body read to fingerprint a request; larger ones get 413
+	MaxBodyMB int `yaml:"max_body_mb" env:"IDEMPOTENCY_MAX_BODY_MB" default:"10" validate:"gt=0"`
+}
+
+// RateLimitConfig holds rate limiting configuration
+// Policies are "<limit>/<period>" token buckets (e.g. "300/1m"); "off" disables limiting for the route group.
+type RateLimitConfig struct {
+	// Store is "memory" (per instance) or "redis" (shared by all instances)
+	Store         string `yaml:"store" env:"RATE_LIMIT_STORE" default:"memory" validate:"oneof=memory redis"`
+	RedisAddr     string `yaml:"redis_addr" env:"REDIS_ADDR" default:"localhost:6379" validate:"required_if=Store redis"`
+	RedisPassword string `yaml:"redis_password" env:"REDIS_PASSWORD" secret:"true"`
+	RedisDB       int    `yaml:"redis_db" env:"REDIS_DB" default:"0" validate:"gte=0"`
+	// API applies to every /api/v1 route
+	API string `yaml:"api" env:"RATE_LIMIT_API" default:"300/1m"`
+	// Admin applies additionally to /api/v1/admin routes
+	Admin string `yaml:"admin" env:"RATE_LIMIT_ADMIN" default:"120/1m"`
+	// Privacy applies additionally to GDPR export and erasure requests
+	Privacy string `yaml:"privacy" env:"RATE_LIMIT_PRIVACY" default:"5/1h"`
+	// Signup applies additionally to public registration, counted per IP
+	Signup string `yaml:"signup" env:"RATE_LIMIT_SIGNUP" default:"10/1h"`
+}
+
+// CacheConfig holds the user lookup cache configuration
+// Lookups by ID, email and username are cached; writes and user events invalidate the entries.
+type CacheConfig struct {
+	// Store is "off", "memory" (per instance) or "redis" (shared by all instances)
+	Store string `yaml:"store" env:"CACHE_STORE" default:"memory" validate:"oneof=off memory redis"`
+	// MultiInstance declares that several API instances serve traffic; the memory store
+	// cannot be invalidated across them and is refused
+	MultiInstance bool `yaml:"multi_instance" env:"CACHE_MULTI_INSTANCE" default:"false"`
+	// Size bounds the entries of the memory store; the least recently used are evicted first
+	Size int `yaml:"size" env:"CACHE_SIZE" default:"10000" validate:"gt=0"`
+	// TTL bounds how stale a cached user can be when an invalidation is missed
+	TTL time.Duration `yaml:"ttl" env:"CACHE_TTL" default:"1m" validate:"gt=0"`
+	// NegativeTTL is how long a lookup that found no user is remembered; 0 disables negative caching
+	NegativeTTL   time.Duration `yaml:"negative_ttl" env:"CACHE_NEGATIVE_TTL" default:"5s" validate:"gte=0"`
+	RedisAddr     string        `yaml:"redis_addr" env:"CACHE_REDIS_ADDR" default:"localhost:6379" validate:"required_if=Store redis"`
+	RedisPassword string        `yaml:"redis_password" env:"CACHE_REDIS_PASSWORD" secret:"true"`
+	RedisDB       int           `yaml:"redis_db" env:"CACHE_REDIS_DB" default:"0" validate:"gte=0"`
+}
+
+// CORSConfig holds the cross-origin policy for browser clients
+// Lists are comma separated. Origins may use a * wildcard ("https://*.example.com");
+// a lone "*" allows any origin without credentials.
+type CORSConfig struct {
+	// AllowedOrigins applies to every route unless a route group overrides it; empty allows none
+	AllowedOrigins string `yaml:"allowed_origins" env:"CORS_ALLOWED_ORIGINS"`
+	// AdminOrigins overrides AllowedOrigins for /api/v1/admin routes; "off" allows none
+	AdminOrigins     string `yaml:"admin_origins" env:"CORS_ADMIN_ORIGINS"`
+	AllowedMethods   string `yaml:"allowed_methods" env:"CORS_ALLOWED_METHODS" default:"GET,POST,PUT,PATCH,DELETE" validate:"required"`
+	AllowedHeaders   string `yaml:"allowed_headers" env:"CORS_ALLOWED_HEADERS" default:"Accept,Authorization,Content-Type,Idempotency-Key,X-Consistency,X-Request-ID"`
+	ExposedHeaders   string `yaml:"exposed_headers" env:"CORS_EXPOSED_HEADERS" default:"X-Request-ID,Idempotent-Replayed,RateLimit-Policy,RateLimit-Limit,RateLimit-Remaining,RateLimit-Reset,Retry-After"`
+	AllowCredentials bool   `yaml:"allow_credentials" env:"CORS_ALLOW_CREDENTIALS" default:"true"`
+	// MaxAge is how long browsers cache preflight responses
+	MaxAge time.Duration `yaml:"max_age" env:"CORS_MAX_AGE" default:"10m" validate:"gte=0"`
+}
+
+// OpenAPIConfig holds validation of requests and responses against the OpenAPI document
+type OpenAPIConfig struct {
+	// ValidateRequests rejects invalid path parameters, query parameters and JSON bodies with 400
+	ValidateRequests bool `yaml:"validate_requests" env:"OPENAPI_VALIDATE_REQUESTS" default:"true"`
+	// ValidateResponses replaces responses the document doesn't allow with 500; for tests and staging
+	ValidateResponses bool `yaml:"validate_responses" env:"OPENAPI_VALIDATE_RESPONSES" default:"false"`
+}
+
+// GraphQLConfig holds the /graphql endpoint configuration
+type GraphQLConfig struct {
+	Enabled bool `yaml:"enabled" env:"GRAPHQL_ENABLED" default:"true"`
+	// MaxDepth and MaxComplexity reject expensive queries before they run
+	MaxDepth      int `yaml:"max_depth" env:"GRAPHQL_MAX_DEPTH" default:"8" validate:"min=1"`
+	MaxComplexity int `yaml:"max_complexity" env:"GRAPHQL_MAX_COMPLEXITY" default:"500" validate:"min=1"`
+	// PersistedQueriesFile pins the queries of the mobile build, a JSON object of sha256 hash to query
+	PersistedQueriesFile string `yaml:"persisted_queries_file" env:"GRAPHQL_PERSISTED_QUERIES_FILE"`
+	// PersistedOnly rejects every query that isn't pinned from PersistedQueriesFile
+	PersistedOnly bool `yaml:"persisted_only" env:"GRAPHQL_PERSISTED_ONLY" default:"false"`
+	// PersistedCacheSize bounds the queries clients register at runtime; 0 disables registration
+	PersistedCacheSize int `yaml:"persisted_cache_size" env:"GRAPHQL_PERSISTED_CACHE_SIZE" default:"1000" validate:"min=0"`
+}
+
+// LoggingConfig holds structured logging configuration
+type LoggingConfig struct {
+	// Level is the default minimum level: debug, info, warn or error
+	Level string `yaml:"level" env:"LOG_LEVEL" default:"info" validate:"oneof=debug info warn error DEBUG INFO WARN ERROR"`
+	// Levels overrides the level per component, e.g. "database=debug,http=warn"
+	Levels string `yaml:"levels" env:"LOG_LEVELS"`
+	// Format is "json" (default) or "text" for local development
+	Format string `yaml:"format" env:"LOG_FORMAT" default:"json" validate:"oneof=json text"`
+	// SlowQueryThreshold logs slower database queries as warnings
+	SlowQueryThreshold time.Duration `yaml:"slow_query_threshold" env:"LOG_SLOW_QUERY_THRESHOLD" default:"200ms" validate:"gte=0"`
+}
+
+// MetricsConfig holds Prometheus metrics configuration
+type MetricsConfig struct {
+	Enabled bool `yaml:"enabled" env:"METRICS_ENABLED" default:"true"`
+	// Path is where the metrics are served, outside the rate-limited API
+	Path string `yaml:"path" env:"METRICS_PATH" default:"/metrics" validate:"startswith=/"`
+}
+
+// TracingConfig holds OpenTelemetry tracing configuration
+// The OTLP endpoint is read by the exporter from OTEL_EXPORTER_OTLP_ENDPOINT.
+type TracingConfig struct {
+	// Exporter is "none", "stdout" or "otlp"
+	Exporter    string `yaml:"exporter" env:"TRACING_EXPORTER" default:"none" validate:"oneof=none stdout otlp"`
+	ServiceName string `yaml:"service_name" env:"OTEL_SERVICE_NAME" default:"booking" validate:"required"`
+	// SampleRatio is the fraction of new traces that are recorded
+	SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO" default:"1" validate:"gte=0,lte=1"`
+}
+
+// HealthConfig holds readiness probe configuration
+type HealthConfig struct {
+	// CheckTimeout bounds each dependency check
+	CheckTimeout time.Duration `yaml:"check_timeout" env:"HEALTH_CHECK_TIMEOUT" default:"2s" validate:"gt=0"`
+	// CacheTTL is how long a readiness report is reused between probes
+	CacheTTL time.Duration `yaml:"cache_ttl" env:"HEALTH_CACHE_TTL" default:"2s" validate:"gte=0"`
+	// MinFreeDiskMB is the free space required in the export directory
+	MinFreeDiskMB int `yaml:"min_free_disk_mb" env:"HEALTH_MIN_FREE_DISK_MB" default:"100" validate:"gte=0"`
+	// MaxObserverBacklog is how many undelivered events are tolerated
+	MaxObserverBacklog int `yaml:"max_observer_backlog" env:"HEALTH_MAX_OBSERVER_BACKLOG" default:"1000" validate:"gt=0"`
+	// MaxOutboxLag is how long the oldest undelivered event may wait
+	MaxOutboxLag time.Duration `yaml:"max_outbox_lag" env:"HEALTH_MAX_OUTBOX_LAG" default:"30s" validate:"gt=0"`
 }
 
 // DatabaseConfig holds database configuration
 type DatabaseConfig struct {
 	// PostgreSQL specific
-	Host     string
-	Port     string
-	User     string
-	Password string
-	DBName   string
-	SSLMode  string
+	Host     string `yaml:"host" env:"DB_HOST" default:"localhost"`
+	Port     string `yaml:"port" env:"DB_PORT" default:"5432" validate:"numeric"`
+	User     string `yaml:"user" env:"DB_USER" default:"postgres"`
+	Password string `yaml:"password" env:"DB_PASSWORD" default:"postgres" secret:"true"`
+	DBName   string `yaml:"name" env:"DB_NAME" default:"booking_db"`
+	SSLMode  string `yaml:"sslmode" env:"DB_SSLMODE" default:"disable" validate:"oneof=disable allow prefer require verify-ca verify-full"`
+	// ReplicaHosts are comma separated host[:port] read replicas; they share user, password, name and sslmode with the primary
+	ReplicaHosts string `yaml:"replica_hosts" env:"DB_REPLICA_HOSTS"`
+	// ReplicaCheckInterval is how often replicas are checked; failing ones get no reads until they recover
+	ReplicaCheckInterval time.Duration `yaml:"replica_check_interval" env:"DB_REPLICA_CHECK_INTERVAL" default:"5s" validate:"gt=0"`
+	// ReplicaMaxLag is how far a replica may fall behind the primary and still serve reads; 0 disables the check
+	ReplicaMaxLag time.Duration `yaml:"replica_max_lag" env:"DB_REPLICA_MAX_LAG" default:"30s" validate:"gte=0"`
+	// ReadYourWritesWindow is how long an actor's reads go to the primary after a write; 0 disables the window
+	ReadYourWritesWindow time.Duration `yaml:"read_your_writes_window" env:"DB_READ_YOUR_WRITES_WINDOW" default:"5s" validate:"gte=0"`
 
 	// MongoDB specific
-	MongoURI     string
-	MongoDBName  string
-	MongoTimeout int
-}
-
-// Load loads configuration from environment variables
-func Load() (*Config, error) {
-	// Load .env file if exists
-	_ = godotenv.Load()
-
-	dbType := DatabaseType(getEnv("DB_TYPE", "postgres"))
-
-	return &Config{
-		Server: ServerConfig{
-			Port: getEnv("SERVER_PORT", "8080"),
-			Host: getEnv("SERVER_HOST", "0.0.0.0"),
-		},
-		DatabaseType: dbType,
-		Database: DatabaseConfig{
-			// PostgreSQL config
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnv("DB_PORT", "5432"),
-			User:     getEnv("DB_USER", "postgres"),
-			Password: getEnv("DB_PASSWORD", "postgres"),
-			DBName:   getEnv("DB_NAME", "booking_db"),
-			SSLMode:  getEnv("DB_SSLMODE", "disable"),
-
-			// MongoDB config
-			MongoURI:     getEnv("MONGO_URI", "mongodb://localhost:27017"),
-			MongoDBName:  getEnv("MONGO_DB_NAME", "booking_db"),
-			MongoTimeout: getEnvAsInt("MONGO_TIMEOUT", 10),
-		},
-	}, nil
-}
-
-// getEnv gets an environment variable or returns a default value
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
-}
-
-// getEnvAsInt gets an environment variable as int or returns a default value
-func getEnvAsInt(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		if intValue, err := strconv.Atoi(value); err == nil {
-			return intValue
-		}
-	}
-	return defaultValue
-}
-
-// getEnvAsBool gets an environment variable as bool or returns a default value
-func getEnvAsBool(key string, defaultValue bool) bool {
-	if value := os.Getenv(key); value != "" {
-		if boolValue, err := strconv.ParseBool(value); err == nil {
-			return boolValue
-		}
-	}
-	return defaultValue
+	MongoURI     string `yaml:"mongo_uri" env:"MONGO_URI" default:"mongodb://localhost:27017" secret:"true"`
+	MongoDBName  string `yaml:"mongo_name" env:"MONGO_DB_NAME" default:"booking_db"`
+	MongoTimeout int    `yaml:"mongo_timeout" env:"MONGO_TIMEOUT" default:"10" validate:"gt=0"`
 }
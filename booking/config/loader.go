@@ -0,0 +1,353 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileEnv names the config file when the -config flag is not given
+const ConfigFileEnv = "CONFIG_FILE"
+
+// fileEnvSuffix marks a variable holding the path of a file with the value, e.g. DB_PASSWORD_FILE
+const fileEnvSuffix = "_FILE"
+
+// durationType is set from strings like "30s" rather than as an integer
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// ValidationError lists every invalid configuration field
+type ValidationError struct {
+	Problems []string
+}
+
+// Error implements error
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// Flags holds command-line overrides bound by RegisterFlags
+type Flags struct {
+	configFile string
+	values     map[string]string
+}
+
+// RegisterFlags binds -config and one flag per field, named after its yaml path (e.g. -server.port)
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	flags := &Flags{values: map[string]string{}}
+	fs.StringVar(&flags.configFile, "config", "", "YAML or TOML config file (default $"+ConfigFileEnv+")")
+
+	for _, f := range fields(&Config{}) {
+		path := f.path
+		usage := "overrides " + path
+		if f.env != "" {
+			usage += " and $" + f.env
+		}
+		fs.Func(path, usage, func(value string) error {
+			flags.values[path] = value
+			return nil
+		})
+	}
+	return flags
+}
+
+// Load builds the configuration from defaults, the config file, the environment and flags
+// flags may be nil for commands without configuration flags. All problems are
+// reported at once in a *ValidationError.
+func Load(flags *Flags) (*Config, error) {
+	// Load .env file if exists
+	_ = godotenv.Load()
+
+	cfg := &Config{}
+	all := fields(cfg)
+	byPath := make(map[string]field, len(all))
+	for _, f := range all {
+		byPath[f.path] = f
+	}
+
+	var problems []string
+	set := func(f field, source, value string) {
+		if err := f.set(value); err != nil {
+			problems = append(problems, fmt.Sprintf("%s (from %s): %v", f.path, source, err))
+		}
+	}
+
+	for _, f := range all {
+		if f.defaultValue != "" {
+			set(f, "default", f.defaultValue)
+		}
+	}
+
+	configFile := os.Getenv(ConfigFileEnv)
+	if flags != nil && flags.configFile != "" {
+		configFile = flags.configFile
+	}
+	if configFile != "" {
+		values, err := readConfigFile(configFile)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range sortedKeys(values) {
+			f, ok := byPath[path]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s (from %s): unknown setting", path, configFile))
+				continue
+			}
+			set(f, configFile, values[path])
+		}
+	}
+
+	for _, f := range all {
+		if f.env == "" {
+			continue
+		}
+		value, fromFile, err := lookupEnv(f.env)
+		switch {
+		case err != nil:
+			problems = append(problems, fmt.Sprintf("%s: %v", f.path, err))
+		case fromFile:
+			set(f, "$"+f.env+fileEnvSuffix, value)
+		case value != "":
+			set(f, "$"+f.env, value)
+		}
+	}
+
+	if flags != nil {
+		for _, path := range sortedKeys(flags.values) {
+			set(byPath[path], "-"+path, flags.values[path])
+		}
+	}
+
+	problems = append(problems, validate(cfg, byPath)...)
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+	return cfg, nil
+}
+
+// lookupEnv reads a variable, or the file named by <name>_FILE (e.g. a mounted secret)
+func lookupEnv(name string) (string, bool, error) {
+	path := os.Getenv(name + fileEnvSuffix)
+	if path == "" {
+		return os.Getenv(name), false, nil
+	}
+	if os.Getenv(name) != "" {
+		return "", false, fmt.Errorf("both $%s and $%s%s are set", name, name, fileEnvSuffix)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("$%s%s: %w", name, fileEnvSuffix, err)
+	}
+	return strings.TrimRight(string(content), "\r\n"), true, nil
+}
+
+// readConfigFile parses a YAML or TOML file into values keyed by dotted path
+func readConfigFile(path string) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config file: %w", err)
+	}
+
+	tree := map[string]interface{}{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &tree)
+	case ".toml":
+		err = toml.Unmarshal(content, &tree)
+	default:
+		return nil, fmt.Errorf("config file %s: expected .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	values := map[string]string{}
+	flatten("", tree, values)
+	return values, nil
+}
+
+// flatten turns nested maps into dotted paths with string values
+func flatten(prefix string, tree map[string]interface{}, values map[string]string) {
+	for key, value := range tree {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			flatten(path, nested, values)
+			continue
+		}
+		values[path] = fmt.Sprint(value)
+	}
+}
+
+// sortedKeys returns the keys of m in order, so problems are reported deterministically
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// field is one configurable leaf of Config
+type field struct {
+	path         string
+	env          string
+	defaultValue string
+	secret       bool
+	value        reflect.Value
+}
+
+// fields lists the leaves of cfg in declaration order
+func fields(cfg *Config) []field {
+	var out []field
+	walk("", reflect.ValueOf(cfg).Elem(), &out)
+	return out
+}
+
+// walk collects the leaves of a struct value
+func walk(prefix string, v reflect.Value, out *[]field) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name := sf.Tag.Get("yaml")
+		if name == "" || name == "-" {
+			continue
+		}
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+
+		if sf.Type.Kind() == reflect.Struct && sf.Type != durationType {
+			walk(path, v.Field(i), out)
+			continue
+		}
+
+		*out = append(*out, field{
+			path:         path,
+			env:          sf.Tag.Get("env"),
+			defaultValue: sf.Tag.Get("default"),
+			secret:       sf.Tag.Get("secret") == "true",
+			value:        v.Field(i),
+		})
+	}
+}
+
+// set parses raw into the field
+func (f field) set(raw string) error {
+	raw = strings.TrimSpace(raw)
+
+	if f.value.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q, expected e.g. 30s or 5m", raw)
+		}
+		f.value.SetInt(int64(d))
+		return nil
+	}
+
+	switch f.value.Kind() {
+	case reflect.String:
+		f.value.SetString(raw)
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		f.value.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		f.value.SetBool(b)
+	case reflect.Float64:
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		f.value.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported type %s", f.value.Type())
+	}
+	return nil
+}
+
+// validate checks the `validate` tags and describes each failure by path and env name
+func validate(cfg *Config, byPath map[string]field) []string {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(sf reflect.StructField) string {
+		return sf.Tag.Get("yaml")
+	})
+
+	err := v.Struct(cfg)
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		if err != nil {
+			return []string{err.Error()}
+		}
+		return nil
+	}
+
+	problems := make([]string, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		// Namespace is "Config.server.port"
+		path := fe.Namespace()[strings.Index(fe.Namespace(), ".")+1:]
+		label := path
+		if f, ok := byPath[path]; ok && f.env != "" {
+			label += " ($" + f.env + ")"
+		}
+
+		value := fmt.Sprint(fe.Value())
+		if f, ok := byPath[path]; ok && f.secret {
+			value = redacted
+		}
+		problems = append(problems, fmt.Sprintf("%s: %s, got %q", label, describe(fe), value))
+	}
+	return problems
+}
+
+// describe turns a failed validation tag into a sentence
+func describe(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "required_with":
+		return "is required when " + fe.Param() + " is set"
+	case "required_if":
+		return "is required when " + fe.Param()
+	case "oneof":
+		return "must be one of " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "numeric":
+		return "must be a number"
+	case "email":
+		return "must be an email address"
+	case "startswith":
+		return "must start with " + fe.Param()
+	case "gt":
+		return "must be greater than " + fe.Param()
+	case "min":
+		return "must be at least " + fe.Param() + " characters"
+	case "gte":
+		return "must be at least " + fe.Param()
+	case "lte":
+		return "must be at most " + fe.Param()
+	default:
+		return "failed " + fe.Tag() + " " + fe.Param()
+	}
+}
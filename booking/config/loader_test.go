@@ -0,0 +1,146 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeFile writes content to name in a temporary directory and returns its path
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// parseFlags registers the configuration flags and parses args
+func parseFlags(t *testing.T, args ...string) *Flags {
+	t.Helper()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags := RegisterFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return flags
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	file := "server:\n  port: 9000\n  shutdown_timeout: 45s\n"
+	tests := []struct {
+		name     string
+		file     string
+		env      map[string]string
+		args     []string
+		wantPort string
+	}{
+		{name: "default", wantPort: "8080"},
+		{name: "file over default", file: file, wantPort: "9000"},
+		{name: "env over file", file: file, env: map[string]string{"SERVER_PORT": "9100"}, wantPort: "9100"},
+		{name: "empty env is unset", file: file, env: map[string]string{"SERVER_PORT": ""}, wantPort: "9000"},
+		{name: "flag over env", file: file, env: map[string]string{"SERVER_PORT": "9100"}, args: []string{"-server.port=9200"}, wantPort: "9200"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(ConfigFileEnv, "")
+			t.Setenv("SERVER_PORT", "")
+			t.Setenv("SERVER_SHUTDOWN_TIMEOUT", "")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			args := tt.args
+			if tt.file != "" {
+				args = append([]string{"-config", writeFile(t, "config.yaml", tt.file)}, args...)
+			}
+
+			cfg, err := Load(parseFlags(t, args...))
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if cfg.Server.Port != tt.wantPort {
+				t.Errorf("Server.Port = %q, want %q", cfg.Server.Port, tt.wantPort)
+			}
+			// Settings only the file sets keep their file value under env and flags
+			if tt.file != "" && cfg.Server.ShutdownTimeout != 45*time.Second {
+				t.Errorf("Server.ShutdownTimeout = %s, want 45s from the file", cfg.Server.ShutdownTimeout)
+			}
+		})
+	}
+}
+
+func TestLoadSources(t *testing.T) {
+	t.Setenv(ConfigFileEnv, writeFile(t, "config.toml", "[database]\nname = \"from_toml\"\n"))
+	t.Setenv("DB_NAME", "")
+	t.Setenv("DB_PASSWORD", "")
+	t.Setenv("DB_PASSWORD_FILE", writeFile(t, "password", "s3cret\n"))
+
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Database.DBName != "from_toml" {
+		t.Errorf("Database.DBName = %q, want the value of $CONFIG_FILE", cfg.Database.DBName)
+	}
+	if cfg.Database.Password != "s3cret" {
+		t.Errorf("Database.Password = %q, want the content of $DB_PASSWORD_FILE without the newline", cfg.Database.Password)
+	}
+}
+
+func TestLoadInvalidValues(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		env  map[string]string
+		want []string
+	}{
+		{name: "not numeric", env: map[string]string{"SERVER_PORT": "http"}, want: []string{"server.port", "$SERVER_PORT"}},
+		{name: "not a duration", env: map[string]string{"HEALTH_CHECK_TIMEOUT": "soon"}, want: []string{"health.check_timeout (from $HEALTH_CHECK_TIMEOUT)"}},
+		{name: "not one of", env: map[string]string{"DB_SSLMODE": "always"}, want: []string{"database.sslmode"}},
+		{name: "not positive", env: map[string]string{"HEALTH_MAX_OUTBOX_LAG": "0s"}, want: []string{"health.max_outbox_lag"}},
+		{name: "unknown file setting", file: "server:\n  prot: 9000\n", want: []string{"server.prot", "unknown setting"}},
+		{
+			name: "every problem at once",
+			file: "server:\n  port: abc\n",
+			env:  map[string]string{"IDEMPOTENCY_MAX_BODY_MB": "-1", "DB_SSLMODE": "always"},
+			want: []string{"server.port", "idempotency.max_body_mb", "database.sslmode"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(ConfigFileEnv, "")
+			for _, key := range []string{"SERVER_PORT", "HEALTH_CHECK_TIMEOUT", "DB_SSLMODE", "HEALTH_MAX_OUTBOX_LAG", "IDEMPOTENCY_MAX_BODY_MB"} {
+				t.Setenv(key, "")
+			}
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			if tt.file != "" {
+				t.Setenv(ConfigFileEnv, writeFile(t, "config.yml", tt.file))
+			}
+
+			_, err := Load(nil)
+			var invalid *ValidationError
+			if !errors.As(err, &invalid) {
+				t.Fatalf("Load() error = %v, want a *ValidationError", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not mention %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadRejectsUnknownFileType(t *testing.T) {
+	t.Setenv(ConfigFileEnv, writeFile(t, "config.json", "{}"))
+	if _, err := Load(nil); err == nil || !strings.Contains(err.Error(), "expected .yaml, .yml or .toml") {
+		t.Errorf("Load() error = %v, want the supported file types", err)
+	}
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// redacted replaces secret values in printed configuration and error messages
+const redacted = "[REDACTED]"
+
+// WriteYAML writes the effective configuration as YAML with secrets redacted
+// The output uses the same keys as the config file, so it can be saved and edited.
+func WriteYAML(w io.Writer, cfg *Config) error {
+	root := map[string]interface{}{}
+	for _, f := range fields(cfg) {
+		var value interface{} = f.value.Interface()
+		switch {
+		case f.secret && !f.value.IsZero():
+			value = redacted
+		case f.value.Type() == durationType:
+			value = time.Duration(f.value.Int()).String()
+		case f.value.Kind() == reflect.String:
+			value = f.value.String()
+		}
+
+		node := root
+		keys := strings.Split(f.path, ".")
+		for _, key := range keys[:len(keys)-1] {
+			child, ok := node[key].(map[string]interface{})
+			if !ok {
+				child = map[string]interface{}{}
+				node[key] = child
+			}
+			node = child
+		}
+		node[keys[len(keys)-1]] = value
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(root); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
@@ -0,0 +1,106 @@
+// Package consistency decides which requests must read their own writes
+//
+// With read replicas, a read right after a write may reach a replica that
+// has not applied it yet. A request reads from the primary, skipping the
+// user cache, when the caller asks for it with the X-Consistency header
+// (x-consistency metadata over gRPC) set to "strong", or when its actor
+// wrote through this instance within the read-your-writes window. Both
+// transports turn that into repository.WithReadYourWrites.
+package consistency
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"booking/domain/identity"
+	"booking/domain/repository"
+)
+
+// Header is the request header asking for read-your-writes
+const Header = "X-Consistency"
+
+// Strong is the Header value asking for read-your-writes
+const Strong = "strong"
+
+// Requested reports whether a Header value asks for read-your-writes
+func Requested(value string) bool {
+	return strings.TrimSpace(value) == Strong
+}
+
+// Tracker remembers which actors wrote recently, so their next reads see the writes
+// It is per instance: a write through another instance does not start a window here.
+// A nil Tracker remembers nothing.
+type Tracker struct {
+	window time.Duration
+	now    func() time.Time
+
+	mu     sync.Mutex
+	writes map[uint]time.Time
+	swept  time.Time
+}
+
+// NewTracker creates a tracker whose windows last window; 0 returns nil
+func NewTracker(window time.Duration) *Tracker {
+	if window <= 0 {
+		return nil
+	}
+	return &Tracker{
+		window: window,
+		now:    time.Now,
+		writes: make(map[uint]time.Time),
+	}
+}
+
+// Wrote starts the window of actor; anonymous writes (actor 0) are not tracked
+func (t *Tracker) Wrote(actor uint) {
+	if t == nil || actor == 0 {
+		return
+	}
+
+	now := t.now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.writes[actor] = now
+
+	// Expired windows are dropped once per window, so the map holds only recent writers
+	if now.Sub(t.swept) >= t.window {
+		for id, at := range t.writes {
+			if now.Sub(at) >= t.window {
+				delete(t.writes, id)
+			}
+		}
+		t.swept = now
+	}
+}
+
+// Recent reports whether actor wrote within the window
+func (t *Tracker) Recent(actor uint) bool {
+	if t == nil || actor == 0 {
+		return false
+	}
+
+	t.mu.Lock()
+	at, ok := t.writes[actor]
+	t.mu.Unlock()
+	return ok && t.now().Sub(at) < t.window
+}
+
+// Context marks ctx for read-your-writes when header asks for it or the actor in ctx wrote recently
+func (t *Tracker) Context(ctx context.Context, header string) context.Context {
+	if Requested(header) {
+		return repository.WithReadYourWrites(ctx)
+	}
+	if actor, ok := identity.ActorFromContext(ctx); ok && t.Recent(actor) {
+		return repository.WithReadYourWrites(ctx)
+	}
+	return ctx
+}
+
+// WroteIn starts the window of the actor in ctx, if any
+func (t *Tracker) WroteIn(ctx context.Context) {
+	if actor, ok := identity.ActorFromContext(ctx); ok {
+		t.Wrote(actor)
+	}
+}
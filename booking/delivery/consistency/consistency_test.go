@@ -0,0 +1,66 @@
+package consistency
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"booking/domain/identity"
+	"booking/domain/repository"
+)
+
+func TestTrackerWindow(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tracker := NewTracker(5 * time.Second)
+	tracker.now = func() time.Time { return now }
+
+	tracker.Wrote(7)
+	tracker.Wrote(0)
+	if !tracker.Recent(7) {
+		t.Error("actor 7 is not recent right after writing")
+	}
+	if tracker.Recent(8) || tracker.Recent(0) {
+		t.Error("actors that did not write, or anonymous ones, are recent")
+	}
+
+	now = now.Add(5 * time.Second)
+	if tracker.Recent(7) {
+		t.Error("actor 7 is still recent after the window")
+	}
+
+	// Writing again drops the expired entries
+	tracker.Wrote(8)
+	if len(tracker.writes) != 1 {
+		t.Errorf("tracker holds %d actors, want only the recent writer", len(tracker.writes))
+	}
+}
+
+func TestContext(t *testing.T) {
+	tracker := NewTracker(time.Minute)
+	actor := identity.WithActor(context.Background(), 7)
+
+	if repository.ReadYourWrites(tracker.Context(actor, "")) {
+		t.Error("read-your-writes before the actor wrote")
+	}
+	if !repository.ReadYourWrites(tracker.Context(context.Background(), Strong)) {
+		t.Error("the strong header is ignored")
+	}
+	if repository.ReadYourWrites(tracker.Context(context.Background(), "eventual")) {
+		t.Error("read-your-writes for another header value")
+	}
+
+	tracker.WroteIn(actor)
+	if !repository.ReadYourWrites(tracker.Context(actor, "")) {
+		t.Error("no read-your-writes after the actor wrote")
+	}
+
+	// Without a tracker only the header counts
+	var disabled *Tracker
+	disabled.WroteIn(actor)
+	if NewTracker(0) != nil || repository.ReadYourWrites(disabled.Context(actor, "")) {
+		t.Error("a disabled tracker remembers writes")
+	}
+	if !repository.ReadYourWrites(disabled.Context(actor, Strong)) {
+		t.Error("a disabled tracker ignores the header")
+	}
+}
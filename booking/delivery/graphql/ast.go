@@ -0,0 +1,146 @@
+package graphql
+
+// Syntax tree of an executable GraphQL document (queries and fragments)
+
+// Document is a parsed request document
+type Document struct {
+	Operations []*Operation
+	Fragments  map[string]*Fragment
+}
+
+// Operation is a query, mutation or subscription
+type Operation struct {
+	Type       string
+	Name       string
+	Variables  []*VariableDefinition
+	Directives []*Directive
+	Selections []Selection
+	Location   Location
+}
+
+// VariableDefinition declares an operation variable
+type VariableDefinition struct {
+	Name     string
+	Type     *TypeRef
+	Default  *Value
+	Location Location
+}
+
+// TypeRef is a type as written in a variable definition, e.g. [ID!]!
+type TypeRef struct {
+	Name    string
+	Elem    *TypeRef
+	NonNull bool
+}
+
+// String renders the type as written
+func (t *TypeRef) String() string {
+	s := t.Name
+	if t.Elem != nil {
+		s = "[" + t.Elem.String() + "]"
+	}
+	if t.NonNull {
+		s += "!"
+	}
+	return s
+}
+
+// Selection is a *Field, *FragmentSpread or *InlineFragment
+type Selection interface {
+	selection()
+}
+
+// Field selects a field, optionally under an alias
+type Field struct {
+	Alias      string
+	Name       string
+	Arguments  []*Argument
+	Directives []*Directive
+	Selections []Selection
+	Location   Location
+}
+
+// ResponseKey is the alias, or the name when there is none
+func (f *Field) ResponseKey() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+// FragmentSpread includes a named fragment
+type FragmentSpread struct {
+	Name       string
+	Directives []*Directive
+	Location   Location
+}
+
+// InlineFragment groups selections under an optional type condition
+type InlineFragment struct {
+	TypeCondition string
+	Directives    []*Directive
+	Selections    []Selection
+	Location      Location
+}
+
+func (*Field) selection()          {}
+func (*FragmentSpread) selection() {}
+func (*InlineFragment) selection() {}
+
+// Fragment is a named fragment definition
+type Fragment struct {
+	Name          string
+	TypeCondition string
+	Directives    []*Directive
+	Selections    []Selection
+	Location      Location
+}
+
+// Argument is a named argument of a field or directive
+type Argument struct {
+	Name  string
+	Value *Value
+}
+
+// Directive is e.g. @skip(if: $flag)
+type Directive struct {
+	Name      string
+	Arguments []*Argument
+	Location  Location
+}
+
+// ValueKind is the kind of a literal or variable
+type ValueKind int
+
+const (
+	VariableValue ValueKind = iota
+	IntValue
+	FloatValue
+	StringValue
+	BooleanValue
+	NullValue
+	EnumValue
+	ListValue
+	ObjectValue
+)
+
+// Value is an input value as written in the document
+type Value struct {
+	Kind ValueKind
+	// Raw is the variable name, the literal text or the decoded string
+	Raw    string
+	List   []*Value
+	Fields []*ObjectField
+}
+
+// ObjectField is one field of an input object literal
+type ObjectField struct {
+	Name  string
+	Value *Value
+}
+
+// Location is a 1-based position in the document
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
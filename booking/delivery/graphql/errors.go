@@ -0,0 +1,45 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"booking/domain/entity"
+	"booking/domain/identity"
+	"booking/infrastructure/logging"
+	"booking/usecase/role"
+	"booking/usecase/user"
+)
+
+// toError maps a use case error to a GraphQL error with a code extension
+// Unexpected errors are reported as internal without their message, which
+// may contain database details; the cause is logged instead.
+func toError(ctx context.Context, err error) error {
+	var validation *user.ValidationError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &validation),
+		errors.Is(err, user.ErrInvalidSort),
+		errors.Is(err, entity.ErrInvalidCursor),
+		errors.Is(err, identity.ErrInvalidUserID):
+		return coded(err, "BAD_USER_INPUT")
+	case errors.Is(err, user.ErrUserNotFound):
+		return coded(err, "NOT_FOUND")
+	case errors.Is(err, role.ErrUnauthenticated):
+		return coded(err, "UNAUTHENTICATED")
+	case errors.Is(err, role.ErrForbidden):
+		return coded(err, "FORBIDDEN")
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return coded(err, "CANCELED")
+	default:
+		logging.For("graphql").ErrorContext(ctx, "resolver failed", slog.Any("error", err))
+		return errInternal
+	}
+}
+
+// coded wraps err in an *Error carrying code
+func coded(err error, code string) *Error {
+	return &Error{Message: err.Error(), Extensions: map[string]interface{}{"code": code}}
+}
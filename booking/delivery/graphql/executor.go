@@ -0,0 +1,388 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+
+	"booking/infrastructure/logging"
+)
+
+// Error is a GraphQL error as returned to clients
+// Resolvers may return an *Error to set extensions such as a code.
+type Error struct {
+	Message    string                 `json:"message"`
+	Locations  []Location             `json:"locations,omitempty"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// Error implements error
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Response is the result of a request
+// Data is left out when the request failed before execution, and null when
+// execution failed at the root.
+type Response struct {
+	Data   interface{} `json:"data,omitempty"`
+	Errors []*Error    `json:"errors,omitempty"`
+	// executed is set once execution started, so a null Data is written
+	executed bool
+}
+
+// MarshalJSON implements json.Marshaler
+func (r *Response) MarshalJSON() ([]byte, error) {
+	if r.executed && r.Data == nil {
+		return json.Marshal(struct {
+			Data   *struct{} `json:"data"`
+			Errors []*Error  `json:"errors,omitempty"`
+		}{Errors: r.Errors})
+	}
+	type response Response
+	return json.Marshal((*response)(r))
+}
+
+// resultObject is an object in the response, keeping the field order of the query
+type resultObject struct {
+	keys   []string
+	values []interface{}
+	// slot is where the object sits in its parent
+	slot *slot
+}
+
+// MarshalJSON implements json.Marshaler
+func (o *resultObject) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		b.Write(k)
+		b.WriteByte(':')
+		v, err := json.Marshal(o.values[i])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(v)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// slot is a position in the response holding an object, for null propagation
+// When a non-null field of an object resolves to null, the object is
+// replaced by null, and so on up through non-null positions.
+type slot struct {
+	clear   func()
+	nonNull bool
+	parent  *slot
+}
+
+// collectedField is a response key and the fields merged under it
+type collectedField struct {
+	key    string
+	fields []*Field
+}
+
+// task is an object whose fields are resolved at the next depth
+type task struct {
+	object *Object
+	source interface{}
+	fields []*collectedField
+	result *resultObject
+	path   []interface{}
+}
+
+// resolution is a resolved, possibly deferred, field value
+type resolution struct {
+	task  *task
+	index int
+	def   *FieldDef
+	value interface{}
+	err   error
+}
+
+// executor runs one operation
+type executor struct {
+	ctx       context.Context
+	doc       *Document
+	variables map[string]interface{}
+	errors    []*Error
+	logger    *slog.Logger
+}
+
+// execute resolves the operation breadth first
+// All fields at one depth are resolved before any Thunk among them is
+// called, so every key of that depth reaches a loader before its batch runs.
+func execute(ctx context.Context, schema *Schema, doc *Document, op *Operation, variables map[string]interface{}, root interface{}) *Response {
+	e := &executor{ctx: ctx, doc: doc, variables: variables, logger: logging.For("graphql")}
+
+	var data interface{}
+	result := &resultObject{}
+	result.slot = &slot{clear: func() { data = nil }}
+	data = result
+
+	level := []*task{{
+		object: schema.Query,
+		source: root,
+		fields: e.collectFields(schema.Query, op.Selections, map[string]bool{}),
+		result: result,
+	}}
+	for len(level) > 0 {
+		if err := ctx.Err(); err != nil {
+			e.errors = append(e.errors, &Error{Message: err.Error()})
+			return &Response{Errors: e.errors, executed: true}
+		}
+
+		var resolutions []*resolution
+		for _, t := range level {
+			t.result.keys = make([]string, len(t.fields))
+			t.result.values = make([]interface{}, len(t.fields))
+			for i, field := range t.fields {
+				t.result.keys[i] = field.key
+				name := field.fields[0].Name
+				if name == "__typename" {
+					t.result.values[i] = t.object.Name
+					continue
+				}
+				r := &resolution{task: t, index: i, def: t.object.Field(name)}
+				args, err := coerceArguments(r.def.Args, field.fields[0].Arguments, variables)
+				if err != nil {
+					r.err = err
+				} else {
+					r.value, r.err = e.resolve(r.def, ResolveParams{Source: t.source, Args: args})
+				}
+				resolutions = append(resolutions, r)
+			}
+		}
+
+		var next []*task
+		for _, r := range resolutions {
+			t, field := r.task, r.task.fields[r.index]
+			path := appendPath(t.path, field.key)
+			value, err := r.value, r.err
+			if thunk, ok := value.(Thunk); ok && err == nil {
+				value, err = e.call(thunk)
+			}
+
+			_, nonNull := r.def.Type.(*NonNull)
+			if err != nil {
+				e.fieldError(err, field.fields[0].Location, path)
+				if nonNull {
+					e.nullify(t.result.slot)
+				}
+				continue
+			}
+
+			index, result := r.index, t.result
+			s := &slot{clear: func() { result.values[index] = nil }, nonNull: nonNull, parent: t.result.slot}
+			completed, _ := e.complete(r.def.Type, value, field.fields, path, s, &next)
+			t.result.values[r.index] = completed
+			if completed == nil && nonNull {
+				e.nullify(t.result.slot)
+			}
+		}
+		level = next
+	}
+	return &Response{Data: data, Errors: e.errors, executed: true}
+}
+
+// complete converts a resolved value to its response form
+// Objects are returned empty and queued on next; their fields are filled in
+// at the next depth. reported tells that a null was caused by an error
+// already recorded, so wrapping non-null types don't report it again.
+func (e *executor) complete(t Type, value interface{}, fields []*Field, path []interface{}, s *slot, next *[]*task) (completed interface{}, reported bool) {
+	if nonNull, ok := t.(*NonNull); ok {
+		completed, reported = e.complete(nonNull.Of, value, fields, path, s, next)
+		if completed == nil && !reported {
+			e.errors = append(e.errors, &Error{
+				Message:   fmt.Sprintf("cannot return null for non-nullable field of type %s", t),
+				Locations: []Location{fields[0].Location},
+				Path:      path,
+			})
+		}
+		return completed, completed == nil
+	}
+	if isNil(value) {
+		return nil, false
+	}
+
+	switch t := t.(type) {
+	case *List:
+		items := reflect.ValueOf(value)
+		if items.Kind() != reflect.Slice && items.Kind() != reflect.Array {
+			e.fieldError(fmt.Errorf("expected a list, got %T", value), fields[0].Location, path)
+			return nil, true
+		}
+		_, itemNonNull := t.Of.(*NonNull)
+		list := make([]interface{}, items.Len())
+		for i := range list {
+			itemSlot := &slot{clear: func() { list[i] = nil }, nonNull: itemNonNull, parent: s}
+			item, itemReported := e.complete(t.Of, items.Index(i).Interface(), fields, appendPath(path, i), itemSlot, next)
+			if item == nil && itemNonNull {
+				return nil, itemReported
+			}
+			list[i] = item
+		}
+		return list, false
+	case *Scalar:
+		serialized, err := t.Serialize(value)
+		if err != nil {
+			e.fieldError(err, fields[0].Location, path)
+			return nil, true
+		}
+		return serialized, false
+	case *Enum:
+		return fmt.Sprint(value), false
+	case *Object:
+		var selections []Selection
+		for _, field := range fields {
+			selections = append(selections, field.Selections...)
+		}
+		result := &resultObject{slot: s}
+		*next = append(*next, &task{
+			object: t,
+			source: value,
+			fields: e.collectFields(t, selections, map[string]bool{}),
+			result: result,
+			path:   path,
+		})
+		return result, false
+	}
+	return nil, false
+}
+
+// nullify replaces the object at s with null, propagating through non-null positions
+func (e *executor) nullify(s *slot) {
+	for ; s != nil; s = s.parent {
+		s.clear()
+		if !s.nonNull {
+			return
+		}
+	}
+}
+
+// resolve calls a resolver, turning a panic into a field error
+func (e *executor) resolve(def *FieldDef, params ResolveParams) (value interface{}, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			e.logger.ErrorContext(e.ctx, "resolver panicked", slog.String("field", def.Name), slog.Any("panic", recovered))
+			value, err = nil, errInternal
+		}
+	}()
+	if def.Resolve == nil {
+		if source, ok := params.Source.(map[string]interface{}); ok {
+			return source[def.Name], nil
+		}
+		return nil, nil
+	}
+	return def.Resolve(e.ctx, params)
+}
+
+// call runs a thunk, turning a panic into a field error
+func (e *executor) call(thunk Thunk) (value interface{}, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			e.logger.ErrorContext(e.ctx, "deferred resolver panicked", slog.Any("panic", recovered))
+			value, err = nil, errInternal
+		}
+	}()
+	return thunk()
+}
+
+// errInternal is reported instead of details of unexpected failures
+var errInternal = &Error{Message: "internal error", Extensions: map[string]interface{}{"code": "INTERNAL"}}
+
+// fieldError records the error of a field
+func (e *executor) fieldError(err error, loc Location, path []interface{}) {
+	gqlErr := &Error{Message: err.Error()}
+	var typed *Error
+	if errors.As(err, &typed) {
+		gqlErr.Message, gqlErr.Extensions = typed.Message, typed.Extensions
+	}
+	gqlErr.Locations = []Location{loc}
+	gqlErr.Path = path
+	e.errors = append(e.errors, gqlErr)
+}
+
+// collectFields flattens fragments and groups fields by response key, in query order
+func (e *executor) collectFields(object *Object, selections []Selection, visited map[string]bool) []*collectedField {
+	var collected []*collectedField
+	byKey := map[string]*collectedField{}
+	var collect func(selections []Selection)
+	collect = func(selections []Selection) {
+		for _, selection := range selections {
+			switch sel := selection.(type) {
+			case *Field:
+				if !e.included(sel.Directives) {
+					continue
+				}
+				key := sel.ResponseKey()
+				if field, ok := byKey[key]; ok {
+					field.fields = append(field.fields, sel)
+					continue
+				}
+				field := &collectedField{key: key, fields: []*Field{sel}}
+				byKey[key] = field
+				collected = append(collected, field)
+			case *FragmentSpread:
+				fragment := e.doc.Fragments[sel.Name]
+				if visited[sel.Name] || !e.included(sel.Directives) || fragment.TypeCondition != object.Name {
+					continue
+				}
+				visited[sel.Name] = true
+				collect(fragment.Selections)
+			case *InlineFragment:
+				if !e.included(sel.Directives) || (sel.TypeCondition != "" && sel.TypeCondition != object.Name) {
+					continue
+				}
+				collect(sel.Selections)
+			}
+		}
+	}
+	collect(selections)
+	return collected
+}
+
+// included evaluates @skip and @include
+func (e *executor) included(directives []*Directive) bool {
+	for _, directive := range directives {
+		args, err := coerceArguments(conditionArgs, directive.Arguments, e.variables)
+		if err != nil {
+			continue
+		}
+		condition, _ := args["if"].(bool)
+		if directive.Name == "skip" && condition || directive.Name == "include" && !condition {
+			return false
+		}
+	}
+	return true
+}
+
+// appendPath copies path with one more element
+func appendPath(path []interface{}, element interface{}) []interface{} {
+	extended := make([]interface{}, len(path), len(path)+1)
+	copy(extended, path)
+	return append(extended, element)
+}
+
+// isNil reports whether value is nil or a nil pointer, slice or map
+func isNil(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface, reflect.Func:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+)
+
+// BatchFunc loads the values of many keys at once
+// Keys missing from the returned map resolve to the zero value.
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// Loader collects the keys requested while one depth of a query resolves
+// and loads them with a single call, caching the values for the request
+// Create one per request; values are never invalidated.
+type Loader[K comparable, V any] struct {
+	ctx     context.Context
+	batch   BatchFunc[K, V]
+	mu      sync.Mutex
+	pending []K
+	results map[K]*loaded[V]
+}
+
+// loaded is the outcome for one key
+type loaded[V any] struct {
+	value V
+	err   error
+}
+
+// NewLoader creates a loader for one request
+func NewLoader[K comparable, V any](ctx context.Context, batch BatchFunc[K, V]) *Loader[K, V] {
+	return &Loader[K, V]{ctx: ctx, batch: batch, results: map[K]*loaded[V]{}}
+}
+
+// Load queues key and returns a Thunk for its value
+// The batch runs when the first of the queued thunks is called.
+func (l *Loader[K, V]) Load(key K) Thunk {
+	l.mu.Lock()
+	if _, ok := l.results[key]; !ok {
+		l.results[key] = nil
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (interface{}, error) {
+		value, err := l.get(key)
+		if err != nil {
+			return nil, err
+		}
+		return value, nil
+	}
+}
+
+// get returns the value of key, running the pending batch if needed
+func (l *Loader[K, V]) get(key K) (V, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.results[key] == nil {
+		keys := l.pending
+		l.pending = nil
+		values, err := l.batch(l.ctx, keys)
+		for _, k := range keys {
+			l.results[k] = &loaded[V]{value: values[k], err: err}
+		}
+	}
+	result := l.results[key]
+	return result.value, result.err
+}
+
+// Prime caches a value loaded by other means, e.g. a user from a listing
+func (l *Loader[K, V]) Prime(key K, value V) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.results[key] == nil {
+		l.results[key] = &loaded[V]{value: value}
+	}
+}
@@ -0,0 +1,61 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestLoaderBatchesAndCaches(t *testing.T) {
+	var batches [][]int
+	l := NewLoader(context.Background(), func(ctx context.Context, keys []int) (map[int]string, error) {
+		batches = append(batches, keys)
+		values := make(map[int]string, len(keys))
+		for _, k := range keys {
+			if k != 3 {
+				values[k] = string(rune('a' + k))
+			}
+		}
+		return values, nil
+	})
+
+	l.Prime(0, "primed")
+	one, two, again, missing, primed := l.Load(1), l.Load(2), l.Load(1), l.Load(3), l.Load(0)
+	if len(batches) != 0 {
+		t.Fatal("the batch ran before a thunk was called")
+	}
+
+	for _, tt := range []struct {
+		thunk Thunk
+		want  interface{}
+	}{{one, "b"}, {two, "c"}, {again, "b"}, {missing, ""}, {primed, "primed"}} {
+		got, err := tt.thunk()
+		if err != nil || got != tt.want {
+			t.Errorf("thunk() = %v, %v; want %v", got, err, tt.want)
+		}
+	}
+	if len(batches) != 1 || len(batches[0]) != 3 {
+		t.Fatalf("batches = %v, want one of the keys 1, 2 and 3", batches)
+	}
+
+	// Cached keys are not loaded again; new ones start the next batch
+	l.Load(2)()
+	l.Load(4)()
+	if len(batches) != 2 || len(batches[1]) != 1 || batches[1][0] != 4 {
+		t.Errorf("batches = %v, want a second batch of key 4 only", batches)
+	}
+}
+
+func TestLoaderBatchError(t *testing.T) {
+	failure := errors.New("down")
+	l := NewLoader(context.Background(), func(ctx context.Context, keys []int) (map[int]int, error) {
+		return nil, failure
+	})
+
+	a, b := l.Load(1), l.Load(2)
+	for _, thunk := range []Thunk{a, b} {
+		if _, err := thunk(); !errors.Is(err, failure) {
+			t.Errorf("thunk() error = %v, want %v", err, failure)
+		}
+	}
+}
@@ -0,0 +1,465 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxDocumentSize bounds the query text accepted by Parse
+const maxDocumentSize = 64 << 10
+
+// tokenKind classifies lexer tokens
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunct
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+// token is one lexical token
+type token struct {
+	kind  tokenKind
+	value string
+	loc   Location
+}
+
+// SyntaxError reports an invalid document
+type SyntaxError struct {
+	Message  string
+	Location Location
+}
+
+// Error implements error
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at %d:%d: %s", e.Location.Line, e.Location.Column, e.Message)
+}
+
+// parser is a recursive-descent parser over the token stream
+type parser struct {
+	src    string
+	pos    int
+	line   int
+	column int
+	tok    token
+}
+
+// Parse parses an executable document: operations and fragments
+// Type system definitions (schemas) are not accepted.
+func Parse(src string) (doc *Document, err error) {
+	if len(src) > maxDocumentSize {
+		return nil, &SyntaxError{Message: fmt.Sprintf("document is larger than %d bytes", maxDocumentSize), Location: Location{Line: 1, Column: 1}}
+	}
+
+	p := &parser{src: src, line: 1, column: 1}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			syntaxErr, ok := recovered.(*SyntaxError)
+			if !ok {
+				panic(recovered)
+			}
+			doc, err = nil, syntaxErr
+		}
+	}()
+
+	p.next()
+	doc = &Document{Fragments: map[string]*Fragment{}}
+	for p.tok.kind != tokenEOF {
+		switch {
+		case p.peek("{"):
+			doc.Operations = append(doc.Operations, &Operation{Type: "query", Location: p.tok.loc, Selections: p.selectionSet()})
+		case p.peekName("query"), p.peekName("mutation"), p.peekName("subscription"):
+			doc.Operations = append(doc.Operations, p.operation())
+		case p.peekName("fragment"):
+			fragment := p.fragment()
+			if _, exists := doc.Fragments[fragment.Name]; exists {
+				p.failAt(fragment.Location, "fragment %q is defined more than once", fragment.Name)
+			}
+			doc.Fragments[fragment.Name] = fragment
+		default:
+			p.fail("expected an operation or fragment, got %q", p.tok.value)
+		}
+	}
+	if len(doc.Operations) == 0 {
+		p.fail("document has no operation")
+	}
+	return doc, nil
+}
+
+// operation parses a named or typed operation
+func (p *parser) operation() *Operation {
+	op := &Operation{Location: p.tok.loc, Type: p.name()}
+	if p.tok.kind == tokenName {
+		op.Name = p.name()
+	}
+	if p.skip("(") {
+		for !p.skip(")") {
+			op.Variables = append(op.Variables, p.variableDefinition())
+		}
+	}
+	op.Directives = p.directives()
+	op.Selections = p.selectionSet()
+	return op
+}
+
+// variableDefinition parses $name: Type = default
+func (p *parser) variableDefinition() *VariableDefinition {
+	def := &VariableDefinition{Location: p.tok.loc}
+	p.expect("$")
+	def.Name = p.name()
+	p.expect(":")
+	def.Type = p.typeRef()
+	if p.skip("=") {
+		def.Default = p.value(true)
+	}
+	return def
+}
+
+// typeRef parses Name, [Type] and their non-null forms
+func (p *parser) typeRef() *TypeRef {
+	t := &TypeRef{}
+	if p.skip("[") {
+		t.Elem = p.typeRef()
+		p.expect("]")
+	} else {
+		t.Name = p.name()
+	}
+	t.NonNull = p.skip("!")
+	return t
+}
+
+// fragment parses fragment Name on Type { ... }
+func (p *parser) fragment() *Fragment {
+	f := &Fragment{Location: p.tok.loc}
+	p.name()
+	f.Name = p.name()
+	if f.Name == "on" {
+		p.failAt(f.Location, "fragment cannot be named \"on\"")
+	}
+	if p.tok.value != "on" {
+		p.fail("expected \"on\", got %q", p.tok.value)
+	}
+	p.next()
+	f.TypeCondition = p.name()
+	f.Directives = p.directives()
+	f.Selections = p.selectionSet()
+	return f
+}
+
+// selectionSet parses { selection... }
+func (p *parser) selectionSet() []Selection {
+	p.expect("{")
+	var selections []Selection
+	for !p.skip("}") {
+		selections = append(selections, p.selection())
+	}
+	if len(selections) == 0 {
+		p.fail("selection set is empty")
+	}
+	return selections
+}
+
+// selection parses a field, fragment spread or inline fragment
+func (p *parser) selection() Selection {
+	loc := p.tok.loc
+	if p.skip("...") {
+		if p.tok.kind == tokenName && p.tok.value != "on" {
+			return &FragmentSpread{Location: loc, Name: p.name(), Directives: p.directives()}
+		}
+		inline := &InlineFragment{Location: loc}
+		if p.tok.value == "on" {
+			p.next()
+			inline.TypeCondition = p.name()
+		}
+		inline.Directives = p.directives()
+		inline.Selections = p.selectionSet()
+		return inline
+	}
+
+	field := &Field{Location: loc, Name: p.name()}
+	if p.skip(":") {
+		field.Alias, field.Name = field.Name, p.name()
+	}
+	field.Arguments = p.arguments(false)
+	field.Directives = p.directives()
+	if p.peek("{") {
+		field.Selections = p.selectionSet()
+	}
+	return field
+}
+
+// arguments parses an optional (name: value, ...) list
+func (p *parser) arguments(constant bool) []*Argument {
+	if !p.skip("(") {
+		return nil
+	}
+	var args []*Argument
+	for !p.skip(")") {
+		arg := &Argument{Name: p.name()}
+		p.expect(":")
+		arg.Value = p.value(constant)
+		args = append(args, arg)
+	}
+	return args
+}
+
+// directives parses zero or more @name(args)
+func (p *parser) directives() []*Directive {
+	var directives []*Directive
+	for p.peek("@") {
+		loc := p.tok.loc
+		p.next()
+		directives = append(directives, &Directive{Location: loc, Name: p.name(), Arguments: p.arguments(false)})
+	}
+	return directives
+}
+
+// value parses a literal or, unless constant, a variable
+func (p *parser) value(constant bool) *Value {
+	tok := p.tok
+	switch tok.kind {
+	case tokenInt:
+		p.next()
+		return &Value{Kind: IntValue, Raw: tok.value}
+	case tokenFloat:
+		p.next()
+		return &Value{Kind: FloatValue, Raw: tok.value}
+	case tokenString:
+		p.next()
+		return &Value{Kind: StringValue, Raw: tok.value}
+	case tokenName:
+		p.next()
+		switch tok.value {
+		case "true", "false":
+			return &Value{Kind: BooleanValue, Raw: tok.value}
+		case "null":
+			return &Value{Kind: NullValue}
+		default:
+			return &Value{Kind: EnumValue, Raw: tok.value}
+		}
+	}
+
+	switch {
+	case p.skip("$"):
+		if constant {
+			p.failAt(tok.loc, "variables are not allowed here")
+		}
+		return &Value{Kind: VariableValue, Raw: p.name()}
+	case p.skip("["):
+		list := &Value{Kind: ListValue}
+		for !p.skip("]") {
+			list.List = append(list.List, p.value(constant))
+		}
+		return list
+	case p.skip("{"):
+		object := &Value{Kind: ObjectValue}
+		for !p.skip("}") {
+			field := &ObjectField{Name: p.name()}
+			p.expect(":")
+			field.Value = p.value(constant)
+			object.Fields = append(object.Fields, field)
+		}
+		return object
+	}
+	p.fail("expected a value, got %q", tok.value)
+	return nil
+}
+
+// name consumes a name token
+func (p *parser) name() string {
+	if p.tok.kind != tokenName {
+		p.fail("expected a name, got %q", p.tok.value)
+	}
+	name := p.tok.value
+	p.next()
+	return name
+}
+
+// expect consumes the punctuator or fails
+func (p *parser) expect(punct string) {
+	if !p.skip(punct) {
+		p.fail("expected %q, got %q", punct, p.tok.value)
+	}
+}
+
+// skip consumes the punctuator if it is next
+func (p *parser) skip(punct string) bool {
+	if p.peek(punct) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+// peek reports whether the next token is the punctuator
+func (p *parser) peek(punct string) bool {
+	return p.tok.kind == tokenPunct && p.tok.value == punct
+}
+
+// peekName reports whether the next token is the name
+func (p *parser) peekName(name string) bool {
+	return p.tok.kind == tokenName && p.tok.value == name
+}
+
+// fail aborts parsing at the current token
+func (p *parser) fail(format string, args ...interface{}) {
+	p.failAt(p.tok.loc, format, args...)
+}
+
+// failAt aborts parsing at loc
+func (p *parser) failAt(loc Location, format string, args ...interface{}) {
+	panic(&SyntaxError{Message: fmt.Sprintf(format, args...), Location: loc})
+}
+
+// next reads the next token, skipping whitespace, commas and comments
+func (p *parser) next() {
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == '\n':
+			p.advance(1)
+			p.line++
+			p.column = 1
+		case c == ' ' || c == '\t' || c == '\r' || c == ',':
+			p.advance(1)
+		case c == '#':
+			for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+				p.advance(1)
+			}
+		case strings.HasPrefix(p.src[p.pos:], "\uFEFF"):
+			p.pos += len("\uFEFF")
+		default:
+			p.lex()
+			return
+		}
+	}
+	p.tok = token{kind: tokenEOF, value: "<EOF>", loc: Location{Line: p.line, Column: p.column}}
+}
+
+// lex reads the token starting at the current position
+func (p *parser) lex() {
+	loc := Location{Line: p.line, Column: p.column}
+	start := p.pos
+	c := p.src[p.pos]
+
+	switch {
+	case strings.HasPrefix(p.src[p.pos:], "..."):
+		p.advance(3)
+		p.tok = token{kind: tokenPunct, value: "...", loc: loc}
+	case strings.ContainsRune("!$&()/:=@[]{}|", rune(c)):
+		p.advance(1)
+		p.tok = token{kind: tokenPunct, value: string(c), loc: loc}
+	case c == '_' || isLetter(c):
+		for p.pos < len(p.src) && (p.src[p.pos] == '_' || isLetter(p.src[p.pos]) || isDigit(p.src[p.pos])) {
+			p.advance(1)
+		}
+		p.tok = token{kind: tokenName, value: p.src[start:p.pos], loc: loc}
+	case c == '-' || isDigit(c):
+		p.lexNumber(loc)
+	case c == '"':
+		p.lexString(loc)
+	default:
+		r, _ := utf8.DecodeRuneInString(p.src[p.pos:])
+		p.failAt(loc, "unexpected character %q", r)
+	}
+}
+
+// lexNumber reads an int or float literal
+func (p *parser) lexNumber(loc Location) {
+	start := p.pos
+	kind := tokenInt
+	if p.src[p.pos] == '-' {
+		p.advance(1)
+	}
+	p.digits(loc)
+	if p.pos < len(p.src) && p.src[p.pos] == '.' {
+		kind = tokenFloat
+		p.advance(1)
+		p.digits(loc)
+	}
+	if p.pos < len(p.src) && (p.src[p.pos] == 'e' || p.src[p.pos] == 'E') {
+		kind = tokenFloat
+		p.advance(1)
+		if p.pos < len(p.src) && (p.src[p.pos] == '+' || p.src[p.pos] == '-') {
+			p.advance(1)
+		}
+		p.digits(loc)
+	}
+	p.tok = token{kind: kind, value: p.src[start:p.pos], loc: loc}
+}
+
+// digits reads one or more digits
+func (p *parser) digits(loc Location) {
+	start := p.pos
+	for p.pos < len(p.src) && isDigit(p.src[p.pos]) {
+		p.advance(1)
+	}
+	if p.pos == start {
+		p.failAt(loc, "invalid number")
+	}
+}
+
+// lexString reads a quoted or block string and decodes it
+func (p *parser) lexString(loc Location) {
+	if strings.HasPrefix(p.src[p.pos:], `"""`) {
+		end := strings.Index(p.src[p.pos+3:], `"""`)
+		if end < 0 {
+			p.failAt(loc, "unterminated block string")
+		}
+		raw := p.src[p.pos+3 : p.pos+3+end]
+		for _, r := range p.src[p.pos : p.pos+end+6] {
+			if r == '\n' {
+				p.line++
+				p.column = 0
+			}
+			p.column++
+		}
+		p.pos += end + 6
+		p.tok = token{kind: tokenString, value: strings.TrimSpace(strings.ReplaceAll(raw, `\"""`, `"""`)), loc: loc}
+		return
+	}
+
+	start := p.pos
+	p.advance(1)
+	for {
+		if p.pos >= len(p.src) || p.src[p.pos] == '\n' {
+			p.failAt(loc, "unterminated string")
+		}
+		if p.src[p.pos] == '\\' {
+			p.advance(2)
+			continue
+		}
+		if p.src[p.pos] == '"' {
+			p.advance(1)
+			break
+		}
+		p.advance(1)
+	}
+
+	value, err := strconv.Unquote(p.src[start:p.pos])
+	if err != nil {
+		p.failAt(loc, "invalid string %s", p.src[start:p.pos])
+	}
+	p.tok = token{kind: tokenString, value: value, loc: loc}
+}
+
+// advance moves n bytes forward on the current line
+func (p *parser) advance(n int) {
+	p.pos += n
+	p.column += n
+}
+
+// isLetter reports whether c is an ASCII letter
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// isDigit reports whether c is an ASCII digit
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,112 @@
+package graphql
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// PersistedQueryStore maps SHA-256 hashes to query documents
+// Queries loaded from a file are pinned; queries registered by clients
+// through automatic persisted queries are kept in a bounded LRU.
+type PersistedQueryStore struct {
+	mu      sync.Mutex
+	pinned  map[string]string
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+// persistedEntry is one registered query
+type persistedEntry struct {
+	hash  string
+	query string
+}
+
+// NewPersistedQueryStore creates a store keeping up to size registered queries
+func NewPersistedQueryStore(size int) *PersistedQueryStore {
+	return &PersistedQueryStore{
+		pinned:  map[string]string{},
+		size:    size,
+		order:   list.New(),
+		entries: map[string]*list.Element{},
+	}
+}
+
+// LoadFile pins the queries of a JSON file mapping hashes to queries,
+// as produced by the mobile build
+func (s *PersistedQueryStore) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read persisted queries: %w", err)
+	}
+	var queries map[string]string
+	if err := json.Unmarshal(data, &queries); err != nil {
+		return fmt.Errorf("parse persisted queries %s: %w", path, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for hash, query := range queries {
+		if HashQuery(query) != strings.ToLower(hash) {
+			return fmt.Errorf("persisted query %s does not match its hash", hash)
+		}
+		s.pinned[strings.ToLower(hash)] = query
+	}
+	return nil
+}
+
+// Get returns the query with the hash
+func (s *PersistedQueryStore) Get(hash string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if query, ok := s.pinned[hash]; ok {
+		return query, true
+	}
+	if element, ok := s.entries[hash]; ok {
+		s.order.MoveToFront(element)
+		return element.Value.(*persistedEntry).query, true
+	}
+	return "", false
+}
+
+// Register stores a query under its hash, evicting the least recently used one
+func (s *PersistedQueryStore) Register(hash, query string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.pinned[hash]; ok || s.size <= 0 {
+		return
+	}
+	if element, ok := s.entries[hash]; ok {
+		s.order.MoveToFront(element)
+		return
+	}
+	s.entries[hash] = s.order.PushFront(&persistedEntry{hash: hash, query: query})
+	for s.order.Len() > s.size {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*persistedEntry).hash)
+	}
+}
+
+// Pinned reports whether the hash belongs to a query loaded from file
+func (s *PersistedQueryStore) Pinned(hash string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.pinned[hash]
+	return ok
+}
+
+// HashQuery returns the hex SHA-256 of a query, as used by persisted queries
+func HashQuery(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:])
+}
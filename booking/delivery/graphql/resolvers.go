@@ -0,0 +1,326 @@
+package graphql
+
+import (
+	"context"
+	"strings"
+
+	"booking/domain/entity"
+	"booking/domain/identity"
+	"booking/usecase/role"
+	"booking/usecase/user"
+)
+
+// resolver resolves the booking schema with the use cases
+type resolver struct {
+	userUseCase user.UserUseCase
+	roleUseCase role.RoleUseCase
+}
+
+// New creates the server for the booking schema
+// Users and their roles are loaded in batches per depth of the query, so a
+// page of users with their roles costs two repository calls, not 1+N.
+func New(userUseCase user.UserUseCase, roleUseCase role.RoleUseCase, opts ...Option) *Server {
+	r := &resolver{userUseCase: userUseCase, roleUseCase: roleUseCase}
+	return NewServer(r.schema(), append([]Option{WithContext(r.withLoaders)}, opts...)...)
+}
+
+// loaders are the per-request loaders and permission answers
+type loaders struct {
+	users       *Loader[uint, *entity.User]
+	roles       *Loader[uint, []*entity.Role]
+	permissions *Loader[string, bool]
+}
+
+// loadersKey is the context key of the request's loaders
+type loadersKey struct{}
+
+// withLoaders attaches fresh loaders to the context of one request
+func (r *resolver) withLoaders(ctx context.Context) context.Context {
+	l := &loaders{
+		users: NewLoader(ctx, func(ctx context.Context, ids []uint) (map[uint]*entity.User, error) {
+			users, err := r.userUseCase.GetUsersByIDs(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			byID := make(map[uint]*entity.User, len(users))
+			for _, u := range users {
+				byID[u.ID] = u
+			}
+			return byID, nil
+		}),
+		roles: NewLoader(ctx, func(ctx context.Context, userIDs []uint) (map[uint][]*entity.Role, error) {
+			return r.roleUseCase.GetRolesForUsers(ctx, userIDs)
+		}),
+		permissions: NewLoader(ctx, func(ctx context.Context, permissions []string) (map[string]bool, error) {
+			actorID, ok := identity.ActorFromContext(ctx)
+			granted := make(map[string]bool, len(permissions))
+			if !ok {
+				return granted, nil
+			}
+			for _, permission := range permissions {
+				allowed, err := r.roleUseCase.HasPermission(ctx, actorID, permission)
+				if err != nil {
+					return nil, err
+				}
+				granted[permission] = allowed
+			}
+			return granted, nil
+		}),
+	}
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+// loadersFrom returns the loaders attached by withLoaders
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+// Types of the booking schema
+var (
+	userSortEnum = &Enum{
+		Name:        "UserSort",
+		Description: "Order of a user listing",
+		Values:      []string{"CREATED_AT", "CREATED_AT_DESC", "USERNAME", "USERNAME_DESC", "EMAIL", "EMAIL_DESC"},
+	}
+
+	roleType = &Object{
+		Name: "Role",
+		Fields: []*FieldDef{
+			{Name: "name", Type: &NonNull{Of: String}, Resolve: func(ctx context.Context, p ResolveParams) (interface{}, error) {
+				return p.Source.(*entity.Role).Name, nil
+			}},
+			{Name: "description", Type: &NonNull{Of: String}, Resolve: func(ctx context.Context, p ResolveParams) (interface{}, error) {
+				return p.Source.(*entity.Role).Description, nil
+			}},
+			{Name: "permissions", Type: &NonNull{Of: &List{Of: &NonNull{Of: String}}}, Resolve: func(ctx context.Context, p ResolveParams) (interface{}, error) {
+				permissions := p.Source.(*entity.Role).Permissions
+				names := make([]string, 0, len(permissions))
+				for _, permission := range permissions {
+					names = append(names, permission.Name)
+				}
+				return names, nil
+			}},
+		},
+	}
+)
+
+// schema builds the query type and the types reachable from it
+func (r *resolver) schema() *Schema {
+	userType := &Object{
+		Name: "User",
+		Fields: []*FieldDef{
+			{Name: "id", Type: &NonNull{Of: ID}, Resolve: userField(func(u *entity.User) interface{} { return u.ID })},
+			{Name: "email", Type: &NonNull{Of: String}, Resolve: userField(func(u *entity.User) interface{} { return u.Email })},
+			{Name: "username", Type: &NonNull{Of: String}, Resolve: userField(func(u *entity.User) interface{} { return u.Username })},
+			{Name: "fullName", Type: &NonNull{Of: String}, Resolve: userField(func(u *entity.User) interface{} { return u.FullName })},
+			{Name: "phone", Type: &NonNull{Of: String}, Resolve: userField(func(u *entity.User) interface{} { return u.Phone })},
+			{Name: "isActive", Type: &NonNull{Of: Boolean}, Resolve: userField(func(u *entity.User) interface{} { return u.IsActive })},
+			{Name: "createdAt", Type: &NonNull{Of: DateTime}, Resolve: userField(func(u *entity.User) interface{} { return u.CreatedAt })},
+			{Name: "updatedAt", Type: &NonNull{Of: DateTime}, Resolve: userField(func(u *entity.User) interface{} { return u.UpdatedAt })},
+			{
+				Name:        "roles",
+				Description: "Visible to the user themselves and to holders of " + entity.PermissionRolesManage,
+				Type:        &List{Of: &NonNull{Of: roleType}},
+				Resolve:     r.userRoles,
+			},
+		},
+	}
+
+	connectionType := &Object{
+		Name:        "UserConnection",
+		Description: "One page of users",
+		Fields: []*FieldDef{
+			{Name: "nodes", Type: &NonNull{Of: &List{Of: &NonNull{Of: userType}}}, Resolve: func(ctx context.Context, p ResolveParams) (interface{}, error) {
+				return p.Source.(*userConnection).page.Users, nil
+			}},
+			{Name: "endCursor", Description: "Pass as after to get the next page", Type: String, Resolve: func(ctx context.Context, p ResolveParams) (interface{}, error) {
+				if cursor := p.Source.(*userConnection).page.NextCursor; cursor != "" {
+					return cursor, nil
+				}
+				return nil, nil
+			}},
+			{Name: "hasNextPage", Type: &NonNull{Of: Boolean}, Resolve: func(ctx context.Context, p ResolveParams) (interface{}, error) {
+				return p.Source.(*userConnection).page.HasMore, nil
+			}},
+			{Name: "totalCount", Description: "Users matching the filters, on every page", Type: &NonNull{Of: Int}, Resolve: r.totalCount},
+		},
+	}
+
+	return &Schema{Query: &Object{
+		Name: "Query",
+		Fields: []*FieldDef{
+			{Name: "me", Description: "The authenticated user", Type: userType, Resolve: r.me},
+			{
+				Name:        "user",
+				Description: "A user by ID; null when there is none. Requires " + entity.PermissionUsersRead,
+				Type:        userType,
+				Args:        []*ArgDef{{Name: "id", Type: &NonNull{Of: ID}}},
+				Resolve:     r.user,
+			},
+			{
+				Name:        "users",
+				Description: "Users with filters, sorting and cursor pagination. Requires " + entity.PermissionUsersRead,
+				Type:        &NonNull{Of: connectionType},
+				Args: []*ArgDef{
+					{Name: "first", Description: "Page size, at most 100", Type: Int, Default: 20},
+					{Name: "after", Description: "endCursor of the previous page", Type: String},
+					{Name: "query", Description: "Prefix of username or email, or the whole full name", Type: String},
+					{Name: "isActive", Type: Boolean},
+					{Name: "sort", Type: userSortEnum, Default: "CREATED_AT"},
+				},
+				SizeArg: "first",
+				Resolve: r.users,
+			},
+		},
+	}}
+}
+
+// userField resolves a plain field of a user
+func userField(get func(u *entity.User) interface{}) ResolveFunc {
+	return func(ctx context.Context, p ResolveParams) (interface{}, error) {
+		return get(p.Source.(*entity.User)), nil
+	}
+}
+
+// require checks that the actor holds permission, asking the role use case once per request
+func (r *resolver) require(ctx context.Context, permission string) error {
+	if _, ok := identity.ActorFromContext(ctx); !ok {
+		return toError(ctx, role.ErrUnauthenticated)
+	}
+	granted, err := loadersFrom(ctx).permissions.Load(permission)()
+	if err != nil {
+		return toError(ctx, err)
+	}
+	if granted != true {
+		return toError(ctx, role.ErrForbidden)
+	}
+	return nil
+}
+
+// me resolves Query.me, the only query open to any authenticated user
+func (r *resolver) me(ctx context.Context, p ResolveParams) (interface{}, error) {
+	actorID, ok := identity.ActorFromContext(ctx)
+	if !ok {
+		return nil, toError(ctx, role.ErrUnauthenticated)
+	}
+	return wrapThunk(ctx, loadersFrom(ctx).users.Load(actorID)), nil
+}
+
+// user resolves Query.user
+func (r *resolver) user(ctx context.Context, p ResolveParams) (interface{}, error) {
+	if err := r.require(ctx, entity.PermissionUsersRead); err != nil {
+		return nil, err
+	}
+	id, err := identity.ParseUserID(p.Args["id"].(string))
+	if err != nil {
+		return nil, toError(ctx, err)
+	}
+	return wrapThunk(ctx, loadersFrom(ctx).users.Load(id)), nil
+}
+
+// userConnection is the source of UserConnection
+type userConnection struct {
+	filter entity.UserFilter
+	page   *entity.UserPage
+}
+
+// users resolves Query.users
+func (r *resolver) users(ctx context.Context, p ResolveParams) (interface{}, error) {
+	if err := r.require(ctx, entity.PermissionUsersRead); err != nil {
+		return nil, err
+	}
+	// An explicit null falls back to the defaults of the use case
+	first, _ := p.Args["first"].(int)
+	filter := entity.UserFilter{Limit: first}
+	if query, ok := p.Args["query"].(string); ok {
+		filter.Search = &query
+	}
+	if isActive, ok := p.Args["isActive"].(bool); ok {
+		filter.IsActive = &isActive
+	}
+	sort, _ := p.Args["sort"].(string)
+	filter.SortBy = strings.ToLower(strings.TrimSuffix(sort, "_DESC"))
+	filter.SortDesc = strings.HasSuffix(sort, "_DESC")
+	if after, ok := p.Args["after"].(string); ok {
+		cursor, err := entity.DecodeUserCursor(after)
+		if err != nil {
+			return nil, toError(ctx, err)
+		}
+		filter.After = cursor
+	}
+
+	// ListUsers fills in defaults, so the filter is copied for the count first
+	connection := &userConnection{filter: filter}
+	page, err := r.userUseCase.ListUsers(ctx, &filter)
+	if err != nil {
+		return nil, toError(ctx, err)
+	}
+	connection.page = page
+
+	// Users listed here need no second load when selected again by ID
+	users := loadersFrom(ctx).users
+	for _, u := range page.Users {
+		users.Prime(u.ID, u)
+	}
+	return connection, nil
+}
+
+// totalCount resolves UserConnection.totalCount, counting only when selected
+func (r *resolver) totalCount(ctx context.Context, p ResolveParams) (interface{}, error) {
+	filter := p.Source.(*userConnection).filter
+	filter.After = nil
+	count, err := r.userUseCase.CountUsers(ctx, &filter)
+	if err != nil {
+		return nil, toError(ctx, err)
+	}
+	return int(count), nil
+}
+
+// userRoles resolves User.roles for the user themselves or a role manager
+func (r *resolver) userRoles(ctx context.Context, p ResolveParams) (interface{}, error) {
+	u := p.Source.(*entity.User)
+	l := loadersFrom(ctx)
+	actorID, ok := identity.ActorFromContext(ctx)
+	if !ok {
+		return nil, toError(ctx, role.ErrUnauthenticated)
+	}
+
+	load := l.roles.Load(u.ID)
+	roles := func() (interface{}, error) {
+		value, err := load()
+		if err != nil {
+			return nil, toError(ctx, err)
+		}
+		// Users without roles are missing from the batch
+		if isNil(value) {
+			return []*entity.Role{}, nil
+		}
+		return value, nil
+	}
+	if actorID == u.ID {
+		return Thunk(roles), nil
+	}
+	// The permission is checked once per request, however many users are listed
+	allowed := l.permissions.Load(entity.PermissionRolesManage)
+	return Thunk(func() (interface{}, error) {
+		granted, err := allowed()
+		if err != nil {
+			return nil, toError(ctx, err)
+		}
+		if granted != true {
+			return nil, toError(ctx, role.ErrForbidden)
+		}
+		return roles()
+	}), nil
+}
+
+// wrapThunk maps the error of a loader thunk like any other resolver error
+func wrapThunk(ctx context.Context, thunk Thunk) Thunk {
+	return func() (interface{}, error) {
+		value, err := thunk()
+		if err != nil {
+			return nil, toError(ctx, err)
+		}
+		return value, nil
+	}
+}
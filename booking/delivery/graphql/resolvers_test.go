@@ -0,0 +1,218 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+
+	"booking/domain/entity"
+	"booking/domain/identity"
+	"booking/usecase/role"
+	"booking/usecase/user"
+)
+
+// fakeUsers serves users from a map, recording the batches asked for
+type fakeUsers struct {
+	user.UserUseCase
+	users   map[uint]*entity.User
+	batches [][]uint
+}
+
+func (f *fakeUsers) GetUsersByIDs(ctx context.Context, ids []uint) ([]*entity.User, error) {
+	f.batches = append(f.batches, ids)
+	var users []*entity.User
+	for _, id := range ids {
+		if u, ok := f.users[id]; ok {
+			users = append(users, u)
+		}
+	}
+	return users, nil
+}
+
+func (f *fakeUsers) ListUsers(ctx context.Context, filter *entity.UserFilter) (*entity.UserPage, error) {
+	page := &entity.UserPage{}
+	for _, u := range f.users {
+		page.Users = append(page.Users, u)
+	}
+	sort.Slice(page.Users, func(i, j int) bool { return page.Users[i].ID < page.Users[j].ID })
+	return page, nil
+}
+
+func (f *fakeUsers) CountUsers(ctx context.Context, filter *entity.UserFilter) (int64, error) {
+	return int64(len(f.users)), nil
+}
+
+// fakeRoles grants permissions per user, counting the calls
+type fakeRoles struct {
+	role.RoleUseCase
+	granted     map[uint][]string
+	checks      map[string]int
+	roleBatches [][]uint
+	err         error
+}
+
+func (f *fakeRoles) HasPermission(ctx context.Context, userID uint, permission string) (bool, error) {
+	if f.checks == nil {
+		f.checks = map[string]int{}
+	}
+	f.checks[permission]++
+	if f.err != nil {
+		return false, f.err
+	}
+	for _, p := range f.granted[userID] {
+		if p == permission {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeRoles) GetRolesForUsers(ctx context.Context, userIDs []uint) (map[uint][]*entity.Role, error) {
+	f.roleBatches = append(f.roleBatches, userIDs)
+	roles := make(map[uint][]*entity.Role, len(userIDs))
+	for _, id := range userIDs {
+		roles[id] = []*entity.Role{{Name: entity.RoleRunner}}
+	}
+	return roles, nil
+}
+
+// newBookingServer serves users 1 (an admin holding users:read and roles:manage), 2 and 3
+func newBookingServer() (*Server, *fakeUsers, *fakeRoles) {
+	users := &fakeUsers{users: map[uint]*entity.User{
+		1: {ID: 1, Email: "admin@example.com", Username: "admin"},
+		2: {ID: 2, Email: "an@example.com", Username: "an"},
+		3: {ID: 3, Email: "binh@example.com", Username: "binh"},
+	}}
+	roles := &fakeRoles{granted: map[uint][]string{
+		1: {entity.PermissionUsersRead, entity.PermissionRolesManage},
+	}}
+	return New(users, roles), users, roles
+}
+
+func TestUserQueriesRequireUsersRead(t *testing.T) {
+	tests := []struct {
+		name     string
+		actor    uint
+		query    string
+		wantCode string
+	}{
+		{name: "anonymous user", query: "{ user(id: 2) { id } }", wantCode: "UNAUTHENTICATED"},
+		{name: "anonymous users", query: "{ users { totalCount } }", wantCode: "UNAUTHENTICATED"},
+		{name: "anonymous me", query: "{ me { id } }", wantCode: "UNAUTHENTICATED"},
+		{name: "runner user", actor: 2, query: "{ user(id: 3) { id } }", wantCode: "FORBIDDEN"},
+		{name: "runner users", actor: 2, query: "{ users { nodes { id } } }", wantCode: "FORBIDDEN"},
+		{name: "runner user of themselves", actor: 2, query: "{ user(id: 2) { id } }", wantCode: "FORBIDDEN"},
+		{name: "runner me", actor: 2, query: "{ me { id email } }"},
+		{name: "admin user", actor: 1, query: "{ user(id: 3) { id } }"},
+		{name: "admin users", actor: 1, query: "{ users { nodes { id } totalCount } }"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, _ := newBookingServer()
+			ctx := context.Background()
+			if tt.actor != 0 {
+				ctx = identity.WithActor(ctx, tt.actor)
+			}
+			resp := do(t, s, ctx, &Request{Query: tt.query})
+			if got := resp.code(); got != tt.wantCode {
+				t.Fatalf("code = %q, want %q (errors %+v)", got, tt.wantCode, resp.Errors)
+			}
+			if tt.wantCode == "" && len(resp.Errors) > 0 {
+				t.Fatalf("errors = %+v", resp.Errors)
+			}
+		})
+	}
+}
+
+func TestPermissionCheckedOncePerRequest(t *testing.T) {
+	s, _, roles := newBookingServer()
+	ctx := identity.WithActor(context.Background(), 1)
+
+	resp := do(t, s, ctx, &Request{Query: "{ a: user(id: 2) { id } b: user(id: 3) { id } users { totalCount } }"})
+	if len(resp.Errors) > 0 {
+		t.Fatalf("errors = %+v", resp.Errors)
+	}
+	if got := roles.checks[entity.PermissionUsersRead]; got != 1 {
+		t.Errorf("users:read checked %d times, want 1", got)
+	}
+
+	// A new request asks again
+	do(t, s, ctx, &Request{Query: "{ user(id: 2) { id } }"})
+	if got := roles.checks[entity.PermissionUsersRead]; got != 2 {
+		t.Errorf("users:read checked %d times over two requests, want 2", got)
+	}
+}
+
+func TestPermissionCheckFailureIsInternal(t *testing.T) {
+	s, _, roles := newBookingServer()
+	roles.err = errors.New("connection refused")
+
+	resp := do(t, s, identity.WithActor(context.Background(), 1), &Request{Query: "{ user(id: 2) { id } }"})
+	if got := resp.code(); got != "INTERNAL" {
+		t.Fatalf("code = %q, want INTERNAL (errors %+v)", got, resp.Errors)
+	}
+	if resp.Errors[0].Message == "connection refused" {
+		t.Error("the cause leaked into the response")
+	}
+}
+
+func TestUsersLoadedInOneBatch(t *testing.T) {
+	s, users, _ := newBookingServer()
+	ctx := identity.WithActor(context.Background(), 1)
+
+	resp := do(t, s, ctx, &Request{Query: "{ me { id } a: user(id: 2) { id } b: user(id: 3) { email } c: user(id: 9) { id } }"})
+	if len(resp.Errors) > 0 {
+		t.Fatalf("errors = %+v", resp.Errors)
+	}
+	if len(users.batches) != 1 {
+		t.Fatalf("GetUsersByIDs called %d times, want 1: %v", len(users.batches), users.batches)
+	}
+	if got := len(users.batches[0]); got != 4 {
+		t.Errorf("batch = %v, want the 4 IDs", users.batches[0])
+	}
+	if resp.Data["c"] != nil {
+		t.Errorf("c = %v, want null for a missing user", resp.Data["c"])
+	}
+	if b, _ := resp.Data["b"].(map[string]interface{}); b["email"] != "binh@example.com" {
+		t.Errorf("b = %v", resp.Data["b"])
+	}
+}
+
+func TestListedUsersNeedNoSecondLoad(t *testing.T) {
+	s, users, roles := newBookingServer()
+	ctx := identity.WithActor(context.Background(), 1)
+
+	resp := do(t, s, ctx, &Request{Query: "{ users { nodes { id roles { name } } } again: user(id: 2) { id } }"})
+	if len(resp.Errors) > 0 {
+		t.Fatalf("errors = %+v", resp.Errors)
+	}
+	if len(roles.roleBatches) != 1 || len(roles.roleBatches[0]) != 3 {
+		t.Errorf("GetRolesForUsers batches = %v, want one of 3 users", roles.roleBatches)
+	}
+	if len(users.batches) != 0 {
+		t.Errorf("GetUsersByIDs batches = %v, want none for listed users", users.batches)
+	}
+	if got := roles.checks[entity.PermissionRolesManage]; got != 1 {
+		t.Errorf("roles:manage checked %d times, want 1", got)
+	}
+}
+
+func TestRolesOfOthersRequireRolesManage(t *testing.T) {
+	s, users, roles := newBookingServer()
+	// User 2 may read users but not manage roles
+	roles.granted[2] = []string{entity.PermissionUsersRead}
+	ctx := identity.WithActor(context.Background(), 2)
+
+	resp := do(t, s, ctx, &Request{Query: "{ me { roles { name } } other: user(id: 3) { id roles { name } } }"})
+	if len(resp.Errors) != 1 || resp.code() != "FORBIDDEN" {
+		t.Fatalf("errors = %+v, want one FORBIDDEN for the other user's roles", resp.Errors)
+	}
+	me, _ := resp.Data["me"].(map[string]interface{})
+	if ownRoles, _ := me["roles"].([]interface{}); len(ownRoles) != 1 {
+		t.Errorf("me.roles = %v, want the user's own roles", me["roles"])
+	}
+	if len(users.batches) != 1 {
+		t.Errorf("GetUsersByIDs called %d times, want 1", len(users.batches))
+	}
+}
@@ -0,0 +1,373 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Type is a *Scalar, *Enum, *Object, *List or *NonNull
+type Type interface {
+	String() string
+}
+
+// Scalar is a leaf type with its own serialization
+type Scalar struct {
+	Name        string
+	Description string
+	// Serialize converts a resolved Go value to its JSON representation
+	Serialize func(value interface{}) (interface{}, error)
+	// Parse converts a literal or JSON variable value to the Go value
+	// passed to resolvers
+	Parse func(value interface{}) (interface{}, error)
+}
+
+// Enum is a leaf type restricted to a set of names
+type Enum struct {
+	Name        string
+	Description string
+	Values      []string
+}
+
+// Object is an output type with fields
+type Object struct {
+	Name        string
+	Description string
+	Fields      []*FieldDef
+}
+
+// List wraps a type in a list
+type List struct {
+	Of Type
+}
+
+// NonNull marks a type as never null
+type NonNull struct {
+	Of Type
+}
+
+func (t *Scalar) String() string  { return t.Name }
+func (t *Enum) String() string    { return t.Name }
+func (t *Object) String() string  { return t.Name }
+func (t *List) String() string    { return "[" + t.Of.String() + "]" }
+func (t *NonNull) String() string { return t.Of.String() + "!" }
+
+// Field returns the field definition with the name, or nil
+func (t *Object) Field(name string) *FieldDef {
+	for _, field := range t.Fields {
+		if field.Name == name {
+			return field
+		}
+	}
+	return nil
+}
+
+// FieldDef defines a field of an object type
+type FieldDef struct {
+	Name        string
+	Description string
+	Type        Type
+	Args        []*ArgDef
+	Resolve     ResolveFunc
+	// SizeArg names the argument bounding the length of a list field; the
+	// complexity of its selections is multiplied by that value
+	SizeArg string
+}
+
+// Arg returns the argument definition with the name, or nil
+func (f *FieldDef) Arg(name string) *ArgDef {
+	for _, arg := range f.Args {
+		if arg.Name == name {
+			return arg
+		}
+	}
+	return nil
+}
+
+// ArgDef defines a field argument
+type ArgDef struct {
+	Name        string
+	Description string
+	Type        Type
+	// Default is the parsed value used when the argument is omitted
+	Default interface{}
+}
+
+// ResolveParams is what a resolver receives
+type ResolveParams struct {
+	// Source is the resolved value of the parent object
+	Source interface{}
+	// Args holds the coerced arguments, defaults applied
+	Args map[string]interface{}
+}
+
+// ResolveFunc resolves a field value
+// The value may be a Thunk, which is called only once every field of the
+// current depth has been resolved, so loaders can batch their keys.
+type ResolveFunc func(ctx context.Context, p ResolveParams) (interface{}, error)
+
+// Thunk is a deferred field value
+type Thunk func() (interface{}, error)
+
+// Schema is the root of the type system
+// Only queries are supported; the API is read-only.
+type Schema struct {
+	Query *Object
+}
+
+// types returns every named type reachable from the query type, in a stable order
+func (s *Schema) types() []Type {
+	seen := map[string]Type{}
+	var walk func(t Type)
+	walk = func(t Type) {
+		switch t := t.(type) {
+		case *List:
+			walk(t.Of)
+		case *NonNull:
+			walk(t.Of)
+		case *Object:
+			if _, ok := seen[t.Name]; ok {
+				return
+			}
+			seen[t.Name] = t
+			for _, field := range t.Fields {
+				walk(field.Type)
+				for _, arg := range field.Args {
+					walk(arg.Type)
+				}
+			}
+		case *Scalar:
+			seen[t.Name] = t
+		case *Enum:
+			seen[t.Name] = t
+		}
+	}
+	walk(s.Query)
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		if name != s.Query.Name && !builtinScalars[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	types := []Type{s.Query}
+	for _, name := range names {
+		types = append(types, seen[name])
+	}
+	return types
+}
+
+// SDL renders the schema in the GraphQL schema definition language
+// It stands in for introspection, which is not implemented.
+func (s *Schema) SDL() string {
+	var b strings.Builder
+	for i, t := range s.types() {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		switch t := t.(type) {
+		case *Scalar:
+			writeDescription(&b, "", t.Description)
+			fmt.Fprintf(&b, "scalar %s\n", t.Name)
+		case *Enum:
+			writeDescription(&b, "", t.Description)
+			fmt.Fprintf(&b, "enum %s {\n", t.Name)
+			for _, value := range t.Values {
+				fmt.Fprintf(&b, "  %s\n", value)
+			}
+			b.WriteString("}\n")
+		case *Object:
+			writeDescription(&b, "", t.Description)
+			fmt.Fprintf(&b, "type %s {\n", t.Name)
+			for _, field := range t.Fields {
+				writeDescription(&b, "  ", field.Description)
+				b.WriteString("  " + field.Name)
+				if len(field.Args) > 0 {
+					args := make([]string, 0, len(field.Args))
+					for _, arg := range field.Args {
+						def := arg.Name + ": " + arg.Type.String()
+						if arg.Default != nil {
+							def += " = " + formatDefault(arg.Type, arg.Default)
+						}
+						args = append(args, def)
+					}
+					b.WriteString("(" + strings.Join(args, ", ") + ")")
+				}
+				b.WriteString(": " + field.Type.String() + "\n")
+			}
+			b.WriteString("}\n")
+		}
+	}
+	return b.String()
+}
+
+// writeDescription writes a description as a block string
+func writeDescription(b *strings.Builder, indent, description string) {
+	if description != "" {
+		fmt.Fprintf(b, "%s\"\"\"%s\"\"\"\n", indent, description)
+	}
+}
+
+// formatDefault renders a default value as a literal
+func formatDefault(t Type, value interface{}) string {
+	if nonNull, ok := t.(*NonNull); ok {
+		t = nonNull.Of
+	}
+	if _, ok := t.(*Enum); ok {
+		return fmt.Sprint(value)
+	}
+	if s, ok := value.(string); ok {
+		return strconv.Quote(s)
+	}
+	return fmt.Sprint(value)
+}
+
+// builtinScalars are left out of the SDL
+var builtinScalars = map[string]bool{"Int": true, "Float": true, "String": true, "Boolean": true, "ID": true}
+
+// Built-in scalars
+var (
+	Int = &Scalar{
+		Name: "Int",
+		Serialize: func(value interface{}) (interface{}, error) {
+			return toInt(value)
+		},
+		Parse: func(value interface{}) (interface{}, error) {
+			return toInt(value)
+		},
+	}
+
+	Float = &Scalar{
+		Name: "Float",
+		Serialize: func(value interface{}) (interface{}, error) {
+			return toFloat(value)
+		},
+		Parse: func(value interface{}) (interface{}, error) {
+			return toFloat(value)
+		},
+	}
+
+	String = &Scalar{
+		Name: "String",
+		Serialize: func(value interface{}) (interface{}, error) {
+			return fmt.Sprint(value), nil
+		},
+		Parse: func(value interface{}) (interface{}, error) {
+			s, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected a string, got %v", value)
+			}
+			return s, nil
+		},
+	}
+
+	Boolean = &Scalar{
+		Name: "Boolean",
+		Serialize: func(value interface{}) (interface{}, error) {
+			b, ok := value.(bool)
+			if !ok {
+				return nil, fmt.Errorf("expected a boolean, got %v", value)
+			}
+			return b, nil
+		},
+		Parse: func(value interface{}) (interface{}, error) {
+			b, ok := value.(bool)
+			if !ok {
+				return nil, fmt.Errorf("expected a boolean, got %v", value)
+			}
+			return b, nil
+		},
+	}
+
+	// ID is serialized as a string but accepts integers as input
+	ID = &Scalar{
+		Name: "ID",
+		Serialize: func(value interface{}) (interface{}, error) {
+			return fmt.Sprint(value), nil
+		},
+		Parse: func(value interface{}) (interface{}, error) {
+			switch v := value.(type) {
+			case string:
+				return v, nil
+			case int:
+				return strconv.Itoa(v), nil
+			case int64:
+				return strconv.FormatInt(v, 10), nil
+			case float64:
+				if v == math.Trunc(v) {
+					return strconv.FormatFloat(v, 'f', 0, 64), nil
+				}
+			}
+			return nil, fmt.Errorf("expected an ID, got %v", value)
+		},
+	}
+
+	// DateTime is an RFC 3339 timestamp
+	DateTime = &Scalar{
+		Name:        "DateTime",
+		Description: "An RFC 3339 timestamp",
+		Serialize: func(value interface{}) (interface{}, error) {
+			t, ok := value.(time.Time)
+			if !ok {
+				return nil, fmt.Errorf("expected a time, got %v", value)
+			}
+			return t.UTC().Format(time.RFC3339), nil
+		},
+		Parse: func(value interface{}) (interface{}, error) {
+			switch v := value.(type) {
+			case time.Time:
+				return v, nil
+			case string:
+				return time.Parse(time.RFC3339, v)
+			}
+			return nil, fmt.Errorf("expected an RFC 3339 timestamp, got %v", value)
+		},
+	}
+)
+
+// toInt accepts Go integers and integral JSON numbers within 32 bits
+func toInt(value interface{}) (interface{}, error) {
+	var n int64
+	switch v := value.(type) {
+	case int:
+		n = int64(v)
+	case int32:
+		n = int64(v)
+	case int64:
+		n = v
+	case uint:
+		n = int64(v)
+	case float64:
+		if v != math.Trunc(v) {
+			return nil, fmt.Errorf("expected an integer, got %v", value)
+		}
+		n = int64(v)
+	default:
+		return nil, fmt.Errorf("expected an integer, got %v", value)
+	}
+	if n < math.MinInt32 || n > math.MaxInt32 {
+		return nil, fmt.Errorf("integer %d does not fit in 32 bits", n)
+	}
+	return int(n), nil
+}
+
+// toFloat accepts Go numbers
+func toFloat(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	}
+	return nil, fmt.Errorf("expected a number, got %v", value)
+}
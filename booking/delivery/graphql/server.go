@@ -0,0 +1,214 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Default limits, overridable with options
+const (
+	DefaultMaxDepth      = 8
+	DefaultMaxComplexity = 500
+)
+
+// Request is a GraphQL request as sent over HTTP
+type Request struct {
+	Query         string                 `json:"query,omitempty"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+	Extensions    *RequestExtensions     `json:"extensions,omitempty"`
+}
+
+// RequestExtensions carries the persisted query hash
+type RequestExtensions struct {
+	PersistedQuery *PersistedQuery `json:"persistedQuery,omitempty"`
+}
+
+// PersistedQuery identifies a query by its SHA-256 hash (Apollo APQ protocol)
+type PersistedQuery struct {
+	Version    int    `json:"version"`
+	Sha256Hash string `json:"sha256Hash"`
+}
+
+// Server validates and executes requests against a schema
+type Server struct {
+	schema        *Schema
+	maxDepth      int
+	maxComplexity int
+	persisted     *PersistedQueryStore
+	persistedOnly bool
+	prepare       func(ctx context.Context) context.Context
+}
+
+// Option configures a Server
+type Option func(*Server)
+
+// WithMaxDepth rejects queries nesting fields deeper than depth
+func WithMaxDepth(depth int) Option {
+	return func(s *Server) {
+		s.maxDepth = depth
+	}
+}
+
+// WithMaxComplexity rejects queries costing more than complexity
+// Every field costs 1; the selections of a paginated list are counted once
+// per requested item.
+func WithMaxComplexity(complexity int) Option {
+	return func(s *Server) {
+		s.maxComplexity = complexity
+	}
+}
+
+// WithPersistedQueries enables persisted queries backed by store
+// When only is set, queries are accepted only by the hash of a query
+// loaded into the store from file.
+func WithPersistedQueries(store *PersistedQueryStore, only bool) Option {
+	return func(s *Server) {
+		s.persisted = store
+		s.persistedOnly = only
+	}
+}
+
+// WithContext sets a hook deriving the context of each execution, e.g. to
+// attach per-request loaders
+func WithContext(prepare func(ctx context.Context) context.Context) Option {
+	return func(s *Server) {
+		s.prepare = prepare
+	}
+}
+
+// NewServer creates a server for schema
+func NewServer(schema *Schema, opts ...Option) *Server {
+	s := &Server{
+		schema:        schema,
+		maxDepth:      DefaultMaxDepth,
+		maxComplexity: DefaultMaxComplexity,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Schema returns the schema served
+func (s *Server) Schema() *Schema {
+	return s.schema
+}
+
+// Do runs a request
+// Errors before execution (syntax, validation, limits) come back without data.
+func (s *Server) Do(ctx context.Context, req *Request) *Response {
+	query, err := s.query(req)
+	if err != nil {
+		return failed(err)
+	}
+
+	doc, err := Parse(query)
+	if err != nil {
+		if syntaxErr, ok := err.(*SyntaxError); ok {
+			return failed(&Error{Message: "syntax error: " + syntaxErr.Message, Locations: []Location{syntaxErr.Location}})
+		}
+		return failed(err)
+	}
+	op, err := selectOperation(doc, req.OperationName)
+	if err != nil {
+		return failed(err)
+	}
+	if op.Type != "query" {
+		return failed(&Error{Message: fmt.Sprintf("%s operations are not supported", op.Type), Locations: []Location{op.Location}})
+	}
+
+	variables, errs := coerceVariables(s.schema, op, req.Variables)
+	if len(errs) > 0 {
+		return &Response{Errors: errs}
+	}
+	result := validate(s.schema, doc, op, variables)
+	if len(result.errors) > 0 {
+		return &Response{Errors: result.errors}
+	}
+	if s.maxDepth > 0 && result.depth > s.maxDepth {
+		return failed(&Error{
+			Message:    fmt.Sprintf("query depth %d exceeds the limit of %d", result.depth, s.maxDepth),
+			Extensions: map[string]interface{}{"code": "QUERY_TOO_DEEP"},
+		})
+	}
+	if s.maxComplexity > 0 && result.complexity > s.maxComplexity {
+		return failed(&Error{
+			Message:    fmt.Sprintf("query complexity %d exceeds the limit of %d", result.complexity, s.maxComplexity),
+			Extensions: map[string]interface{}{"code": "QUERY_TOO_COMPLEX"},
+		})
+	}
+
+	if s.prepare != nil {
+		ctx = s.prepare(ctx)
+	}
+	return execute(ctx, s.schema, doc, op, variables, nil)
+}
+
+// query returns the query text of a request, resolving and registering persisted queries
+func (s *Server) query(req *Request) (string, error) {
+	var hash string
+	if req.Extensions != nil && req.Extensions.PersistedQuery != nil {
+		if req.Extensions.PersistedQuery.Version != 1 {
+			return "", &Error{Message: "unsupported persisted query version", Extensions: map[string]interface{}{"code": "PERSISTED_QUERY_NOT_SUPPORTED"}}
+		}
+		hash = strings.ToLower(req.Extensions.PersistedQuery.Sha256Hash)
+	}
+
+	if hash == "" {
+		if s.persistedOnly {
+			return "", &Error{Message: "only persisted queries are allowed", Extensions: map[string]interface{}{"code": "PERSISTED_QUERY_REQUIRED"}}
+		}
+		if req.Query == "" {
+			return "", &Error{Message: "query is required"}
+		}
+		return req.Query, nil
+	}
+
+	if s.persisted == nil {
+		return "", &Error{Message: "PersistedQueryNotSupported", Extensions: map[string]interface{}{"code": "PERSISTED_QUERY_NOT_SUPPORTED"}}
+	}
+	if req.Query == "" {
+		query, ok := s.persisted.Get(hash)
+		if !ok || s.persistedOnly && !s.persisted.Pinned(hash) {
+			return "", &Error{Message: "PersistedQueryNotFound", Extensions: map[string]interface{}{"code": "PERSISTED_QUERY_NOT_FOUND"}}
+		}
+		return query, nil
+	}
+
+	// The client sent the query with its hash after a PersistedQueryNotFound
+	if HashQuery(req.Query) != hash {
+		return "", &Error{Message: "provided sha256Hash does not match query", Extensions: map[string]interface{}{"code": "PERSISTED_QUERY_HASH_MISMATCH"}}
+	}
+	if s.persistedOnly && !s.persisted.Pinned(hash) {
+		return "", &Error{Message: "only persisted queries are allowed", Extensions: map[string]interface{}{"code": "PERSISTED_QUERY_REQUIRED"}}
+	}
+	s.persisted.Register(hash, req.Query)
+	return req.Query, nil
+}
+
+// selectOperation picks the operation to run
+func selectOperation(doc *Document, name string) (*Operation, error) {
+	if name == "" {
+		if len(doc.Operations) > 1 {
+			return nil, &Error{Message: "operationName is required when the document has several operations"}
+		}
+		return doc.Operations[0], nil
+	}
+	for _, op := range doc.Operations {
+		if op.Name == name {
+			return op, nil
+		}
+	}
+	return nil, &Error{Message: fmt.Sprintf("unknown operation %q", name)}
+}
+
+// failed is the response of a request rejected before execution
+func failed(err error) *Response {
+	gqlErr, ok := err.(*Error)
+	if !ok {
+		gqlErr = &Error{Message: err.Error()}
+	}
+	return &Response{Errors: []*Error{gqlErr}}
+}
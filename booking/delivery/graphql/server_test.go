@@ -0,0 +1,208 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// echoSchema has one field returning its arguments, for tests of the engine alone
+func echoSchema() *Schema {
+	item := &Object{
+		Name: "Item",
+		Fields: []*FieldDef{
+			{Name: "id", Type: &NonNull{Of: ID}, Resolve: func(ctx context.Context, p ResolveParams) (interface{}, error) {
+				return p.Source.(map[string]interface{})["id"], nil
+			}},
+			{Name: "children", Type: &List{Of: &NonNull{Of: String}}, Resolve: func(ctx context.Context, p ResolveParams) (interface{}, error) {
+				return []string{}, nil
+			}},
+		},
+	}
+	return &Schema{Query: &Object{
+		Name: "Query",
+		Fields: []*FieldDef{
+			{
+				Name: "item",
+				Type: item,
+				Args: []*ArgDef{{Name: "id", Type: &NonNull{Of: ID}}, {Name: "first", Type: Int}},
+				Resolve: func(ctx context.Context, p ResolveParams) (interface{}, error) {
+					return p.Args, nil
+				},
+			},
+			{
+				Name:    "items",
+				Type:    &NonNull{Of: &List{Of: &NonNull{Of: item}}},
+				Args:    []*ArgDef{{Name: "first", Type: Int, Default: 10}},
+				SizeArg: "first",
+				Resolve: func(ctx context.Context, p ResolveParams) (interface{}, error) {
+					return []map[string]interface{}{}, nil
+				},
+			},
+		},
+	}}
+}
+
+// decoded is a response as a client sees it
+type decoded struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []struct {
+		Message    string                 `json:"message"`
+		Locations  []Location             `json:"locations"`
+		Extensions map[string]interface{} `json:"extensions"`
+	} `json:"errors"`
+}
+
+// do runs a request and decodes its JSON response
+func do(t *testing.T, s *Server, ctx context.Context, req *Request) decoded {
+	t.Helper()
+	body, err := json.Marshal(s.Do(ctx, req))
+	if err != nil {
+		t.Fatalf("marshal response: %v", err)
+	}
+	var resp decoded
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("decode response %s: %v", body, err)
+	}
+	return resp
+}
+
+// code returns the code extension of the first error
+func (d decoded) code() string {
+	if len(d.Errors) == 0 {
+		return ""
+	}
+	code, _ := d.Errors[0].Extensions["code"].(string)
+	return code
+}
+
+func TestParseSyntaxErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		message  string
+		location Location
+	}{
+		{name: "unterminated selection", query: "{ item(id: 1) { id }", message: "expected", location: Location{Line: 1, Column: 21}},
+		{name: "bad value", query: "{\n  item(id: ) { id }\n}", message: "expected a value", location: Location{Line: 2, Column: 12}},
+		{name: "unterminated string", query: `{ item(id: "1) { id } }`, message: "unterminated string", location: Location{Line: 1, Column: 12}},
+		{name: "empty selection", query: "{ }", message: "selection set is empty", location: Location{Line: 1, Column: 4}},
+		{name: "no operation", query: "# nothing", message: "document has no operation", location: Location{Line: 1, Column: 10}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.query)
+			syntaxErr, ok := err.(*SyntaxError)
+			if !ok {
+				t.Fatalf("Parse() error = %v, want a *SyntaxError", err)
+			}
+			if !strings.Contains(syntaxErr.Message, tt.message) {
+				t.Errorf("message = %q, want it to contain %q", syntaxErr.Message, tt.message)
+			}
+			if syntaxErr.Location != tt.location {
+				t.Errorf("location = %+v, want %+v", syntaxErr.Location, tt.location)
+			}
+
+			resp := do(t, NewServer(echoSchema()), context.Background(), &Request{Query: tt.query})
+			if resp.Data != nil || len(resp.Errors) != 1 {
+				t.Fatalf("Do() = %+v, want one error and no data", resp)
+			}
+			if !strings.HasPrefix(resp.Errors[0].Message, "syntax error: ") {
+				t.Errorf("Do() message = %q, want a syntax error", resp.Errors[0].Message)
+			}
+			if len(resp.Errors[0].Locations) != 1 || resp.Errors[0].Locations[0] != tt.location {
+				t.Errorf("Do() locations = %+v, want [%+v]", resp.Errors[0].Locations, tt.location)
+			}
+		})
+	}
+}
+
+func TestVariableCoercion(t *testing.T) {
+	const query = "query($id: ID!, $first: Int) { item(id: $id, first: $first) { id } }"
+	tests := []struct {
+		name      string
+		variables map[string]interface{}
+		wantID    interface{}
+		wantError string
+	}{
+		{name: "string ID", variables: map[string]interface{}{"id": "7"}, wantID: "7"},
+		{name: "integral number ID", variables: map[string]interface{}{"id": float64(7)}, wantID: "7"},
+		{name: "integral number Int", variables: map[string]interface{}{"id": "7", "first": float64(10)}, wantID: "7"},
+		{name: "missing required", variables: map[string]interface{}{}, wantError: `variable $id of type "ID!" is required`},
+		{name: "null for non-null", variables: map[string]interface{}{"id": nil}, wantError: "variable $id: expected a non-null ID"},
+		{name: "fractional ID", variables: map[string]interface{}{"id": 1.5}, wantError: "variable $id"},
+		{name: "string Int", variables: map[string]interface{}{"id": "7", "first": "ten"}, wantError: "variable $first"},
+		{name: "fractional Int", variables: map[string]interface{}{"id": "7", "first": 1.5}, wantError: "variable $first"},
+		{name: "Int over 32 bits", variables: map[string]interface{}{"id": "7", "first": float64(1 << 40)}, wantError: "variable $first"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := do(t, NewServer(echoSchema()), context.Background(), &Request{Query: query, Variables: tt.variables})
+			if tt.wantError != "" {
+				if len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0].Message, tt.wantError) {
+					t.Fatalf("errors = %+v, want one containing %q", resp.Errors, tt.wantError)
+				}
+				if resp.Data != nil {
+					t.Errorf("data = %v, want none", resp.Data)
+				}
+				return
+			}
+			if len(resp.Errors) > 0 {
+				t.Fatalf("errors = %+v", resp.Errors)
+			}
+			item, _ := resp.Data["item"].(map[string]interface{})
+			if item["id"] != tt.wantID {
+				t.Errorf("id = %#v, want %#v", item["id"], tt.wantID)
+			}
+		})
+	}
+}
+
+func TestUndefinedVariable(t *testing.T) {
+	for _, query := range []string{
+		"{ item(id: $id) { id } }",
+		"query($id: ID!) { item(id: $id, first: $first) { id } }",
+		"query($id: ID!) { item(id: $id) { id @include(if: $show) } }",
+	} {
+		resp := do(t, NewServer(echoSchema()), context.Background(), &Request{Query: query, Variables: map[string]interface{}{"id": "1"}})
+		if len(resp.Errors) == 0 || !strings.Contains(resp.Errors[0].Message, "is not defined") {
+			t.Errorf("%s: errors = %+v, want an undefined variable", query, resp.Errors)
+		}
+	}
+}
+
+func TestQueryLimits(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []Option
+		query     string
+		variables map[string]interface{}
+		wantCode  string
+	}{
+		{name: "within depth", opts: []Option{WithMaxDepth(2)}, query: "{ item(id: 1) { id } }"},
+		{name: "too deep", opts: []Option{WithMaxDepth(1)}, query: "{ item(id: 1) { id } }", wantCode: "QUERY_TOO_DEEP"},
+		{name: "fragments count toward depth", opts: []Option{WithMaxDepth(1)}, query: "{ ...F } fragment F on Query { item(id: 1) { id } }", wantCode: "QUERY_TOO_DEEP"},
+		{name: "within complexity", opts: []Option{WithMaxComplexity(50)}, query: "{ items(first: 5) { id children } }"},
+		{name: "size multiplies complexity", opts: []Option{WithMaxComplexity(50)}, query: "{ items(first: 100) { id children } }", wantCode: "QUERY_TOO_COMPLEX"},
+		{name: "default size", opts: []Option{WithMaxComplexity(15)}, query: "{ items { id children } }", wantCode: "QUERY_TOO_COMPLEX"},
+		{
+			name:      "size from a variable",
+			opts:      []Option{WithMaxComplexity(50)},
+			query:     "query($n: Int) { items(first: $n) { id children } }",
+			variables: map[string]interface{}{"n": float64(100)},
+			wantCode:  "QUERY_TOO_COMPLEX",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := do(t, NewServer(echoSchema(), tt.opts...), context.Background(), &Request{Query: tt.query, Variables: tt.variables})
+			if got := resp.code(); got != tt.wantCode {
+				t.Fatalf("code = %q, want %q (errors %+v)", got, tt.wantCode, resp.Errors)
+			}
+			if tt.wantCode != "" && resp.Data != nil {
+				t.Errorf("data = %v, want none for a rejected query", resp.Data)
+			}
+		})
+	}
+}
@@ -0,0 +1,438 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// analysis is the outcome of validating an operation against the schema
+type analysis struct {
+	errors     []*Error
+	depth      int
+	complexity int
+}
+
+// validator walks an operation, checking it against the schema and
+// measuring its depth and complexity
+type validator struct {
+	schema    *Schema
+	doc       *Document
+	variables map[string]interface{}
+	declared  map[string]bool
+	errors    []*Error
+}
+
+// validate checks the selections of op and measures them
+// Variables must already be coerced, so that list sizes given as
+// variables count towards the complexity.
+func validate(schema *Schema, doc *Document, op *Operation, variables map[string]interface{}) *analysis {
+	v := &validator{schema: schema, doc: doc, variables: variables, declared: map[string]bool{}}
+	for _, def := range op.Variables {
+		v.declared[def.Name] = true
+	}
+	for name, fragment := range doc.Fragments {
+		if fragment.TypeCondition != schema.Query.Name && schema.object(fragment.TypeCondition) == nil {
+			v.fail(fragment.Location, "fragment %q is on unknown type %q", name, fragment.TypeCondition)
+		}
+	}
+	v.directives(op.Directives)
+	depth, complexity := v.selections(schema.Query, op.Selections, map[string]bool{})
+	return &analysis{errors: v.errors, depth: depth, complexity: complexity}
+}
+
+// selections returns the depth and complexity of a selection set on parent
+// Every field costs 1, plus the cost of its own selections multiplied by
+// its size argument for paginated lists.
+func (v *validator) selections(parent *Object, selections []Selection, spreads map[string]bool) (int, int) {
+	depth, complexity := 0, 0
+	for _, selection := range selections {
+		var d, c int
+		switch sel := selection.(type) {
+		case *Field:
+			d, c = v.field(parent, sel, spreads)
+		case *FragmentSpread:
+			v.directives(sel.Directives)
+			fragment, ok := v.doc.Fragments[sel.Name]
+			if !ok {
+				v.fail(sel.Location, "unknown fragment %q", sel.Name)
+				continue
+			}
+			if spreads[sel.Name] {
+				v.fail(sel.Location, "fragment %q spreads itself", sel.Name)
+				continue
+			}
+			if !v.typeCondition(parent, fragment.TypeCondition, sel.Location) {
+				continue
+			}
+			spreads[sel.Name] = true
+			d, c = v.selections(parent, fragment.Selections, spreads)
+			delete(spreads, sel.Name)
+		case *InlineFragment:
+			v.directives(sel.Directives)
+			if !v.typeCondition(parent, sel.TypeCondition, sel.Location) {
+				continue
+			}
+			d, c = v.selections(parent, sel.Selections, spreads)
+		}
+		if d > depth {
+			depth = d
+		}
+		complexity += c
+	}
+	return depth, complexity
+}
+
+// field checks one field and its arguments, then descends into its selections
+func (v *validator) field(parent *Object, field *Field, spreads map[string]bool) (int, int) {
+	v.directives(field.Directives)
+	if field.Name == "__typename" {
+		if len(field.Selections) > 0 {
+			v.fail(field.Location, "field \"__typename\" of type \"String!\" must not have a selection")
+		}
+		return 1, 0
+	}
+
+	v.variableUses(field.Location, field.Arguments)
+	def := parent.Field(field.Name)
+	if def == nil {
+		v.fail(field.Location, "cannot query field %q on type %q", field.Name, parent.Name)
+		return 0, 0
+	}
+	args, err := coerceArguments(def.Args, field.Arguments, v.variables)
+	if err != nil {
+		v.fail(field.Location, "field %q: %v", field.Name, err)
+	}
+
+	object, ok := namedType(def.Type).(*Object)
+	if !ok {
+		if len(field.Selections) > 0 {
+			v.fail(field.Location, "field %q of type %q must not have a selection", field.Name, def.Type)
+		}
+		return 1, 1
+	}
+	if len(field.Selections) == 0 {
+		v.fail(field.Location, "field %q of type %q must have a selection of subfields", field.Name, def.Type)
+		return 1, 1
+	}
+
+	depth, complexity := v.selections(object, field.Selections, spreads)
+	if def.SizeArg != "" {
+		if size, ok := args[def.SizeArg].(int); ok && size > 1 {
+			complexity *= size
+		}
+	}
+	return depth + 1, complexity + 1
+}
+
+// typeCondition reports whether a fragment on typeName applies to parent
+// There are no interfaces or unions, so it must name parent itself.
+func (v *validator) typeCondition(parent *Object, typeName string, loc Location) bool {
+	if typeName == "" || typeName == parent.Name {
+		return true
+	}
+	v.fail(loc, "fragment on %q cannot be spread within type %q", typeName, parent.Name)
+	return false
+}
+
+// directives accepts @skip and @include with a boolean "if" argument
+func (v *validator) directives(directives []*Directive) {
+	for _, directive := range directives {
+		if directive.Name != "skip" && directive.Name != "include" {
+			v.fail(directive.Location, "unknown directive @%s", directive.Name)
+			continue
+		}
+		v.variableUses(directive.Location, directive.Arguments)
+		if _, err := coerceArguments(conditionArgs, directive.Arguments, v.variables); err != nil {
+			v.fail(directive.Location, "directive @%s: %v", directive.Name, err)
+		}
+	}
+}
+
+// variableUses checks that the variables used in args are declared by the operation
+// Otherwise an undeclared variable would pass as an omitted argument.
+func (v *validator) variableUses(loc Location, args []*Argument) {
+	var check func(value *Value)
+	check = func(value *Value) {
+		switch value.Kind {
+		case VariableValue:
+			if !v.declared[value.Raw] {
+				v.fail(loc, "variable $%s is not defined", value.Raw)
+			}
+		case ListValue:
+			for _, item := range value.List {
+				check(item)
+			}
+		case ObjectValue:
+			for _, field := range value.Fields {
+				check(field.Value)
+			}
+		}
+	}
+	for _, arg := range args {
+		check(arg.Value)
+	}
+}
+
+// fail records a validation error
+func (v *validator) fail(loc Location, format string, args ...interface{}) {
+	v.errors = append(v.errors, &Error{Message: fmt.Sprintf(format, args...), Locations: []Location{loc}})
+}
+
+// conditionArgs are the arguments of @skip and @include
+var conditionArgs = []*ArgDef{{Name: "if", Type: &NonNull{Of: Boolean}}}
+
+// object looks up an object type reachable from the query type
+func (s *Schema) object(name string) *Object {
+	for _, t := range s.types() {
+		if object, ok := t.(*Object); ok && object.Name == name {
+			return object
+		}
+	}
+	return nil
+}
+
+// inputType resolves a variable type reference to a schema input type
+func (s *Schema) inputType(ref *TypeRef) (Type, error) {
+	var t Type
+	if ref.Elem != nil {
+		elem, err := s.inputType(ref.Elem)
+		if err != nil {
+			return nil, err
+		}
+		t = &List{Of: elem}
+	} else {
+		for _, candidate := range append([]Type{Int, Float, String, Boolean, ID}, s.types()...) {
+			if candidate.String() == ref.Name {
+				t = candidate
+				break
+			}
+		}
+		if t == nil {
+			return nil, fmt.Errorf("unknown type %q", ref.Name)
+		}
+		if _, ok := t.(*Object); ok {
+			return nil, fmt.Errorf("type %q is not an input type", ref.Name)
+		}
+	}
+	if ref.NonNull {
+		t = &NonNull{Of: t}
+	}
+	return t, nil
+}
+
+// coerceVariables checks the provided variables against their definitions
+// and applies defaults
+func coerceVariables(schema *Schema, op *Operation, provided map[string]interface{}) (map[string]interface{}, []*Error) {
+	variables := map[string]interface{}{}
+	var errs []*Error
+	for _, def := range op.Variables {
+		t, err := schema.inputType(def.Type)
+		if err != nil {
+			errs = append(errs, &Error{Message: fmt.Sprintf("variable $%s: %v", def.Name, err), Locations: []Location{def.Location}})
+			continue
+		}
+
+		value, ok := provided[def.Name]
+		if !ok && def.Default != nil {
+			coerced, err := coerceLiteral(t, def.Default, nil)
+			if err != nil {
+				errs = append(errs, &Error{Message: fmt.Sprintf("variable $%s: default value: %v", def.Name, err), Locations: []Location{def.Location}})
+				continue
+			}
+			variables[def.Name] = coerced
+			continue
+		}
+		if !ok {
+			if _, nonNull := t.(*NonNull); nonNull {
+				errs = append(errs, &Error{Message: fmt.Sprintf("variable $%s of type %q is required", def.Name, def.Type), Locations: []Location{def.Location}})
+			}
+			continue
+		}
+
+		coerced, err := coerceValue(t, value)
+		if err != nil {
+			errs = append(errs, &Error{Message: fmt.Sprintf("variable $%s: %v", def.Name, err), Locations: []Location{def.Location}})
+			continue
+		}
+		variables[def.Name] = coerced
+	}
+	return variables, errs
+}
+
+// coerceArguments coerces the arguments of a field or directive, applying defaults
+func coerceArguments(defs []*ArgDef, args []*Argument, variables map[string]interface{}) (map[string]interface{}, error) {
+	values := make(map[string]interface{}, len(defs))
+	given := make(map[string]*Argument, len(args))
+	for _, arg := range args {
+		if _, ok := given[arg.Name]; ok {
+			return nil, fmt.Errorf("argument %q is given more than once", arg.Name)
+		}
+		given[arg.Name] = arg
+	}
+
+	for _, def := range defs {
+		arg, ok := given[def.Name]
+		delete(given, def.Name)
+		// A variable that was not provided counts as an omitted argument
+		if ok && arg.Value.Kind == VariableValue {
+			if _, provided := variables[arg.Value.Raw]; !provided {
+				ok = false
+			}
+		}
+		if !ok {
+			if def.Default != nil {
+				values[def.Name] = def.Default
+			} else if _, nonNull := def.Type.(*NonNull); nonNull {
+				return nil, fmt.Errorf("argument %q of type %q is required", def.Name, def.Type)
+			}
+			continue
+		}
+
+		value, err := coerceLiteral(def.Type, arg.Value, variables)
+		if err != nil {
+			return nil, fmt.Errorf("argument %q: %v", def.Name, err)
+		}
+		values[def.Name] = value
+	}
+
+	for name := range given {
+		return nil, fmt.Errorf("unknown argument %q", name)
+	}
+	return values, nil
+}
+
+// coerceLiteral coerces a value written in the document to t
+func coerceLiteral(t Type, value *Value, variables map[string]interface{}) (interface{}, error) {
+	if value.Kind == VariableValue {
+		variable, ok := variables[value.Raw]
+		if !ok {
+			return nil, fmt.Errorf("variable $%s is not defined", value.Raw)
+		}
+		// Variables were coerced to their declared type; this checks that
+		// the declared type fits where the variable is used
+		return coerceValue(t, variable)
+	}
+
+	if nonNull, ok := t.(*NonNull); ok {
+		if value.Kind == NullValue {
+			return nil, fmt.Errorf("expected a non-null %s", nonNull.Of)
+		}
+		return coerceLiteral(nonNull.Of, value, variables)
+	}
+	if value.Kind == NullValue {
+		return nil, nil
+	}
+
+	switch t := t.(type) {
+	case *List:
+		if value.Kind != ListValue {
+			item, err := coerceLiteral(t.Of, value, variables)
+			if err != nil {
+				return nil, err
+			}
+			return []interface{}{item}, nil
+		}
+		items := make([]interface{}, 0, len(value.List))
+		for _, item := range value.List {
+			coerced, err := coerceLiteral(t.Of, item, variables)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, coerced)
+		}
+		return items, nil
+	case *Enum:
+		if value.Kind != EnumValue {
+			return nil, fmt.Errorf("expected a %s value, got %s", t.Name, value.Raw)
+		}
+		return coerceValue(t, value.Raw)
+	case *Scalar:
+		var raw interface{}
+		switch value.Kind {
+		case IntValue:
+			n, err := strconv.ParseInt(value.Raw, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid integer %s", value.Raw)
+			}
+			raw = int64(n)
+			if t == Float {
+				raw = float64(n)
+			}
+		case FloatValue:
+			f, err := strconv.ParseFloat(value.Raw, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %s", value.Raw)
+			}
+			raw = f
+		case StringValue:
+			raw = value.Raw
+		case BooleanValue:
+			raw = value.Raw == "true"
+		default:
+			return nil, fmt.Errorf("expected a %s value", t.Name)
+		}
+		return t.Parse(raw)
+	}
+	return nil, fmt.Errorf("type %s is not an input type", t)
+}
+
+// coerceValue coerces a JSON-decoded value to t
+func coerceValue(t Type, value interface{}) (interface{}, error) {
+	if nonNull, ok := t.(*NonNull); ok {
+		if value == nil {
+			return nil, fmt.Errorf("expected a non-null %s", nonNull.Of)
+		}
+		return coerceValue(nonNull.Of, value)
+	}
+	if value == nil {
+		return nil, nil
+	}
+
+	switch t := t.(type) {
+	case *List:
+		items, ok := value.([]interface{})
+		if !ok {
+			item, err := coerceValue(t.Of, value)
+			if err != nil {
+				return nil, err
+			}
+			return []interface{}{item}, nil
+		}
+		coerced := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			c, err := coerceValue(t.Of, item)
+			if err != nil {
+				return nil, err
+			}
+			coerced = append(coerced, c)
+		}
+		return coerced, nil
+	case *Enum:
+		name, ok := value.(string)
+		if ok {
+			for _, allowed := range t.Values {
+				if name == allowed {
+					return name, nil
+				}
+			}
+		}
+		return nil, fmt.Errorf("expected a %s value, got %v", t.Name, value)
+	case *Scalar:
+		return t.Parse(value)
+	}
+	return nil, fmt.Errorf("type %s is not an input type", t)
+}
+
+// namedType strips list and non-null wrappers
+func namedType(t Type) Type {
+	for {
+		switch wrapped := t.(type) {
+		case *List:
+			t = wrapped.Of
+		case *NonNull:
+			t = wrapped.Of
+		default:
+			return t
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"booking/domain/entity"
+	"booking/infrastructure/logging"
+	"booking/usecase/role"
+	"booking/usecase/user"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// toStatus maps a use case error to a gRPC status
+// Unexpected errors become Internal without their message, which may contain
+// database details; the cause is logged instead.
+func toStatus(ctx context.Context, err error) error {
+	var validation *user.ValidationError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &validation),
+		errors.Is(err, user.ErrInvalidSort),
+		errors.Is(err, entity.ErrInvalidCursor):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, user.ErrUserNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, user.ErrEmailTaken), errors.Is(err, user.ErrUsernameTaken):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, role.ErrUnauthenticated):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, role.ErrForbidden):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	default:
+		logging.For("grpc").ErrorContext(ctx, "call failed", slog.Any("error", err))
+		return status.Error(codes.Internal, "internal error")
+	}
+}
@@ -0,0 +1,154 @@
+package grpc
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"booking/delivery/consistency"
+	"booking/domain/identity"
+	"booking/infrastructure/auth"
+	"booking/infrastructure/logging"
+	"booking/usecase/role"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// requestIDKey is the metadata key used to accept and return the request ID, like X-Request-ID over HTTP
+const requestIDKey = "x-request-id"
+
+// RequestID accepts the caller's x-request-id or generates one, stores it in
+// the context and returns it in the response header
+func RequestID() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		requestID := identity.AcceptRequestID(firstValue(ctx, requestIDKey))
+
+		ctx = identity.WithRequestID(ctx, requestID)
+		_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, requestID))
+
+		return handler(ctx, req)
+	}
+}
+
+// Logger logs one structured record per call, like the HTTP Logger middleware
+func Logger() grpc.UnaryServerInterceptor {
+	logger := logging.For("grpc")
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		startTime := time.Now()
+
+		resp, err := handler(ctx, req)
+
+		code := status.Code(err)
+		level := slog.LevelInfo
+		switch code {
+		case codes.OK:
+		case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable:
+			level = slog.LevelError
+		default:
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", info.FullMethod),
+			slog.String("code", code.String()),
+			slog.Float64("latency_ms", float64(time.Since(startTime).Microseconds())/1000),
+		}
+		if p, ok := peer.FromContext(ctx); ok {
+			attrs = append(attrs, slog.String("peer", p.Addr.String()))
+		}
+		if err != nil {
+			attrs = append(attrs, slog.String("error", status.Convert(err).Message()))
+		}
+
+		logger.LogAttrs(ctx, level, "call", attrs...)
+		return resp, err
+	}
+}
+
+// Recovery turns panics into Internal errors and logs them
+func Recovery() grpc.UnaryServerInterceptor {
+	logger := logging.For("grpc")
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				logger.ErrorContext(ctx, "panic recovered",
+					slog.Any("panic", recovered),
+					slog.String("method", info.FullMethod),
+				)
+				err = status.Error(codes.Internal, "internal error")
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// Authenticate checks the authorization metadata, and the user ID metadata when
+// it is trusted, exactly like the HTTP middleware. Calls without credentials
+// continue anonymously; Authorize decides whether that is acceptable.
+func Authenticate(authenticator *auth.Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		credentials := auth.Credentials{Authorization: firstValue(ctx, "authorization")}
+		if key := authenticator.UserHeader(); key != "" {
+			credentials.UserID = firstValue(ctx, strings.ToLower(key))
+		}
+
+		userID, err := authenticator.Authenticate(credentials)
+		if err != nil {
+			return nil, status.Errorf(codes.Unauthenticated, "invalid credentials: %v", err)
+		}
+		if userID == 0 {
+			return handler(ctx, req)
+		}
+
+		return handler(identity.WithActor(ctx, userID), req)
+	}
+}
+
+// Authorize rejects calls unless the authenticated user holds the permission of the method
+// Methods without an entry in permissions are rejected.
+func Authorize(checker role.PermissionChecker, permissions map[string]string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		permission, ok := permissions[info.FullMethod]
+		if !ok {
+			return nil, status.Error(codes.PermissionDenied, "method is not open to callers")
+		}
+		if err := role.Require(ctx, checker, permission); err != nil {
+			return nil, toStatus(ctx, err)
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// ReadYourWrites serves reads from the primary when the x-consistency metadata
+// is "strong", or when the authenticated actor wrote within the tracker's
+// window, like the HTTP middleware. Successful calls of methods missing from
+// readOnly start that window.
+func ReadYourWrites(tracker *consistency.Tracker, readOnly map[string]bool) grpc.UnaryServerInterceptor {
+	key := strings.ToLower(consistency.Header)
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx = tracker.Context(ctx, firstValue(ctx, key))
+
+		resp, err := handler(ctx, req)
+		if err == nil && !readOnly[info.FullMethod] {
+			tracker.WroteIn(ctx)
+		}
+		return resp, err
+	}
+}
+
+// firstValue returns the first incoming metadata value of key, or ""
+func firstValue(ctx context.Context, key string) string {
+	if values := metadata.ValueFromIncomingContext(ctx, key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
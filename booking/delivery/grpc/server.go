@@ -0,0 +1,93 @@
+// Package grpc serves the use cases over gRPC, next to the REST router in delivery/http.
+// The protobuf definitions live in proto/ and are compiled into delivery/grpc/pb
+// with `make proto`.
+package grpc
+
+import (
+	"context"
+	"net"
+
+	"booking/config"
+	"booking/delivery/consistency"
+	"booking/delivery/grpc/pb"
+	"booking/domain/entity"
+	"booking/infrastructure/auth"
+	"booking/usecase/role"
+	"booking/usecase/user"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+// permissions maps each unary method to the permission it requires
+// Methods missing here are rejected, so a new RPC can't be exposed by accident.
+var permissions = map[string]string{
+	pb.UserService_CreateUser_FullMethodName:  entity.PermissionUsersWrite,
+	pb.UserService_GetUser_FullMethodName:     entity.PermissionUsersRead,
+	pb.UserService_ListUsers_FullMethodName:   entity.PermissionUsersRead,
+	pb.UserService_UpdateUser_FullMethodName:  entity.PermissionUsersWrite,
+	pb.UserService_DeleteUser_FullMethodName:  entity.PermissionUsersDelete,
+	pb.UserService_RestoreUser_FullMethodName: entity.PermissionUsersDelete,
+}
+
+// readOnly lists the methods that do not start a read-your-writes window
+// Any other method that succeeds does, so a new write can't be missed.
+var readOnly = map[string]bool{
+	pb.UserService_GetUser_FullMethodName:   true,
+	pb.UserService_ListUsers_FullMethodName: true,
+}
+
+// Server is the gRPC server
+type Server struct {
+	server *grpc.Server
+}
+
+// NewServer registers the gRPC services backed by the use cases
+// Callers authenticate like over HTTP: a bearer token in authorization
+// metadata, or the trusted user header sent as lower-case metadata
+// (x-user-id by default) when enabled; checker authorizes each call.
+// writes is shared with the HTTP router, so a write over one transport is
+// read back over the other; nil leaves only the x-consistency metadata.
+func NewServer(userUseCase user.UserUseCase, checker role.PermissionChecker, cfg *config.Config, writes *consistency.Tracker) *Server {
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		RequestID(),
+		Logger(),
+		Recovery(),
+		Authenticate(auth.NewAuthenticator(cfg.Auth)),
+		Authorize(checker, permissions),
+		ReadYourWrites(writes, readOnly),
+	))
+
+	pb.RegisterUserServiceServer(server, newUserService(userUseCase))
+	if cfg.GRPC.Reflection {
+		reflection.Register(server)
+	}
+
+	return &Server{server: server}
+}
+
+// ListenAndServe serves on addr until Drain is called
+func (s *Server) ListenAndServe(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.server.Serve(listener)
+}
+
+// Drain stops accepting calls and waits for in-flight ones until ctx is done, then closes them
+func (s *Server) Drain(ctx context.Context) error {
+	stopped := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.server.Stop()
+		return ctx.Err()
+	}
+}
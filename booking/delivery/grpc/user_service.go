@@ -0,0 +1,185 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+
+	"booking/delivery/grpc/pb"
+	"booking/domain/entity"
+	"booking/usecase/user"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// userService implements pb.UserServiceServer on top of the user use case
+type userService struct {
+	pb.UnimplementedUserServiceServer
+	userUseCase user.UserUseCase
+}
+
+// newUserService creates the user service
+func newUserService(userUseCase user.UserUseCase) *userService {
+	return &userService{userUseCase: userUseCase}
+}
+
+// CreateUser implements pb.UserServiceServer
+func (s *userService) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.User, error) {
+	u := &entity.User{
+		Email:    req.GetEmail(),
+		Username: req.GetUsername(),
+		Password: req.GetPassword(),
+		FullName: req.GetFullName(),
+		Phone:    req.GetPhone(),
+		IsActive: true,
+	}
+	if err := s.userUseCase.CreateUser(ctx, u); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return toUser(u), nil
+}
+
+// GetUser implements pb.UserServiceServer
+func (s *userService) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.User, error) {
+	if req.GetId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	u, err := s.userUseCase.GetUserByID(ctx, uint(req.GetId()))
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return toUser(u), nil
+}
+
+// ListUsers implements pb.UserServiceServer
+func (s *userService) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	filter := &entity.UserFilter{
+		Email:    req.Email,
+		Username: req.Username,
+		FullName: req.FullName,
+		Phone:    req.Phone,
+		IsActive: req.IsActive,
+		Search:   req.Query,
+		Limit:    int(req.GetPageSize()),
+	}
+	if req.CreatedFrom != nil {
+		from := req.GetCreatedFrom().AsTime()
+		filter.CreatedFrom = &from
+	}
+	if req.CreatedTo != nil {
+		to := req.GetCreatedTo().AsTime()
+		filter.CreatedTo = &to
+	}
+	// sort=username sorts ascending, sort=-username descending
+	if sort := req.GetSort(); sort != "" {
+		filter.SortBy = strings.TrimPrefix(sort, "-")
+		filter.SortDesc = strings.HasPrefix(sort, "-")
+	}
+	if token := req.GetPageToken(); token != "" {
+		after, err := entity.DecodeUserCursor(token)
+		if err != nil {
+			return nil, toStatus(ctx, err)
+		}
+		filter.After = after
+	}
+
+	page, err := s.userUseCase.ListUsers(ctx, filter)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	count, err := s.userUseCase.CountUsers(ctx, filter)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	resp := &pb.ListUsersResponse{
+		Users:         make([]*pb.User, 0, len(page.Users)),
+		NextPageToken: page.NextCursor,
+		TotalSize:     count,
+	}
+	for _, u := range page.Users {
+		resp.Users = append(resp.Users, toUser(u))
+	}
+	return resp, nil
+}
+
+// UpdateUser implements pb.UserServiceServer
+// Unlike PUT /users/:id, only the fields set in the request change.
+func (s *userService) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.User, error) {
+	if req.GetId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	u, err := s.userUseCase.GetUserByID(ctx, uint(req.GetId()))
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	if req.Email != nil {
+		u.Email = req.GetEmail()
+	}
+	if req.Username != nil {
+		u.Username = req.GetUsername()
+	}
+	if req.Password != nil {
+		u.Password = req.GetPassword()
+	}
+	if req.FullName != nil {
+		u.FullName = req.GetFullName()
+	}
+	if req.Phone != nil {
+		u.Phone = req.GetPhone()
+	}
+	if req.IsActive != nil {
+		u.IsActive = req.GetIsActive()
+	}
+
+	if err := s.userUseCase.UpdateUser(ctx, u); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return toUser(u), nil
+}
+
+// DeleteUser implements pb.UserServiceServer
+func (s *userService) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest) (*pb.DeleteUserResponse, error) {
+	if req.GetId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	if err := s.userUseCase.DeleteUser(ctx, uint(req.GetId())); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return &pb.DeleteUserResponse{}, nil
+}
+
+// RestoreUser implements pb.UserServiceServer
+func (s *userService) RestoreUser(ctx context.Context, req *pb.RestoreUserRequest) (*pb.User, error) {
+	if req.GetId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	u, err := s.userUseCase.RestoreUser(ctx, uint(req.GetId()))
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return toUser(u), nil
+}
+
+// toUser converts a user entity to its protobuf message
+func toUser(u *entity.User) *pb.User {
+	msg := &pb.User{
+		Id:        uint32(u.ID),
+		Email:     u.Email,
+		Username:  u.Username,
+		FullName:  u.FullName,
+		Phone:     u.Phone,
+		IsActive:  u.IsActive,
+		CreatedAt: timestamppb.New(u.CreatedAt),
+		UpdatedAt: timestamppb.New(u.UpdatedAt),
+	}
+	if u.DeletedAt.Valid {
+		msg.DeletedAt = timestamppb.New(u.DeletedAt.Time)
+	}
+	if u.PurgedAt != nil {
+		msg.PurgedAt = timestamppb.New(*u.PurgedAt)
+	}
+	return msg
+}
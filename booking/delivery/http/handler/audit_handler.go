@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"booking/domain/entity"
+	"booking/usecase/audit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultAuditPageSize and maxAuditPageSize bound the audit listing page size
+const (
+	defaultAuditPageSize = 50
+	maxAuditPageSize     = 500
+)
+
+// AuditHandler handles HTTP requests for the audit log
+type AuditHandler struct {
+	auditUseCase audit.AuditUseCase
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(auditUseCase audit.AuditUseCase) *AuditHandler {
+	return &AuditHandler{
+		auditUseCase: auditUseCase,
+	}
+}
+
+// ListEntries handles GET /admin/audit
+func (h *AuditHandler) ListEntries(c *gin.Context) {
+	filter := &entity.AuditFilter{Limit: defaultAuditPageSize}
+
+	// Parse query parameters
+	if actorID := c.Query("actor_id"); actorID != "" {
+		id, err := strconv.ParseUint(actorID, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid actor_id"})
+			return
+		}
+		actor := uint(id)
+		filter.ActorID = &actor
+	}
+	if action := c.Query("action"); action != "" {
+		filter.Action = &action
+	}
+	if targetType := c.Query("target_type"); targetType != "" {
+		filter.TargetType = &targetType
+	}
+	if targetID := c.Query("target_id"); targetID != "" {
+		filter.TargetID = &targetID
+	}
+	if requestID := c.Query("request_id"); requestID != "" {
+		filter.RequestID = &requestID
+	}
+	if from := c.Query("from"); from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid from, expected RFC3339"})
+			return
+		}
+		filter.From = &t
+	}
+	if to := c.Query("to"); to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid to, expected RFC3339"})
+			return
+		}
+		filter.To = &t
+	}
+	if limit := c.Query("limit"); limit != "" {
+		if l, err := strconv.Atoi(limit); err == nil && l > 0 {
+			filter.Limit = min(l, maxAuditPageSize)
+		}
+	}
+	if offset := c.Query("offset"); offset != "" {
+		if o, err := strconv.Atoi(offset); err == nil && o > 0 {
+			filter.Offset = o
+		}
+	}
+
+	entries, err := h.auditUseCase.ListEntries(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	count, err := h.auditUseCase.CountEntries(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, AuditListResponse{
+		Data:   entries,
+		Total:  count,
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	})
+}
+
+// VerifyChain handles GET /admin/audit/verify
+func (h *AuditHandler) VerifyChain(c *gin.Context) {
+	report, err := h.auditUseCase.VerifyChain(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	status := http.StatusOK
+	if !report.Valid {
+		status = http.StatusConflict
+	}
+	c.JSON(status, AuditChainResponse{Data: report})
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"booking/delivery/graphql"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GraphQLHandler serves GraphQL queries for the mobile app
+type GraphQLHandler struct {
+	server *graphql.Server
+}
+
+// NewGraphQLHandler creates a new GraphQL handler
+func NewGraphQLHandler(server *graphql.Server) *GraphQLHandler {
+	return &GraphQLHandler{
+		server: server,
+	}
+}
+
+// Query handles GET and POST /graphql
+// GET takes query, operationName and the JSON encoded variables and
+// extensions as query parameters, so persisted queries can be cached by
+// hash; POST takes the same fields as a JSON body. Query errors come back
+// with 200 in the errors list, as GraphQL clients expect.
+func (h *GraphQLHandler) Query(c *gin.Context) {
+	var req graphql.Request
+	if c.Request.Method == http.MethodGet {
+		req.Query = c.Query("query")
+		req.OperationName = c.Query("operationName")
+		if variables := c.Query("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				c.JSON(http.StatusBadRequest, graphQLError("variables must be a JSON object"))
+				return
+			}
+		}
+		if extensions := c.Query("extensions"); extensions != "" {
+			if err := json.Unmarshal([]byte(extensions), &req.Extensions); err != nil {
+				c.JSON(http.StatusBadRequest, graphQLError("extensions must be a JSON object"))
+				return
+			}
+		}
+	} else if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, graphQLError("body must be a JSON object with query, operationName, variables and extensions"))
+		return
+	}
+
+	c.JSON(http.StatusOK, h.server.Do(c.Request.Context(), &req))
+}
+
+// Schema handles GET /graphql/schema
+// It returns the schema in SDL, for client code generation.
+func (h *GraphQLHandler) Schema(c *gin.Context) {
+	c.String(http.StatusOK, h.server.Schema().SDL())
+}
+
+// graphQLError is a response for a request that isn't valid GraphQL over HTTP
+func graphQLError(message string) *graphql.Response {
+	return &graphql.Response{Errors: []*graphql.Error{{Message: message}}}
+}
@@ -1,6 +1,12 @@
 package handler
 
 import (
+	"booking/delivery/graphql"
+	"booking/infrastructure/health"
+	"booking/usecase/audit"
+	"booking/usecase/idempotency"
+	"booking/usecase/privacy"
+	"booking/usecase/role"
 	"booking/usecase/user"
 )
 
@@ -8,20 +14,58 @@ import (
 type HandlerType string
 
 const (
-	UserHandlerType HandlerType = "user"
+	UserHandlerType    HandlerType = "user"
+	RoleHandlerType    HandlerType = "role"
+	AuditHandlerType   HandlerType = "audit"
+	PrivacyHandlerType HandlerType = "privacy"
+	HealthHandlerType  HandlerType = "health"
+	GraphQLHandlerType HandlerType = "graphql"
 )
 
 // HandlerFactory creates handlers based on type
 // Factory Pattern: Creates different types of handlers
 type HandlerFactory struct {
-	userUseCase user.UserUseCase
+	userUseCase        user.UserUseCase
+	roleUseCase        role.RoleUseCase
+	auditUseCase       audit.AuditUseCase
+	privacyUseCase     privacy.PrivacyUseCase
+	idempotencyUseCase idempotency.IdempotencyUseCase
+	health             *health.Health
+	graphqlOptions     []graphql.Option
+}
+
+// FactoryOption configures the handlers built by a HandlerFactory
+type FactoryOption func(*HandlerFactory)
+
+// WithGraphQLOptions sets the limits and persisted queries of the GraphQL handler
+func WithGraphQLOptions(opts ...graphql.Option) FactoryOption {
+	return func(f *HandlerFactory) {
+		f.graphqlOptions = append(f.graphqlOptions, opts...)
+	}
 }
 
 // NewHandlerFactory creates a new handler factory
-func NewHandlerFactory(userUseCase user.UserUseCase) *HandlerFactory {
-	return &HandlerFactory{
-		userUseCase: userUseCase,
+func NewHandlerFactory(
+	userUseCase user.UserUseCase,
+	roleUseCase role.RoleUseCase,
+	auditUseCase audit.AuditUseCase,
+	privacyUseCase privacy.PrivacyUseCase,
+	idempotencyUseCase idempotency.IdempotencyUseCase,
+	health *health.Health,
+	opts ...FactoryOption,
+) *HandlerFactory {
+	f := &HandlerFactory{
+		userUseCase:        userUseCase,
+		roleUseCase:        roleUseCase,
+		auditUseCase:       auditUseCase,
+		privacyUseCase:     privacyUseCase,
+		idempotencyUseCase: idempotencyUseCase,
+		health:             health,
+	}
+	for _, opt := range opts {
+		opt(f)
 	}
+	return f
 }
 
 // CreateHandler creates a handler based on type
@@ -29,6 +73,16 @@ func (f *HandlerFactory) CreateHandler(handlerType HandlerType) interface{} {
 	switch handlerType {
 	case UserHandlerType:
 		return NewUserHandler(f.userUseCase)
+	case RoleHandlerType:
+		return NewRoleHandler(f.roleUseCase)
+	case AuditHandlerType:
+		return NewAuditHandler(f.auditUseCase)
+	case PrivacyHandlerType:
+		return NewPrivacyHandler(f.privacyUseCase)
+	case HealthHandlerType:
+		return NewHealthHandler(f.health)
+	case GraphQLHandlerType:
+		return NewGraphQLHandler(graphql.New(f.userUseCase, f.roleUseCase, f.graphqlOptions...))
 	default:
 		return nil
 	}
@@ -39,3 +93,37 @@ func (f *HandlerFactory) GetUserHandler() *UserHandler {
 	return f.CreateHandler(UserHandlerType).(*UserHandler)
 }
 
+// GetRoleHandler returns a role handler
+func (f *HandlerFactory) GetRoleHandler() *RoleHandler {
+	return f.CreateHandler(RoleHandlerType).(*RoleHandler)
+}
+
+// GetAuditHandler returns an audit handler
+func (f *HandlerFactory) GetAuditHandler() *AuditHandler {
+	return f.CreateHandler(AuditHandlerType).(*AuditHandler)
+}
+
+// GetPrivacyHandler returns a privacy handler
+func (f *HandlerFactory) GetPrivacyHandler() *PrivacyHandler {
+	return f.CreateHandler(PrivacyHandlerType).(*PrivacyHandler)
+}
+
+// GetHealthHandler returns a health handler
+func (f *HandlerFactory) GetHealthHandler() *HealthHandler {
+	return f.CreateHandler(HealthHandlerType).(*HealthHandler)
+}
+
+// GetGraphQLHandler returns a GraphQL handler
+func (f *HandlerFactory) GetGraphQLHandler() *GraphQLHandler {
+	return f.CreateHandler(GraphQLHandlerType).(*GraphQLHandler)
+}
+
+// GetPermissionChecker returns the checker used by authorization middleware
+func (f *HandlerFactory) GetPermissionChecker() role.PermissionChecker {
+	return f.roleUseCase
+}
+
+// GetIdempotencyUseCase returns the use case backing the idempotency middleware
+func (f *HandlerFactory) GetIdempotencyUseCase() idempotency.IdempotencyUseCase {
+	return f.idempotencyUseCase
+}
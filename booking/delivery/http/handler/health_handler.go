@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"net/http"
+
+	"booking/infrastructure/health"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HealthHandler handles liveness and readiness probes
+type HealthHandler struct {
+	health *health.Health
+}
+
+// NewHealthHandler creates a new health handler
+func NewHealthHandler(h *health.Health) *HealthHandler {
+	return &HealthHandler{
+		health: h,
+	}
+}
+
+// Livez handles GET /livez
+// The process is alive if it can serve this request; dependencies are not checked,
+// so an outage of the database doesn't get every instance restarted.
+func (h *HealthHandler) Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, LivenessResponse{
+		Status:  health.StatusOK,
+		Message: "Booking service is running",
+	})
+}
+
+// Readyz handles GET /readyz
+// It returns 503 with the report of every check until all of them pass.
+func (h *HealthHandler) Readyz(c *gin.Context) {
+	report := h.health.Readiness(c.Request.Context())
+
+	status := http.StatusOK
+	if !report.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"booking/usecase/privacy"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PrivacyHandler handles self-service GDPR export and erasure requests
+type PrivacyHandler struct {
+	privacyUseCase privacy.PrivacyUseCase
+}
+
+// NewPrivacyHandler creates a new privacy handler
+func NewPrivacyHandler(privacyUseCase privacy.PrivacyUseCase) *PrivacyHandler {
+	return &PrivacyHandler{
+		privacyUseCase: privacyUseCase,
+	}
+}
+
+// RequestExport handles POST /me/exports
+func (h *PrivacyHandler) RequestExport(c *gin.Context) {
+	req, err := h.privacyUseCase.RequestExport(c.Request.Context())
+	if err != nil {
+		c.JSON(privacyErrorStatus(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, PrivacyRequestResponse{
+		Message: "Export requested; poll the request until its status is completed",
+		Data:    req,
+	})
+}
+
+// RequestErasure handles POST /me/erasure
+func (h *PrivacyHandler) RequestErasure(c *gin.Context) {
+	req, err := h.privacyUseCase.RequestErasure(c.Request.Context())
+	if err != nil {
+		c.JSON(privacyErrorStatus(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, PrivacyRequestResponse{
+		Message: "Erasure requested",
+		Data:    req,
+	})
+}
+
+// ListRequests handles GET /me/privacy-requests
+func (h *PrivacyHandler) ListRequests(c *gin.Context) {
+	reqs, err := h.privacyUseCase.ListRequests(c.Request.Context())
+	if err != nil {
+		c.JSON(privacyErrorStatus(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, PrivacyRequestListResponse{Data: reqs})
+}
+
+// GetRequest handles GET /me/privacy-requests/:id
+func (h *PrivacyHandler) GetRequest(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request ID"})
+		return
+	}
+
+	req, err := h.privacyUseCase.GetRequest(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(privacyErrorStatus(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, PrivacyRequestResponse{Data: req})
+}
+
+// DownloadExport handles GET /me/exports/:id/download
+func (h *PrivacyHandler) DownloadExport(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request ID"})
+		return
+	}
+
+	path, err := h.privacyUseCase.ExportFile(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(privacyErrorStatus(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.FileAttachment(path, fmt.Sprintf("data-export-%d.zip", id))
+}
+
+// privacyErrorStatus maps privacy use case errors to HTTP status codes
+func privacyErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, privacy.ErrUnauthenticated):
+		return http.StatusUnauthorized
+	case errors.Is(err, privacy.ErrRequestNotFound), errors.Is(err, privacy.ErrUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, privacy.ErrExportNotReady), errors.Is(err, privacy.ErrNotAnExport):
+		return http.StatusConflict
+	case errors.Is(err, privacy.ErrExportExpired):
+		return http.StatusGone
+	default:
+		return http.StatusInternalServerError
+	}
+}
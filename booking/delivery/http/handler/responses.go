@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"booking/delivery/http/openapi"
+	"booking/domain/entity"
+	"booking/usecase/user"
+)
+
+// Response bodies rendered by the handlers
+// They are the source of the OpenAPI document, so handlers render these types
+// rather than ad-hoc gin.H maps.
+
+// ErrorResponse is the body of every error response
+type ErrorResponse struct {
+	Error string `json:"error"`
+	// Fields lists each invalid parameter or body field when request validation fails
+	Fields []openapi.FieldError `json:"fields,omitempty"`
+}
+
+// MessageResponse confirms an operation that returns no data
+type MessageResponse struct {
+	Message string `json:"message"`
+}
+
+// UserResponse wraps a single user
+type UserResponse struct {
+	Message string       `json:"message,omitempty"`
+	Data    *entity.User `json:"data"`
+}
+
+// UserListResponse is one page of users
+type UserListResponse struct {
+	Data  []*entity.User `json:"data"`
+	Total int64          `json:"total"`
+	Limit int            `json:"limit"`
+	// NextCursor is passed as ?cursor= to fetch the next page; empty on the last page
+	NextCursor string `json:"next_cursor"`
+	HasMore    bool   `json:"has_more"`
+}
+
+// UserImportResponse wraps the report of a user import
+type UserImportResponse struct {
+	Data *user.ImportReport `json:"data"`
+}
+
+// RoleListResponse lists roles with their permissions
+type RoleListResponse struct {
+	Data []*entity.Role `json:"data"`
+}
+
+// PrivacyRequestResponse wraps a single GDPR request
+type PrivacyRequestResponse struct {
+	Message string                 `json:"message,omitempty"`
+	Data    *entity.PrivacyRequest `json:"data"`
+}
+
+// PrivacyRequestListResponse lists the GDPR requests of the caller
+type PrivacyRequestListResponse struct {
+	Data []*entity.PrivacyRequest `json:"data"`
+}
+
+// AuditListResponse is one page of audit entries
+type AuditListResponse struct {
+	Data   []*entity.AuditEntry `json:"data"`
+	Total  int64                `json:"total"`
+	Limit  int                  `json:"limit"`
+	Offset int                  `json:"offset"`
+}
+
+// AuditChainResponse wraps the result of verifying the audit hash chain
+type AuditChainResponse struct {
+	Data *entity.AuditChainReport `json:"data"`
+}
+
+// LivenessResponse is the body of the liveness probe
+type LivenessResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"booking/usecase/role"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoleHandler handles HTTP requests for role administration
+type RoleHandler struct {
+	roleUseCase role.RoleUseCase
+}
+
+// NewRoleHandler creates a new role handler
+func NewRoleHandler(roleUseCase role.RoleUseCase) *RoleHandler {
+	return &RoleHandler{
+		roleUseCase: roleUseCase,
+	}
+}
+
+// GrantRoleRequest represents the request body for granting a role
+type GrantRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// ListRoles handles GET /admin/roles
+func (h *RoleHandler) ListRoles(c *gin.Context) {
+	roles, err := h.roleUseCase.ListRoles(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, RoleListResponse{Data: roles})
+}
+
+// GetUserRoles handles GET /admin/users/:id/roles
+func (h *RoleHandler) GetUserRoles(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+
+	roles, err := h.roleUseCase.GetUserRoles(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(roleErrorStatus(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, RoleListResponse{Data: roles})
+}
+
+// GrantRole handles POST /admin/users/:id/roles
+func (h *RoleHandler) GrantRole(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+
+	var req GrantRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if err := h.roleUseCase.GrantRole(c.Request.Context(), uint(id), req.Role); err != nil {
+		c.JSON(roleErrorStatus(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "Role granted successfully"})
+}
+
+// RevokeRole handles DELETE /admin/users/:id/roles/:role
+func (h *RoleHandler) RevokeRole(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+
+	if err := h.roleUseCase.RevokeRole(c.Request.Context(), uint(id), c.Param("role")); err != nil {
+		c.JSON(roleErrorStatus(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "Role revoked successfully"})
+}
+
+// roleErrorStatus maps role use case errors to HTTP status codes
+func roleErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, role.ErrUnauthenticated):
+		return http.StatusUnauthorized
+	case errors.Is(err, role.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, role.ErrUserNotFound), errors.Is(err, role.ErrRoleNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, role.ErrSelfRevokeAdmin):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -1,14 +1,24 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
+	"booking/delivery/userfile"
 	"booking/domain/entity"
 	"booking/usecase/user"
 	
 	"github.com/gin-gonic/gin"
 )
 
+// maxImportBodySize bounds the body of a user import
+const maxImportBodySize = 10 << 20
+
+// exportFlushEvery is how many exported users are sent to the client at a time
+const exportFlushEvery = 100
+
 // UserHandler handles HTTP requests for user operations
 type UserHandler struct {
 	userUseCase user.UserUseCase
@@ -44,7 +54,7 @@ type UpdateUserRequest struct {
 func (h *UserHandler) CreateUser(c *gin.Context) {
 	var req CreateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		return
 	}
 	
@@ -58,13 +68,41 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 	}
 	
 	if err := h.userUseCase.CreateUser(c.Request.Context(), user); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(userErrorStatus(err), ErrorResponse{Error: err.Error()})
 		return
 	}
 	
-	c.JSON(http.StatusCreated, gin.H{
-		"message": "User created successfully",
-		"data":    user,
+	c.JSON(http.StatusCreated, UserResponse{
+		Message: "User created successfully",
+		Data:    user,
+	})
+}
+
+// Signup handles POST /signup
+// Anyone may register; the account is active and gets the runner role.
+func (h *UserHandler) Signup(c *gin.Context) {
+	var req CreateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	
+	user := &entity.User{
+		Email:    req.Email,
+		Username: req.Username,
+		Password: req.Password,
+		FullName: req.FullName,
+		Phone:    req.Phone,
+	}
+	
+	if err := h.userUseCase.RegisterUser(c.Request.Context(), user); err != nil {
+		c.JSON(userErrorStatus(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+	
+	c.JSON(http.StatusCreated, UserResponse{
+		Message: "Account created successfully",
+		Data:    user,
 	})
 }
 
@@ -72,56 +110,62 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 func (h *UserHandler) GetUser(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid user ID"})
 		return
 	}
 	
 	user, err := h.userUseCase.GetUserByID(c.Request.Context(), uint(id))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
 		return
 	}
 	
-	c.JSON(http.StatusOK, gin.H{"data": user})
+	c.JSON(http.StatusOK, UserResponse{Data: user})
 }
 
 // ListUsers handles GET /users
 func (h *UserHandler) ListUsers(c *gin.Context) {
-	filter := &entity.UserFilter{}
-	
-	// Parse query parameters
-	if email := c.Query("email"); email != "" {
-		filter.Email = &email
-	}
-	if username := c.Query("username"); username != "" {
-		filter.Username = &username
+	filter, message := parseUserFilter(c)
+	if message != "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: message})
+		return
 	}
-	if isActive := c.Query("is_active"); isActive != "" {
-		active := isActive == "true"
-		filter.IsActive = &active
+	
+	if cursor := c.Query("cursor"); cursor != "" {
+		after, err := entity.DecodeUserCursor(cursor)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid cursor"})
+			return
+		}
+		filter.After = after
 	}
 	if limit := c.Query("limit"); limit != "" {
 		if l, err := strconv.Atoi(limit); err == nil {
 			filter.Limit = l
 		}
 	}
-	if offset := c.Query("offset"); offset != "" {
-		if o, err := strconv.Atoi(offset); err == nil {
-			filter.Offset = o
-		}
-	}
 	
-	users, err := h.userUseCase.ListUsers(c.Request.Context(), filter)
+	page, err := h.userUseCase.ListUsers(c.Request.Context(), filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		switch {
+		case errors.Is(err, user.ErrInvalidSort):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid sort, expected one of created_at, username, email"})
+		case errors.Is(err, entity.ErrInvalidCursor):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid cursor"})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
 		return
 	}
 	
 	count, _ := h.userUseCase.CountUsers(c.Request.Context(), filter)
 	
-	c.JSON(http.StatusOK, gin.H{
-		"data":  users,
-		"total": count,
+	c.JSON(http.StatusOK, UserListResponse{
+		Data:       page.Users,
+		Total:      count,
+		Limit:      filter.Limit,
+		NextCursor: page.NextCursor,
+		HasMore:    page.HasMore,
 	})
 }
 
@@ -129,13 +173,13 @@ func (h *UserHandler) ListUsers(c *gin.Context) {
 func (h *UserHandler) UpdateUser(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid user ID"})
 		return
 	}
 	
 	var req UpdateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		return
 	}
 	
@@ -153,13 +197,13 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 	}
 	
 	if err := h.userUseCase.UpdateUser(c.Request.Context(), user); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(userErrorStatus(err), ErrorResponse{Error: err.Error()})
 		return
 	}
 	
-	c.JSON(http.StatusOK, gin.H{
-		"message": "User updated successfully",
-		"data":    user,
+	c.JSON(http.StatusOK, UserResponse{
+		Message: "User updated successfully",
+		Data:    user,
 	})
 }
 
@@ -167,15 +211,204 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 func (h *UserHandler) DeleteUser(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid user ID"})
 		return
 	}
 	
 	if err := h.userUseCase.DeleteUser(c.Request.Context(), uint(id)); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		if errors.Is(err, user.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+	
+	c.JSON(http.StatusOK, MessageResponse{Message: "User deleted successfully"})
+}
+
+// RestoreUser handles POST /admin/users/:id/restore
+func (h *UserHandler) RestoreUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+	
+	restored, err := h.userUseCase.RestoreUser(c.Request.Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Deleted user not found or already purged"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
 	
-	c.JSON(http.StatusOK, gin.H{"message": "User deleted successfully"})
+	c.JSON(http.StatusOK, UserResponse{
+		Message: "User restored successfully",
+		Data:    restored,
+	})
 }
 
+// ImportUsers handles POST /admin/users/import
+// The body is CSV (text/csv) or NDJSON (application/x-ndjson); with
+// ?dry_run=true rows are only validated. Row problems are listed in the
+// report, so a file with invalid rows still gets 200.
+func (h *UserHandler) ImportUsers(c *gin.Context) {
+	format, ok := userfile.FormatForContentType(c.ContentType())
+	if !ok {
+		c.JSON(http.StatusUnsupportedMediaType, ErrorResponse{Error: "Content-Type must be text/csv or application/x-ndjson"})
+		return
+	}
+	
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBodySize)
+	source, err := userfile.NewReader(body, format)
+	if err != nil {
+		c.JSON(importErrorStatus(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+	
+	report, err := h.userUseCase.ImportUsers(c.Request.Context(), source, c.Query("dry_run") == "true")
+	if err != nil {
+		c.JSON(importErrorStatus(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+	
+	c.JSON(http.StatusOK, UserImportResponse{Data: report})
+}
+
+// ExportUsers handles GET /admin/users/export
+// Users matching the ListUsers filters are streamed as CSV (default) or
+// NDJSON, one page at a time. An error after the first users were sent can
+// only cut the download short; it is logged with the request.
+func (h *UserHandler) ExportUsers(c *gin.Context) {
+	format := userfile.CSV
+	if name := c.Query("format"); name != "" {
+		var err error
+		if format, err = userfile.ParseFormat(name); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid format, expected csv or ndjson"})
+			return
+		}
+	}
+	filter, message := parseUserFilter(c)
+	if message != "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: message})
+		return
+	}
+	
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", `attachment; filename="users.`+string(format)+`"`)
+	
+	exported := 0
+	writer := userfile.NewWriter(c.Writer, format)
+	_, err := h.userUseCase.ExportUsers(c.Request.Context(), filter, func(u *entity.User) error {
+		if err := writer.Write(u); err != nil {
+			return err
+		}
+		exported++
+		if exported%exportFlushEvery == 0 {
+			if err := writer.Flush(); err != nil {
+				return err
+			}
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = writer.Flush()
+	}
+	if err == nil {
+		return
+	}
+	
+	if !c.Writer.Written() {
+		c.Writer.Header().Del("Content-Type")
+		c.Writer.Header().Del("Content-Disposition")
+		if errors.Is(err, user.ErrInvalidSort) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid sort, expected one of created_at, username, email"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+	_ = c.Error(err)
+}
+
+// userErrorStatus maps an error of CreateUser or UpdateUser to its HTTP status
+// Emails and usernames of soft-deleted users stay taken until they are purged.
+func userErrorStatus(err error) int {
+	var invalidUser *user.ValidationError
+	switch {
+	case errors.As(err, &invalidUser):
+		return http.StatusBadRequest
+	case errors.Is(err, user.ErrUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, user.ErrEmailTaken), errors.Is(err, user.ErrUsernameTaken):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// importErrorStatus is the status of an import that failed as a whole
+func importErrorStatus(err error) int {
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge), errors.Is(err, user.ErrImportTooLarge):
+		return http.StatusRequestEntityTooLarge
+	}
+	var invalidFile *userfile.FileError
+	if errors.As(err, &invalidFile) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// parseUserFilter reads the filter and sort query parameters shared by listing and export
+// The message is set when a parameter is invalid.
+func parseUserFilter(c *gin.Context) (*entity.UserFilter, string) {
+	filter := &entity.UserFilter{}
+	
+	// Parse query parameters
+	if email := c.Query("email"); email != "" {
+		filter.Email = &email
+	}
+	if username := c.Query("username"); username != "" {
+		filter.Username = &username
+	}
+	if fullName := c.Query("full_name"); fullName != "" {
+		filter.FullName = &fullName
+	}
+	if phone := c.Query("phone"); phone != "" {
+		filter.Phone = &phone
+	}
+	if isActive := c.Query("is_active"); isActive != "" {
+		active := isActive == "true"
+		filter.IsActive = &active
+	}
+	if q := c.Query("q"); q != "" {
+		filter.Search = &q
+	}
+	if from := c.Query("created_from"); from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return nil, "Invalid created_from, expected RFC3339"
+		}
+		filter.CreatedFrom = &t
+	}
+	if to := c.Query("created_to"); to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return nil, "Invalid created_to, expected RFC3339"
+		}
+		filter.CreatedTo = &t
+	}
+	// sort=username sorts ascending, sort=-username descending
+	if sort := c.Query("sort"); sort != "" {
+		filter.SortBy = strings.TrimPrefix(sort, "-")
+		filter.SortDesc = strings.HasPrefix(sort, "-")
+	}
+	
+	return filter, ""
+}
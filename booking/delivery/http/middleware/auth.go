@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"booking/domain/identity"
+	"booking/infrastructure/auth"
+	"booking/usecase/role"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Authenticate stores the user proven by the request's bearer token, or by the
+// user ID header when it is trusted, in the request context. Requests without
+// credentials continue anonymously; RequirePermission decides whether that is acceptable.
+func Authenticate(authenticator *auth.Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		credentials := auth.Credentials{Authorization: c.GetHeader("Authorization")}
+		if header := authenticator.UserHeader(); header != "" {
+			credentials.UserID = c.GetHeader(header)
+		}
+
+		userID, err := authenticator.Authenticate(credentials)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials: " + err.Error()})
+			return
+		}
+		if userID == 0 {
+			c.Next()
+			return
+		}
+
+		ctx := identity.WithActor(c.Request.Context(), userID)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// RequirePermission aborts the request unless the authenticated user holds the permission
+func RequirePermission(checker role.PermissionChecker, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := role.Require(c.Request.Context(), checker, permission); err != nil {
+			switch {
+			case errors.Is(err, role.ErrUnauthenticated):
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			case errors.Is(err, role.ErrForbidden):
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			default:
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			}
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+
+	"booking/delivery/consistency"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ConsistencyOption configures the ReadYourWrites middleware
+type ConsistencyOption func(*consistencyOptions)
+
+type consistencyOptions struct {
+	recordWrites bool
+}
+
+// WithWriteRecording sets whether successful non-GET requests start the actor's window
+// Routes whose POST requests only read, like /graphql, turn it off.
+func WithWriteRecording(record bool) ConsistencyOption {
+	return func(o *consistencyOptions) {
+		o.recordWrites = record
+	}
+}
+
+// ReadYourWrites serves reads from the primary when the X-Consistency header
+// is "strong", or when the authenticated actor wrote within the tracker's
+// window. Successful requests other than GET, HEAD and OPTIONS start that
+// window. It runs after Authenticate; tracker may be nil, leaving the header.
+func ReadYourWrites(tracker *consistency.Tracker, opts ...ConsistencyOption) gin.HandlerFunc {
+	options := consistencyOptions{recordWrites: true}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return func(c *gin.Context) {
+		ctx := tracker.Context(c.Request.Context(), c.GetHeader(consistency.Header))
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		if !options.recordWrites || c.Writer.Status() >= http.StatusBadRequest {
+			return
+		}
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			tracker.WroteIn(ctx)
+		}
+	}
+}
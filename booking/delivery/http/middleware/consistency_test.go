@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"booking/delivery/consistency"
+	"booking/domain/identity"
+	"booking/domain/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newConsistencyEngine serves /things for actor 7, recording whether each request read its own writes
+func newConsistencyEngine(tracker *consistency.Tracker, opts ...ConsistencyOption) (*gin.Engine, *bool) {
+	gin.SetMode(gin.TestMode)
+	var readYourWrites bool
+
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(identity.WithActor(c.Request.Context(), 7))
+	})
+	engine.Use(ReadYourWrites(tracker, opts...))
+	record := func(status int) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			readYourWrites = repository.ReadYourWrites(c.Request.Context())
+			c.Status(status)
+		}
+	}
+	engine.GET("/things", record(http.StatusOK))
+	engine.POST("/things", record(http.StatusCreated))
+	engine.PUT("/things", record(http.StatusConflict))
+	return engine, &readYourWrites
+}
+
+// send serves a request with an optional X-Consistency header
+func send(engine *gin.Engine, method, header string) {
+	req := httptest.NewRequest(method, "/things", nil)
+	if header != "" {
+		req.Header.Set(consistency.Header, header)
+	}
+	engine.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+func TestReadYourWritesAfterWrite(t *testing.T) {
+	engine, readYourWrites := newConsistencyEngine(consistency.NewTracker(time.Minute))
+
+	send(engine, http.MethodGet, "")
+	if *readYourWrites {
+		t.Error("read-your-writes before any write")
+	}
+
+	// A failed write does not start the window
+	send(engine, http.MethodPut, "")
+	send(engine, http.MethodGet, "")
+	if *readYourWrites {
+		t.Error("read-your-writes after a failed write")
+	}
+
+	send(engine, http.MethodPost, "")
+	send(engine, http.MethodGet, "")
+	if !*readYourWrites {
+		t.Error("no read-your-writes after a successful write")
+	}
+}
+
+func TestReadYourWritesHeader(t *testing.T) {
+	engine, readYourWrites := newConsistencyEngine(nil)
+
+	send(engine, http.MethodGet, consistency.Strong)
+	if !*readYourWrites {
+		t.Error("X-Consistency: strong is ignored")
+	}
+	send(engine, http.MethodGet, "")
+	if *readYourWrites {
+		t.Error("read-your-writes without the header or a tracker")
+	}
+}
+
+func TestReadYourWritesWithoutWriteRecording(t *testing.T) {
+	engine, readYourWrites := newConsistencyEngine(consistency.NewTracker(time.Minute), WithWriteRecording(false))
+
+	send(engine, http.MethodPost, "")
+	send(engine, http.MethodGet, "")
+	if *readYourWrites {
+		t.Error("a POST started the window with write recording off")
+	}
+}
@@ -1,23 +1,139 @@
 package middleware
 
 import (
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/gin-gonic/gin"
 )
 
+// CORSPolicy describes which cross-origin browser requests are allowed
+// AllowedOrigins holds exact origins ("https://app.example.com") or patterns
+// with a * wildcard ("https://*.example.com"). A lone "*" allows any origin,
+// but then the response is never credentialed. An empty list allows no
+// cross-origin access.
+type CORSPolicy struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache a preflight response
+	MaxAge time.Duration
+}
+
+// CORSRoute overrides the policy for requests whose path starts with Prefix
+type CORSRoute struct {
+	Prefix string
+	Policy CORSPolicy
+}
+
 // CORS middleware for handling Cross-Origin Resource Sharing
-func CORS() gin.HandlerFunc {
+// The longest matching route prefix selects the policy, falling back to
+// policy. Allowed origins are reflected back; others get no CORS headers, and
+// their preflight requests are refused. It runs globally, before
+// authentication, because preflight requests carry no credentials and match no
+// route.
+func CORS(policy CORSPolicy, routes ...CORSRoute) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+		selected := policy
+		longest := -1
+		for _, route := range routes {
+			if strings.HasPrefix(c.Request.URL.Path, route.Prefix) && len(route.Prefix) > longest {
+				selected = route.Policy
+				longest = len(route.Prefix)
+			}
+		}
+
+		header := c.Writer.Header()
+		origin := c.GetHeader("Origin")
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+
+		// Responses differ by origin, so shared caches must not mix them up
+		header.Add("Vary", "Origin")
+		if preflight {
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+		}
+
+		if origin == "" {
+			c.Next()
+			return
+		}
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
+		allowOrigin, credentials := selected.allowOrigin(origin)
+		if allowOrigin == "" {
+			if preflight {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Origin not allowed"})
+				return
+			}
+			c.Next()
 			return
 		}
 
-		c.Next()
+		header.Set("Access-Control-Allow-Origin", allowOrigin)
+		if credentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			if len(selected.ExposedHeaders) > 0 {
+				header.Set("Access-Control-Expose-Headers", strings.Join(selected.ExposedHeaders, ", "))
+			}
+			c.Next()
+			return
+		}
+
+		if !containsFold(selected.AllowedMethods, c.GetHeader("Access-Control-Request-Method")) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Method not allowed for cross-origin requests"})
+			return
+		}
+		header.Set("Access-Control-Allow-Methods", strings.Join(selected.AllowedMethods, ", "))
+		if len(selected.AllowedHeaders) > 0 {
+			header.Set("Access-Control-Allow-Headers", strings.Join(selected.AllowedHeaders, ", "))
+		}
+		if selected.MaxAge > 0 {
+			header.Set("Access-Control-Max-Age", strconv.Itoa(int(selected.MaxAge.Seconds())))
+		}
+		c.AbortWithStatus(http.StatusNoContent)
 	}
 }
 
+// allowOrigin returns the Access-Control-Allow-Origin value for origin, or "" if it is not allowed
+func (p CORSPolicy) allowOrigin(origin string) (string, bool) {
+	origin = strings.ToLower(origin)
+	anyOrigin := false
+	for _, allowed := range p.AllowedOrigins {
+		allowed = strings.ToLower(allowed)
+		if allowed == "*" {
+			anyOrigin = true
+			continue
+		}
+		if allowed == origin {
+			return origin, p.AllowCredentials
+		}
+		if strings.Contains(allowed, "*") {
+			// path.Match keeps * from spanning a "/", so a pattern cannot match a different scheme or path
+			if matched, _ := path.Match(allowed, origin); matched {
+				return origin, p.AllowCredentials
+			}
+		}
+	}
+	if anyOrigin {
+		return "*", false
+	}
+	return "", false
+}
+
+// containsFold reports whether values contains value, ignoring case
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
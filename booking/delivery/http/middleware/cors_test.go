@@ -0,0 +1,168 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// appPolicy allows the web app and its preview subdomains with credentials
+var appPolicy = CORSPolicy{
+	AllowedOrigins:   []string{"https://app.example.com", "https://*.preview.example.com"},
+	AllowedMethods:   []string{"GET", "POST"},
+	AllowedHeaders:   []string{"Authorization", "Content-Type"},
+	ExposedHeaders:   []string{"X-Request-ID"},
+	AllowCredentials: true,
+	MaxAge:           10 * time.Minute,
+}
+
+func TestCORSOriginMatching(t *testing.T) {
+	tests := []struct {
+		name            string
+		origins         []string
+		origin          string
+		wantOrigin      string
+		wantCredentials bool
+	}{
+		{name: "exact", origin: "https://app.example.com", wantOrigin: "https://app.example.com", wantCredentials: true},
+		{name: "case insensitive", origin: "https://APP.example.com", wantOrigin: "https://app.example.com", wantCredentials: true},
+		{name: "wildcard subdomain", origin: "https://pr-12.preview.example.com", wantOrigin: "https://pr-12.preview.example.com", wantCredentials: true},
+		{name: "wildcard needs a subdomain", origin: "https://preview.example.com"},
+		{name: "other scheme", origin: "http://app.example.com"},
+		{name: "other port", origin: "https://app.example.com:8443"},
+		{name: "suffix of another domain", origin: "https://app.example.com.evil.test"},
+		{name: "wildcard cannot span a path", origin: "https://evil.test/x.preview.example.com"},
+		{name: "no origins allowed", origins: []string{}, origin: "https://app.example.com"},
+		{name: "any origin without credentials", origins: []string{"*"}, origin: "https://elsewhere.test", wantOrigin: "*"},
+		{name: "listed origin beats any", origins: []string{"*", "https://app.example.com"}, origin: "https://app.example.com", wantOrigin: "https://app.example.com", wantCredentials: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := appPolicy
+			if tt.origins != nil {
+				policy.AllowedOrigins = tt.origins
+			}
+			rec := serveCORS(policy, http.MethodGet, "/api/v1/users", tt.origin, "")
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200; simple requests reach the handler", rec.Code)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Credentials") == "true"; got != tt.wantCredentials {
+				t.Errorf("Allow-Credentials = %v, want %v", got, tt.wantCredentials)
+			}
+			if got := rec.Header().Get("Access-Control-Expose-Headers"); (got != "") != (tt.wantOrigin != "") {
+				t.Errorf("Expose-Headers = %q for allowed origin %q", got, tt.wantOrigin)
+			}
+			if rec.Header().Get("Vary") != "Origin" {
+				t.Errorf("Vary = %q, want Origin", rec.Header().Values("Vary"))
+			}
+		})
+	}
+}
+
+func TestCORSPreflight(t *testing.T) {
+	tests := []struct {
+		name        string
+		origin      string
+		method      string
+		wantStatus  int
+		wantAllowed bool
+	}{
+		{name: "allowed", origin: "https://app.example.com", method: "POST", wantStatus: http.StatusNoContent, wantAllowed: true},
+		{name: "method in other case", origin: "https://app.example.com", method: "post", wantStatus: http.StatusNoContent, wantAllowed: true},
+		{name: "method not allowed", origin: "https://app.example.com", method: "DELETE", wantStatus: http.StatusForbidden},
+		{name: "origin not allowed", origin: "https://evil.test", method: "POST", wantStatus: http.StatusForbidden},
+		{name: "plain OPTIONS passes through", origin: "https://app.example.com", wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveCORS(appPolicy, http.MethodOptions, "/api/v1/users", tt.origin, tt.method)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			header := rec.Header()
+			if got := header.Get("Access-Control-Allow-Methods") != ""; got != tt.wantAllowed {
+				t.Errorf("Allow-Methods = %q, want it set: %v", header.Get("Access-Control-Allow-Methods"), tt.wantAllowed)
+			}
+			if !tt.wantAllowed {
+				return
+			}
+			if got := header.Get("Access-Control-Allow-Headers"); got != "Authorization, Content-Type" {
+				t.Errorf("Allow-Headers = %q", got)
+			}
+			if got := header.Get("Access-Control-Max-Age"); got != "600" {
+				t.Errorf("Max-Age = %q, want 600", got)
+			}
+			if got := header.Values("Vary"); len(got) != 3 {
+				t.Errorf("Vary = %q, want Origin and the preflight request headers", got)
+			}
+		})
+	}
+}
+
+func TestCORSRoutePolicy(t *testing.T) {
+	adminPolicy := appPolicy
+	adminPolicy.AllowedOrigins = []string{"https://admin.example.com"}
+	routes := []CORSRoute{
+		{Prefix: "/api/v1", Policy: appPolicy},
+		{Prefix: "/api/v1/admin", Policy: adminPolicy},
+	}
+
+	tests := []struct {
+		path    string
+		origin  string
+		allowed bool
+	}{
+		{path: "/api/v1/users", origin: "https://app.example.com", allowed: true},
+		{path: "/api/v1/users", origin: "https://admin.example.com"},
+		{path: "/api/v1/admin/audit", origin: "https://admin.example.com", allowed: true},
+		{path: "/api/v1/admin/audit", origin: "https://app.example.com"},
+		// The default policy allows nothing
+		{path: "/graphql", origin: "https://app.example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path+" from "+tt.origin, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			engine := gin.New()
+			engine.Use(CORS(CORSPolicy{}, routes...))
+			engine.NoRoute(func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			req := httptest.NewRequest(http.MethodOptions, tt.path, nil)
+			req.Header.Set("Origin", tt.origin)
+			req.Header.Set("Access-Control-Request-Method", "GET")
+			rec := httptest.NewRecorder()
+			engine.ServeHTTP(rec, req)
+
+			if got := rec.Code == http.StatusNoContent; got != tt.allowed {
+				t.Errorf("preflight status = %d, want allowed %v", rec.Code, tt.allowed)
+			}
+		})
+	}
+}
+
+// serveCORS sends a request through the CORS middleware in front of a handler answering 200
+// requestMethod, when set, makes an OPTIONS request a preflight.
+func serveCORS(policy CORSPolicy, method, target, origin, requestMethod string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(CORS(policy))
+	engine.Handle(method, target, func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	req := httptest.NewRequest(method, target, nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	if requestMethod != "" {
+		req.Header.Set("Access-Control-Request-Method", requestMethod)
+	}
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+	return rec
+}
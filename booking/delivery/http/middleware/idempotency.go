@@ -0,0 +1,165 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"booking/domain/entity"
+	"booking/domain/identity"
+	"booking/infrastructure/logging"
+	"booking/usecase/idempotency"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// IdempotencyKeyHeader carries the client-chosen key of a retryable request
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader marks a response replayed from an earlier request
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// maxIdempotencyKeyLength bounds client-supplied keys
+const maxIdempotencyKeyLength = 255
+
+// defaultIdempotencyMaxBody is how much of a body is read when no limit is configured
+const defaultIdempotencyMaxBody = 10 << 20
+
+// IdempotencyOption configures the Idempotency middleware
+type IdempotencyOption func(*idempotencyOptions)
+
+// idempotencyOptions holds the Idempotency middleware settings
+type idempotencyOptions struct {
+	maxBodySize int64
+}
+
+// WithMaxBodySize bounds the body read to fingerprint a request
+// Larger requests carrying an Idempotency-Key get 413; values <= 0 keep the default of 10 MB.
+func WithMaxBodySize(n int64) IdempotencyOption {
+	return func(o *idempotencyOptions) {
+		if n > 0 {
+			o.maxBodySize = n
+		}
+	}
+}
+
+// Idempotency makes POST requests carrying an Idempotency-Key safe to retry
+// The first response per caller and key is stored and replayed for retries.
+// Reusing a key with a different request is rejected with 422, and a retry
+// that arrives while the first request is still running gets 409. Server
+// errors are not stored, so the client can retry them with the same key.
+// The body is read into memory to fingerprint the request, up to WithMaxBodySize.
+func Idempotency(idempotencyUseCase idempotency.IdempotencyUseCase, opts ...IdempotencyOption) gin.HandlerFunc {
+	options := &idempotencyOptions{maxBodySize: defaultIdempotencyMaxBody}
+	for _, opt := range opts {
+		opt(options)
+	}
+	logger := logging.For("http")
+
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if c.Request.Method != http.MethodPost || key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key must be at most 255 characters"})
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, options.maxBodySize))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+					"error": "Request body exceeds " + strconv.FormatInt(tooLarge.Limit, 10) + " bytes",
+				})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		record, err := idempotencyUseCase.Begin(c.Request.Context(), idempotencyScope(c), key, requestHash(c, body))
+		if err != nil {
+			switch {
+			case errors.Is(err, idempotency.ErrKeyReused):
+				c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			case errors.Is(err, idempotency.ErrRequestInProgress):
+				c.Header("Retry-After", "1")
+				c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			default:
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			}
+			return
+		}
+
+		if record.Status == entity.IdempotencyCompleted {
+			c.Header(IdempotentReplayedHeader, "true")
+			c.Data(record.ResponseStatus, record.ContentType, record.ResponseBody)
+			c.Abort()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		c.Next()
+
+		// Store the outcome even if the client has gone away; its retry needs it
+		ctx := context.WithoutCancel(c.Request.Context())
+		if recorder.Status() >= http.StatusInternalServerError {
+			err = idempotencyUseCase.Release(ctx, record)
+		} else {
+			err = idempotencyUseCase.Complete(ctx, record, recorder.Status(), recorder.Header().Get("Content-Type"), recorder.body.Bytes())
+		}
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to store idempotent response",
+				slog.String("idempotency_key", key),
+				slog.Any("error", err),
+			)
+		}
+	}
+}
+
+// idempotencyScope keeps keys of different callers apart
+func idempotencyScope(c *gin.Context) string {
+	if actorID, ok := identity.ActorFromContext(c.Request.Context()); ok {
+		return "user:" + strconv.FormatUint(uint64(actorID), 10)
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// requestHash fingerprints a request so a reused key with a different payload is detected
+func requestHash(c *gin.Context, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(c.Request.Method + " " + c.Request.URL.Path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder copies the response body while it is written
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+// Write implements io.Writer
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString implements io.StringWriter
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
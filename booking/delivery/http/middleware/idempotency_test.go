@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"booking/domain/entity"
+	"booking/usecase/idempotency"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeIdempotency starts every key afresh, counting the claims
+type fakeIdempotency struct {
+	idempotency.IdempotencyUseCase
+	begun int
+}
+
+func (f *fakeIdempotency) Begin(ctx context.Context, scope, key, requestHash string) (*entity.IdempotencyRecord, error) {
+	f.begun++
+	return &entity.IdempotencyRecord{Status: entity.IdempotencyInProgress}, nil
+}
+
+func (f *fakeIdempotency) Complete(ctx context.Context, record *entity.IdempotencyRecord, status int, contentType string, body []byte) error {
+	return nil
+}
+
+func TestIdempotencyBodyLimit(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		key        string
+		wantStatus int
+		wantBegun  int
+	}{
+		{name: "within limit", body: strings.Repeat("a", 16), key: "k1", wantStatus: http.StatusCreated, wantBegun: 1},
+		{name: "over limit", body: strings.Repeat("a", 17), key: "k2", wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "no key skips the limit", body: strings.Repeat("a", 64), wantStatus: http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			uc := &fakeIdempotency{}
+			var received string
+
+			engine := gin.New()
+			engine.Use(Idempotency(uc, WithMaxBodySize(16)))
+			engine.POST("/things", func(c *gin.Context) {
+				body, _ := io.ReadAll(c.Request.Body)
+				received = string(body)
+				c.Status(http.StatusCreated)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/things", strings.NewReader(tt.body))
+			if tt.key != "" {
+				req.Header.Set(IdempotencyKeyHeader, tt.key)
+			}
+			rec := httptest.NewRecorder()
+			engine.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if uc.begun != tt.wantBegun {
+				t.Errorf("Begin called %d times, want %d", uc.begun, tt.wantBegun)
+			}
+			if tt.wantStatus == http.StatusCreated && received != tt.body {
+				t.Errorf("handler read %d bytes, want the whole body of %d", len(received), len(tt.body))
+			}
+		})
+	}
+}
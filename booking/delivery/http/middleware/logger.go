@@ -1,26 +1,64 @@
 package middleware
 
 import (
+	"io"
+	"log/slog"
+	"net/http"
 	"time"
-	
+
+	"booking/infrastructure/logging"
+
 	"github.com/gin-gonic/gin"
 )
 
 // Logger middleware for logging HTTP requests
+// One structured record per request; the request ID and actor come from the
+// request context set by RequestID and Authenticate.
 func Logger() gin.HandlerFunc {
+	logger := logging.For("http")
+
 	return func(c *gin.Context) {
 		startTime := time.Now()
-		
+
 		c.Next()
-		
-		endTime := time.Now()
-		latency := endTime.Sub(startTime)
-		
+
+		latency := time.Since(startTime)
 		statusCode := c.Writer.Status()
-		method := c.Request.Method
-		path := c.Request.URL.Path
-		
-		println("📊 [HTTP]", method, path, "- Status:", statusCode, "- Latency:", latency.String())
+
+		level := slog.LevelInfo
+		switch {
+		case statusCode >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case statusCode >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("route", c.FullPath()),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", statusCode),
+			slog.Float64("latency_ms", float64(latency.Microseconds())/1000),
+			slog.String("client_ip", c.ClientIP()),
+			slog.Int("bytes", c.Writer.Size()),
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("error", c.Errors.String()))
+		}
+
+		logger.LogAttrs(c.Request.Context(), level, "request", attrs...)
 	}
 }
 
+// Recovery middleware turns panics into 500 responses and logs them
+func Recovery() gin.HandlerFunc {
+	logger := logging.For("http")
+
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, recovered any) {
+		logger.ErrorContext(c.Request.Context(), "panic recovered",
+			slog.Any("panic", recovered),
+			slog.String("path", c.Request.URL.Path),
+		)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	})
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"time"
+
+	"booking/infrastructure/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute labels requests that matched no route, keeping label cardinality bounded
+const unmatchedRoute = "unmatched"
+
+// Metrics middleware records request counts and latency by route template and status
+func Metrics(m *metrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		startTime := time.Now()
+		done := m.RequestStarted()
+		defer done()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		m.ObserveRequest(c.Request.Method, route, c.Writer.Status(), time.Since(startTime))
+	}
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"booking/infrastructure/metrics"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetricsLabelsRequestsByRouteTemplate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	registry := prometheus.NewRegistry()
+
+	engine := gin.New()
+	engine.Use(Metrics(metrics.New(registry)))
+	engine.GET("/api/v1/users/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, path := range []string{"/api/v1/users/1", "/api/v1/users/2", "/nowhere"} {
+		engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	// Raw paths never become labels; unmatched requests share one series
+	expected := `
+# HELP booking_http_requests_total HTTP requests by method, route template and status code.
+# TYPE booking_http_requests_total counter
+booking_http_requests_total{method="GET",route="/api/v1/users/:id",status="200"} 2
+booking_http_requests_total{method="GET",route="unmatched",status="404"} 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "booking_http_requests_total"); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"booking/domain/identity"
+	"booking/infrastructure/logging"
+	"booking/infrastructure/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimiter applies token bucket policies to route groups
+type RateLimiter struct {
+	store    ratelimit.Store
+	policies map[string]ratelimit.Policy
+	logger   *slog.Logger
+}
+
+// NewRateLimiter creates a rate limiter with one policy per route group name
+func NewRateLimiter(store ratelimit.Store, policies ...ratelimit.Policy) *RateLimiter {
+	limiter := &RateLimiter{
+		store:    store,
+		policies: map[string]ratelimit.Policy{},
+		logger:   logging.For("http"),
+	}
+	for _, policy := range policies {
+		if policy.Enabled() {
+			limiter.policies[policy.Name] = policy
+		}
+	}
+	return limiter
+}
+
+// For returns middleware enforcing the policy of a route group
+// Clients are identified by user ID when authenticated and by IP otherwise.
+// Every response carries RateLimit-* headers; exhausted clients get 429.
+func (l *RateLimiter) For(group string) gin.HandlerFunc {
+	policy, ok := l.policies[group]
+	if !ok {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	return func(c *gin.Context) {
+		result, err := l.store.Take(c.Request.Context(), rateLimitIdentity(c), policy)
+		if err != nil {
+			// Fail open: an unavailable store should not take the API down with it
+			l.logger.WarnContext(c.Request.Context(), "rate limit store unavailable, allowing request",
+				slog.String("policy", policy.Name),
+				slog.Any("error", err),
+			)
+			c.Next()
+			return
+		}
+
+		header := c.Writer.Header()
+		header.Set("RateLimit-Policy", strconv.Itoa(policy.Limit)+";w="+strconv.Itoa(ceilSeconds(policy.Period)))
+		header.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+		header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		header.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter)))
+
+		if !result.Allowed {
+			header.Set("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Rate limit exceeded, retry later"})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// rateLimitIdentity returns the bucket key of the caller
+func rateLimitIdentity(c *gin.Context) string {
+	if actorID, ok := identity.ActorFromContext(c.Request.Context()); ok {
+		return "user:" + strconv.FormatUint(uint64(actorID), 10)
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// ceilSeconds rounds a duration up to whole seconds, as the headers require
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"booking/domain/identity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader is the header used to accept and return the request ID
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID accepts the caller's X-Request-ID or generates one, stores it in the
+// request context and echoes it in the response
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := identity.AcceptRequestID(c.GetHeader(RequestIDHeader))
+
+		ctx := identity.WithRequestID(c.Request.Context(), requestID)
+		c.Request = c.Request.WithContext(ctx)
+		c.Writer.Header().Set(RequestIDHeader, requestID)
+
+		c.Next()
+	}
+}
@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"booking/infrastructure/tracing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// newTracedEngine serves two routes under the Tracing middleware, exporting spans in memory
+func newTracedEngine(t *testing.T) (*gin.Engine, *tracetest.InMemoryExporter) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider, err := tracing.NewTracerProvider("booking-test", 1, sdktrace.WithSyncer(exporter))
+	if err != nil {
+		t.Fatal(err)
+	}
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+		provider.Shutdown(context.Background())
+	})
+
+	engine := gin.New()
+	engine.Use(Tracing())
+	engine.GET("/api/v1/users/:id", func(c *gin.Context) {
+		// Spans started by the layers below nest under the request span
+		_, span := tracing.Tracer("usecase/test").Start(c.Request.Context(), "UserUseCase.GetUserByID")
+		span.End()
+		c.Status(http.StatusOK)
+	})
+	engine.GET("/fail", func(c *gin.Context) { c.Status(http.StatusInternalServerError) })
+	return engine, exporter
+}
+
+func TestTracingStartsServerSpanPerRequest(t *testing.T) {
+	engine, exporter := newTracedEngine(t)
+
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/users/7", nil))
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("exported %d spans, want the use case and the request", len(spans))
+	}
+	child, server := spans[0], spans[1]
+
+	if server.Name != "GET /api/v1/users/:id" || server.SpanKind != trace.SpanKindServer {
+		t.Errorf("server span = %s (%v), want GET /api/v1/users/:id server span", server.Name, server.SpanKind)
+	}
+	if child.Parent.SpanID() != server.SpanContext.SpanID() {
+		t.Error("use case span is not a child of the request span")
+	}
+
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range server.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	if got := attrs["http.route"].AsString(); got != "/api/v1/users/:id" {
+		t.Errorf("http.route = %q", got)
+	}
+	if got := attrs["url.path"].AsString(); got != "/api/v1/users/7" {
+		t.Errorf("url.path = %q", got)
+	}
+	if got := attrs["http.response.status_code"].AsInt64(); got != http.StatusOK {
+		t.Errorf("http.response.status_code = %d", got)
+	}
+	if server.Status.Code != codes.Unset {
+		t.Errorf("status = %v for a 200", server.Status.Code)
+	}
+}
+
+func TestTracingContinuesCallerTrace(t *testing.T) {
+	engine, exporter := newTracedEngine(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/fail", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	engine.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("exported %d spans, want 1", len(spans))
+	}
+	span := spans[0]
+	if got := span.SpanContext.TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("trace ID = %s, want the caller's", got)
+	}
+	if got := span.Parent.SpanID().String(); got != "00f067aa0ba902b7" || !span.Parent.IsRemote() {
+		t.Errorf("parent = %s, want the caller's remote span", got)
+	}
+	if span.Status.Code != codes.Error {
+		t.Errorf("status = %v for a 500, want Error", span.Status.Code)
+	}
+}
@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+
+	"booking/delivery/http/openapi"
+	"booking/infrastructure/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ValidationOption configures the Validation middleware
+type ValidationOption func(*validationOptions)
+
+// validationOptions holds the Validation middleware settings
+type validationOptions struct {
+	validateResponses bool
+}
+
+// WithResponseValidation also checks every response against the document
+// A response the document does not allow is replaced by a 500 listing the
+// mismatches. Responses are buffered to make that possible, so enable it in
+// tests and staging rather than in production.
+func WithResponseValidation(enabled bool) ValidationOption {
+	return func(o *validationOptions) {
+		o.validateResponses = enabled
+	}
+}
+
+// Validation rejects requests whose path parameters, query or JSON body don't match the OpenAPI document
+// Invalid requests get 400 with one entry per problem in "fields". Routes
+// missing from the document pass through; VerifyOpenAPI keeps them out.
+func Validation(validator *openapi.Validator, opts ...ValidationOption) gin.HandlerFunc {
+	options := &validationOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	logger := logging.For("http")
+
+	return func(c *gin.Context) {
+		op := validator.Operation(c.Request.Method, c.FullPath())
+		if op == nil {
+			c.Next()
+			return
+		}
+
+		problems, err := validator.ValidateRequest(op, c.Request, c.Param)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+			return
+		}
+		if len(problems) > 0 {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error":  "Request validation failed",
+				"fields": problems,
+			})
+			return
+		}
+
+		if !options.validateResponses {
+			c.Next()
+			return
+		}
+
+		buffer := &bufferedWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+		c.Writer = buffer
+		c.Next()
+		c.Writer = buffer.ResponseWriter
+
+		if problems := validator.ValidateResponse(op, buffer.status, buffer.Header().Get("Content-Type"), buffer.body.Bytes()); len(problems) > 0 {
+			logger.ErrorContext(c.Request.Context(), "response does not match the OpenAPI document",
+				slog.String("operation", op.OperationID),
+				slog.Int("status", buffer.status),
+				slog.Any("problems", problems),
+			)
+			c.Writer.Header().Del("Content-Length")
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":  "Response does not match the OpenAPI document",
+				"fields": problems,
+			})
+			return
+		}
+
+		c.Writer.WriteHeader(buffer.status)
+		_, _ = c.Writer.Write(buffer.body.Bytes())
+	}
+}
+
+// bufferedWriter holds back the response so it can be checked before it is sent
+type bufferedWriter struct {
+	gin.ResponseWriter
+	status  int
+	written bool
+	body    bytes.Buffer
+}
+
+// WriteHeader records the status without sending it
+func (w *bufferedWriter) WriteHeader(status int) {
+	if status > 0 && !w.written {
+		w.status = status
+	}
+}
+
+// WriteHeaderNow marks the header as written without sending it
+func (w *bufferedWriter) WriteHeaderNow() {
+	w.written = true
+}
+
+// Write implements io.Writer
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	w.written = true
+	return w.body.Write(data)
+}
+
+// WriteString implements io.StringWriter
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	w.written = true
+	return w.body.WriteString(s)
+}
+
+// Status returns the buffered status
+func (w *bufferedWriter) Status() int {
+	return w.status
+}
+
+// Size returns the number of buffered body bytes
+func (w *bufferedWriter) Size() int {
+	return w.body.Len()
+}
+
+// Written reports whether the handler has started a response
+func (w *bufferedWriter) Written() bool {
+	return w.written
+}
+
+// Flush is a no-op; the response is sent once it has been checked
+func (w *bufferedWriter) Flush() {}
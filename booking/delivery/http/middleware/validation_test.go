@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"booking/delivery/http/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// renameRequest and renamed are the body types of the test document
+type renameRequest struct {
+	Name string `json:"name" binding:"required,min=2"`
+}
+
+type renamed struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+// newValidatedEngine serves PUT /things/:id, answering with the given status and body
+func newValidatedEngine(t *testing.T, status int, body interface{}, opts ...ValidationOption) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	builder := openapi.NewBuilder(openapi.Info{Title: "test", Version: "1"})
+	builder.Add(openapi.Route{
+		Method:      http.MethodPut,
+		Path:        "/things/:id",
+		OperationID: "renameThing",
+		Params:      []*openapi.Parameter{openapi.PathParam("id", &openapi.Schema{Type: "integer", Minimum: openapi.Ptr(1.0)}, "")},
+		Body:        renameRequest{},
+		Responses:   map[int]interface{}{http.StatusOK: renamed{}},
+	})
+
+	engine := gin.New()
+	engine.Use(Validation(openapi.NewValidator(builder.Document()), opts...))
+	engine.PUT("/things/:id", func(c *gin.Context) {
+		// The handler still binds the body the middleware read
+		var req renameRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusTeapot, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(status, body)
+	})
+	engine.GET("/undocumented", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	return engine
+}
+
+// serve sends a request and decodes the JSON response
+func serve(engine *gin.Engine, method, path, body string) (int, map[string]interface{}) {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+
+	var decoded map[string]interface{}
+	_ = json.Unmarshal(rec.Body.Bytes(), &decoded)
+	return rec.Code, decoded
+}
+
+func TestValidationRejectsInvalidRequests(t *testing.T) {
+	engine := newValidatedEngine(t, http.StatusOK, renamed{ID: 1, Name: "ok"})
+
+	status, body := serve(engine, http.MethodPut, "/things/0", `{"name":"x"}`)
+	if status != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", status)
+	}
+	if body["error"] != "Request validation failed" {
+		t.Errorf("error = %v", body["error"])
+	}
+	problems, _ := body["fields"].([]interface{})
+	if len(problems) != 2 {
+		t.Errorf("fields = %v, want the id and the name", body["fields"])
+	}
+
+	if status, _ := serve(engine, http.MethodPut, "/things/1", `{"name":"ok"}`); status != http.StatusOK {
+		t.Errorf("valid request status = %d, want 200", status)
+	}
+	if status, _ := serve(engine, http.MethodGet, "/undocumented", ""); status != http.StatusOK {
+		t.Errorf("undocumented route status = %d, want it passed through", status)
+	}
+}
+
+func TestResponseValidation(t *testing.T) {
+	// The handler answers with a body that breaks the documented schema
+	wrong := gin.H{"id": "one", "name": "ok"}
+
+	t.Run("off", func(t *testing.T) {
+		engine := newValidatedEngine(t, http.StatusOK, wrong)
+		if status, body := serve(engine, http.MethodPut, "/things/1", `{"name":"ok"}`); status != http.StatusOK || body["id"] != "one" {
+			t.Errorf("status, body = %d, %v, want the handler's response untouched", status, body)
+		}
+	})
+
+	t.Run("valid response", func(t *testing.T) {
+		engine := newValidatedEngine(t, http.StatusOK, renamed{ID: 1, Name: "ok"}, WithResponseValidation(true))
+		if status, body := serve(engine, http.MethodPut, "/things/1", `{"name":"ok"}`); status != http.StatusOK || body["name"] != "ok" {
+			t.Errorf("status, body = %d, %v, want the handler's response", status, body)
+		}
+	})
+
+	t.Run("schema mismatch", func(t *testing.T) {
+		engine := newValidatedEngine(t, http.StatusOK, wrong, WithResponseValidation(true))
+		status, body := serve(engine, http.MethodPut, "/things/1", `{"name":"ok"}`)
+		if status != http.StatusInternalServerError || body["error"] != "Response does not match the OpenAPI document" {
+			t.Errorf("status, body = %d, %v, want 500", status, body)
+		}
+	})
+
+	t.Run("undocumented status", func(t *testing.T) {
+		engine := newValidatedEngine(t, http.StatusCreated, renamed{ID: 1, Name: "ok"}, WithResponseValidation(true))
+		if status, _ := serve(engine, http.MethodPut, "/things/1", `{"name":"ok"}`); status != http.StatusInternalServerError {
+			t.Errorf("status = %d, want 500 for a status missing from the document", status)
+		}
+	})
+}
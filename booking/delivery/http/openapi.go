@@ -0,0 +1,490 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"booking/config"
+	"booking/delivery/consistency"
+	"booking/delivery/graphql"
+	"booking/delivery/http/handler"
+	"booking/delivery/http/middleware"
+	"booking/delivery/http/openapi"
+	"booking/delivery/userfile"
+	"booking/domain/entity"
+	"booking/infrastructure/health"
+
+	"gorm.io/gorm"
+)
+
+// Paths of the API description and its docs UI
+const (
+	OpenAPIPath = "/openapi.json"
+	DocsPath    = "/docs"
+)
+
+// apiVersion is the version of the document, bumped on breaking changes
+const apiVersion = "1.0.0"
+
+// Schemas reused by the parameters below
+var (
+	idSchema     = &openapi.Schema{Type: "integer", Format: "int32", Minimum: openapi.Ptr(1.0)}
+	stringSchema = &openapi.Schema{Type: "string"}
+	timeSchema   = &openapi.Schema{Type: "string", Format: "date-time"}
+	limitSchema  = &openapi.Schema{Type: "integer", Format: "int32", Minimum: openapi.Ptr(1.0)}
+	// userSortSchema lists the sort values of user listings and exports
+	userSortSchema = &openapi.Schema{Type: "string", Enum: []interface{}{
+		"created_at", "-created_at", "username", "-username", "email", "-email",
+	}}
+)
+
+// newOpenAPI documents every route registered by SetupRoutes
+// VerifyOpenAPI fails when the two disagree, so a route added to SetupRoutes
+// must be added here as well.
+func newOpenAPI(cfg *config.Config) *openapi.Builder {
+	spec := openapi.NewBuilder(openapi.Info{
+		Title:       "Booking API",
+		Version:     apiVersion,
+		Description: "User accounts, roles, GDPR requests and the audit log.",
+	})
+	spec.Schemas().Override(gorm.DeletedAt{}, &openapi.Schema{Type: "string", Format: "date-time", Nullable: true})
+	spec.Schemas().Override(entity.JSON{}, &openapi.Schema{Description: "JSON document"})
+	spec.Security("userId", &openapi.SecurityScheme{
+		Type:        "apiKey",
+		In:          openapi.InHeader,
+		Name:        cfg.Auth.UserIDHeader,
+		Description: "ID of the authenticated user, set by the API gateway",
+	})
+	spec.Tag("health", "Liveness and readiness probes")
+	spec.Tag("users", "User accounts")
+	spec.Tag("me", "Self-service GDPR export and erasure")
+	spec.Tag("admin", "Role management, user restore and the audit log")
+	spec.Tag("graphql", "GraphQL queries for the mobile app")
+
+	userID := openapi.PathParam("id", idSchema, "User ID")
+	requestID := openapi.PathParam("id", idSchema, "Privacy request ID")
+
+	// Health checks
+	spec.Add(openapi.Route{
+		Method: http.MethodGet, Path: "/livez", Tag: "health", Public: true,
+		OperationID: "liveness",
+		Summary:     "Liveness probe; never checks dependencies",
+		Responses:   map[int]interface{}{http.StatusOK: handler.LivenessResponse{}},
+	})
+	spec.Add(openapi.Route{
+		Method: http.MethodGet, Path: "/health", Tag: "health", Public: true,
+		OperationID: "health",
+		Summary:     "Same as /livez, kept for existing monitors",
+		Responses:   map[int]interface{}{http.StatusOK: handler.LivenessResponse{}},
+	})
+	spec.Add(openapi.Route{
+		Method: http.MethodGet, Path: "/readyz", Tag: "health", Public: true,
+		OperationID: "readiness",
+		Summary:     "Readiness probe with the result of every dependency check",
+		Responses: map[int]interface{}{
+			http.StatusOK:                 health.Report{},
+			http.StatusServiceUnavailable: health.Report{},
+		},
+	})
+
+	// GraphQL; the schema itself is described by GET /graphql/schema
+	if cfg.GraphQL.Enabled {
+		graphqlResponses := map[int]interface{}{
+			http.StatusOK:                  graphql.Response{},
+			http.StatusBadRequest:          graphql.Response{},
+			http.StatusUnauthorized:        handler.ErrorResponse{},
+			http.StatusTooManyRequests:     handler.ErrorResponse{},
+			http.StatusInternalServerError: handler.ErrorResponse{},
+		}
+		spec.Add(openapi.Route{
+			Method: http.MethodGet, Path: "/graphql", Tag: "graphql",
+			OperationID: "graphqlQueryGet",
+			Summary:     "Run a GraphQL query given as query parameters",
+			Description: "Send extensions.persistedQuery.sha256Hash without query to run a persisted query.",
+			Params: []*openapi.Parameter{
+				openapi.QueryParam("query", stringSchema, "GraphQL document"),
+				openapi.QueryParam("operationName", stringSchema, "Operation to run when the document has several"),
+				openapi.QueryParam("variables", stringSchema, "JSON object of variables"),
+				openapi.QueryParam("extensions", stringSchema, "JSON object, e.g. the persisted query hash"),
+			},
+			Responses: graphqlResponses,
+		})
+		spec.Add(openapi.Route{
+			Method: http.MethodPost, Path: "/graphql", Tag: "graphql",
+			OperationID: "graphqlQuery",
+			Summary:     "Run a GraphQL query",
+			Description: "Query errors are returned with 200 in errors; see GET /graphql/schema for the schema.",
+			Body:        graphql.Request{},
+			Responses:   graphqlResponses,
+		})
+		spec.Add(openapi.Route{
+			Method: http.MethodGet, Path: "/graphql/schema", Tag: "graphql",
+			OperationID: "graphqlSchema",
+			Summary:     "The GraphQL schema in SDL",
+			Responses: map[int]interface{}{
+				http.StatusOK: openapi.Binary{ContentType: "text/plain"},
+			},
+		})
+	}
+
+	// Routes guarded by RequirePermission answer 401 and 403
+	adminErrors := map[int]interface{}{
+		http.StatusUnauthorized: handler.ErrorResponse{},
+		http.StatusForbidden:    handler.ErrorResponse{},
+	}
+
+	// Public registration, the only route creating users without a permission
+	addAPI(spec, openapi.Route{
+		Method: http.MethodPost, Path: "/api/v1/signup", Tag: "users",
+		OperationID: "signup",
+		Summary:     "Create your own account",
+		Description: "Open to anonymous callers. The account is active and gets the " + entity.RoleRunner + " role.",
+		Body:        handler.CreateUserRequest{},
+		Responses: map[int]interface{}{
+			http.StatusCreated:    handler.UserResponse{},
+			http.StatusBadRequest: handler.ErrorResponse{},
+			http.StatusConflict:   handler.ErrorResponse{},
+		},
+	})
+
+	// User routes; each needs a permission
+	addAPI(spec, openapi.Route{
+		Method: http.MethodPost, Path: "/api/v1/users", Tag: "users",
+		OperationID: "createUser",
+		Summary:     "Create a user",
+		Description: "Requires " + entity.PermissionUsersWrite + ".",
+		Body:        handler.CreateUserRequest{},
+		Responses: with(adminErrors,
+			http.StatusCreated, handler.UserResponse{},
+			http.StatusBadRequest, handler.ErrorResponse{},
+			http.StatusConflict, handler.ErrorResponse{},
+		),
+	})
+	addAPI(spec, openapi.Route{
+		Method: http.MethodGet, Path: "/api/v1/users", Tag: "users",
+		OperationID: "listUsers",
+		Summary:     "List users with filters, sorting and cursor pagination",
+		Description: "Requires " + entity.PermissionUsersRead + ".",
+		Params: []*openapi.Parameter{
+			openapi.QueryParam("email", stringSchema, "Exact email"),
+			openapi.QueryParam("username", stringSchema, "Exact username"),
+			openapi.QueryParam("full_name", stringSchema, "Exact full name"),
+			openapi.QueryParam("phone", stringSchema, "Exact phone number"),
+			openapi.QueryParam("is_active", &openapi.Schema{Type: "boolean"}, "Active or deactivated users"),
+			openapi.QueryParam("q", stringSchema, "Prefix of username or email, or the whole full name"),
+			openapi.QueryParam("created_from", timeSchema, "Created at or after (RFC 3339)"),
+			openapi.QueryParam("created_to", timeSchema, "Created before (RFC 3339)"),
+			openapi.QueryParam("sort", userSortSchema, "Sort field; a leading - sorts descending"),
+			openapi.QueryParam("cursor", stringSchema, "next_cursor of the previous page"),
+			openapi.QueryParam("limit", limitSchema, "Page size"),
+		},
+		Responses: with(adminErrors,
+			http.StatusOK, handler.UserListResponse{},
+			http.StatusBadRequest, handler.ErrorResponse{},
+		),
+	})
+	addAPI(spec, openapi.Route{
+		Method: http.MethodGet, Path: "/api/v1/users/:id", Tag: "users",
+		OperationID: "getUser",
+		Summary:     "Get a user",
+		Description: "Requires " + entity.PermissionUsersRead + ".",
+		Params:      []*openapi.Parameter{userID},
+		Responses: with(adminErrors,
+			http.StatusOK, handler.UserResponse{},
+			http.StatusBadRequest, handler.ErrorResponse{},
+			http.StatusNotFound, handler.ErrorResponse{},
+		),
+	})
+	addAPI(spec, openapi.Route{
+		Method: http.MethodPut, Path: "/api/v1/users/:id", Tag: "users",
+		OperationID: "updateUser",
+		Summary:     "Update a user; empty fields are left unchanged",
+		Description: "Requires " + entity.PermissionUsersWrite + ".",
+		Params:      []*openapi.Parameter{userID},
+		Body:        handler.UpdateUserRequest{},
+		Responses: with(adminErrors,
+			http.StatusOK, handler.UserResponse{},
+			http.StatusBadRequest, handler.ErrorResponse{},
+			http.StatusNotFound, handler.ErrorResponse{},
+			http.StatusConflict, handler.ErrorResponse{},
+		),
+	})
+	addAPI(spec, openapi.Route{
+		Method: http.MethodDelete, Path: "/api/v1/users/:id", Tag: "users",
+		OperationID: "deleteUser",
+		Summary:     "Soft-delete a user; it can be restored during the grace period",
+		Description: "Requires " + entity.PermissionUsersDelete + ".",
+		Params:      []*openapi.Parameter{userID},
+		Responses: with(adminErrors,
+			http.StatusOK, handler.MessageResponse{},
+			http.StatusBadRequest, handler.ErrorResponse{},
+			http.StatusNotFound, handler.ErrorResponse{},
+		),
+	})
+
+	// Self-service routes
+	privacyErrors := map[int]interface{}{
+		http.StatusUnauthorized: handler.ErrorResponse{},
+		http.StatusNotFound:     handler.ErrorResponse{},
+	}
+	addAPI(spec, openapi.Route{
+		Method: http.MethodPost, Path: "/api/v1/me/exports", Tag: "me",
+		OperationID: "requestExport",
+		Summary:     "Request a GDPR export of the caller's data",
+		Responses:   with(privacyErrors, http.StatusAccepted, handler.PrivacyRequestResponse{}),
+	})
+	addAPI(spec, openapi.Route{
+		Method: http.MethodGet, Path: "/api/v1/me/exports/:id/download", Tag: "me",
+		OperationID: "downloadExport",
+		Summary:     "Download a completed export as a ZIP file",
+		Params:      []*openapi.Parameter{requestID},
+		Responses: with(privacyErrors,
+			http.StatusOK, openapi.Binary{ContentType: "application/zip"},
+			http.StatusBadRequest, handler.ErrorResponse{},
+			http.StatusConflict, handler.ErrorResponse{},
+			http.StatusGone, handler.ErrorResponse{},
+		),
+	})
+	addAPI(spec, openapi.Route{
+		Method: http.MethodPost, Path: "/api/v1/me/erasure", Tag: "me",
+		OperationID: "requestErasure",
+		Summary:     "Request erasure of the caller's personal data",
+		Responses:   with(privacyErrors, http.StatusAccepted, handler.PrivacyRequestResponse{}),
+	})
+	addAPI(spec, openapi.Route{
+		Method: http.MethodGet, Path: "/api/v1/me/privacy-requests", Tag: "me",
+		OperationID: "listPrivacyRequests",
+		Summary:     "List the caller's GDPR requests",
+		Responses:   with(privacyErrors, http.StatusOK, handler.PrivacyRequestListResponse{}),
+	})
+	addAPI(spec, openapi.Route{
+		Method: http.MethodGet, Path: "/api/v1/me/privacy-requests/:id", Tag: "me",
+		OperationID: "getPrivacyRequest",
+		Summary:     "Get one of the caller's GDPR requests",
+		Params:      []*openapi.Parameter{requestID},
+		Responses: with(privacyErrors,
+			http.StatusOK, handler.PrivacyRequestResponse{},
+			http.StatusBadRequest, handler.ErrorResponse{},
+		),
+	})
+
+	// Admin routes
+	addAPI(spec, openapi.Route{
+		Method: http.MethodGet, Path: "/api/v1/admin/roles", Tag: "admin",
+		OperationID: "listRoles",
+		Summary:     "List roles and their permissions",
+		Description: "Requires " + entity.PermissionRolesManage + ".",
+		Responses:   with(adminErrors, http.StatusOK, handler.RoleListResponse{}),
+	})
+	addAPI(spec, openapi.Route{
+		Method: http.MethodGet, Path: "/api/v1/admin/users/:id/roles", Tag: "admin",
+		OperationID: "getUserRoles",
+		Summary:     "List the roles of a user",
+		Description: "Requires " + entity.PermissionRolesManage + ".",
+		Params:      []*openapi.Parameter{userID},
+		Responses: with(adminErrors,
+			http.StatusOK, handler.RoleListResponse{},
+			http.StatusBadRequest, handler.ErrorResponse{},
+			http.StatusNotFound, handler.ErrorResponse{},
+		),
+	})
+	addAPI(spec, openapi.Route{
+		Method: http.MethodPost, Path: "/api/v1/admin/users/:id/roles", Tag: "admin",
+		OperationID: "grantRole",
+		Summary:     "Grant a role to a user",
+		Description: "Requires " + entity.PermissionRolesManage + ".",
+		Params:      []*openapi.Parameter{userID},
+		Body:        handler.GrantRoleRequest{},
+		Responses: with(adminErrors,
+			http.StatusOK, handler.MessageResponse{},
+			http.StatusBadRequest, handler.ErrorResponse{},
+			http.StatusNotFound, handler.ErrorResponse{},
+		),
+	})
+	addAPI(spec, openapi.Route{
+		Method: http.MethodDelete, Path: "/api/v1/admin/users/:id/roles/:role", Tag: "admin",
+		OperationID: "revokeRole",
+		Summary:     "Revoke a role from a user",
+		Description: "Requires " + entity.PermissionRolesManage + ". Admins cannot revoke their own admin role.",
+		Params: []*openapi.Parameter{
+			userID,
+			openapi.PathParam("role", stringSchema, "Role name"),
+		},
+		Responses: with(adminErrors,
+			http.StatusOK, handler.MessageResponse{},
+			http.StatusBadRequest, handler.ErrorResponse{},
+			http.StatusNotFound, handler.ErrorResponse{},
+			http.StatusConflict, handler.ErrorResponse{},
+		),
+	})
+	addAPI(spec, openapi.Route{
+		Method: http.MethodPost, Path: "/api/v1/admin/users/:id/restore", Tag: "admin",
+		OperationID: "restoreUser",
+		Summary:     "Restore a soft-deleted user within the grace period",
+		Description: "Requires " + entity.PermissionUsersDelete + ".",
+		Params:      []*openapi.Parameter{userID},
+		Responses: with(adminErrors,
+			http.StatusOK, handler.UserResponse{},
+			http.StatusBadRequest, handler.ErrorResponse{},
+			http.StatusNotFound, handler.ErrorResponse{},
+		),
+	})
+	userFiles := []openapi.Binary{
+		{ContentType: userfile.CSV.ContentType()},
+		{ContentType: userfile.NDJSON.ContentType()},
+	}
+	addAPI(spec, openapi.Route{
+		Method: http.MethodPost, Path: "/api/v1/admin/users/import", Tag: "admin",
+		OperationID: "importUsers",
+		Summary:     "Create users from a CSV or NDJSON file",
+		Description: "Requires " + entity.PermissionUsersWrite + ". Columns: email, username, password, full_name, phone, is_active. " +
+			"Rows are validated like createUser; invalid rows are listed in errors and the valid ones are inserted in batches, one transaction per batch.",
+		Params: []*openapi.Parameter{
+			openapi.QueryParam("dry_run", &openapi.Schema{Type: "boolean"}, "Only validate the rows"),
+		},
+		Body: userFiles,
+		Responses: with(adminErrors,
+			http.StatusOK, handler.UserImportResponse{},
+			http.StatusBadRequest, handler.ErrorResponse{},
+			http.StatusRequestEntityTooLarge, handler.ErrorResponse{},
+			http.StatusUnsupportedMediaType, handler.ErrorResponse{},
+		),
+	})
+	addAPI(spec, openapi.Route{
+		Method: http.MethodGet, Path: "/api/v1/admin/users/export", Tag: "admin",
+		OperationID: "exportUsers",
+		Summary:     "Stream the users matching the listUsers filters as CSV or NDJSON",
+		Description: "Requires " + entity.PermissionUsersRead + ". Passwords are never exported.",
+		Params: []*openapi.Parameter{
+			openapi.QueryParam("format", &openapi.Schema{Type: "string", Enum: []interface{}{"csv", "ndjson"}}, "File format, csv by default"),
+			openapi.QueryParam("email", stringSchema, "Exact email"),
+			openapi.QueryParam("username", stringSchema, "Exact username"),
+			openapi.QueryParam("full_name", stringSchema, "Exact full name"),
+			openapi.QueryParam("phone", stringSchema, "Exact phone number"),
+			openapi.QueryParam("is_active", &openapi.Schema{Type: "boolean"}, "Active or deactivated users"),
+			openapi.QueryParam("q", stringSchema, "Prefix of username or email, or the whole full name"),
+			openapi.QueryParam("created_from", timeSchema, "Created at or after (RFC 3339)"),
+			openapi.QueryParam("created_to", timeSchema, "Created before (RFC 3339)"),
+			openapi.QueryParam("sort", userSortSchema, "Sort field; a leading - sorts descending"),
+		},
+		Responses: with(adminErrors,
+			http.StatusOK, userFiles,
+			http.StatusBadRequest, handler.ErrorResponse{},
+		),
+	})
+	addAPI(spec, openapi.Route{
+		Method: http.MethodGet, Path: "/api/v1/admin/audit", Tag: "admin",
+		OperationID: "listAuditEntries",
+		Summary:     "List audit log entries, newest first",
+		Description: "Requires " + entity.PermissionAuditRead + ".",
+		Params: []*openapi.Parameter{
+			openapi.QueryParam("actor_id", idSchema, "User who made the change"),
+			openapi.QueryParam("action", stringSchema, "Action, e.g. user.updated"),
+			openapi.QueryParam("target_type", stringSchema, "Type of the changed record"),
+			openapi.QueryParam("target_id", stringSchema, "ID of the changed record"),
+			openapi.QueryParam("request_id", stringSchema, "X-Request-ID of the request that made the change"),
+			openapi.QueryParam("from", timeSchema, "Created at or after (RFC 3339)"),
+			openapi.QueryParam("to", timeSchema, "Created before (RFC 3339)"),
+			openapi.QueryParam("limit", limitSchema, "Page size, at most 500"),
+			openapi.QueryParam("offset", &openapi.Schema{Type: "integer", Format: "int32", Minimum: openapi.Ptr(0.0)}, "Entries to skip"),
+		},
+		Responses: with(adminErrors,
+			http.StatusOK, handler.AuditListResponse{},
+			http.StatusBadRequest, handler.ErrorResponse{},
+		),
+	})
+	addAPI(spec, openapi.Route{
+		Method: http.MethodGet, Path: "/api/v1/admin/audit/verify", Tag: "admin",
+		OperationID: "verifyAuditChain",
+		Summary:     "Verify the audit hash chain; 409 when it is broken",
+		Description: "Requires " + entity.PermissionAuditRead + ".",
+		Responses: with(adminErrors,
+			http.StatusOK, handler.AuditChainResponse{},
+			http.StatusConflict, handler.AuditChainResponse{},
+		),
+	})
+
+	return spec
+}
+
+// addAPI documents a /api/v1 route with the responses of the middleware in front of it
+func addAPI(spec *openapi.Builder, route openapi.Route) {
+	route.Responses = with(route.Responses,
+		// Validation rejects requests the document doesn't allow
+		http.StatusBadRequest, handler.ErrorResponse{},
+		// Authenticate rejects a malformed user ID header
+		http.StatusUnauthorized, handler.ErrorResponse{},
+		http.StatusTooManyRequests, handler.ErrorResponse{},
+		http.StatusInternalServerError, handler.ErrorResponse{},
+	)
+	if route.Method == http.MethodGet {
+		route.Params = append(route.Params, openapi.HeaderParam(consistency.Header,
+			&openapi.Schema{Type: "string", Enum: []interface{}{consistency.Strong}},
+			"Reads from the primary database, seeing every committed write"))
+	}
+	if route.Method == http.MethodPost {
+		route.Params = append(route.Params, openapi.HeaderParam(middleware.IdempotencyKeyHeader,
+			&openapi.Schema{Type: "string", MaxLength: openapi.Ptr(255)},
+			"Makes the request safe to retry: the first response is replayed for the same key"))
+		route.Responses = with(route.Responses,
+			http.StatusConflict, handler.ErrorResponse{},
+			// Idempotency rejects a keyed body over IDEMPOTENCY_MAX_BODY_MB
+			http.StatusRequestEntityTooLarge, handler.ErrorResponse{},
+			http.StatusUnprocessableEntity, handler.ErrorResponse{},
+		)
+	}
+	spec.Add(route)
+}
+
+// with returns a copy of responses with the status/body pairs added, keeping existing entries
+func with(responses map[int]interface{}, pairs ...interface{}) map[int]interface{} {
+	out := make(map[int]interface{}, len(responses)+len(pairs)/2)
+	for status, body := range responses {
+		out[status] = body
+	}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		status := pairs[i].(int)
+		if _, ok := out[status]; !ok {
+			out[status] = pairs[i+1]
+		}
+	}
+	return out
+}
+
+// OpenAPI returns the document served at OpenAPIPath
+func (r *Router) OpenAPI() *openapi.Document {
+	return r.spec.Document()
+}
+
+// VerifyOpenAPI reports routes missing from the OpenAPI document and documented routes that don't exist
+// Call it after SetupRoutes; main refuses to start when it fails.
+func (r *Router) VerifyOpenAPI() error {
+	skip := map[string]bool{
+		openapi.RouteKey(http.MethodGet, OpenAPIPath): true,
+		openapi.RouteKey(http.MethodGet, DocsPath):    true,
+	}
+	if r.metrics != nil {
+		skip[openapi.RouteKey(http.MethodGet, r.config.Metrics.Path)] = true
+	}
+
+	var routes []string
+	for _, route := range r.engine.Routes() {
+		if key := openapi.RouteKey(route.Method, route.Path); !skip[key] {
+			routes = append(routes, key)
+		}
+	}
+
+	missing, stale := r.spec.Undocumented(routes)
+	var problems []string
+	for _, key := range missing {
+		problems = append(problems, "undocumented route "+key)
+	}
+	for _, key := range stale {
+		problems = append(problems, "documented route not registered "+key)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("openapi document out of date (see delivery/http/openapi.go): %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
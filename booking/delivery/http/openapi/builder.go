@@ -0,0 +1,220 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Route documents one gin route
+// Body and the Responses values are zero values of the Go types that are
+// bound or rendered, e.g. handler.CreateUserRequest{}; a nil response value
+// means the response has no body.
+type Route struct {
+	Method      string
+	Path        string // gin syntax, e.g. /api/v1/users/:id
+	OperationID string
+	Summary     string
+	Description string
+	Tag         string
+	// Params documents query and header parameters, and path parameters that are not plain strings
+	Params    []*Parameter
+	Body      interface{}
+	Responses map[int]interface{}
+	// Public routes don't require the security scheme
+	Public bool
+}
+
+// Binary documents a request body or response that is a file rather than JSON
+// Use []Binary for one that comes in several formats.
+type Binary struct {
+	ContentType string
+}
+
+// Builder assembles a document from routes
+type Builder struct {
+	doc     *Document
+	schemas *Schemas
+	routes  map[string]bool
+}
+
+// NewBuilder creates a builder for an API described by info
+func NewBuilder(info Info) *Builder {
+	return &Builder{
+		doc: &Document{
+			OpenAPI: Version,
+			Info:    info,
+			Paths:   map[string]*PathItem{},
+		},
+		schemas: NewSchemas(),
+		routes:  map[string]bool{},
+	}
+}
+
+// Schemas returns the schema registry, e.g. to add overrides before adding routes
+func (b *Builder) Schemas() *Schemas {
+	return b.schemas
+}
+
+// Security sets the scheme every non-public operation requires
+func (b *Builder) Security(name string, scheme *SecurityScheme) {
+	if b.doc.Components.SecuritySchemes == nil {
+		b.doc.Components.SecuritySchemes = map[string]*SecurityScheme{}
+	}
+	b.doc.Components.SecuritySchemes[name] = scheme
+	b.doc.Security = []SecurityRequirement{{name: {}}}
+}
+
+// Tag describes a tag used by routes
+func (b *Builder) Tag(name, description string) {
+	b.doc.Tags = append(b.doc.Tags, Tag{Name: name, Description: description})
+}
+
+// Add documents a route; documenting the same method and path twice panics
+func (b *Builder) Add(route Route) {
+	key := RouteKey(route.Method, route.Path)
+	if b.routes[key] {
+		panic("openapi: route documented twice: " + key)
+	}
+	b.routes[key] = true
+
+	op := &Operation{
+		OperationID: route.OperationID,
+		Summary:     route.Summary,
+		Description: route.Description,
+		Responses:   map[string]*Response{},
+	}
+	if route.Tag != "" {
+		op.Tags = []string{route.Tag}
+	}
+	if route.Public {
+		// An empty requirement overrides the document-wide security
+		op.Security = []SecurityRequirement{{}}
+	}
+
+	path, pathParams := convertPath(route.Path)
+	for _, name := range pathParams {
+		if param := findParam(route.Params, name, InPath); param != nil {
+			param.Required = true
+			op.Parameters = append(op.Parameters, param)
+			continue
+		}
+		op.Parameters = append(op.Parameters, &Parameter{Name: name, In: InPath, Required: true, Schema: &Schema{Type: "string"}})
+	}
+	for _, param := range route.Params {
+		if param.In != InPath {
+			op.Parameters = append(op.Parameters, param)
+		}
+	}
+
+	if route.Body != nil {
+		op.RequestBody = &RequestBody{Required: true, Content: b.content(route.Body)}
+	}
+
+	for status, body := range route.Responses {
+		response := &Response{Description: http.StatusText(status)}
+		if body != nil {
+			response.Content = b.content(body)
+		}
+		op.Responses[strconv.Itoa(status)] = response
+	}
+
+	item, ok := b.doc.Paths[path]
+	if !ok {
+		item = &PathItem{}
+		b.doc.Paths[path] = item
+	}
+	(*item)[strings.ToLower(route.Method)] = op
+}
+
+// content describes a body given as a Go value, a Binary or a []Binary
+func (b *Builder) content(body interface{}) map[string]MediaType {
+	var files []Binary
+	switch body := body.(type) {
+	case Binary:
+		files = []Binary{body}
+	case []Binary:
+		files = body
+	default:
+		return map[string]MediaType{"application/json": {Schema: b.schemas.For(body)}}
+	}
+
+	content := make(map[string]MediaType, len(files))
+	for _, file := range files {
+		content[file.ContentType] = MediaType{Schema: &Schema{Type: "string", Format: "binary"}}
+	}
+	return content
+}
+
+// Document returns the assembled document
+func (b *Builder) Document() *Document {
+	b.doc.Components.Schemas = b.schemas.Components()
+	sort.Slice(b.doc.Tags, func(i, j int) bool { return b.doc.Tags[i].Name < b.doc.Tags[j].Name })
+	return b.doc
+}
+
+// Undocumented returns the routes, as "METHOD /path" keys, that have no documented operation
+// It also returns documented operations that are not routes, so stale entries are caught too.
+func (b *Builder) Undocumented(routes []string) (missing, stale []string) {
+	registered := map[string]bool{}
+	for _, key := range routes {
+		registered[key] = true
+		if !b.routes[key] {
+			missing = append(missing, key)
+		}
+	}
+	for key := range b.routes {
+		if !registered[key] {
+			stale = append(stale, key)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(stale)
+	return missing, stale
+}
+
+// RouteKey identifies a route by method and gin path, e.g. "GET /api/v1/users/:id"
+func RouteKey(method, path string) string {
+	return strings.ToUpper(method) + " " + path
+}
+
+// convertPath turns gin path parameters (:id, *file) into OpenAPI templates ({id}) and lists them
+func convertPath(ginPath string) (string, []string) {
+	segments := strings.Split(ginPath, "/")
+	var params []string
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			name := segment[1:]
+			params = append(params, name)
+			segments[i] = fmt.Sprintf("{%s}", name)
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// findParam returns the parameter with name and location, or nil
+func findParam(params []*Parameter, name, in string) *Parameter {
+	for _, param := range params {
+		if param.Name == name && param.In == in {
+			return param
+		}
+	}
+	return nil
+}
+
+// PathParam documents a path parameter
+func PathParam(name string, schema *Schema, description string) *Parameter {
+	return &Parameter{Name: name, In: InPath, Required: true, Schema: schema, Description: description}
+}
+
+// QueryParam documents an optional query parameter
+func QueryParam(name string, schema *Schema, description string) *Parameter {
+	return &Parameter{Name: name, In: InQuery, Schema: schema, Description: description}
+}
+
+// HeaderParam documents an optional request header
+func HeaderParam(name string, schema *Schema, description string) *Parameter {
+	return &Parameter{Name: name, In: InHeader, Schema: schema, Description: description}
+}
@@ -0,0 +1,17 @@
+package openapi
+
+import (
+	_ "embed"
+	"html"
+	"strings"
+)
+
+// docsPage is a dependency-free page that renders a document in the browser
+//
+//go:embed docs.html
+var docsPage string
+
+// DocsPage returns the HTML of the docs UI for the document served at specURL
+func DocsPage(specURL string) []byte {
+	return []byte(strings.ReplaceAll(docsPage, "{{SPEC_URL}}", html.EscapeString(specURL)))
+}
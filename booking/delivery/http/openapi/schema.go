@@ -0,0 +1,208 @@
+package openapi
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeType is rendered as an RFC 3339 string instead of an object
+var timeType = reflect.TypeOf(time.Time{})
+
+// Schemas derives JSON Schemas from Go types and collects named structs as components
+// Field names come from `json` tags, and `binding` tags (the ones gin
+// validates) add constraints: required, email, min, max, len and oneof.
+type Schemas struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+	overrides  map[reflect.Type]*Schema
+}
+
+// NewSchemas creates an empty schema registry
+func NewSchemas() *Schemas {
+	return &Schemas{
+		components: map[string]*Schema{},
+		names:      map[reflect.Type]string{},
+		overrides:  map[reflect.Type]*Schema{},
+	}
+}
+
+// Override uses schema for every value of the type of v
+// Use it for types with custom JSON encodings, e.g. gorm.DeletedAt.
+func (s *Schemas) Override(v interface{}, schema *Schema) {
+	s.overrides[reflect.TypeOf(v)] = schema
+}
+
+// Components returns the named schemas referenced so far
+func (s *Schemas) Components() map[string]*Schema {
+	return s.components
+}
+
+// Resolve follows a component reference; other schemas are returned as-is
+func (s *Schemas) Resolve(schema *Schema) *Schema {
+	if schema == nil || schema.Ref == "" {
+		return schema
+	}
+	return s.components[strings.TrimPrefix(schema.Ref, componentPrefix)]
+}
+
+// componentPrefix starts every reference to a component schema
+const componentPrefix = "#/components/schemas/"
+
+// For returns the schema of the type of v, a reference for named structs
+func (s *Schemas) For(v interface{}) *Schema {
+	return s.forType(reflect.TypeOf(v))
+}
+
+// forType returns the schema of t
+func (s *Schemas) forType(t reflect.Type) *Schema {
+	if override, ok := s.overrides[t]; ok {
+		copied := *override
+		return &copied
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		schema := s.forType(t.Elem())
+		if schema.Ref == "" {
+			schema.Nullable = true
+		}
+		return schema
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer", Format: intFormat(t)}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: intFormat(t), Minimum: Ptr(0.0)}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		// encoding/json writes nil slices as null
+		return &Schema{Type: "array", Items: s.forType(t.Elem()), Nullable: t.Kind() == reflect.Slice}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.forType(t.Elem())}
+	case reflect.Interface:
+		// Any JSON value
+		return &Schema{}
+	case reflect.Struct:
+		if t == timeType {
+			return &Schema{Type: "string", Format: "date-time"}
+		}
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		return &Schema{Ref: componentPrefix + s.component(t)}
+	default:
+		panic(fmt.Sprintf("openapi: unsupported type %s", t))
+	}
+}
+
+// component registers the named struct t and returns its component name
+func (s *Schemas) component(t reflect.Type) string {
+	if name, ok := s.names[t]; ok {
+		return name
+	}
+
+	name := t.Name()
+	if _, taken := s.components[name]; taken {
+		// Same name in another package, e.g. two Request types
+		name = strings.ReplaceAll(t.PkgPath(), "/", "_") + "_" + name
+	}
+	s.names[t] = name
+	// Reserve the name before recursing so self-referencing types terminate
+	s.components[name] = &Schema{}
+	*s.components[name] = *s.object(t)
+	return name
+}
+
+// object builds the schema of a struct from its exported fields
+func (s *Schemas) object(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	s.addFields(schema, t)
+	return schema
+}
+
+// addFields adds the fields of t, flattening embedded structs like encoding/json does
+func (s *Schemas) addFields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			s.addFields(schema, field.Type)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := s.forType(field.Type)
+		if description := field.Tag.Get("description"); description != "" {
+			property.Description = description
+		}
+		if applyBinding(property, field.Tag.Get("binding")) {
+			schema.Required = append(schema.Required, name)
+		}
+		schema.Properties[name] = property
+	}
+}
+
+// applyBinding turns gin binding rules into schema constraints and reports whether the field is required
+func applyBinding(schema *Schema, binding string) bool {
+	required := false
+	for _, rule := range strings.Split(binding, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = true
+		case "email":
+			schema.Format = "email"
+		case "oneof":
+			for _, value := range strings.Fields(param) {
+				schema.Enum = append(schema.Enum, value)
+			}
+		case "min", "max", "len":
+			n, err := strconv.Atoi(param)
+			if err != nil {
+				continue
+			}
+			if schema.Type == "string" {
+				if name != "max" {
+					schema.MinLength = Ptr(n)
+				}
+				if name != "min" {
+					schema.MaxLength = Ptr(n)
+				}
+			} else {
+				if name != "max" {
+					schema.Minimum = Ptr(float64(n))
+				}
+				if name != "min" {
+					schema.Maximum = Ptr(float64(n))
+				}
+			}
+		}
+	}
+	return required
+}
+
+// intFormat returns the OpenAPI format of an integer kind
+func intFormat(t reflect.Type) string {
+	if t.Bits() == 64 {
+		return "int64"
+	}
+	return "int32"
+}
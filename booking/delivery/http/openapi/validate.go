@@ -0,0 +1,364 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/mail"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Locations of a FieldError besides the parameter locations
+const (
+	InBody     = "body"
+	InResponse = "response"
+)
+
+// FieldError is one value that does not match the document
+type FieldError struct {
+	// In is path, query, header, body or response
+	In string `json:"in"`
+	// Field is the parameter name or the JSON path inside the body, e.g. items[0].name; empty for the whole body
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Validator checks requests and responses against a document
+type Validator struct {
+	schemas    map[string]*Schema
+	operations map[string]*Operation
+	patterns   sync.Map // pattern -> *regexp.Regexp
+}
+
+// NewValidator indexes the operations of doc by method and path
+func NewValidator(doc *Document) *Validator {
+	v := &Validator{
+		schemas:    doc.Components.Schemas,
+		operations: map[string]*Operation{},
+	}
+	for path, item := range doc.Paths {
+		for method, op := range *item {
+			v.operations[RouteKey(method, path)] = op
+		}
+	}
+	return v
+}
+
+// Operation returns the operation of a gin route (e.g. GET /users/:id), or nil if it is not documented
+func (v *Validator) Operation(method, ginPath string) *Operation {
+	path, _ := convertPath(ginPath)
+	return v.operations[RouteKey(method, path)]
+}
+
+// ValidateRequest checks the parameters and JSON body of req
+// pathParam looks up path parameters by name. The body is read and replaced,
+// so handlers can still bind it. The error is only set if the body cannot be read.
+func (v *Validator) ValidateRequest(op *Operation, req *http.Request, pathParam func(name string) string) ([]FieldError, error) {
+	var problems []FieldError
+
+	query := req.URL.Query()
+	for _, param := range op.Parameters {
+		var raw string
+		var present bool
+		switch param.In {
+		case InPath:
+			raw = pathParam(param.Name)
+			present = raw != ""
+		case InQuery:
+			present = query.Has(param.Name) && query.Get(param.Name) != ""
+			raw = query.Get(param.Name)
+		case InHeader:
+			raw = req.Header.Get(param.Name)
+			present = raw != ""
+		}
+
+		if !present {
+			if param.Required {
+				problems = append(problems, FieldError{In: param.In, Field: param.Name, Message: "is required"})
+			}
+			continue
+		}
+		problems = append(problems, v.validateParam(param, raw)...)
+	}
+
+	if op.RequestBody == nil {
+		return problems, nil
+	}
+	media, ok := op.RequestBody.Content["application/json"]
+	if !ok {
+		return problems, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		if op.RequestBody.Required {
+			problems = append(problems, FieldError{In: InBody, Message: "is required"})
+		}
+		return problems, nil
+	}
+	if contentType := req.Header.Get("Content-Type"); contentType != "" {
+		if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType != "application/json" {
+			return append(problems, FieldError{In: InBody, Message: "must be application/json"}), nil
+		}
+	}
+
+	value, err := decodeJSON(body)
+	if err != nil {
+		return append(problems, FieldError{In: InBody, Message: "invalid JSON: " + err.Error()}), nil
+	}
+	return append(problems, v.validateValue(media.Schema, value, InBody, "")...), nil
+}
+
+// ValidateResponse checks that status is documented and that a JSON body matches its schema
+func (v *Validator) ValidateResponse(op *Operation, status int, contentType string, body []byte) []FieldError {
+	response, ok := op.Responses[strconv.Itoa(status)]
+	if !ok {
+		return []FieldError{{In: InResponse, Message: fmt.Sprintf("status %d is not documented", status)}}
+	}
+	if len(response.Content) == 0 {
+		if len(body) > 0 {
+			return []FieldError{{In: InResponse, Message: fmt.Sprintf("status %d is documented without a body", status)}}
+		}
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	media, ok := response.Content[mediaType]
+	if !ok {
+		return []FieldError{{In: InResponse, Message: fmt.Sprintf("content type %q is not documented for status %d", mediaType, status)}}
+	}
+	if mediaType != "application/json" {
+		return nil
+	}
+
+	value, err := decodeJSON(body)
+	if err != nil {
+		return []FieldError{{In: InResponse, Message: "invalid JSON: " + err.Error()}}
+	}
+	return v.validateValue(media.Schema, value, InResponse, "")
+}
+
+// decodeJSON decodes a document keeping numbers exact, so integers can be told from floats
+func decodeJSON(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("unexpected data after the document")
+	}
+	return value, nil
+}
+
+// validateParam converts a parameter from its string form and validates it
+func (v *Validator) validateParam(param *Parameter, raw string) []FieldError {
+	schema := v.resolve(param.Schema)
+	var value interface{} = raw
+	switch schema.Type {
+	case "integer", "number":
+		value = json.Number(raw)
+	case "boolean":
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return []FieldError{{In: param.In, Field: param.Name, Message: "must be true or false"}}
+		}
+		value = b
+	}
+	return v.validateValue(schema, value, param.In, param.Name)
+}
+
+// resolve follows a component reference
+func (v *Validator) resolve(schema *Schema) *Schema {
+	if schema != nil && schema.Ref != "" {
+		if resolved, ok := v.schemas[strings.TrimPrefix(schema.Ref, componentPrefix)]; ok {
+			return resolved
+		}
+	}
+	return schema
+}
+
+// validateValue checks a decoded JSON value against schema
+func (v *Validator) validateValue(schema *Schema, value interface{}, in, field string) []FieldError {
+	schema = v.resolve(schema)
+	if schema == nil {
+		return nil
+	}
+	fail := func(format string, args ...interface{}) []FieldError {
+		return []FieldError{{In: in, Field: field, Message: fmt.Sprintf(format, args...)}}
+	}
+
+	if value == nil {
+		if schema.Type == "" || schema.Nullable {
+			return nil
+		}
+		return fail("must not be null")
+	}
+
+	switch schema.Type {
+	case "":
+		// Any value
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return fail("must be a string")
+		}
+		if problem := v.checkString(schema, s); problem != "" {
+			return fail("%s", problem)
+		}
+	case "integer", "number":
+		n, ok := value.(json.Number)
+		if !ok && schema.Type == "integer" {
+			return fail("must be an integer")
+		}
+		if !ok {
+			return fail("must be a number")
+		}
+		if schema.Type == "integer" {
+			if _, err := n.Int64(); err != nil {
+				return fail("must be an integer")
+			}
+		}
+		f, err := n.Float64()
+		if err != nil {
+			return fail("must be a number")
+		}
+		if schema.Minimum != nil && f < *schema.Minimum {
+			return fail("must be at least %v", *schema.Minimum)
+		}
+		if schema.Maximum != nil && f > *schema.Maximum {
+			return fail("must be at most %v", *schema.Maximum)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fail("must be true or false")
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return fail("must be an array")
+		}
+		var problems []FieldError
+		for i, item := range items {
+			problems = append(problems, v.validateValue(schema.Items, item, in, fmt.Sprintf("%s[%d]", field, i))...)
+		}
+		return problems
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return fail("must be an object")
+		}
+		return v.validateObject(schema, object, in, field)
+	}
+
+	if len(schema.Enum) > 0 && !inEnum(schema.Enum, value) {
+		return fail("must be one of %s", formatEnum(schema.Enum))
+	}
+	return nil
+}
+
+// validateObject checks required and known properties of an object
+func (v *Validator) validateObject(schema *Schema, object map[string]interface{}, in, field string) []FieldError {
+	var problems []FieldError
+	for _, name := range schema.Required {
+		if _, ok := object[name]; !ok {
+			problems = append(problems, FieldError{In: in, Field: joinField(field, name), Message: "is required"})
+		}
+	}
+
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		property, ok := schema.Properties[name]
+		if !ok {
+			property = schema.AdditionalProperties
+		}
+		problems = append(problems, v.validateValue(property, object[name], in, joinField(field, name))...)
+	}
+	return problems
+}
+
+// checkString returns why s does not match the string constraints of schema, or ""
+func (v *Validator) checkString(schema *Schema, s string) string {
+	length := utf8.RuneCountInString(s)
+	if schema.MinLength != nil && length < *schema.MinLength {
+		return fmt.Sprintf("must be at least %d characters", *schema.MinLength)
+	}
+	if schema.MaxLength != nil && length > *schema.MaxLength {
+		return fmt.Sprintf("must be at most %d characters", *schema.MaxLength)
+	}
+	switch schema.Format {
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, s); err != nil {
+			return "must be an RFC 3339 date-time, e.g. 2024-01-31T09:00:00Z"
+		}
+	case "email":
+		if _, err := mail.ParseAddress(s); err != nil {
+			return "must be an email address"
+		}
+	}
+	if schema.Pattern != "" {
+		if re := v.pattern(schema.Pattern); re != nil && !re.MatchString(s) {
+			return "must match " + schema.Pattern
+		}
+	}
+	return ""
+}
+
+// pattern compiles and caches a schema pattern; invalid patterns are ignored
+func (v *Validator) pattern(pattern string) *regexp.Regexp {
+	if cached, ok := v.patterns.Load(pattern); ok {
+		return cached.(*regexp.Regexp)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil
+	}
+	v.patterns.Store(pattern, re)
+	return re
+}
+
+// inEnum reports whether value equals one of the allowed values
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, allowed := range enum {
+		if fmt.Sprint(allowed) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatEnum lists the allowed values for an error message
+func formatEnum(enum []interface{}) string {
+	values := make([]string, len(enum))
+	for i, value := range enum {
+		values[i] = fmt.Sprint(value)
+	}
+	return strings.Join(values, ", ")
+}
+
+// joinField appends a property name to a JSON path
+func joinField(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
@@ -0,0 +1,190 @@
+package openapi
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// widgetRequest and widget are the body types of the test document
+type widgetRequest struct {
+	Name  string   `json:"name" binding:"required,min=2,max=10"`
+	Email string   `json:"email" binding:"required,email"`
+	Kind  string   `json:"kind" binding:"omitempty,oneof=small large"`
+	Count int      `json:"count" binding:"omitempty,min=1,max=5"`
+	Tags  []string `json:"tags"`
+	Note  *string  `json:"note"`
+}
+
+type widget struct {
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	CreatedAt string `json:"created_at"`
+}
+
+// newTestValidator documents GET and PUT /widgets/:id
+func newTestValidator(t *testing.T) *Validator {
+	t.Helper()
+
+	builder := NewBuilder(Info{Title: "test", Version: "1"})
+	builder.Add(Route{
+		Method:      http.MethodGet,
+		Path:        "/widgets/:id",
+		OperationID: "getWidget",
+		Params: []*Parameter{
+			PathParam("id", &Schema{Type: "integer", Minimum: Ptr(1.0)}, ""),
+			QueryParam("verbose", &Schema{Type: "boolean"}, ""),
+			QueryParam("after", &Schema{Type: "string", Format: "date-time"}, ""),
+		},
+		Responses: map[int]interface{}{
+			http.StatusOK:        widget{},
+			http.StatusNoContent: nil,
+		},
+	})
+	builder.Add(Route{
+		Method:      http.MethodPut,
+		Path:        "/widgets/:id",
+		OperationID: "updateWidget",
+		Body:        widgetRequest{},
+		Responses:   map[int]interface{}{http.StatusOK: widget{}},
+	})
+	return NewValidator(builder.Document())
+}
+
+// pathParams serves path parameters from a map
+func pathParams(values map[string]string) func(string) string {
+	return func(name string) string { return values[name] }
+}
+
+// fields lists the "in:field" of each problem
+func fields(problems []FieldError) []string {
+	out := make([]string, len(problems))
+	for i, problem := range problems {
+		out[i] = problem.In + ":" + problem.Field
+	}
+	return out
+}
+
+func TestOperationMatchesGinRoutes(t *testing.T) {
+	v := newTestValidator(t)
+
+	if op := v.Operation("GET", "/widgets/:id"); op == nil || op.OperationID != "getWidget" {
+		t.Errorf("Operation(GET /widgets/:id) = %+v", op)
+	}
+	if op := v.Operation("put", "/widgets/:id"); op == nil || op.OperationID != "updateWidget" {
+		t.Errorf("Operation(put /widgets/:id) = %+v", op)
+	}
+	if op := v.Operation("DELETE", "/widgets/:id"); op != nil {
+		t.Errorf("Operation(DELETE /widgets/:id) = %+v, want nil", op)
+	}
+}
+
+func TestValidateRequestParameters(t *testing.T) {
+	v := newTestValidator(t)
+	op := v.Operation("GET", "/widgets/:id")
+
+	tests := []struct {
+		name   string
+		id     string
+		query  string
+		fields []string
+	}{
+		{"valid", "7", "verbose=true&after=2024-01-31T09:00:00Z", []string{}},
+		{"empty query values are absent", "7", "verbose=&after=", []string{}},
+		{"missing path parameter", "", "", []string{"path:id"}},
+		{"path parameter not an integer", "abc", "", []string{"path:id"}},
+		{"path parameter below minimum", "0", "", []string{"path:id"}},
+		{"bad boolean and date-time", "7", "verbose=maybe&after=yesterday", []string{"query:verbose", "query:after"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/widgets/x?"+tc.query, nil)
+			problems, err := v.ValidateRequest(op, req, pathParams(map[string]string{"id": tc.id}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := fields(problems); !reflect.DeepEqual(got, tc.fields) {
+				t.Errorf("problems = %+v, want %v", problems, tc.fields)
+			}
+		})
+	}
+}
+
+func TestValidateRequestBody(t *testing.T) {
+	v := newTestValidator(t)
+	op := v.Operation("PUT", "/widgets/:id")
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		fields      []string
+	}{
+		{"valid", "application/json", `{"name":"bolt","email":"a@example.com","kind":"small","count":2,"tags":["x"],"note":null}`, []string{}},
+		{"charset is allowed", "application/json; charset=utf-8", `{"name":"bolt","email":"a@example.com"}`, []string{}},
+		{"missing required fields", "application/json", `{}`, []string{"body:name", "body:email"}},
+		{"empty body", "application/json", ``, []string{"body:"}},
+		{"not JSON", "application/json", `{"name":`, []string{"body:"}},
+		{"trailing data", "application/json", `{"name":"bolt","email":"a@example.com"} {}`, []string{"body:"}},
+		{"wrong content type", "text/plain", `name=bolt`, []string{"body:"}},
+		{"constraints", "application/json", `{"name":"b","email":"nope","kind":"huge","count":9}`, []string{"body:count", "body:email", "body:kind", "body:name"}},
+		{"wrong types", "application/json", `{"name":1,"email":"a@example.com","count":1.5,"tags":[1],"note":false}`, []string{"body:count", "body:name", "body:note", "body:tags[0]"}},
+		{"null for a non-nullable field", "application/json", `{"name":null,"email":"a@example.com"}`, []string{"body:name"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/widgets/7", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", tc.contentType)
+			problems, err := v.ValidateRequest(op, req, pathParams(map[string]string{"id": "7"}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := fields(problems); !reflect.DeepEqual(got, tc.fields) {
+				t.Errorf("problems = %+v, want %v", problems, tc.fields)
+			}
+
+			// The body is put back for the handler to bind
+			body, _ := io.ReadAll(req.Body)
+			if string(body) != tc.body {
+				t.Errorf("body after validation = %q, want %q", body, tc.body)
+			}
+		})
+	}
+}
+
+func TestValidateResponse(t *testing.T) {
+	v := newTestValidator(t)
+	op := v.Operation("GET", "/widgets/:id")
+
+	tests := []struct {
+		name        string
+		status      int
+		contentType string
+		body        string
+		fields      []string
+	}{
+		{"valid", http.StatusOK, "application/json; charset=utf-8", `{"id":1,"name":"bolt","created_at":"x"}`, nil},
+		{"documented without a body", http.StatusNoContent, "", ``, nil},
+		{"undocumented status", http.StatusTeapot, "application/json", `{}`, []string{"response:"}},
+		{"body on a bodiless status", http.StatusNoContent, "application/json", `{}`, []string{"response:"}},
+		{"undocumented content type", http.StatusOK, "text/html", `<p>`, []string{"response:"}},
+		{"schema mismatch", http.StatusOK, "application/json", `{"id":-1,"name":2}`, []string{"response:id", "response:name"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			problems := v.ValidateResponse(op, tc.status, tc.contentType, []byte(tc.body))
+			if tc.fields == nil {
+				if len(problems) != 0 {
+					t.Errorf("problems = %+v, want none", problems)
+				}
+				return
+			}
+			if got := fields(problems); !reflect.DeepEqual(got, tc.fields) {
+				t.Errorf("problems = %+v, want %v", problems, tc.fields)
+			}
+		})
+	}
+}
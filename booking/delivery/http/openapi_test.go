@@ -0,0 +1,89 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"booking/config"
+	"booking/delivery/http/handler"
+	"booking/delivery/http/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newTestRouter builds the routes without use cases, as the openapi command does
+func newTestRouter(t *testing.T) *Router {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	cfg, err := config.Load(nil)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	router := NewRouter(handler.NewHandlerFactory(nil, nil, nil, nil, nil, nil), cfg, middleware.NewRateLimiter(nil), nil, nil)
+	router.SetupRoutes()
+	return router
+}
+
+func TestOpenAPIDocumentsEveryRoute(t *testing.T) {
+	router := newTestRouter(t)
+
+	if err := router.VerifyOpenAPI(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyOpenAPIRejectsUndocumentedRoute(t *testing.T) {
+	router := newTestRouter(t)
+	router.GetEngine().GET("/api/v1/undocumented", func(c *gin.Context) {})
+
+	if err := router.VerifyOpenAPI(); err == nil {
+		t.Fatal("VerifyOpenAPI() = nil for a route missing from the document")
+	}
+}
+
+func TestOpenAPIDocumentParses(t *testing.T) {
+	router := newTestRouter(t)
+
+	rec := httptest.NewRecorder()
+	router.GetEngine().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, OpenAPIPath, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET %s = %d, want 200", OpenAPIPath, rec.Code)
+	}
+
+	var doc struct {
+		OpenAPI string                                `json:"openapi"`
+		Paths   map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("document is not JSON: %v", err)
+	}
+	if doc.OpenAPI != "3.1.0" {
+		t.Errorf("openapi = %q, want 3.1.0", doc.OpenAPI)
+	}
+
+	for path, method := range map[string]string{
+		"/api/v1/users":      "post",
+		"/api/v1/users/{id}": "put",
+		"/api/v1/me/exports": "post",
+	} {
+		var operation struct {
+			OperationID string                     `json:"operationId"`
+			Responses   map[string]json.RawMessage `json:"responses"`
+		}
+		raw, ok := doc.Paths[path][method]
+		if !ok {
+			t.Errorf("%s %s missing from the document", method, path)
+			continue
+		}
+		if err := json.Unmarshal(raw, &operation); err != nil {
+			t.Errorf("%s %s: %v", method, path, err)
+			continue
+		}
+		if operation.OperationID == "" || len(operation.Responses) == 0 {
+			t.Errorf("%s %s has no operationId or responses", method, path)
+		}
+	}
+}
@@ -1,8 +1,17 @@
 package http
 
 import (
+	"net/http"
+	"strings"
+	"time"
+	"booking/config"
+	"booking/delivery/consistency"
 	"booking/delivery/http/handler"
 	"booking/delivery/http/middleware"
+	"booking/delivery/http/openapi"
+	"booking/domain/entity"
+	"booking/infrastructure/auth"
+	"booking/infrastructure/metrics"
 	
 	"github.com/gin-gonic/gin"
 )
@@ -11,48 +20,162 @@ import (
 type Router struct {
 	engine         *gin.Engine
 	handlerFactory *handler.HandlerFactory
+	config         *config.Config
+	rateLimiter    *middleware.RateLimiter
+	metrics        *metrics.Metrics
+	writes         *consistency.Tracker
+	spec           *openapi.Builder
 }
 
 // NewRouter creates a new router
-func NewRouter(handlerFactory *handler.HandlerFactory) *Router {
-	engine := gin.Default()
+// m may be nil when metrics are disabled. writes tracks recent writers for
+// read-your-writes; nil leaves only the X-Consistency header.
+func NewRouter(handlerFactory *handler.HandlerFactory, cfg *config.Config, rateLimiter *middleware.RateLimiter, m *metrics.Metrics, writes *consistency.Tracker) *Router {
+	engine := gin.New()
 	
 	// Apply global middleware
-	engine.Use(middleware.CORS())
+	// Logger wraps Recovery so that recovered panics are logged as 500s
+	engine.Use(middleware.RequestID())
+	engine.Use(middleware.Tracing())
+	engine.Use(middleware.Metrics(m))
 	engine.Use(middleware.Logger())
+	engine.Use(middleware.Recovery())
+	engine.Use(middleware.CORS(corsPolicy(cfg.CORS, cfg.CORS.AllowedOrigins), middleware.CORSRoute{
+		Prefix: "/api/v1/admin",
+		Policy: corsPolicy(cfg.CORS, cfg.CORS.AdminOrigins),
+	}))
+	engine.Use(middleware.Authenticate(auth.NewAuthenticator(cfg.Auth)))
 	
 	return &Router{
 		engine:         engine,
 		handlerFactory: handlerFactory,
+		config:         cfg,
+		rateLimiter:    rateLimiter,
+		metrics:        m,
+		writes:         writes,
+		spec:           newOpenAPI(cfg),
 	}
 }
 
 // SetupRoutes configures all routes
 func (r *Router) SetupRoutes() {
-	// Health check
-	r.engine.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"status":  "ok",
-			"message": "Booking service is running",
-		})
+	// Health checks: liveness never touches dependencies, readiness checks all of them
+	healthHandler := r.handlerFactory.GetHealthHandler()
+	r.engine.GET("/livez", healthHandler.Livez)
+	r.engine.GET("/readyz", healthHandler.Readyz)
+	// Kept for existing monitors; same as /livez
+	r.engine.GET("/health", healthHandler.Livez)
+	
+	// Prometheus scrape endpoint
+	if r.metrics != nil {
+		r.engine.GET(r.config.Metrics.Path, gin.WrapH(r.metrics.Handler()))
+	}
+	
+	// OpenAPI document and its docs UI
+	spec := r.OpenAPI()
+	r.engine.GET(OpenAPIPath, func(c *gin.Context) {
+		c.JSON(http.StatusOK, spec)
+	})
+	r.engine.GET(DocsPath, func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", openapi.DocsPage(OpenAPIPath))
 	})
 	
+	// GraphQL for the mobile app, next to the REST routes it reads from
+	if r.config.GraphQL.Enabled {
+		graphqlHandler := r.handlerFactory.GetGraphQLHandler()
+		// Queries read like GET requests, so they do not start a read-your-writes window
+		graphqlRoutes := r.engine.Group("/graphql",
+			r.rateLimiter.For("api"),
+			middleware.ReadYourWrites(r.writes, middleware.WithWriteRecording(false)),
+		)
+		graphqlRoutes.GET("", graphqlHandler.Query)
+		graphqlRoutes.POST("", graphqlHandler.Query)
+		graphqlRoutes.GET("/schema", graphqlHandler.Schema)
+	}
+	
 	// API v1 routes
 	v1 := r.engine.Group("/api/v1")
+	v1.Use(r.rateLimiter.For("api"))
+	// Reads after a write by the same actor go to the primary
+	v1.Use(middleware.ReadYourWrites(r.writes))
+	// Requests are checked against the OpenAPI document before idempotency keys are claimed
+	if r.config.OpenAPI.ValidateRequests {
+		v1.Use(middleware.Validation(
+			openapi.NewValidator(spec),
+			middleware.WithResponseValidation(r.config.OpenAPI.ValidateResponses),
+		))
+	}
+	// POST requests carrying an Idempotency-Key are safe to retry
+	v1.Use(middleware.Idempotency(
+		r.handlerFactory.GetIdempotencyUseCase(),
+		middleware.WithMaxBodySize(int64(r.config.Idempotency.MaxBodyMB)<<20),
+	))
 	{
-		// User routes
+		// User routes need the same permissions as the gRPC methods
+		checker := r.handlerFactory.GetPermissionChecker()
 		userHandler := r.handlerFactory.GetUserHandler()
 		users := v1.Group("/users")
 		{
-			users.POST("", userHandler.CreateUser)
-			users.GET("", userHandler.ListUsers)
-			users.GET("/:id", userHandler.GetUser)
-			users.PUT("/:id", userHandler.UpdateUser)
-			users.DELETE("/:id", userHandler.DeleteUser)
+			users.POST("", middleware.RequirePermission(checker, entity.PermissionUsersWrite), userHandler.CreateUser)
+			users.GET("", middleware.RequirePermission(checker, entity.PermissionUsersRead), userHandler.ListUsers)
+			users.GET("/:id", middleware.RequirePermission(checker, entity.PermissionUsersRead), userHandler.GetUser)
+			users.PUT("/:id", middleware.RequirePermission(checker, entity.PermissionUsersWrite), userHandler.UpdateUser)
+			users.DELETE("/:id", middleware.RequirePermission(checker, entity.PermissionUsersDelete), userHandler.DeleteUser)
+		}
+		
+		// The only way to get an account without an admin; it comes with the runner role
+		v1.POST("/signup", r.rateLimiter.For("signup"), userHandler.Signup)
+		
+		// Self-service routes for the authenticated user (GDPR export and erasure)
+		privacyHandler := r.handlerFactory.GetPrivacyHandler()
+		me := v1.Group("/me")
+		{
+			me.POST("/exports", r.rateLimiter.For("privacy"), privacyHandler.RequestExport)
+			me.GET("/exports/:id/download", privacyHandler.DownloadExport)
+			me.POST("/erasure", r.rateLimiter.For("privacy"), privacyHandler.RequestErasure)
+			me.GET("/privacy-requests", privacyHandler.ListRequests)
+			me.GET("/privacy-requests/:id", privacyHandler.GetRequest)
+		}
+		
+		// Admin routes, each group guarded by its own permission
+		admin := v1.Group("/admin", r.rateLimiter.For("admin"))
+		
+		roleHandler := r.handlerFactory.GetRoleHandler()
+		roles := admin.Group("", middleware.RequirePermission(checker, entity.PermissionRolesManage))
+		{
+			roles.GET("/roles", roleHandler.ListRoles)
+			roles.GET("/users/:id/roles", roleHandler.GetUserRoles)
+			roles.POST("/users/:id/roles", roleHandler.GrantRole)
+			roles.DELETE("/users/:id/roles/:role", roleHandler.RevokeRole)
+		}
+		
+		adminUsers := admin.Group("/users", middleware.RequirePermission(checker, entity.PermissionUsersDelete))
+		{
+			adminUsers.POST("/:id/restore", userHandler.RestoreUser)
+		}
+		
+		// Bulk import and export of users, e.g. when onboarding a partner club
+		admin.POST("/users/import", middleware.RequirePermission(checker, entity.PermissionUsersWrite), userHandler.ImportUsers)
+		admin.GET("/users/export", middleware.RequirePermission(checker, entity.PermissionUsersRead), userHandler.ExportUsers)
+		
+		auditHandler := r.handlerFactory.GetAuditHandler()
+		auditLog := admin.Group("/audit", middleware.RequirePermission(checker, entity.PermissionAuditRead))
+		{
+			auditLog.GET("", auditHandler.ListEntries)
+			auditLog.GET("/verify", auditHandler.VerifyChain)
 		}
 	}
 }
 
+// Server returns an HTTP server for the routes, to be run by the application lifecycle
+func (r *Router) Server(addr string) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           r.engine,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+}
+
 // Run starts the HTTP server
 func (r *Router) Run(addr string) error {
 	return r.engine.Run(addr)
@@ -63,3 +186,32 @@ func (r *Router) GetEngine() *gin.Engine {
 	return r.engine
 }
 
+// corsPolicy builds the CORS policy for a route group from its origin list
+// An empty list inherits AllowedOrigins; "off" allows no cross-origin access.
+func corsPolicy(cfg config.CORSConfig, origins string) middleware.CORSPolicy {
+	if origins == "" {
+		origins = cfg.AllowedOrigins
+	}
+	if origins == "off" {
+		origins = ""
+	}
+	return middleware.CORSPolicy{
+		AllowedOrigins:   splitList(origins),
+		AllowedMethods:   splitList(strings.ToUpper(cfg.AllowedMethods)),
+		AllowedHeaders:   splitList(cfg.AllowedHeaders),
+		ExposedHeaders:   splitList(cfg.ExposedHeaders),
+		AllowCredentials: cfg.AllowCredentials,
+		MaxAge:           cfg.MaxAge,
+	}
+}
+
+// splitList splits a comma separated list, dropping empty entries
+func splitList(list string) []string {
+	var values []string
+	for _, value := range strings.Split(list, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,169 @@
+package userfile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"booking/domain/entity"
+	"booking/usecase/user"
+)
+
+// maxLineSize bounds one NDJSON line
+const maxLineSize = 64 * 1024
+
+// NewReader returns the rows of r as a source for UserUseCase.ImportUsers
+// A CSV header is read right away, so a file with missing or unknown
+// columns fails here rather than row by row.
+func NewReader(r io.Reader, format Format) (user.UserSource, error) {
+	if format == NDJSON {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 4096), maxLineSize)
+		return &ndjsonReader{scanner: scanner}, nil
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	var parseErr *csv.ParseError
+	switch {
+	case errors.Is(err, io.EOF):
+		return nil, &FileError{Reason: "csv: missing header row"}
+	case errors.As(err, &parseErr):
+		return nil, &FileError{Reason: "csv: malformed header row: " + parseErr.Err.Error()}
+	case err != nil:
+		return nil, fmt.Errorf("csv: %w", err)
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if i == 0 {
+			// Spreadsheets often save CSV with a byte order mark
+			name = strings.TrimPrefix(name, "\uFEFF")
+		}
+		if !slices.Contains(importColumns, name) {
+			return nil, &FileError{Reason: fmt.Sprintf("csv: unknown column %q, expected %s", name, strings.Join(importColumns, ", "))}
+		}
+		if _, ok := columns[name]; ok {
+			return nil, &FileError{Reason: fmt.Sprintf("csv: column %q appears twice", name)}
+		}
+		columns[name] = i
+	}
+	for _, name := range requiredColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, &FileError{Reason: fmt.Sprintf("csv: missing column %q", name)}
+		}
+	}
+
+	return &csvReader{reader: reader, columns: columns, width: len(header)}, nil
+}
+
+// csvReader reads users from CSV rows
+type csvReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+	width   int
+}
+
+// Next implements user.UserSource
+func (r *csvReader) Next() (*entity.User, error) {
+	record, err := r.reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, io.EOF
+	}
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return nil, &user.ValidationError{Reason: "malformed CSV: " + parseErr.Err.Error()}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(record) != r.width {
+		return nil, &user.ValidationError{Reason: fmt.Sprintf("expected %d fields, got %d", r.width, len(record))}
+	}
+
+	value := func(name string) string {
+		if i, ok := r.columns[name]; ok {
+			return record[i]
+		}
+		return ""
+	}
+	u := &entity.User{
+		Email:    strings.TrimSpace(value("email")),
+		Username: strings.TrimSpace(value("username")),
+		Password: value("password"),
+		FullName: strings.TrimSpace(value("full_name")),
+		Phone:    strings.TrimSpace(value("phone")),
+		IsActive: true,
+	}
+	if active := strings.TrimSpace(value("is_active")); active != "" {
+		if u.IsActive, err = strconv.ParseBool(active); err != nil {
+			return nil, &user.ValidationError{Reason: "is_active must be true or false"}
+		}
+	}
+	return u, nil
+}
+
+// ndjsonRecord is one line of an NDJSON import
+type ndjsonRecord struct {
+	Email    string `json:"email"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	FullName string `json:"full_name"`
+	Phone    string `json:"phone"`
+	IsActive *bool  `json:"is_active"`
+}
+
+// ndjsonReader reads users from NDJSON lines; blank lines are skipped
+type ndjsonReader struct {
+	scanner *bufio.Scanner
+}
+
+// Next implements user.UserSource
+func (r *ndjsonReader) Next() (*entity.User, error) {
+	var line []byte
+	for len(line) == 0 {
+		if !r.scanner.Scan() {
+			err := r.scanner.Err()
+			if errors.Is(err, bufio.ErrTooLong) {
+				return nil, &FileError{Reason: fmt.Sprintf("ndjson: a line is longer than %d bytes", maxLineSize)}
+			}
+			if err != nil {
+				return nil, fmt.Errorf("ndjson: %w", err)
+			}
+			return nil, io.EOF
+		}
+		line = bytes.TrimSpace(r.scanner.Bytes())
+	}
+
+	var record ndjsonRecord
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&record); err != nil {
+		return nil, &user.ValidationError{Reason: "invalid JSON: " + err.Error()}
+	}
+	if decoder.More() {
+		return nil, &user.ValidationError{Reason: "invalid JSON: more than one value on the line"}
+	}
+
+	u := &entity.User{
+		Email:    strings.TrimSpace(record.Email),
+		Username: strings.TrimSpace(record.Username),
+		Password: record.Password,
+		FullName: strings.TrimSpace(record.FullName),
+		Phone:    strings.TrimSpace(record.Phone),
+		IsActive: true,
+	}
+	if record.IsActive != nil {
+		u.IsActive = *record.IsActive
+	}
+	return u, nil
+}
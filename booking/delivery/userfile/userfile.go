@@ -0,0 +1,74 @@
+// Package userfile reads and writes users as CSV or NDJSON for bulk import and export
+//
+// Imports have the columns email, username, password, full_name, phone and
+// is_active; a CSV header may list them in any order and only email,
+// username and password are required. Exports have id, email, username,
+// full_name, phone, is_active, created_at and updated_at, never passwords.
+package userfile
+
+import (
+	"fmt"
+	"mime"
+	"strings"
+)
+
+// Format is a file format of imports and exports
+type Format string
+
+const (
+	// CSV has a header row naming the columns
+	CSV Format = "csv"
+	// NDJSON has one JSON object per line
+	NDJSON Format = "ndjson"
+)
+
+// ParseFormat returns the format called name; "jsonl" is accepted for NDJSON
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(name) {
+	case "csv":
+		return CSV, nil
+	case "ndjson", "jsonl":
+		return NDJSON, nil
+	}
+	return "", fmt.Errorf("unknown format %q, expected csv or ndjson", name)
+}
+
+// FormatForContentType returns the format of a request body with the Content-Type header contentType
+func FormatForContentType(contentType string) (Format, bool) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv":
+		return CSV, true
+	case "application/x-ndjson", "application/ndjson", "application/jsonl":
+		return NDJSON, true
+	}
+	return "", false
+}
+
+// ContentType is the media type of the format
+func (f Format) ContentType() string {
+	if f == CSV {
+		return "text/csv"
+	}
+	return "application/x-ndjson"
+}
+
+// Columns of the two directions, in the order exports write them
+var (
+	importColumns = []string{"email", "username", "password", "full_name", "phone", "is_active"}
+	exportColumns = []string{"id", "email", "username", "full_name", "phone", "is_active", "created_at", "updated_at"}
+)
+
+// requiredColumns must be present in the header of a CSV import
+var requiredColumns = []string{"email", "username", "password"}
+
+// FileError reports a file that cannot be read in its format at all, e.g. a CSV header with unknown columns
+// Problems with a single row are reported for that row instead.
+type FileError struct {
+	Reason string
+}
+
+// Error implements error
+func (e *FileError) Error() string {
+	return e.Reason
+}
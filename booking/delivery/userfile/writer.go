@@ -0,0 +1,103 @@
+package userfile
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	"booking/domain/entity"
+)
+
+// Writer writes users in an export format
+type Writer interface {
+	Write(u *entity.User) error
+	// Flush writes anything buffered; a CSV export without users still gets its header
+	Flush() error
+}
+
+// NewWriter returns a writer of format to w
+func NewWriter(w io.Writer, format Format) Writer {
+	if format == NDJSON {
+		return &ndjsonWriter{encoder: json.NewEncoder(w)}
+	}
+	return &csvWriter{writer: csv.NewWriter(w)}
+}
+
+// csvWriter writes users as CSV rows after a header row
+type csvWriter struct {
+	writer *csv.Writer
+	header bool
+}
+
+// Write implements Writer
+func (w *csvWriter) Write(u *entity.User) error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	return w.writer.Write([]string{
+		strconv.FormatUint(uint64(u.ID), 10),
+		u.Email,
+		u.Username,
+		u.FullName,
+		u.Phone,
+		strconv.FormatBool(u.IsActive),
+		u.CreatedAt.UTC().Format(time.RFC3339),
+		u.UpdatedAt.UTC().Format(time.RFC3339),
+	})
+}
+
+// Flush implements Writer
+func (w *csvWriter) Flush() error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// writeHeader writes the header row once
+func (w *csvWriter) writeHeader() error {
+	if w.header {
+		return nil
+	}
+	w.header = true
+	return w.writer.Write(exportColumns)
+}
+
+// exportRecord is one line of an NDJSON export
+type exportRecord struct {
+	ID        uint      `json:"id"`
+	Email     string    `json:"email"`
+	Username  string    `json:"username"`
+	FullName  string    `json:"full_name"`
+	Phone     string    `json:"phone"`
+	IsActive  bool      `json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ndjsonWriter writes users as one JSON object per line
+type ndjsonWriter struct {
+	encoder *json.Encoder
+}
+
+// Write implements Writer
+func (w *ndjsonWriter) Write(u *entity.User) error {
+	return w.encoder.Encode(exportRecord{
+		ID:        u.ID,
+		Email:     u.Email,
+		Username:  u.Username,
+		FullName:  u.FullName,
+		Phone:     u.Phone,
+		IsActive:  u.IsActive,
+		CreatedAt: u.CreatedAt.UTC(),
+		UpdatedAt: u.UpdatedAt.UTC(),
+	})
+}
+
+// Flush implements Writer; lines are written as they are encoded
+func (w *ndjsonWriter) Flush() error {
+	return nil
+}
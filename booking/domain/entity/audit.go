@@ -0,0 +1,310 @@
+package entity
+
+import (
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// JSON is a raw JSON document stored as text, so its bytes (and hash) survive a round trip
+type JSON []byte
+
+// Value implements driver.Valuer
+func (j JSON) Value() (driver.Value, error) {
+	if len(j) == 0 {
+		return nil, nil
+	}
+	return string(j), nil
+}
+
+// Scan implements sql.Scanner
+func (j *JSON) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*j = nil
+	case string:
+		*j = JSON(v)
+	case []byte:
+		*j = append((*j)[:0], v...)
+	default:
+		return fmt.Errorf("cannot scan %T into entity.JSON", src)
+	}
+	return nil
+}
+
+// MarshalJSON embeds the document as-is instead of base64 encoding it
+func (j JSON) MarshalJSON() ([]byte, error) {
+	if len(j) == 0 {
+		return []byte("null"), nil
+	}
+	return j, nil
+}
+
+// UnmarshalJSON keeps a copy of the raw document
+func (j *JSON) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*j = nil
+		return nil
+	}
+	*j = append((*j)[:0], data...)
+	return nil
+}
+
+// AuditEntry is one append-only record of a state-changing operation
+// Entries form a hash chain: each Hash covers the previous entry's Hash, so
+// editing or removing a stored entry breaks every hash after it. Redacted
+// entries had their payload erased; PayloadHash keeps them verifiable.
+type AuditEntry struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Sequence    uint64    `json:"sequence" gorm:"uniqueIndex;not null"`
+	ActorID     uint      `json:"actor_id" gorm:"index"`
+	Action      string    `json:"action" gorm:"index;not null"`
+	TargetType  string    `json:"target_type" gorm:"index:idx_audit_target;not null"`
+	TargetID    string    `json:"target_id" gorm:"index:idx_audit_target"`
+	RequestID   string    `json:"request_id" gorm:"index"`
+	Before      JSON      `json:"before,omitempty" gorm:"type:text"`
+	After       JSON      `json:"after,omitempty" gorm:"type:text"`
+	Changes     JSON      `json:"changes,omitempty" gorm:"type:text"`
+	PayloadHash string    `json:"payload_hash" gorm:"not null"`
+	Redacted    bool      `json:"redacted" gorm:"not null;default:false"`
+	PrevHash    string    `json:"prev_hash"`
+	Hash        string    `json:"hash" gorm:"not null"`
+	CreatedAt   time.Time `json:"created_at" gorm:"index"`
+}
+
+// TableName specifies the table name for GORM
+func (AuditEntry) TableName() string {
+	return "audit_entries"
+}
+
+// AuditFilter represents filter options for querying audit entries
+type AuditFilter struct {
+	ActorID    *uint
+	Action     *string
+	TargetType *string
+	TargetID   *string
+	RequestID  *string
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+	Offset     int
+}
+
+// AuditRedactor is implemented by audited values with fields that must not be stored in the log
+type AuditRedactor interface {
+	// AuditRedactedFields returns the JSON names of those fields
+	AuditRedactedFields() []string
+}
+
+// RedactedValue replaces redacted fields in audit snapshots and changes
+const RedactedValue = "[REDACTED]"
+
+// FieldChange is one entry of AuditEntry.Changes
+type FieldChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// AuditChainReport is the result of verifying the audit hash chain
+type AuditChainReport struct {
+	Valid          bool   `json:"valid"`
+	EntriesChecked int64  `json:"entries_checked"`
+	FirstBroken    uint64 `json:"first_broken_sequence,omitempty"`
+	Reason         string `json:"reason,omitempty"`
+}
+
+// NewAuditEntry builds an entry with JSON snapshots, a field diff and the payload hash
+// Before and after may be nil (creations have no before, deletions no after).
+// Fields named by an AuditRedactor are replaced with RedactedValue after the
+// diff, so changes still show that they changed but not their values.
+func NewAuditEntry(actorID uint, action, targetType, targetID, requestID string, before, after interface{}) (*AuditEntry, error) {
+	entry := &AuditEntry{
+		ActorID:    actorID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		RequestID:  requestID,
+		// Mongo stores milliseconds; truncating keeps the hash stable on both backends
+		CreatedAt: time.Now().UTC().Truncate(time.Millisecond),
+	}
+
+	var err error
+	if entry.Before, err = snapshot(before); err != nil {
+		return nil, err
+	}
+	if entry.After, err = snapshot(after); err != nil {
+		return nil, err
+	}
+	if entry.Changes, err = diff(entry.Before, entry.After); err != nil {
+		return nil, err
+	}
+	if fields := redactedFields(before, after); len(fields) > 0 {
+		if err := entry.redact(fields); err != nil {
+			return nil, err
+		}
+	}
+
+	entry.PayloadHash = entry.ComputePayloadHash()
+	return entry, nil
+}
+
+// ComputePayloadHash hashes the before/after/changes documents
+// The payload hash is chained instead of the payload itself so that personal
+// data can later be erased from an entry without breaking the chain.
+func (e *AuditEntry) ComputePayloadHash() string {
+	h := sha256.New()
+	for _, part := range [][]byte{e.Before, e.After, e.Changes} {
+		h.Write([]byte(strconv.Itoa(len(part))))
+		h.Write([]byte{':'})
+		h.Write(part)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ComputeHash hashes the entry's metadata, payload hash and the previous entry's hash
+func (e *AuditEntry) ComputeHash() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%d|%s|%d|%q|%q|%q|%q|%s|%s",
+		e.Sequence,
+		e.PrevHash,
+		e.ActorID,
+		e.Action,
+		e.TargetType,
+		e.TargetID,
+		e.RequestID,
+		e.CreatedAt.UTC().Format(time.RFC3339Nano),
+		e.PayloadHash,
+	)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Seal links the entry to its predecessor and computes its hash
+func (e *AuditEntry) Seal(prev *AuditEntry) {
+	e.Sequence = 1
+	e.PrevHash = ""
+	if prev != nil {
+		e.Sequence = prev.Sequence + 1
+		e.PrevHash = prev.Hash
+	}
+	e.Hash = e.ComputeHash()
+}
+
+// snapshot marshals a value for storage in an audit entry
+func snapshot(v interface{}) (JSON, error) {
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil()) {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return JSON(data), nil
+}
+
+// redactedFields returns the fields before and after ask to keep out of the log
+func redactedFields(values ...interface{}) map[string]bool {
+	fields := map[string]bool{}
+	for _, v := range values {
+		r, ok := v.(AuditRedactor)
+		if !ok || (reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil()) {
+			continue
+		}
+		for _, name := range r.AuditRedactedFields() {
+			fields[name] = true
+		}
+	}
+	return fields
+}
+
+// redact replaces the values of fields in the snapshots and changes
+// Empty values are kept, so the log still shows a field being set or cleared.
+func (e *AuditEntry) redact(fields map[string]bool) error {
+	for _, doc := range []*JSON{&e.Before, &e.After} {
+		if len(*doc) == 0 {
+			continue
+		}
+		var m map[string]interface{}
+		if err := json.Unmarshal(*doc, &m); err != nil {
+			return errors.New("audit snapshot is not a JSON object")
+		}
+		for name := range fields {
+			if v, ok := m[name]; ok {
+				m[name] = redactValue(v)
+			}
+		}
+		data, err := json.Marshal(m)
+		if err != nil {
+			return err
+		}
+		*doc = JSON(data)
+	}
+
+	if len(e.Changes) == 0 {
+		return nil
+	}
+	var changes map[string]FieldChange
+	if err := json.Unmarshal(e.Changes, &changes); err != nil {
+		return err
+	}
+	for name, change := range changes {
+		if fields[name] {
+			changes[name] = FieldChange{From: redactValue(change.From), To: redactValue(change.To)}
+		}
+	}
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+	e.Changes = JSON(data)
+	return nil
+}
+
+// redactValue hides a non-empty value
+func redactValue(v interface{}) interface{} {
+	if v == nil || v == "" {
+		return v
+	}
+	return RedactedValue
+}
+
+// diff returns the top-level fields whose values differ between two JSON objects
+func diff(before, after JSON) (JSON, error) {
+	if len(before) == 0 || len(after) == 0 {
+		return nil, nil
+	}
+
+	var b, a map[string]interface{}
+	if err := json.Unmarshal(before, &b); err != nil {
+		return nil, errors.New("audit snapshot is not a JSON object")
+	}
+	if err := json.Unmarshal(after, &a); err != nil {
+		return nil, errors.New("audit snapshot is not a JSON object")
+	}
+
+	changes := map[string]FieldChange{}
+	for key, to := range a {
+		if from, ok := b[key]; !ok || !reflect.DeepEqual(from, to) {
+			changes[key] = FieldChange{From: b[key], To: to}
+		}
+	}
+	for key, from := range b {
+		if _, ok := a[key]; !ok {
+			changes[key] = FieldChange{From: from}
+		}
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return nil, err
+	}
+	return JSON(data), nil
+}
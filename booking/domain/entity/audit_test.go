@@ -0,0 +1,144 @@
+package entity
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// credentials is an audited value with a secret token, like an API client
+type credentials struct {
+	Name  string `json:"name"`
+	Token string `json:"token"`
+}
+
+func (c *credentials) AuditRedactedFields() []string {
+	return []string{"token"}
+}
+
+// decode unmarshals an audit document
+func decode(t *testing.T, doc JSON, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(doc, v); err != nil {
+		t.Fatalf("decode %s: %v", doc, err)
+	}
+}
+
+func TestAuditEntryLeavesOutPasswords(t *testing.T) {
+	before := &User{ID: 7, Email: "an@example.com", Password: "$2a$10$old-hash", PIIKeyID: "k1", FullNameIndex: "idx"}
+	after := &User{ID: 7, Email: "an@example.com", Password: "$2a$10$new-hash", PIIKeyID: "k1", FullNameIndex: "idx"}
+
+	entry, err := NewAuditEntry(1, "user.updated", "user", "7", "req-1", before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, doc := range []JSON{entry.Before, entry.After, entry.Changes} {
+		for _, secret := range []string{"hash", "password", "pii_key_id", "idx"} {
+			if strings.Contains(string(doc), secret) {
+				t.Errorf("audit document %s contains %q", doc, secret)
+			}
+		}
+	}
+
+	// A password change alone shows no change, so it is recorded as its own action
+	if len(entry.Changes) != 0 {
+		t.Errorf("changes = %s, want none", entry.Changes)
+	}
+}
+
+func TestAuditEntryRedactsPII(t *testing.T) {
+	before := &User{ID: 7, FullName: "Nguyễn Văn An", Phone: ""}
+	after := &User{ID: 7, FullName: "Nguyễn Văn Anh", Phone: "0901234567"}
+
+	entry, err := NewAuditEntry(1, "user.updated", "user", "7", "", before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, doc := range []JSON{entry.Before, entry.After, entry.Changes} {
+		if strings.Contains(string(doc), "Nguyễn") || strings.Contains(string(doc), "0901234567") {
+			t.Errorf("audit document %s holds PII", doc)
+		}
+	}
+
+	var snapshot map[string]interface{}
+	decode(t, entry.Before, &snapshot)
+	// Empty values stay, so the log shows a field being set
+	if snapshot["full_name"] != RedactedValue || snapshot["phone"] != "" {
+		t.Errorf("before = %v", snapshot)
+	}
+
+	var changes map[string]FieldChange
+	decode(t, entry.Changes, &changes)
+	if got := changes["full_name"]; got.From != RedactedValue || got.To != RedactedValue {
+		t.Errorf("full_name change = %+v", got)
+	}
+	if got := changes["phone"]; got.From != "" || got.To != RedactedValue {
+		t.Errorf("phone change = %+v", got)
+	}
+	if entry.PayloadHash != entry.ComputePayloadHash() {
+		t.Error("the payload hash was computed before redaction")
+	}
+}
+
+func TestAuditEntryRedactsTokens(t *testing.T) {
+	tests := []struct {
+		name          string
+		before, after interface{}
+	}{
+		{name: "created", after: &credentials{Name: "ci", Token: "secret-1"}},
+		{name: "rotated", before: &credentials{Name: "ci", Token: "secret-1"}, after: &credentials{Name: "ci", Token: "secret-2"}},
+		{name: "deleted", before: &credentials{Name: "ci", Token: "secret-1"}},
+		// Either side naming the field is enough
+		{name: "nil before", before: (*credentials)(nil), after: &credentials{Name: "ci", Token: "secret-1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, err := NewAuditEntry(1, "client.updated", "client", "ci", "", tt.before, tt.after)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, doc := range []JSON{entry.Before, entry.After, entry.Changes} {
+				if strings.Contains(string(doc), "secret-") {
+					t.Errorf("audit document %s holds the token", doc)
+				}
+			}
+			for _, doc := range []JSON{entry.Before, entry.After} {
+				if len(doc) == 0 {
+					continue
+				}
+				var snapshot map[string]interface{}
+				decode(t, doc, &snapshot)
+				if snapshot["token"] != RedactedValue || snapshot["name"] != "ci" {
+					t.Errorf("snapshot = %v, want the token redacted and the name kept", snapshot)
+				}
+			}
+		})
+	}
+}
+
+func TestAuditEntryHashes(t *testing.T) {
+	first, err := NewAuditEntry(1, "user.created", "user", "7", "", nil, &User{ID: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.Seal(nil)
+	second, err := NewAuditEntry(1, "user.deleted", "user", "7", "", &User{ID: 7}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second.Seal(first)
+
+	if first.Sequence != 1 || first.PrevHash != "" || second.Sequence != 2 || second.PrevHash != first.Hash {
+		t.Fatalf("entries are not linked: %+v, %+v", first, second)
+	}
+
+	// Metadata is covered by the hash, the payload by the payload hash
+	second.Action = "user.restored"
+	if second.ComputeHash() == second.Hash {
+		t.Error("the hash does not cover the action")
+	}
+	first.After = JSON(`{"id":8}`)
+	if first.ComputePayloadHash() == first.PayloadHash {
+		t.Error("the payload hash does not cover the payload")
+	}
+}
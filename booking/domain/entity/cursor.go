@@ -0,0 +1,46 @@
+package entity
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// ErrInvalidCursor is returned for a pagination cursor that cannot be used
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// UserCursor is the position of the last user on a page
+// It is handed to clients as an opaque string. The sort it was issued for
+// is recorded so that a cursor cannot be replayed against another order.
+type UserCursor struct {
+	SortBy   string `json:"s"`
+	SortDesc bool   `json:"d,omitempty"`
+	// Value is the sort key of the last user (RFC3339Nano for created_at)
+	Value string `json:"v"`
+	// ID is the backend's tie-breaker for users with an equal sort key
+	ID string `json:"id"`
+}
+
+// Encode returns the opaque string form of the cursor
+func (c *UserCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeUserCursor parses a cursor produced by Encode
+func DecodeUserCursor(s string) (*UserCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor UserCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if !IsUserSortField(cursor.SortBy) || cursor.ID == "" {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
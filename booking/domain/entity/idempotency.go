@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"time"
+)
+
+// IdempotencyStatus tracks whether the request behind an idempotency key has finished
+type IdempotencyStatus string
+
+const (
+	IdempotencyInProgress IdempotencyStatus = "in_progress"
+	IdempotencyCompleted  IdempotencyStatus = "completed"
+)
+
+// IdempotencyRecord remembers the first response to a request carrying an Idempotency-Key
+// Keys are scoped to the caller ("user:<id>", or "ip:<addr>" for anonymous
+// requests) so that two clients cannot collide or read each other's responses.
+type IdempotencyRecord struct {
+	ID             uint              `json:"id" gorm:"primaryKey"`
+	Scope          string            `json:"scope" gorm:"uniqueIndex:idx_idempotency_scope_key;not null"`
+	Key            string            `json:"key" gorm:"uniqueIndex:idx_idempotency_scope_key;not null"`
+	RequestHash    string            `json:"request_hash" gorm:"not null"`
+	Status         IdempotencyStatus `json:"status" gorm:"not null"`
+	ResponseStatus int               `json:"response_status"`
+	ContentType    string            `json:"content_type"`
+	ResponseBody   []byte            `json:"-"`
+	ExpiresAt      time.Time         `json:"expires_at" gorm:"index"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (IdempotencyRecord) TableName() string {
+	return "idempotency_records"
+}
@@ -0,0 +1,46 @@
+package entity
+
+import (
+	"time"
+)
+
+// PrivacyRequestType distinguishes data export from erasure requests
+type PrivacyRequestType string
+
+const (
+	PrivacyExport  PrivacyRequestType = "export"
+	PrivacyErasure PrivacyRequestType = "erasure"
+)
+
+// PrivacyRequestStatus tracks a privacy request through the background worker
+type PrivacyRequestStatus string
+
+const (
+	PrivacyPending    PrivacyRequestStatus = "pending"
+	PrivacyProcessing PrivacyRequestStatus = "processing"
+	// PrivacyAnonymized means personal data is gone from the primary records and
+	// the audit log redaction is waiting for in-flight observer events to settle
+	PrivacyAnonymized PrivacyRequestStatus = "anonymized"
+	PrivacyCompleted  PrivacyRequestStatus = "completed"
+	PrivacyFailed     PrivacyRequestStatus = "failed"
+	PrivacyExpired    PrivacyRequestStatus = "expired"
+)
+
+// PrivacyRequest is a user's GDPR data export or right-to-erasure request
+type PrivacyRequest struct {
+	ID          uint                 `json:"id" gorm:"primaryKey"`
+	UserID      uint                 `json:"user_id" gorm:"index;not null"`
+	Type        PrivacyRequestType   `json:"type" gorm:"not null"`
+	Status      PrivacyRequestStatus `json:"status" gorm:"index;not null"`
+	FilePath    string               `json:"-"`
+	Error       string               `json:"error,omitempty"`
+	ExpiresAt   *time.Time           `json:"expires_at,omitempty"`
+	CompletedAt *time.Time           `json:"completed_at,omitempty"`
+	CreatedAt   time.Time            `json:"created_at"`
+	UpdatedAt   time.Time            `json:"updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (PrivacyRequest) TableName() string {
+	return "privacy_requests"
+}
@@ -0,0 +1,117 @@
+package entity
+
+import (
+	"time"
+)
+
+// Permission names checked by use cases and HTTP middleware
+const (
+	PermissionProfileRead  = "profile:read"
+	PermissionProfileWrite = "profile:write"
+	PermissionUsersRead    = "users:read"
+	PermissionUsersWrite   = "users:write"
+	PermissionUsersDelete  = "users:delete"
+	PermissionRolesManage  = "roles:manage"
+	PermissionAuditRead    = "audit:read"
+)
+
+// Default role names seeded at startup
+const (
+	RoleAdmin   = "admin"
+	RoleSupport = "support"
+	RoleRunner  = "runner"
+)
+
+// Permission represents a single capability that can be granted through a role
+type Permission struct {
+	ID          uint   `json:"id" gorm:"primaryKey"`
+	Name        string `json:"name" gorm:"uniqueIndex;not null"`
+	Description string `json:"description"`
+}
+
+// TableName specifies the table name for GORM
+func (Permission) TableName() string {
+	return "permissions"
+}
+
+// Role groups permissions and is assigned to users (many-to-many on both sides)
+type Role struct {
+	ID          uint         `json:"id" gorm:"primaryKey"`
+	Name        string       `json:"name" gorm:"uniqueIndex;not null"`
+	Description string       `json:"description"`
+	Permissions []Permission `json:"permissions" gorm:"many2many:role_permissions"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (Role) TableName() string {
+	return "roles"
+}
+
+// HasPermission reports whether the role grants the named permission
+func (r *Role) HasPermission(name string) bool {
+	for _, p := range r.Permissions {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// UserRole links a user to a role and records who granted it
+type UserRole struct {
+	UserID    uint      `json:"user_id" gorm:"primaryKey"`
+	RoleID    uint      `json:"role_id" gorm:"primaryKey"`
+	GrantedBy uint      `json:"granted_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (UserRole) TableName() string {
+	return "user_roles"
+}
+
+// RoleChange describes a grant or revoke of a role, published to observers
+type RoleChange struct {
+	UserID  uint   `json:"user_id"`
+	Role    string `json:"role"`
+	ActorID uint   `json:"actor_id"`
+}
+
+// DefaultRoles returns the roles seeded on startup with their permissions
+func DefaultRoles() []*Role {
+	return []*Role{
+		{
+			Name:        RoleAdmin,
+			Description: "Full access to users and role management",
+			Permissions: permissions(
+				PermissionProfileRead, PermissionProfileWrite,
+				PermissionUsersRead, PermissionUsersWrite, PermissionUsersDelete,
+				PermissionRolesManage, PermissionAuditRead,
+			),
+		},
+		{
+			Name:        RoleSupport,
+			Description: "Customer support: read and edit user accounts",
+			Permissions: permissions(
+				PermissionProfileRead, PermissionProfileWrite,
+				PermissionUsersRead, PermissionUsersWrite,
+			),
+		},
+		{
+			Name:        RoleRunner,
+			Description: "Regular app user",
+			Permissions: permissions(PermissionProfileRead, PermissionProfileWrite),
+		},
+	}
+}
+
+// permissions builds a permission list from names
+func permissions(names ...string) []Permission {
+	result := make([]Permission, 0, len(names))
+	for _, name := range names {
+		result = append(result, Permission{Name: name})
+	}
+	return result
+}
@@ -1,7 +1,10 @@
 package entity
 
 import (
+	"fmt"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // User represents the user entity in the domain
@@ -15,6 +18,15 @@ type User struct {
 	IsActive  bool      `json:"is_active" gorm:"default:true"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	// DeletedAt marks a soft-deleted user; GORM excludes these rows from every query
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+	// PurgedAt is set once personal data has been anonymized after the grace period
+	PurgedAt *time.Time `json:"purged_at,omitempty" gorm:"index"`
+	// PIIKeyID is the master key FullName and Phone are encrypted under at rest
+	PIIKeyID string `json:"-" gorm:"index"`
+	// FullNameIndex and PhoneIndex are blind indexes for equality lookups on the encrypted columns
+	FullNameIndex string `json:"-" gorm:"index"`
+	PhoneIndex    string `json:"-" gorm:"index"`
 }
 
 // TableName specifies the table name for GORM
@@ -22,12 +34,67 @@ func (User) TableName() string {
 	return "users"
 }
 
+// AuditRedactedFields lists the fields encrypted at rest; audit entries keep
+// only whether they changed, so the log does not hold them in plaintext
+func (u *User) AuditRedactedFields() []string {
+	return []string{"full_name", "phone"}
+}
+
+// Anonymize replaces all personal data with placeholders, keeping the ID so
+// history that references the user (runs, wallet, audit) stays linked
+func (u *User) Anonymize(now time.Time) {
+	placeholder := fmt.Sprintf("purged-%d", u.ID)
+	u.Email = placeholder + "@invalid.local"
+	u.Username = placeholder
+	u.Password = ""
+	u.FullName = ""
+	u.Phone = ""
+	u.IsActive = false
+	u.PurgedAt = &now
+}
+
+// Fields users can be sorted by; ID always breaks ties
+const (
+	UserSortCreatedAt = "created_at"
+	UserSortUsername  = "username"
+	UserSortEmail     = "email"
+)
+
+// IsUserSortField reports whether field is on the sort whitelist
+// FullName and Phone are encrypted at rest, so they cannot be sorted on.
+func IsUserSortField(field string) bool {
+	switch field {
+	case UserSortCreatedAt, UserSortUsername, UserSortEmail:
+		return true
+	default:
+		return false
+	}
+}
+
 // UserFilter represents filter options for querying users
 type UserFilter struct {
 	Email    *string
 	Username *string
+	FullName *string
+	Phone    *string
 	IsActive *bool
-	Limit    int
-	Offset   int
+	// Search matches a case-insensitive prefix of username or email, or the
+	// whole full name (which is only searchable through its blind index)
+	Search *string
+	// CreatedFrom (inclusive) and CreatedTo (exclusive) bound created_at
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	// SortBy is one of the UserSort* fields; SortDesc reverses the order
+	SortBy   string
+	SortDesc bool
+	// After resumes the listing after the last user of a previous page
+	After *UserCursor
+	Limit int
 }
 
+// UserPage is one page of a user listing
+type UserPage struct {
+	Users      []*User
+	NextCursor string
+	HasMore    bool
+}
@@ -0,0 +1,71 @@
+package identity
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strconv"
+)
+
+// contextKey is an unexported type to avoid collisions with other packages
+type contextKey int
+
+const (
+	actorKey contextKey = iota
+	requestIDKey
+)
+
+// WithActor returns a copy of ctx carrying the ID of the user performing the request
+func WithActor(ctx context.Context, userID uint) context.Context {
+	return context.WithValue(ctx, actorKey, userID)
+}
+
+// ActorFromContext returns the ID of the acting user, if the request is authenticated
+func ActorFromContext(ctx context.Context) (uint, bool) {
+	userID, ok := ctx.Value(actorKey).(uint)
+	return userID, ok && userID != 0
+}
+
+// WithRequestID returns a copy of ctx carrying the request ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestIDFromContext returns the request ID, or an empty string outside a request
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// MaxRequestIDLength bounds client-supplied IDs so they can't bloat logs and audit entries
+const MaxRequestIDLength = 128
+
+// AcceptRequestID returns the caller's request ID, or a new one if it is missing or too long
+// HTTP and gRPC share it so both transports accept exactly the same values.
+func AcceptRequestID(supplied string) string {
+	if supplied == "" || len(supplied) > MaxRequestIDLength {
+		return NewRequestID()
+	}
+	return supplied
+}
+
+// NewRequestID returns a random 128-bit hex identifier
+func NewRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// ErrInvalidUserID is returned by ParseUserID for values that are not a positive user ID
+var ErrInvalidUserID = errors.New("invalid user ID")
+
+// ParseUserID parses the user ID forwarded by the API gateway
+// HTTP and gRPC share it so both transports accept exactly the same values.
+func ParseUserID(value string) (uint, error) {
+	userID, err := strconv.ParseUint(value, 10, 32)
+	if err != nil || userID == 0 {
+		return 0, ErrInvalidUserID
+	}
+	return uint(userID), nil
+}
@@ -0,0 +1,31 @@
+package identity
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAcceptRequestID(t *testing.T) {
+	if got := AcceptRequestID("req-123"); got != "req-123" {
+		t.Errorf("AcceptRequestID(req-123) = %q, want it kept", got)
+	}
+
+	maxLength := strings.Repeat("a", MaxRequestIDLength)
+	if got := AcceptRequestID(maxLength); got != maxLength {
+		t.Errorf("AcceptRequestID kept %d of %d characters", len(got), MaxRequestIDLength)
+	}
+
+	for name, supplied := range map[string]string{
+		"missing":  "",
+		"too long": maxLength + "a",
+	} {
+		got := AcceptRequestID(supplied)
+		if got == supplied || len(got) != 32 {
+			t.Errorf("%s: AcceptRequestID = %q, want a new 32 character ID", name, got)
+		}
+	}
+
+	if NewRequestID() == NewRequestID() {
+		t.Error("NewRequestID returned the same ID twice")
+	}
+}
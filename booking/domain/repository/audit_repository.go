@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"booking/domain/entity"
+	"context"
+)
+
+// AuditRepository defines the interface for the append-only audit log
+// There are deliberately no Update or Delete methods; RedactTarget is the only
+// mutation and it leaves every hash intact.
+type AuditRepository interface {
+	// Append seals the entry onto the end of the hash chain and stores it
+	Append(ctx context.Context, entry *entity.AuditEntry) error
+	List(ctx context.Context, filter *entity.AuditFilter) ([]*entity.AuditEntry, error)
+	Count(ctx context.Context, filter *entity.AuditFilter) (int64, error)
+	// ListAfter returns up to limit entries with Sequence > afterSequence, oldest first
+	ListAfter(ctx context.Context, afterSequence uint64, limit int) ([]*entity.AuditEntry, error)
+	// RedactTarget erases the before/after/changes payloads of every entry about a target
+	RedactTarget(ctx context.Context, targetType, targetID string) (int64, error)
+}
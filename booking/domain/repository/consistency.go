@@ -0,0 +1,20 @@
+package repository
+
+import "context"
+
+// readYourWritesKey is the context key of the read-your-writes flag
+type readYourWritesKey struct{}
+
+// WithReadYourWrites returns a copy of ctx whose reads must see every committed write
+// Repositories backed by read replicas serve such reads from the primary.
+// Use cases set it before reading state they are about to change or that
+// was just written, where a lagging replica would hand them stale data.
+func WithReadYourWrites(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readYourWritesKey{}, true)
+}
+
+// ReadYourWrites reports whether reads in ctx must see every committed write
+func ReadYourWrites(ctx context.Context) bool {
+	required, _ := ctx.Value(readYourWritesKey{}).(bool)
+	return required
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"booking/domain/entity"
+	"context"
+	"time"
+)
+
+// IdempotencyRepository defines the interface for idempotency key storage
+type IdempotencyRepository interface {
+	// Reserve inserts a record; it reports false if the scope and key are already taken
+	Reserve(ctx context.Context, record *entity.IdempotencyRecord) (bool, error)
+	Get(ctx context.Context, scope, key string) (*entity.IdempotencyRecord, error)
+	// Complete stores the response of a reserved record
+	Complete(ctx context.Context, record *entity.IdempotencyRecord) error
+	// Delete frees a key, e.g. after a failed request or once its record expired
+	Delete(ctx context.Context, id uint) error
+	// DeleteExpired removes records that expired before cutoff
+	DeleteExpired(ctx context.Context, cutoff time.Time) (int64, error)
+}
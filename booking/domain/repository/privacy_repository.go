@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"booking/domain/entity"
+	"context"
+	"time"
+)
+
+// PrivacyRequestRepository defines the interface for GDPR export and erasure requests
+type PrivacyRequestRepository interface {
+	Create(ctx context.Context, req *entity.PrivacyRequest) error
+	GetByID(ctx context.Context, id uint) (*entity.PrivacyRequest, error)
+	ListByUser(ctx context.Context, userID uint) ([]*entity.PrivacyRequest, error)
+	// ListByStatus returns requests of reqType in status last updated before updatedBefore, oldest first
+	// An empty reqType matches both exports and erasures.
+	ListByStatus(ctx context.Context, reqType entity.PrivacyRequestType, status entity.PrivacyRequestStatus, updatedBefore time.Time, limit int) ([]*entity.PrivacyRequest, error)
+	// Claim moves a request from one status to another; it reports false if another worker got there first
+	Claim(ctx context.Context, id uint, from, to entity.PrivacyRequestStatus) (bool, error)
+	Update(ctx context.Context, req *entity.PrivacyRequest) error
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"booking/domain/entity"
+	"context"
+)
+
+// RoleRepository defines the interface for roles, permissions and user role assignments
+type RoleRepository interface {
+	// Upsert creates the role if missing and replaces its permission set
+	Upsert(ctx context.Context, role *entity.Role) error
+	GetByName(ctx context.Context, name string) (*entity.Role, error)
+	List(ctx context.Context) ([]*entity.Role, error)
+	ListByUser(ctx context.Context, userID uint) ([]*entity.Role, error)
+	// ListByUsers retrieves the roles of several users in one round trip, keyed by user ID
+	ListByUsers(ctx context.Context, userIDs []uint) (map[uint][]*entity.Role, error)
+	AssignToUser(ctx context.Context, userID uint, roleName string, actorID uint) error
+	RevokeFromUser(ctx context.Context, userID uint, roleName string, actorID uint) error
+}
@@ -2,19 +2,40 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"time"
 	"booking/domain/entity"
 )
 
+// ErrDuplicateEmail and ErrDuplicateUsername are returned by Create, CreateBatch
+// and Update when another user holds the value, including a soft-deleted user
+// that has not been purged yet
+var (
+	ErrDuplicateEmail    = errors.New("email is held by another user")
+	ErrDuplicateUsername = errors.New("username is held by another user")
+)
+
 // UserRepository defines the interface for user data operations
 // This follows the Repository pattern and Dependency Inversion Principle
 type UserRepository interface {
 	Create(ctx context.Context, user *entity.User) error
+	// CreateBatch creates several users in one transaction; either all are stored or none
+	CreateBatch(ctx context.Context, users []*entity.User) error
 	GetByID(ctx context.Context, id uint) (*entity.User, error)
+	// GetByIDs retrieves several users in one query; IDs without a user are skipped
+	GetByIDs(ctx context.Context, ids []uint) ([]*entity.User, error)
 	GetByEmail(ctx context.Context, email string) (*entity.User, error)
 	GetByUsername(ctx context.Context, username string) (*entity.User, error)
-	List(ctx context.Context, filter *entity.UserFilter) ([]*entity.User, error)
+	// List returns one page of users in filter order, keyed on the sort field and ID
+	List(ctx context.Context, filter *entity.UserFilter) (*entity.UserPage, error)
 	Update(ctx context.Context, user *entity.User) error
+	// Delete soft-deletes a user; it stays restorable until purged
 	Delete(ctx context.Context, id uint) error
 	Count(ctx context.Context, filter *entity.UserFilter) (int64, error)
+	Restore(ctx context.Context, id uint) (*entity.User, error)
+	// ListDeletedBefore returns soft-deleted, not yet purged users deleted before cutoff
+	ListDeletedBefore(ctx context.Context, cutoff time.Time, limit int) ([]*entity.User, error)
+	// Purge stores an anonymized soft-deleted user
+	Purge(ctx context.Context, user *entity.User) error
 }
 
@@ -0,0 +1,74 @@
+// Package auth authenticates callers of the HTTP and gRPC APIs.
+package auth
+
+import (
+	"strings"
+	"time"
+
+	"booking/config"
+	"booking/domain/identity"
+)
+
+// Credentials are what a request presents to prove who it acts for
+type Credentials struct {
+	// Authorization is the Authorization header or metadata value
+	Authorization string
+	// UserID is the value of the user ID header, read only when it is trusted
+	UserID string
+}
+
+// Authenticator turns request credentials into the acting user ID
+// A bearer token signed with the token secret is always accepted. The user ID
+// header is accepted only when config opts in, because any client can set it:
+// that is safe only behind a proxy that strips it from incoming requests and
+// sets it after authenticating the caller itself.
+type Authenticator struct {
+	tokens     *Tokens
+	userHeader string
+	now        func() time.Time
+}
+
+// NewAuthenticator creates an Authenticator from the auth config
+func NewAuthenticator(cfg config.AuthConfig) *Authenticator {
+	a := &Authenticator{now: time.Now}
+	if cfg.TokenSecret != "" {
+		a.tokens = NewTokens(cfg.TokenSecret)
+	}
+	if cfg.TrustUserHeader {
+		a.userHeader = cfg.UserIDHeader
+	}
+	return a
+}
+
+// UserHeader returns the trusted user ID header, or "" if the header is not trusted
+func (a *Authenticator) UserHeader() string {
+	return a.userHeader
+}
+
+// Enabled reports whether any credential can authenticate a request
+func (a *Authenticator) Enabled() bool {
+	return a.tokens != nil || a.userHeader != ""
+}
+
+// Authenticate returns the user the credentials prove, or 0 for anonymous requests
+// A bearer token takes precedence over the user ID header. Bearer tokens are
+// ignored when no token secret is configured, since a gateway in front of the
+// API may forward tokens it has already checked.
+func (a *Authenticator) Authenticate(c Credentials) (uint, error) {
+	if token, ok := bearerToken(c.Authorization); ok && a.tokens != nil {
+		return a.tokens.Verify(token, a.now())
+	}
+	if a.userHeader == "" || c.UserID == "" {
+		return 0, nil
+	}
+	return identity.ParseUserID(c.UserID)
+}
+
+// bearerToken returns the token of a Bearer authorization value
+func bearerToken(authorization string) (string, bool) {
+	const prefix = "Bearer "
+	if len(authorization) <= len(prefix) || !strings.EqualFold(authorization[:len(prefix)], prefix) {
+		return "", false
+	}
+	return strings.TrimSpace(authorization[len(prefix):]), true
+}
@@ -0,0 +1,110 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// entry is a cached value in the LRU list
+type entry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// MemoryStore keeps up to size entries in process memory, evicting the least recently used
+// Every instance has its own copy, so invalidations only reach the instance
+// that saw the write; other instances serve their copy until the TTL expires.
+type MemoryStore struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List // front is the most recently used
+	entries map[string]*list.Element
+	now     func() time.Time
+}
+
+// NewMemoryStore creates an empty in-memory LRU store
+func NewMemoryStore(size int) *MemoryStore {
+	return &MemoryStore{
+		size:    max(size, 1),
+		order:   list.New(),
+		entries: map[string]*list.Element{},
+		now:     time.Now,
+	}
+}
+
+// Get returns the value of key and whether it was found
+func (s *MemoryStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	e := elem.Value.(*entry)
+	if !s.now().Before(e.expires) {
+		s.remove(elem)
+		return nil, false, nil
+	}
+	s.order.MoveToFront(elem)
+	return e.value, true, nil
+}
+
+// Set stores value under key for ttl
+func (s *MemoryStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expires := s.now().Add(ttl)
+	if elem, ok := s.entries[key]; ok {
+		e := elem.Value.(*entry)
+		e.value, e.expires = value, expires
+		s.order.MoveToFront(elem)
+		return nil
+	}
+
+	s.entries[key] = s.order.PushFront(&entry{key: key, value: value, expires: expires})
+	for s.order.Len() > s.size {
+		s.remove(s.order.Back())
+	}
+	return nil
+}
+
+// Delete removes keys; missing keys are ignored
+func (s *MemoryStore) Delete(ctx context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		if elem, ok := s.entries[key]; ok {
+			s.remove(elem)
+		}
+	}
+	return nil
+}
+
+// Close drops every entry
+func (s *MemoryStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.order.Init()
+	clear(s.entries)
+	return nil
+}
+
+// Len returns how many entries are stored, including expired ones not yet removed
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
+
+// remove drops an element from the list and the index
+func (s *MemoryStore) remove(elem *list.Element) {
+	s.order.Remove(elem)
+	delete(s.entries, elem.Value.(*entry).key)
+}
@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"booking/config"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// keyPrefix namespaces the cache keys in Redis
+const keyPrefix = "cache:"
+
+// RedisStore keeps entries in Redis, shared by every instance
+// It only needs GET, SET with PX and DEL, so any server speaking the Redis
+// protocol works. Redis should be configured with an eviction policy such as
+// allkeys-lru if it is not dedicated to the cache.
+type RedisStore struct {
+	client redis.UniversalClient
+}
+
+// NewRedisStore creates a store on an existing Redis client
+func NewRedisStore(client redis.UniversalClient) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+// NewRedisStoreFromConfig connects to the Redis server in config
+func NewRedisStoreFromConfig(cfg config.CacheConfig) (*RedisStore, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.RedisAddr,
+		Password: cfg.RedisPassword,
+		DB:       cfg.RedisDB,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to Redis at %s: %w", cfg.RedisAddr, err)
+	}
+
+	return NewRedisStore(client), nil
+}
+
+// Get returns the value of key and whether it was found
+func (s *RedisStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := s.client.Get(ctx, keyPrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// Set stores value under key for ttl
+func (s *RedisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return s.client.Set(ctx, keyPrefix+key, value, ttl).Err()
+}
+
+// Delete removes keys; missing keys are ignored
+func (s *RedisStore) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = keyPrefix + key
+	}
+	return s.client.Del(ctx, prefixed...).Err()
+}
+
+// Close closes the Redis client
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,46 @@
+// Package cache keeps copies of frequently read records in front of the repositories
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"booking/config"
+)
+
+// StoreType represents the backend keeping cached entries
+type StoreType string
+
+const (
+	OffStoreType    StoreType = "off"
+	MemoryStoreType StoreType = "memory"
+	RedisStoreType  StoreType = "redis"
+)
+
+// Store keeps entries until their TTL expires or they are deleted
+// Errors are returned rather than treated as misses, so callers decide
+// whether a broken cache may fail a request.
+type Store interface {
+	// Get returns the value of key and whether it was found
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+	Close() error
+}
+
+// NewStore creates the store selected in config
+func NewStore(cfg config.CacheConfig) (Store, error) {
+	switch StoreType(cfg.Store) {
+	case MemoryStoreType:
+		// Writes reach only the instance that made them; the others would serve stale users
+		if cfg.MultiInstance {
+			return nil, fmt.Errorf("cache store %s is per instance: set CACHE_STORE=redis or off when CACHE_MULTI_INSTANCE is true", cfg.Store)
+		}
+		return NewMemoryStore(cfg.Size), nil
+	case RedisStoreType:
+		return NewRedisStoreFromConfig(cfg)
+	default:
+		return nil, fmt.Errorf("unsupported cache store: %s", cfg.Store)
+	}
+}
@@ -0,0 +1,558 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"booking/domain/entity"
+	"booking/domain/repository"
+	"booking/infrastructure/encryption"
+	"booking/infrastructure/logging"
+	"booking/infrastructure/metrics"
+	"booking/infrastructure/observer"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/sync/singleflight"
+	"gorm.io/gorm"
+)
+
+// cacheName labels the lookups of the user cache in metrics
+const cacheName = "users"
+
+// Lookup kinds; each is a key namespace and a metrics label
+const (
+	lookupID       = "id"
+	lookupEmail    = "email"
+	lookupUsername = "username"
+)
+
+// Field names bound into the ciphertext of cached PII, distinct from the database columns
+const (
+	cachedFullName = "cache.users.full_name"
+	cachedPhone    = "cache.users.phone"
+)
+
+// invalidateTimeout bounds invalidations triggered by events, which carry no request context
+const invalidateTimeout = 5 * time.Second
+
+// notFoundValue marks a lookup that found no user; an encoded user never equals it
+var notFoundValue = []byte("-")
+
+// UserRepository caches user lookups by ID, email and username in front of another UserRepository
+// Decorator Pattern: it implements repository.UserRepository, so use cases do
+// not know whether they talk to the cache or the database.
+//
+// A user found by one lookup is stored under all three keys. Lookups that
+// find nothing are remembered for the negative TTL, so probing for unknown
+// emails does not reach the database either. Concurrent misses of the same
+// key share one query. Writes through the decorator invalidate the user's
+// keys right away; writes elsewhere in the process are invalidated by the
+// observer returned from Invalidator. Whatever is missed, e.g. a write by
+// another instance with a per-instance store, is stale for at most the TTL.
+//
+// Misses are loaded from the primary database even when read replicas are
+// configured: a lagging replica would otherwise put a user back into the
+// cache right after a write invalidated it, for a whole TTL. Contexts that
+// demand read-your-writes skip the cache, so use cases changing a user start
+// from its latest state.
+//
+// Cached users carry no password hash, and their full name and phone are
+// sealed with the PII field encryptor, so the store never holds more than
+// the database does. Users read from the cache therefore have an empty
+// Password; Update fills it in from the primary when a caller passes one.
+type UserRepository struct {
+	next        repository.UserRepository
+	store       Store
+	encryptor   *encryption.FieldEncryptor
+	ttl         time.Duration
+	negativeTTL time.Duration
+	notFound    error
+	metrics     *metrics.Metrics
+	logger      *slog.Logger
+	group       singleflight.Group
+	// generation changes with every write; a lookup that raced with one does not store its result
+	generation atomic.Uint64
+}
+
+// UserRepositoryOption configures a UserRepository
+type UserRepositoryOption func(*UserRepository)
+
+// WithTTL sets how long found users are cached
+func WithTTL(ttl time.Duration) UserRepositoryOption {
+	return func(r *UserRepository) {
+		r.ttl = ttl
+	}
+}
+
+// WithNegativeTTL sets how long lookups that found no user are cached; 0 disables negative caching
+func WithNegativeTTL(ttl time.Duration) UserRepositoryOption {
+	return func(r *UserRepository) {
+		r.negativeTTL = ttl
+	}
+}
+
+// WithNotFoundError sets the error returned for cached misses
+// It should be the error the wrapped repository returns for missing users.
+func WithNotFoundError(err error) UserRepositoryOption {
+	return func(r *UserRepository) {
+		r.notFound = err
+	}
+}
+
+// WithMetrics counts hits and misses; nil disables instrumentation
+func WithMetrics(m *metrics.Metrics) UserRepositoryOption {
+	return func(r *UserRepository) {
+		r.metrics = m
+	}
+}
+
+// NewUserRepository wraps next with a cache kept in store
+// encryptor seals the PII of cached users; pass the one next encrypts with.
+func NewUserRepository(next repository.UserRepository, store Store, encryptor *encryption.FieldEncryptor, opts ...UserRepositoryOption) *UserRepository {
+	r := &UserRepository{
+		next:        next,
+		store:       store,
+		encryptor:   encryptor,
+		ttl:         time.Minute,
+		negativeTTL: 5 * time.Second,
+		notFound:    gorm.ErrRecordNotFound,
+		logger:      logging.For("cache"),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Create creates a user and forgets earlier lookups that did not find it
+func (r *UserRepository) Create(ctx context.Context, user *entity.User) error {
+	r.generation.Add(1)
+	if err := r.next.Create(ctx, user); err != nil {
+		return err
+	}
+	r.invalidate(ctx, user)
+	return nil
+}
+
+// CreateBatch creates several users and forgets earlier lookups that did not find them
+func (r *UserRepository) CreateBatch(ctx context.Context, users []*entity.User) error {
+	r.generation.Add(1)
+	if err := r.next.CreateBatch(ctx, users); err != nil {
+		return err
+	}
+	r.invalidate(ctx, users...)
+	return nil
+}
+
+// GetByID retrieves a user by ID
+func (r *UserRepository) GetByID(ctx context.Context, id uint) (*entity.User, error) {
+	return r.lookup(ctx, lookupID, formatID(id), func(ctx context.Context) (*entity.User, error) {
+		return r.next.GetByID(ctx, id)
+	})
+}
+
+// GetByIDs retrieves several users, querying only those not cached
+// IDs cached as missing are skipped like IDs the database has no user for.
+func (r *UserRepository) GetByIDs(ctx context.Context, ids []uint) ([]*entity.User, error) {
+	users := make([]*entity.User, 0, len(ids))
+	seen := make(map[uint]bool, len(ids))
+	var missing []uint
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		user, found, ok := r.cached(ctx, lookupID, formatID(id))
+		switch {
+		case !ok:
+			missing = append(missing, id)
+		case found:
+			users = append(users, user)
+		}
+	}
+	if len(missing) == 0 {
+		return users, nil
+	}
+
+	generation := r.generation.Load()
+	loaded, err := r.next.GetByIDs(repository.WithReadYourWrites(ctx), missing)
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range loaded {
+		r.fill(ctx, generation, user)
+	}
+	return append(users, loaded...), nil
+}
+
+// GetByEmail retrieves a user by email
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
+	return r.lookup(ctx, lookupEmail, email, func(ctx context.Context) (*entity.User, error) {
+		return r.next.GetByEmail(ctx, email)
+	})
+}
+
+// GetByUsername retrieves a user by username
+func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*entity.User, error) {
+	return r.lookup(ctx, lookupUsername, username, func(ctx context.Context) (*entity.User, error) {
+		return r.next.GetByUsername(ctx, username)
+	})
+}
+
+// List is not cached; pages depend on every user
+func (r *UserRepository) List(ctx context.Context, filter *entity.UserFilter) (*entity.UserPage, error) {
+	return r.next.List(ctx, filter)
+}
+
+// Update updates a user and invalidates its keys, including an email or username it had before
+// A user without a password hash, e.g. one read from the cache, keeps the
+// hash stored in the primary instead of having it cleared.
+func (r *UserRepository) Update(ctx context.Context, user *entity.User) error {
+	if user.Password == "" {
+		stored, err := r.next.GetByID(repository.WithReadYourWrites(ctx), user.ID)
+		if err != nil {
+			return err
+		}
+		user.Password = stored.Password
+	}
+
+	before := r.peek(ctx, user.ID)
+	r.generation.Add(1)
+	if err := r.next.Update(ctx, user); err != nil {
+		return err
+	}
+	r.invalidate(ctx, user, before)
+	return nil
+}
+
+// Delete soft-deletes a user and invalidates its keys
+func (r *UserRepository) Delete(ctx context.Context, id uint) error {
+	before := r.peek(ctx, id)
+	r.generation.Add(1)
+	if err := r.next.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.invalidate(ctx, &entity.User{ID: id}, before)
+	return nil
+}
+
+// Count is not cached
+func (r *UserRepository) Count(ctx context.Context, filter *entity.UserFilter) (int64, error) {
+	return r.next.Count(ctx, filter)
+}
+
+// Restore restores a soft-deleted user and forgets that it was missing
+func (r *UserRepository) Restore(ctx context.Context, id uint) (*entity.User, error) {
+	r.generation.Add(1)
+	user, err := r.next.Restore(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	r.invalidate(ctx, user)
+	return user, nil
+}
+
+// ListDeletedBefore is not cached; deleted users are never cached
+func (r *UserRepository) ListDeletedBefore(ctx context.Context, cutoff time.Time, limit int) ([]*entity.User, error) {
+	return r.next.ListDeletedBefore(ctx, cutoff, limit)
+}
+
+// Purge stores an anonymized user and invalidates its keys
+func (r *UserRepository) Purge(ctx context.Context, user *entity.User) error {
+	before := r.peek(ctx, user.ID)
+	r.generation.Add(1)
+	if err := r.next.Purge(ctx, user); err != nil {
+		return err
+	}
+	r.invalidate(ctx, user, before)
+	return nil
+}
+
+// Invalidator returns the observer that invalidates users changed by other writers
+// Attach it to the subject the wrapped repositories notify.
+func (r *UserRepository) Invalidator() observer.Observer {
+	return userInvalidator{repo: r}
+}
+
+// userInvalidator drops the cached copies of users named in user events
+type userInvalidator struct {
+	repo *UserRepository
+}
+
+// Update implements the Observer interface
+// Events of writes through the decorator arrive after it has already
+// invalidated the keys; deleting them again is harmless.
+func (i userInvalidator) Update(event observer.Event) {
+	switch event.Type {
+	case observer.UserCreated, observer.UserUpdated, observer.UserDeleted, observer.UserRestored, observer.UserPurged:
+	default:
+		return
+	}
+
+	user, _ := event.Data.(*entity.User)
+	before, _ := event.Before.(*entity.User)
+
+	ctx, cancel := context.WithTimeout(context.Background(), invalidateTimeout)
+	defer cancel()
+	i.repo.generation.Add(1)
+	i.repo.invalidate(ctx, user, before)
+}
+
+// lookup returns the cached user under kind/value, or loads and caches it
+func (r *UserRepository) lookup(ctx context.Context, kind, value string, load func(context.Context) (*entity.User, error)) (*entity.User, error) {
+	if user, found, ok := r.cached(ctx, kind, value); ok {
+		if !found {
+			return nil, r.notFound
+		}
+		return user, nil
+	}
+
+	// Concurrent misses share one query. It runs without the caller's
+	// cancelation, so one caller giving up does not fail the others.
+	key := userKey(kind, value)
+	ch := r.group.DoChan(key, func() (interface{}, error) {
+		ctx := repository.WithReadYourWrites(context.WithoutCancel(ctx))
+		generation := r.generation.Load()
+		user, err := load(ctx)
+		if err != nil {
+			if isNotFound(err) && r.negativeTTL > 0 && r.generation.Load() == generation {
+				r.set(ctx, key, notFoundValue, r.negativeTTL)
+			}
+			return nil, err
+		}
+		r.fill(ctx, generation, user)
+		return user, nil
+	})
+
+	select {
+	case result := <-ch:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		// Every caller gets its own copy to modify
+		return cloneUser(result.Val.(*entity.User)), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// cached looks kind/value up in the store
+// ok reports whether the store answered; found whether it holds a user
+// rather than a cached miss. Store errors count as misses.
+func (r *UserRepository) cached(ctx context.Context, kind, value string) (user *entity.User, found, ok bool) {
+	if repository.ReadYourWrites(ctx) {
+		r.metrics.ObserveCacheLookup(cacheName, kind, "bypass")
+		return nil, false, false
+	}
+
+	data, ok, err := r.store.Get(ctx, userKey(kind, value))
+	switch {
+	case err != nil:
+		r.metrics.ObserveCacheLookup(cacheName, kind, "error")
+		r.logger.LogAttrs(ctx, slog.LevelWarn, "cache read failed", slog.String("lookup", kind), slog.Any("error", err))
+		return nil, false, false
+	case !ok:
+	case bytes.Equal(data, notFoundValue):
+		r.metrics.ObserveCacheLookup(cacheName, kind, "negative_hit")
+		return nil, false, true
+	default:
+		// An entry that does not decode, e.g. from an older version or under a retired key, is refetched
+		if user, err := r.decode(data); err == nil {
+			r.metrics.ObserveCacheLookup(cacheName, kind, "hit")
+			return user, true, true
+		}
+	}
+	r.metrics.ObserveCacheLookup(cacheName, kind, "miss")
+	return nil, false, false
+}
+
+// peek returns the cached user with id without counting a lookup, or nil
+// Writes use it to learn the email and username keys of the old state.
+func (r *UserRepository) peek(ctx context.Context, id uint) *entity.User {
+	data, ok, err := r.store.Get(ctx, userKey(lookupID, formatID(id)))
+	if err != nil || !ok || bytes.Equal(data, notFoundValue) {
+		return nil
+	}
+	user, err := r.decode(data)
+	if err != nil {
+		return nil
+	}
+	return user
+}
+
+// fill stores user under all its keys, unless a write happened since generation was read
+func (r *UserRepository) fill(ctx context.Context, generation uint64, user *entity.User) {
+	if r.generation.Load() != generation {
+		return
+	}
+	data, err := r.encode(user)
+	if err != nil {
+		r.logger.LogAttrs(ctx, slog.LevelWarn, "cache encode failed", slog.Uint64("user_id", uint64(user.ID)), slog.Any("error", err))
+		return
+	}
+	for _, key := range userKeys(user) {
+		r.set(ctx, key, data, r.ttl)
+	}
+}
+
+// set stores a value, logging failures; the lookup has its answer either way
+func (r *UserRepository) set(ctx context.Context, key string, value []byte, ttl time.Duration) {
+	if err := r.store.Set(ctx, key, value, ttl); err != nil {
+		r.logger.LogAttrs(ctx, slog.LevelWarn, "cache write failed", slog.Any("error", err))
+	}
+}
+
+// invalidate deletes the keys of users; nil users are skipped
+func (r *UserRepository) invalidate(ctx context.Context, users ...*entity.User) {
+	var keys []string
+	for _, user := range users {
+		if user != nil {
+			keys = append(keys, userKeys(user)...)
+		}
+	}
+	if len(keys) == 0 {
+		return
+	}
+
+	// Lookups started from now on must not join a query that may predate the write
+	r.generation.Add(1)
+	for _, key := range keys {
+		r.group.Forget(key)
+	}
+	if err := r.store.Delete(ctx, keys...); err != nil {
+		r.logger.LogAttrs(ctx, slog.LevelError, "cache invalidation failed, entries stay stale until they expire",
+			slog.Int("keys", len(keys)),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// userKey is the store key of a lookup
+func userKey(kind, value string) string {
+	return "user:" + kind + ":" + value
+}
+
+// userKeys are the keys a user is cached under; empty email or username are skipped
+func userKeys(user *entity.User) []string {
+	keys := []string{userKey(lookupID, formatID(user.ID))}
+	if user.Email != "" {
+		keys = append(keys, userKey(lookupEmail, user.Email))
+	}
+	if user.Username != "" {
+		keys = append(keys, userKey(lookupUsername, user.Username))
+	}
+	return keys
+}
+
+// formatID formats a user ID for a key
+func formatID(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+// isNotFound reports whether err is a not-found error from either database backend
+func isNotFound(err error) bool {
+	return errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, mongo.ErrNoDocuments)
+}
+
+// cachedUser is the stored form of a user
+// The password hash is left out, and the full name and phone are sealed.
+// entity.User hides the encryption fields from JSON, so they are listed here
+// to come back unchanged.
+type cachedUser struct {
+	ID            uint       `json:"id"`
+	Email         string     `json:"email"`
+	Username      string     `json:"username"`
+	FullName      string     `json:"full_name"`
+	Phone         string     `json:"phone"`
+	IsActive      bool       `json:"is_active"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	DeletedAt     *time.Time `json:"deleted_at,omitempty"`
+	PurgedAt      *time.Time `json:"purged_at,omitempty"`
+	PIIKeyID      string     `json:"pii_key_id,omitempty"`
+	FullNameIndex string     `json:"full_name_index,omitempty"`
+	PhoneIndex    string     `json:"phone_index,omitempty"`
+}
+
+// encode serializes a user for the store
+func (r *UserRepository) encode(user *entity.User) ([]byte, error) {
+	fullName, err := r.encryptor.Encrypt(cachedFullName, user.FullName)
+	if err != nil {
+		return nil, err
+	}
+	phone, err := r.encryptor.Encrypt(cachedPhone, user.Phone)
+	if err != nil {
+		return nil, err
+	}
+
+	cached := cachedUser{
+		ID:            user.ID,
+		Email:         user.Email,
+		Username:      user.Username,
+		FullName:      fullName,
+		Phone:         phone,
+		IsActive:      user.IsActive,
+		CreatedAt:     user.CreatedAt,
+		UpdatedAt:     user.UpdatedAt,
+		PurgedAt:      user.PurgedAt,
+		PIIKeyID:      user.PIIKeyID,
+		FullNameIndex: user.FullNameIndex,
+		PhoneIndex:    user.PhoneIndex,
+	}
+	if user.DeletedAt.Valid {
+		cached.DeletedAt = &user.DeletedAt.Time
+	}
+	return json.Marshal(cached)
+}
+
+// decode restores a user serialized by encode; its Password is empty
+func (r *UserRepository) decode(data []byte) (*entity.User, error) {
+	var cached cachedUser
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return nil, err
+	}
+	fullName, err := r.encryptor.Decrypt(cachedFullName, cached.FullName)
+	if err != nil {
+		return nil, err
+	}
+	phone, err := r.encryptor.Decrypt(cachedPhone, cached.Phone)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &entity.User{
+		ID:            cached.ID,
+		Email:         cached.Email,
+		Username:      cached.Username,
+		FullName:      fullName,
+		Phone:         phone,
+		IsActive:      cached.IsActive,
+		CreatedAt:     cached.CreatedAt,
+		UpdatedAt:     cached.UpdatedAt,
+		PurgedAt:      cached.PurgedAt,
+		PIIKeyID:      cached.PIIKeyID,
+		FullNameIndex: cached.FullNameIndex,
+		PhoneIndex:    cached.PhoneIndex,
+	}
+	if cached.DeletedAt != nil {
+		user.DeletedAt = gorm.DeletedAt{Time: *cached.DeletedAt, Valid: true}
+	}
+	return user, nil
+}
+
+// cloneUser copies a user so callers sharing a query result cannot affect each other
+func cloneUser(user *entity.User) *entity.User {
+	clone := *user
+	if user.PurgedAt != nil {
+		purgedAt := *user.PurgedAt
+		clone.PurgedAt = &purgedAt
+	}
+	return &clone
+}
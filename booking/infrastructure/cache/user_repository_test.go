@@ -0,0 +1,149 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"booking/config"
+	"booking/domain/entity"
+	"booking/domain/repository"
+	"booking/infrastructure/encryption"
+
+	"gorm.io/gorm"
+)
+
+// fakeUsers serves one user by ID and records updates
+type fakeUsers struct {
+	repository.UserRepository
+	user    entity.User
+	gets    int
+	updated *entity.User
+}
+
+func (f *fakeUsers) GetByID(ctx context.Context, id uint) (*entity.User, error) {
+	f.gets++
+	if id != f.user.ID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	user := f.user
+	return &user, nil
+}
+
+func (f *fakeUsers) Update(ctx context.Context, user *entity.User) error {
+	f.updated = cloneUser(user)
+	return nil
+}
+
+// newEncryptor returns an enabled encryptor whose keys are all the given byte
+func newEncryptor(t *testing.T, b byte) *encryption.FieldEncryptor {
+	t.Helper()
+	key := bytes.Repeat([]byte{b}, 32)
+	encryptor, err := encryption.NewFieldEncryptor(map[string][]byte{"k1": key}, "k1", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return encryptor
+}
+
+func newFakeUsers() *fakeUsers {
+	return &fakeUsers{user: entity.User{
+		ID:       1,
+		Email:    "an@example.com",
+		Username: "an",
+		Password: "$2a$10$storedhash",
+		FullName: "Nguyen Van An",
+		Phone:    "0901234567",
+		IsActive: true,
+	}}
+}
+
+func TestCachedUsersHoldNoPasswordOrPlainPII(t *testing.T) {
+	ctx := context.Background()
+	next := newFakeUsers()
+	store := NewMemoryStore(10)
+	users := NewUserRepository(next, store, newEncryptor(t, 1))
+
+	if _, err := users.GetByID(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	data, found, err := store.Get(ctx, "user:id:1")
+	if err != nil || !found {
+		t.Fatalf("user not cached: found=%v err=%v", found, err)
+	}
+	for _, secret := range []string{next.user.Password, next.user.FullName, next.user.Phone} {
+		if bytes.Contains(data, []byte(secret)) {
+			t.Errorf("cached entry contains %q", secret)
+		}
+	}
+
+	user, err := users.GetByID(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.gets != 1 {
+		t.Errorf("repository queried %d times, want the second lookup served from the cache", next.gets)
+	}
+	if user.FullName != next.user.FullName || user.Phone != next.user.Phone {
+		t.Errorf("cached user PII = %q, %q, want it decrypted", user.FullName, user.Phone)
+	}
+	if user.Password != "" {
+		t.Errorf("cached user password = %q, want empty", user.Password)
+	}
+}
+
+func TestUndecryptableEntriesAreRefetched(t *testing.T) {
+	ctx := context.Background()
+	next := newFakeUsers()
+	store := NewMemoryStore(10)
+
+	// An entry sealed under a key this instance no longer has
+	if _, err := NewUserRepository(next, store, newEncryptor(t, 1)).GetByID(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	user, err := NewUserRepository(next, store, newEncryptor(t, 2)).GetByID(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.gets != 2 || user.FullName != next.user.FullName {
+		t.Errorf("gets = %d, full name = %q, want the user refetched", next.gets, user.FullName)
+	}
+}
+
+func TestUpdateKeepsPrimaryPasswordHash(t *testing.T) {
+	ctx := context.Background()
+	next := newFakeUsers()
+	users := NewUserRepository(next, NewMemoryStore(10), newEncryptor(t, 1))
+
+	cached, err := users.GetByID(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cached.FullName = "Nguyen Van Binh"
+	if err := users.Update(ctx, cached); err != nil {
+		t.Fatal(err)
+	}
+	if next.updated.Password != next.user.Password {
+		t.Errorf("updated password = %q, want the primary's hash kept", next.updated.Password)
+	}
+
+	changed := cloneUser(cached)
+	changed.Password = "$2a$10$newhash"
+	if err := users.Update(ctx, changed); err != nil {
+		t.Fatal(err)
+	}
+	if next.updated.Password != "$2a$10$newhash" {
+		t.Errorf("updated password = %q, want the caller's hash", next.updated.Password)
+	}
+}
+
+func TestNewStoreRefusesMemoryForMultipleInstances(t *testing.T) {
+	if _, err := NewStore(config.CacheConfig{Store: "memory", Size: 10, MultiInstance: true}); err == nil {
+		t.Error("NewStore accepted the memory store with CACHE_MULTI_INSTANCE")
+	}
+	store, err := NewStore(config.CacheConfig{Store: "memory", Size: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+}
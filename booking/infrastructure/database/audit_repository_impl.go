@@ -0,0 +1,139 @@
+package database
+
+import (
+	"booking/domain/entity"
+	"booking/domain/repository"
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// auditChainLockKey is the Postgres advisory lock serializing appends to the audit chain
+const auditChainLockKey = 0x61756469 // "audi"
+
+// auditRepositoryImpl implements the AuditRepository interface with GORM
+type auditRepositoryImpl struct {
+	// db is the primary; database routes the reads that may go to a replica
+	db       *gorm.DB
+	database *Database
+}
+
+// NewAuditRepository creates a new audit repository
+// Browsing the log reads from replicas; appends, chain verification and redaction use the primary.
+func NewAuditRepository(db *Database) repository.AuditRepository {
+	return &auditRepositoryImpl{db: db.DB, database: db}
+}
+
+// Append seals the entry onto the end of the hash chain and stores it
+func (r *auditRepositoryImpl) Append(ctx context.Context, entry *entity.AuditEntry) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Serialize appends across all API instances so the chain never forks
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", auditChainLockKey).Error; err != nil {
+			return err
+		}
+
+		var last entity.AuditEntry
+		err := tx.Order("sequence DESC").First(&last).Error
+		switch {
+		case err == nil:
+			entry.Seal(&last)
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			entry.Seal(nil)
+		default:
+			return err
+		}
+
+		return tx.Create(entry).Error
+	})
+}
+
+// List retrieves audit entries based on filter, newest first
+func (r *auditRepositoryImpl) List(ctx context.Context, filter *entity.AuditFilter) ([]*entity.AuditEntry, error) {
+	var entries []*entity.AuditEntry
+	query := applyAuditFilter(r.database.Reader(ctx, "audit.list"), filter).Order("sequence DESC")
+
+	if filter != nil {
+		if filter.Limit > 0 {
+			query = query.Limit(filter.Limit)
+		}
+		if filter.Offset > 0 {
+			query = query.Offset(filter.Offset)
+		}
+	}
+
+	if err := query.Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// Count counts audit entries based on filter
+func (r *auditRepositoryImpl) Count(ctx context.Context, filter *entity.AuditFilter) (int64, error) {
+	var count int64
+	query := applyAuditFilter(r.database.Reader(ctx, "audit.count").Model(&entity.AuditEntry{}), filter)
+
+	if err := query.Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// ListAfter returns up to limit entries with Sequence > afterSequence, oldest first
+func (r *auditRepositoryImpl) ListAfter(ctx context.Context, afterSequence uint64, limit int) ([]*entity.AuditEntry, error) {
+	var entries []*entity.AuditEntry
+	err := r.db.WithContext(ctx).
+		Where("sequence > ?", afterSequence).
+		Order("sequence ASC").
+		Limit(limit).
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// RedactTarget erases the before/after/changes payloads of every entry about a target
+func (r *auditRepositoryImpl) RedactTarget(ctx context.Context, targetType, targetID string) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&entity.AuditEntry{}).
+		Where("target_type = ? AND target_id = ? AND redacted = ?", targetType, targetID, false).
+		Updates(map[string]interface{}{
+			"before":   nil,
+			"after":    nil,
+			"changes":  nil,
+			"redacted": true,
+		})
+	return result.RowsAffected, result.Error
+}
+
+// applyAuditFilter adds the filter's conditions to query
+func applyAuditFilter(query *gorm.DB, filter *entity.AuditFilter) *gorm.DB {
+	if filter == nil {
+		return query
+	}
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.Action != nil {
+		query = query.Where("action = ?", *filter.Action)
+	}
+	if filter.TargetType != nil {
+		query = query.Where("target_type = ?", *filter.TargetType)
+	}
+	if filter.TargetID != nil {
+		query = query.Where("target_id = ?", *filter.TargetID)
+	}
+	if filter.RequestID != nil {
+		query = query.Where("request_id = ?", *filter.RequestID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	return query
+}
@@ -0,0 +1,248 @@
+package database
+
+import (
+	"booking/domain/entity"
+	"booking/domain/repository"
+	"context"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxAuditAppendAttempts bounds retries when another instance wins the race for a sequence
+const maxAuditAppendAttempts = 5
+
+// MongoAuditEntry represents the audit entry document in MongoDB
+// The sequence doubles as _id, so the unique primary key rejects a forked chain.
+type MongoAuditEntry struct {
+	Sequence    uint64    `bson:"_id"`
+	ActorID     uint      `bson:"actor_id"`
+	Action      string    `bson:"action"`
+	TargetType  string    `bson:"target_type"`
+	TargetID    string    `bson:"target_id"`
+	RequestID   string    `bson:"request_id"`
+	Before      string    `bson:"before,omitempty"`
+	After       string    `bson:"after,omitempty"`
+	Changes     string    `bson:"changes,omitempty"`
+	PayloadHash string    `bson:"payload_hash"`
+	Redacted    bool      `bson:"redacted,omitempty"`
+	PrevHash    string    `bson:"prev_hash"`
+	Hash        string    `bson:"hash"`
+	CreatedAt   time.Time `bson:"created_at"`
+}
+
+// auditRepositoryMongo implements the AuditRepository interface for MongoDB
+type auditRepositoryMongo struct {
+	collection *mongo.Collection
+	// mu avoids needless duplicate-key retries between goroutines of this process
+	mu sync.Mutex
+}
+
+// NewAuditRepositoryMongo creates a new MongoDB audit repository
+func NewAuditRepositoryMongo(db *MongoDB) repository.AuditRepository {
+	collection := db.GetCollection("audit_entries")
+
+	// Create indexes
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "actor_id", Value: 1}}},
+		{Keys: bson.D{{Key: "action", Value: 1}}},
+		{Keys: bson.D{{Key: "target_type", Value: 1}, {Key: "target_id", Value: 1}}},
+		{Keys: bson.D{{Key: "request_id", Value: 1}}},
+		{Keys: bson.D{{Key: "created_at", Value: 1}}},
+	})
+
+	return &auditRepositoryMongo{collection: collection}
+}
+
+// toEntity converts MongoAuditEntry to entity.AuditEntry
+func (m *MongoAuditEntry) toEntity() *entity.AuditEntry {
+	return &entity.AuditEntry{
+		ID:          uint(m.Sequence),
+		Sequence:    m.Sequence,
+		ActorID:     m.ActorID,
+		Action:      m.Action,
+		TargetType:  m.TargetType,
+		TargetID:    m.TargetID,
+		RequestID:   m.RequestID,
+		Before:      jsonOrNil(m.Before),
+		After:       jsonOrNil(m.After),
+		Changes:     jsonOrNil(m.Changes),
+		PayloadHash: m.PayloadHash,
+		Redacted:    m.Redacted,
+		PrevHash:    m.PrevHash,
+		Hash:        m.Hash,
+		CreatedAt:   m.CreatedAt.UTC(),
+	}
+}
+
+// fromAuditEntity converts entity.AuditEntry to MongoAuditEntry
+func fromAuditEntity(e *entity.AuditEntry) *MongoAuditEntry {
+	return &MongoAuditEntry{
+		Sequence:    e.Sequence,
+		ActorID:     e.ActorID,
+		Action:      e.Action,
+		TargetType:  e.TargetType,
+		TargetID:    e.TargetID,
+		RequestID:   e.RequestID,
+		Before:      string(e.Before),
+		After:       string(e.After),
+		Changes:     string(e.Changes),
+		PayloadHash: e.PayloadHash,
+		Redacted:    e.Redacted,
+		PrevHash:    e.PrevHash,
+		Hash:        e.Hash,
+		CreatedAt:   e.CreatedAt,
+	}
+}
+
+// Append seals the entry onto the end of the hash chain and stores it
+func (r *auditRepositoryMongo) Append(ctx context.Context, entry *entity.AuditEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var err error
+	for attempt := 0; attempt < maxAuditAppendAttempts; attempt++ {
+		var last MongoAuditEntry
+		findErr := r.collection.FindOne(ctx, bson.M{}, options.FindOne().SetSort(bson.D{{Key: "_id", Value: -1}})).Decode(&last)
+		switch {
+		case findErr == nil:
+			entry.Seal(last.toEntity())
+		case findErr == mongo.ErrNoDocuments:
+			entry.Seal(nil)
+		default:
+			return findErr
+		}
+
+		_, err = r.collection.InsertOne(ctx, fromAuditEntity(entry))
+		if err == nil {
+			entry.ID = uint(entry.Sequence)
+			return nil
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			return err
+		}
+		// Another instance appended first; re-read the tail and try again
+	}
+
+	return err
+}
+
+// List retrieves audit entries based on filter, newest first
+func (r *auditRepositoryMongo) List(ctx context.Context, filter *entity.AuditFilter) ([]*entity.AuditEntry, error) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}})
+	if filter != nil {
+		if filter.Limit > 0 {
+			findOptions.SetLimit(int64(filter.Limit))
+		}
+		if filter.Offset > 0 {
+			findOptions.SetSkip(int64(filter.Offset))
+		}
+	}
+
+	return r.find(ctx, buildAuditFilter(filter), findOptions)
+}
+
+// Count counts audit entries based on filter
+func (r *auditRepositoryMongo) Count(ctx context.Context, filter *entity.AuditFilter) (int64, error) {
+	return r.collection.CountDocuments(ctx, buildAuditFilter(filter))
+}
+
+// ListAfter returns up to limit entries with Sequence > afterSequence, oldest first
+func (r *auditRepositoryMongo) ListAfter(ctx context.Context, afterSequence uint64, limit int) ([]*entity.AuditEntry, error) {
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(int64(limit))
+
+	return r.find(ctx, bson.M{"_id": bson.M{"$gt": afterSequence}}, findOptions)
+}
+
+// RedactTarget erases the before/after/changes payloads of every entry about a target
+func (r *auditRepositoryMongo) RedactTarget(ctx context.Context, targetType, targetID string) (int64, error) {
+	result, err := r.collection.UpdateMany(
+		ctx,
+		bson.M{"target_type": targetType, "target_id": targetID, "redacted": bson.M{"$ne": true}},
+		bson.M{
+			"$unset": bson.M{"before": "", "after": "", "changes": ""},
+			"$set":   bson.M{"redacted": true},
+		},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// find runs an audit query and converts the documents
+func (r *auditRepositoryMongo) find(ctx context.Context, filter bson.M, findOptions *options.FindOptions) ([]*entity.AuditEntry, error) {
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	entries := []*entity.AuditEntry{}
+	for cursor.Next(ctx) {
+		var doc MongoAuditEntry
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		entries = append(entries, doc.toEntity())
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// buildAuditFilter converts an AuditFilter to a MongoDB query
+func buildAuditFilter(filter *entity.AuditFilter) bson.M {
+	mongoFilter := bson.M{}
+	if filter == nil {
+		return mongoFilter
+	}
+
+	if filter.ActorID != nil {
+		mongoFilter["actor_id"] = *filter.ActorID
+	}
+	if filter.Action != nil {
+		mongoFilter["action"] = *filter.Action
+	}
+	if filter.TargetType != nil {
+		mongoFilter["target_type"] = *filter.TargetType
+	}
+	if filter.TargetID != nil {
+		mongoFilter["target_id"] = *filter.TargetID
+	}
+	if filter.RequestID != nil {
+		mongoFilter["request_id"] = *filter.RequestID
+	}
+
+	createdAt := bson.M{}
+	if filter.From != nil {
+		createdAt["$gte"] = *filter.From
+	}
+	if filter.To != nil {
+		createdAt["$lt"] = *filter.To
+	}
+	if len(createdAt) > 0 {
+		mongoFilter["created_at"] = createdAt
+	}
+
+	return mongoFilter
+}
+
+// jsonOrNil converts a stored JSON string back to entity.JSON
+func jsonOrNil(s string) entity.JSON {
+	if s == "" {
+		return nil
+	}
+	return entity.JSON(s)
+}
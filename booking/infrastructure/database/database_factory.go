@@ -1,25 +1,56 @@
 package database
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"strings"
+	"time"
 	"booking/config"
 	"booking/domain/repository"
+	"booking/infrastructure/encryption"
+	"booking/infrastructure/logging"
+	"booking/infrastructure/metrics"
 	"booking/infrastructure/observer"
+	
+	"go.mongodb.org/mongo-driver/mongo"
+	"gorm.io/gorm"
 )
 
 // DatabaseFactory creates database connections and repositories
 // Factory Pattern: Creates different database implementations based on type
 type DatabaseFactory struct {
-	config  *config.Config
-	subject *observer.Subject
+	config    *config.Config
+	subject   *observer.Subject
+	encryptor *encryption.FieldEncryptor
+	metrics   *metrics.Metrics
+	
+	// Connections handed out to repositories, closed by Close
+	postgres *Database
+	mongo    *MongoDB
+}
+
+// FactoryOption configures optional DatabaseFactory dependencies
+type FactoryOption func(*DatabaseFactory)
+
+// WithMetrics instruments the connections created by the factory
+func WithMetrics(m *metrics.Metrics) FactoryOption {
+	return func(f *DatabaseFactory) {
+		f.metrics = m
+	}
 }
 
 // NewDatabaseFactory creates a new database factory
-func NewDatabaseFactory(cfg *config.Config, subject *observer.Subject) *DatabaseFactory {
-	return &DatabaseFactory{
+func NewDatabaseFactory(cfg *config.Config, subject *observer.Subject, opts ...FactoryOption) *DatabaseFactory {
+	f := &DatabaseFactory{
 		config:  cfg,
 		subject: subject,
 	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
 }
 
 // CreateUserRepository creates a user repository based on database type
@@ -34,39 +65,191 @@ func (f *DatabaseFactory) CreateUserRepository() (repository.UserRepository, err
 	}
 }
 
+// CreateRoleRepository creates a role repository based on database type
+func (f *DatabaseFactory) CreateRoleRepository() (repository.RoleRepository, error) {
+	switch f.config.DatabaseType {
+	case config.PostgresDB:
+		db, err := f.postgresDB()
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to PostgreSQL: %w", err)
+		}
+		return NewRoleRepository(db.DB, f.subject), nil
+	case config.MongoDB:
+		db, err := f.mongoDB()
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
+		}
+		return NewRoleRepositoryMongo(db, f.subject), nil
+	default:
+		return nil, fmt.Errorf("unsupported database type: %s", f.config.DatabaseType)
+	}
+}
+
+// CreateAuditRepository creates an audit log repository based on database type
+func (f *DatabaseFactory) CreateAuditRepository() (repository.AuditRepository, error) {
+	switch f.config.DatabaseType {
+	case config.PostgresDB:
+		db, err := f.postgresDB()
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to PostgreSQL: %w", err)
+		}
+		return NewAuditRepository(db), nil
+	case config.MongoDB:
+		db, err := f.mongoDB()
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
+		}
+		return NewAuditRepositoryMongo(db), nil
+	default:
+		return nil, fmt.Errorf("unsupported database type: %s", f.config.DatabaseType)
+	}
+}
+
+// CreatePrivacyRequestRepository creates a privacy request repository based on database type
+func (f *DatabaseFactory) CreatePrivacyRequestRepository() (repository.PrivacyRequestRepository, error) {
+	switch f.config.DatabaseType {
+	case config.PostgresDB:
+		db, err := f.postgresDB()
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to PostgreSQL: %w", err)
+		}
+		return NewPrivacyRequestRepository(db.DB), nil
+	case config.MongoDB:
+		db, err := f.mongoDB()
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
+		}
+		return NewPrivacyRequestRepositoryMongo(db), nil
+	default:
+		return nil, fmt.Errorf("unsupported database type: %s", f.config.DatabaseType)
+	}
+}
+
+// CreateIdempotencyRepository creates an idempotency key repository based on database type
+func (f *DatabaseFactory) CreateIdempotencyRepository() (repository.IdempotencyRepository, error) {
+	switch f.config.DatabaseType {
+	case config.PostgresDB:
+		db, err := f.postgresDB()
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to PostgreSQL: %w", err)
+		}
+		return NewIdempotencyRepository(db.DB), nil
+	case config.MongoDB:
+		db, err := f.mongoDB()
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
+		}
+		return NewIdempotencyRepositoryMongo(db), nil
+	default:
+		return nil, fmt.Errorf("unsupported database type: %s", f.config.DatabaseType)
+	}
+}
+
 // createPostgresUserRepository creates a PostgreSQL user repository
 func (f *DatabaseFactory) createPostgresUserRepository() (repository.UserRepository, error) {
-	dbConfig := &Config{
-		Host:     f.config.Database.Host,
-		Port:     f.config.Database.Port,
-		User:     f.config.Database.User,
-		Password: f.config.Database.Password,
-		DBName:   f.config.Database.DBName,
-		SSLMode:  f.config.Database.SSLMode,
+	encryptor, err := f.FieldEncryptor()
+	if err != nil {
+		return nil, err
 	}
 	
-	db, err := GetInstance(dbConfig)
+	db, err := f.postgresDB()
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to PostgreSQL: %w", err)
 	}
 	
-	return NewUserRepository(db.DB, f.subject), nil
+	return NewUserRepository(db, f.subject, encryptor), nil
 }
 
 // createMongoUserRepository creates a MongoDB user repository
 func (f *DatabaseFactory) createMongoUserRepository() (repository.UserRepository, error) {
-	mongoConfig := &MongoConfig{
-		URI:      f.config.Database.MongoURI,
-		Database: f.config.Database.MongoDBName,
-		Timeout:  f.config.Database.MongoTimeout,
+	encryptor, err := f.FieldEncryptor()
+	if err != nil {
+		return nil, err
 	}
 	
-	db, err := GetMongoInstance(mongoConfig)
+	db, err := f.mongoDB()
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
 	}
 	
-	return NewUserRepositoryMongo(db, f.subject), nil
+	repo := NewUserRepositoryMongo(db, f.subject, encryptor)
+	
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	assigned, err := AssignMongoUserIDs(ctx, db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assign MongoDB user IDs: %w", err)
+	}
+	if assigned > 0 {
+		logging.For("database").Warn("assigned user IDs to older MongoDB users; grant their roles again", slog.Int("users", assigned))
+	}
+	
+	return repo, nil
+}
+
+// FieldEncryptor loads the PII field encryptor from config once
+// The user repositories seal PII with it; the user cache uses it too.
+func (f *DatabaseFactory) FieldEncryptor() (*encryption.FieldEncryptor, error) {
+	if f.encryptor != nil {
+		return f.encryptor, nil
+	}
+	
+	encryptor, err := encryption.NewFieldEncryptorFromConfig(f.config.Encryption)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load PII encryption keys: %w", err)
+	}
+	if !encryptor.Enabled() {
+		logging.For("database").Warn("PII encryption disabled: set PII_MASTER_KEYS or PII_MASTER_KEY_FILE")
+	}
+	
+	f.encryptor = encryptor
+	return encryptor, nil
+}
+
+// postgresConfig builds the PostgreSQL connection config from application config
+func (f *DatabaseFactory) postgresConfig() *Config {
+	return &Config{
+		Host:                 f.config.Database.Host,
+		Port:                 f.config.Database.Port,
+		User:                 f.config.Database.User,
+		Password:             f.config.Database.Password,
+		DBName:               f.config.Database.DBName,
+		SSLMode:              f.config.Database.SSLMode,
+		SlowQueryThreshold:   f.config.Logging.SlowQueryThreshold,
+		Metrics:              f.metrics,
+		Replicas:             replicaHosts(f.config.Database.ReplicaHosts),
+		ReplicaCheckInterval: f.config.Database.ReplicaCheckInterval,
+		ReplicaMaxLag:        f.config.Database.ReplicaMaxLag,
+	}
+}
+
+// replicaHosts splits the comma separated replica list, skipping empty entries
+func replicaHosts(list string) []string {
+	var hosts []string
+	for _, host := range strings.Split(list, ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+// mongoConfig builds the MongoDB connection config from application config
+func (f *DatabaseFactory) mongoConfig() *MongoConfig {
+	return &MongoConfig{
+		URI:      f.config.Database.MongoURI,
+		Database: f.config.Database.MongoDBName,
+		Timeout:  f.config.Database.MongoTimeout,
+		Metrics:  f.metrics,
+	}
+}
+
+// NotFoundError returns the error repositories of the configured database return for missing records
+func (f *DatabaseFactory) NotFoundError() error {
+	if f.config.DatabaseType == config.MongoDB {
+		return mongo.ErrNoDocuments
+	}
+	return gorm.ErrRecordNotFound
 }
 
 // GetDatabaseType returns the current database type
@@ -74,36 +257,69 @@ func (f *DatabaseFactory) GetDatabaseType() config.DatabaseType {
 	return f.config.DatabaseType
 }
 
-// Close closes the database connection based on type
-func (f *DatabaseFactory) Close() error {
-	switch f.config.DatabaseType {
-	case config.PostgresDB:
-		dbConfig := &Config{
-			Host:     f.config.Database.Host,
-			Port:     f.config.Database.Port,
-			User:     f.config.Database.User,
-			Password: f.config.Database.Password,
-			DBName:   f.config.Database.DBName,
-			SSLMode:  f.config.Database.SSLMode,
-		}
-		db, err := GetInstance(dbConfig)
-		if err != nil {
+// Ping checks the database connections opened by the factory
+func (f *DatabaseFactory) Ping(ctx context.Context) error {
+	if f.postgres != nil {
+		if err := f.postgres.Ping(ctx); err != nil {
 			return err
 		}
-		return db.Close()
-	case config.MongoDB:
-		mongoConfig := &MongoConfig{
-			URI:      f.config.Database.MongoURI,
-			Database: f.config.Database.MongoDBName,
-			Timeout:  f.config.Database.MongoTimeout,
-		}
-		db, err := GetMongoInstance(mongoConfig)
-		if err != nil {
+	}
+	if f.mongo != nil {
+		if err := f.mongo.Ping(ctx); err != nil {
 			return err
 		}
-		return db.Close()
-	default:
-		return nil
 	}
+	return nil
 }
 
+// VerifyMigrations checks that the schema is in place
+// MongoDB collections are created on first write, so there is nothing to verify there.
+func (f *DatabaseFactory) VerifyMigrations(ctx context.Context) error {
+	if f.postgres != nil {
+		return f.postgres.VerifyMigrations(ctx)
+	}
+	return nil
+}
+
+// Close closes the database connections opened by the factory
+// Nothing is opened here, so closing an unused factory is a no-op.
+func (f *DatabaseFactory) Close() error {
+	var errs []error
+	if f.postgres != nil {
+		errs = append(errs, f.postgres.Close())
+		f.postgres = nil
+	}
+	if f.mongo != nil {
+		errs = append(errs, f.mongo.Close())
+		f.mongo = nil
+	}
+	return errors.Join(errs...)
+}
+
+// postgresDB returns the PostgreSQL connection, opening it on first use
+func (f *DatabaseFactory) postgresDB() (*Database, error) {
+	if f.postgres != nil {
+		return f.postgres, nil
+	}
+	
+	db, err := GetInstance(f.postgresConfig())
+	if err != nil {
+		return nil, err
+	}
+	f.postgres = db
+	return db, nil
+}
+
+// mongoDB returns the MongoDB connection, opening it on first use
+func (f *DatabaseFactory) mongoDB() (*MongoDB, error) {
+	if f.mongo != nil {
+		return f.mongo, nil
+	}
+	
+	db, err := GetMongoInstance(f.mongoConfig())
+	if err != nil {
+		return nil, err
+	}
+	f.mongo = db
+	return db, nil
+}
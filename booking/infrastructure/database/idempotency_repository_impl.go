@@ -0,0 +1,59 @@
+package database
+
+import (
+	"booking/domain/entity"
+	"booking/domain/repository"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// idempotencyRepositoryImpl implements the IdempotencyRepository interface with GORM
+type idempotencyRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewIdempotencyRepository creates a new idempotency repository
+func NewIdempotencyRepository(db *gorm.DB) repository.IdempotencyRepository {
+	return &idempotencyRepositoryImpl{db: db}
+}
+
+// Reserve inserts a record unless the scope and key are already taken
+func (r *idempotencyRepositoryImpl) Reserve(ctx context.Context, record *entity.IdempotencyRecord) (bool, error) {
+	// ON CONFLICT DO NOTHING lets the unique index arbitrate concurrent duplicates
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Get retrieves the record for a scope and key
+func (r *idempotencyRepositoryImpl) Get(ctx context.Context, scope, key string) (*entity.IdempotencyRecord, error) {
+	var record entity.IdempotencyRecord
+	if err := r.db.WithContext(ctx).Where("scope = ? AND key = ?", scope, key).First(&record).Error; err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// Complete stores the response of a reserved record
+func (r *idempotencyRepositoryImpl) Complete(ctx context.Context, record *entity.IdempotencyRecord) error {
+	return r.db.WithContext(ctx).
+		Model(record).
+		Select("status", "response_status", "content_type", "response_body", "updated_at").
+		Updates(record).Error
+}
+
+// Delete removes a record
+func (r *idempotencyRepositoryImpl) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&entity.IdempotencyRecord{}, id).Error
+}
+
+// DeleteExpired removes records that expired before cutoff
+func (r *idempotencyRepositoryImpl) DeleteExpired(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at < ?", cutoff).Delete(&entity.IdempotencyRecord{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,152 @@
+package database
+
+import (
+	"booking/domain/entity"
+	"booking/domain/repository"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoIdempotencyRecord represents the idempotency record document in MongoDB
+type MongoIdempotencyRecord struct {
+	ID             uint      `bson:"_id"`
+	Scope          string    `bson:"scope"`
+	Key            string    `bson:"key"`
+	RequestHash    string    `bson:"request_hash"`
+	Status         string    `bson:"status"`
+	ResponseStatus int       `bson:"response_status,omitempty"`
+	ContentType    string    `bson:"content_type,omitempty"`
+	ResponseBody   []byte    `bson:"response_body,omitempty"`
+	ExpiresAt      time.Time `bson:"expires_at"`
+	CreatedAt      time.Time `bson:"created_at"`
+	UpdatedAt      time.Time `bson:"updated_at"`
+}
+
+// idempotencyRepositoryMongo implements the IdempotencyRepository interface for MongoDB
+type idempotencyRepositoryMongo struct {
+	db         *MongoDB
+	collection *mongo.Collection
+}
+
+// NewIdempotencyRepositoryMongo creates a new MongoDB idempotency repository
+func NewIdempotencyRepositoryMongo(db *MongoDB) repository.IdempotencyRepository {
+	collection := db.GetCollection("idempotency_records")
+
+	// Create indexes
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "scope", Value: 1}, {Key: "key", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		// MongoDB removes expired records on its own; DeleteExpired is a fallback
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+
+	return &idempotencyRepositoryMongo{
+		db:         db,
+		collection: collection,
+	}
+}
+
+// toEntity converts MongoIdempotencyRecord to entity.IdempotencyRecord
+func (m *MongoIdempotencyRecord) toEntity() *entity.IdempotencyRecord {
+	return &entity.IdempotencyRecord{
+		ID:             m.ID,
+		Scope:          m.Scope,
+		Key:            m.Key,
+		RequestHash:    m.RequestHash,
+		Status:         entity.IdempotencyStatus(m.Status),
+		ResponseStatus: m.ResponseStatus,
+		ContentType:    m.ContentType,
+		ResponseBody:   m.ResponseBody,
+		ExpiresAt:      m.ExpiresAt,
+		CreatedAt:      m.CreatedAt,
+		UpdatedAt:      m.UpdatedAt,
+	}
+}
+
+// Reserve inserts a record unless the scope and key are already taken
+func (r *idempotencyRepositoryMongo) Reserve(ctx context.Context, record *entity.IdempotencyRecord) (bool, error) {
+	id, err := r.db.NextSequence(ctx, "idempotency_records")
+	if err != nil {
+		return false, err
+	}
+
+	record.ID = id
+	record.CreatedAt = time.Now()
+	record.UpdatedAt = record.CreatedAt
+
+	_, err = r.collection.InsertOne(ctx, &MongoIdempotencyRecord{
+		ID:          record.ID,
+		Scope:       record.Scope,
+		Key:         record.Key,
+		RequestHash: record.RequestHash,
+		Status:      string(record.Status),
+		ExpiresAt:   record.ExpiresAt,
+		CreatedAt:   record.CreatedAt,
+		UpdatedAt:   record.UpdatedAt,
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Get retrieves the record for a scope and key
+func (r *idempotencyRepositoryMongo) Get(ctx context.Context, scope, key string) (*entity.IdempotencyRecord, error) {
+	var doc MongoIdempotencyRecord
+	if err := r.collection.FindOne(ctx, bson.M{"scope": scope, "key": key}).Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc.toEntity(), nil
+}
+
+// Complete stores the response of a reserved record
+func (r *idempotencyRepositoryMongo) Complete(ctx context.Context, record *entity.IdempotencyRecord) error {
+	record.UpdatedAt = time.Now()
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": record.ID}, bson.M{
+		"$set": bson.M{
+			"status":          string(record.Status),
+			"response_status": record.ResponseStatus,
+			"content_type":    record.ContentType,
+			"response_body":   record.ResponseBody,
+			"updated_at":      record.UpdatedAt,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// Delete removes a record
+func (r *idempotencyRepositoryMongo) Delete(ctx context.Context, id uint) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+// DeleteExpired removes records that expired before cutoff
+func (r *idempotencyRepositoryMongo) DeleteExpired(ctx context.Context, cutoff time.Time) (int64, error) {
+	result, err := r.collection.DeleteMany(ctx, bson.M{"expires_at": bson.M{"$lt": cutoff}})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
@@ -6,6 +6,11 @@ import (
 	"sync"
 	"time"
 
+	"booking/infrastructure/metrics"
+	"booking/infrastructure/tracing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -28,6 +33,8 @@ type MongoConfig struct {
 	URI      string
 	Database string
 	Timeout  int // Connection timeout in seconds
+	// Metrics records command durations and pool stats when set
+	Metrics *metrics.Metrics
 }
 
 // GetMongoInstance returns the singleton instance of MongoDB
@@ -41,6 +48,13 @@ func GetMongoInstance(config *MongoConfig) (*MongoDB, error) {
 
 		// Set client options
 		clientOptions := options.Client().ApplyURI(config.URI)
+		clientOptions.SetMonitor(commandMonitors(
+			tracing.NewMongoCommandMonitor(),
+			config.Metrics.MongoCommandMonitor(),
+		))
+		if config.Metrics != nil {
+			clientOptions.SetPoolMonitor(config.Metrics.MongoPoolMonitor())
+		}
 
 		// Connect to MongoDB
 		client, clientErr := mongo.Connect(ctx, clientOptions)
@@ -75,6 +89,34 @@ func (m *MongoDB) Close() error {
 	return m.Client.Disconnect(ctx)
 }
 
+// commandMonitors fans command events out to several monitors
+// The driver accepts a single monitor; nil monitors and callbacks are skipped.
+func commandMonitors(monitors ...*event.CommandMonitor) *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, evt *event.CommandStartedEvent) {
+			for _, m := range monitors {
+				if m != nil && m.Started != nil {
+					m.Started(ctx, evt)
+				}
+			}
+		},
+		Succeeded: func(ctx context.Context, evt *event.CommandSucceededEvent) {
+			for _, m := range monitors {
+				if m != nil && m.Succeeded != nil {
+					m.Succeeded(ctx, evt)
+				}
+			}
+		},
+		Failed: func(ctx context.Context, evt *event.CommandFailedEvent) {
+			for _, m := range monitors {
+				if m != nil && m.Failed != nil {
+					m.Failed(ctx, evt)
+				}
+			}
+		},
+	}
+}
+
 // ResetMongoInstance resets the singleton instance (useful for testing)
 func ResetMongoInstance() {
 	mongoMu.Lock()
@@ -96,6 +138,24 @@ func (m *MongoDB) Ping(ctx context.Context) error {
 	return m.Client.Ping(ctx, nil)
 }
 
+// NextSequence atomically increments and returns the named counter
+// Use it for collections that need stable numeric IDs instead of ObjectID timestamps.
+func (m *MongoDB) NextSequence(ctx context.Context, name string) (uint, error) {
+	var counter struct {
+		Value uint `bson:"value"`
+	}
+	err := m.GetCollection("counters").FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": name},
+		bson.M{"$inc": bson.M{"value": 1}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&counter)
+	if err != nil {
+		return 0, err
+	}
+	return counter.Value, nil
+}
+
 // BuildMongoURI builds MongoDB connection URI from components
 func BuildMongoURI(host, port, username, password string) string {
 	if username != "" && password != "" {
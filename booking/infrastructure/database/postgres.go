@@ -1,25 +1,37 @@
 package database
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"time"
 	"booking/domain/entity"
+	"booking/domain/repository"
+	"booking/infrastructure/logging"
+	"booking/infrastructure/metrics"
+	"booking/infrastructure/tracing"
 	
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
 // Database represents the database connection
 // Implements Singleton Pattern
 type Database struct {
+	// DB is the primary; every write goes here
 	DB *gorm.DB
+	// replicas serve the reads routed by Reader; nil without replicas
+	replicas *replicaSet
+	metrics  *metrics.Metrics
 }
 
 var (
 	instance *Database
-	once     sync.Once
-	mu       sync.Mutex
+	// instanceErr is why the first GetInstance failed
+	instanceErr error
+	once        sync.Once
+	mu          sync.Mutex
 )
 
 // Config holds database configuration
@@ -30,51 +42,152 @@ type Config struct {
 	Password string
 	DBName   string
 	SSLMode  string
+	// SlowQueryThreshold logs slower queries as warnings
+	SlowQueryThreshold time.Duration
+	// Metrics records query durations and pool stats when set
+	Metrics *metrics.Metrics
+	// Replicas are host[:port] read replicas sharing the credentials and database name above
+	Replicas []string
+	// ReplicaCheckInterval is how often replicas are checked
+	ReplicaCheckInterval time.Duration
+	// ReplicaMaxLag takes replicas further behind the primary out of rotation; 0 disables the check
+	ReplicaMaxLag time.Duration
 }
 
 // GetInstance returns the singleton instance of Database
 // Singleton Pattern: Ensures only one database connection exists
+// A failed first call is not retried: later calls return the same error
+// until ResetInstance.
 func GetInstance(config *Config) (*Database, error) {
-	var err error
-	
 	once.Do(func() {
-		dsn := fmt.Sprintf(
-			"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-			config.Host, config.Port, config.User, config.Password, config.DBName, config.SSLMode,
-		)
-		
-		db, dbErr := gorm.Open(postgres.Open(dsn), &gorm.Config{
-			Logger: logger.Default.LogMode(logger.Info),
-		})
-		
-		if dbErr != nil {
-			err = dbErr
-			return
-		}
-		
-		instance = &Database{DB: db}
-		
-		// Auto migrate tables
-		err = instance.AutoMigrate()
+		instance, instanceErr = openDatabase(config)
+	})
+	
+	return instance, instanceErr
+}
+
+// openDatabase connects to the primary and the replicas and migrates the tables
+// The primary is closed again when a later step fails.
+func openDatabase(config *Config) (*Database, error) {
+	dsn := fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		config.Host, config.Port, config.User, config.Password, config.DBName, config.SSLMode,
+	)
+	
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger: logging.NewGormLogger(logging.For("database"), config.SlowQueryThreshold),
 	})
+	if err != nil {
+		return nil, err
+	}
+	
+	database := &Database{DB: db, metrics: config.Metrics}
+	if err := database.setup(config); err != nil {
+		return nil, errors.Join(err, database.Close())
+	}
+	return database, nil
+}
+
+// setup instruments and migrates the primary, then opens the replicas
+func (d *Database) setup(config *Config) error {
+	if err := d.DB.Use(tracing.NewGormPlugin()); err != nil {
+		return err
+	}
+	if err := config.Metrics.InstrumentGorm(d.DB, config.DBName); err != nil {
+		return err
+	}
 	
-	return instance, err
+	// Auto migrate tables
+	if err := d.AutoMigrate(); err != nil {
+		return err
+	}
+	
+	if len(config.Replicas) > 0 {
+		replicas, err := openReplicas(config)
+		if err != nil {
+			return err
+		}
+		d.replicas = replicas
+	}
+	return nil
+}
+
+// models lists the entities stored in PostgreSQL
+func models() []interface{} {
+	return []interface{}{
+		&entity.User{},
+		&entity.Permission{},
+		&entity.Role{},
+		&entity.UserRole{},
+		&entity.AuditEntry{},
+		&entity.PrivacyRequest{},
+		&entity.IdempotencyRecord{},
+	}
 }
 
 // AutoMigrate runs database migrations
 func (d *Database) AutoMigrate() error {
-	return d.DB.AutoMigrate(
-		&entity.User{},
-	)
+	return d.DB.AutoMigrate(models()...)
 }
 
-// Close closes the database connection
-func (d *Database) Close() error {
+// VerifyMigrations checks that the table of every model exists
+func (d *Database) VerifyMigrations(ctx context.Context) error {
+	migrator := d.DB.WithContext(ctx).Migrator()
+	for _, model := range models() {
+		if !migrator.HasTable(model) {
+			return fmt.Errorf("table for %T is missing", model)
+		}
+	}
+	return nil
+}
+
+// Writer returns the primary for statements that write
+func (d *Database) Writer(ctx context.Context) *gorm.DB {
+	return d.DB.WithContext(ctx)
+}
+
+// Reader returns the connection for a read query; query names it in metrics, e.g. "users.list"
+// Reads go round robin to the healthy replicas. They go to the primary when
+// ctx demands read-your-writes, or when there is no healthy replica.
+func (d *Database) Reader(ctx context.Context, query string) *gorm.DB {
+	db, target, reason := d.DB, targetPrimary, reasonNoReplicas
+	switch {
+	case d.replicas == nil:
+		// No replicas configured; everything runs on the primary
+	case repository.ReadYourWrites(ctx):
+		reason = reasonReadYourWrites
+	default:
+		reason = reasonUnhealthy
+		if r := d.replicas.pick(); r != nil {
+			db, target, reason = r.db, targetReplica, reasonReplica
+		}
+	}
+	
+	d.metrics.ObserveRoute(query, target, reason)
+	return db.WithContext(ctx)
+}
+
+// Ping checks that the database is reachable
+// Only the primary is pinged: reads fall back to it while replicas are down.
+func (d *Database) Ping(ctx context.Context) error {
 	sqlDB, err := d.DB.DB()
 	if err != nil {
 		return err
 	}
-	return sqlDB.Close()
+	return sqlDB.PingContext(ctx)
+}
+
+// Close closes the connections of the primary and the replicas
+func (d *Database) Close() error {
+	var errs []error
+	if d.replicas != nil {
+		errs = append(errs, d.replicas.close())
+	}
+	sqlDB, err := d.DB.DB()
+	if err != nil {
+		return errors.Join(append(errs, err)...)
+	}
+	return errors.Join(append(errs, sqlDB.Close())...)
 }
 
 // ResetInstance resets the singleton instance (useful for testing)
@@ -82,6 +195,7 @@ func ResetInstance() {
 	mu.Lock()
 	defer mu.Unlock()
 	instance = nil
+	instanceErr = nil
 	once = sync.Once{}
 }
 
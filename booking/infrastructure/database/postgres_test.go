@@ -0,0 +1,17 @@
+package database
+
+import "testing"
+
+func TestGetInstanceKeepsTheFailure(t *testing.T) {
+	ResetInstance()
+	defer ResetInstance()
+
+	// Nothing listens on port 1, so the first connection fails
+	config := &Config{Host: "127.0.0.1", Port: "1", User: "booking", DBName: "booking", SSLMode: "disable"}
+	for attempt := 1; attempt <= 2; attempt++ {
+		db, err := GetInstance(config)
+		if err == nil || db != nil {
+			t.Fatalf("attempt %d: GetInstance() = %v, %v; want the connection error", attempt, db, err)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package database
+
+import (
+	"booking/domain/entity"
+	"booking/domain/repository"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// privacyRequestRepositoryImpl implements the PrivacyRequestRepository interface with GORM
+type privacyRequestRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewPrivacyRequestRepository creates a new privacy request repository
+func NewPrivacyRequestRepository(db *gorm.DB) repository.PrivacyRequestRepository {
+	return &privacyRequestRepositoryImpl{db: db}
+}
+
+// Create creates a new privacy request
+func (r *privacyRequestRepositoryImpl) Create(ctx context.Context, req *entity.PrivacyRequest) error {
+	return r.db.WithContext(ctx).Create(req).Error
+}
+
+// GetByID retrieves a privacy request by ID
+func (r *privacyRequestRepositoryImpl) GetByID(ctx context.Context, id uint) (*entity.PrivacyRequest, error) {
+	var req entity.PrivacyRequest
+	if err := r.db.WithContext(ctx).First(&req, id).Error; err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// ListByUser retrieves a user's privacy requests, newest first
+func (r *privacyRequestRepositoryImpl) ListByUser(ctx context.Context, userID uint) ([]*entity.PrivacyRequest, error) {
+	var reqs []*entity.PrivacyRequest
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("id DESC").Find(&reqs).Error; err != nil {
+		return nil, err
+	}
+	return reqs, nil
+}
+
+// ListByStatus returns requests of reqType in status last updated before updatedBefore, oldest first
+func (r *privacyRequestRepositoryImpl) ListByStatus(ctx context.Context, reqType entity.PrivacyRequestType, status entity.PrivacyRequestStatus, updatedBefore time.Time, limit int) ([]*entity.PrivacyRequest, error) {
+	var reqs []*entity.PrivacyRequest
+	query := r.db.WithContext(ctx).Where("status = ? AND updated_at < ?", status, updatedBefore)
+	if reqType != "" {
+		query = query.Where("type = ?", reqType)
+	}
+	err := query.
+		Order("id").
+		Limit(limit).
+		Find(&reqs).Error
+	if err != nil {
+		return nil, err
+	}
+	return reqs, nil
+}
+
+// Claim moves a request from one status to another
+func (r *privacyRequestRepositoryImpl) Claim(ctx context.Context, id uint, from, to entity.PrivacyRequestStatus) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&entity.PrivacyRequest{}).
+		Where("id = ? AND status = ?", id, from).
+		Update("status", to)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Update updates a privacy request
+func (r *privacyRequestRepositoryImpl) Update(ctx context.Context, req *entity.PrivacyRequest) error {
+	return r.db.WithContext(ctx).Save(req).Error
+}
@@ -0,0 +1,182 @@
+package database
+
+import (
+	"booking/domain/entity"
+	"booking/domain/repository"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoPrivacyRequest represents the privacy request document in MongoDB
+// IDs come from a counter so they stay unique and numeric
+type MongoPrivacyRequest struct {
+	ID          uint       `bson:"_id"`
+	UserID      uint       `bson:"user_id"`
+	Type        string     `bson:"type"`
+	Status      string     `bson:"status"`
+	FilePath    string     `bson:"file_path,omitempty"`
+	Error       string     `bson:"error,omitempty"`
+	ExpiresAt   *time.Time `bson:"expires_at,omitempty"`
+	CompletedAt *time.Time `bson:"completed_at,omitempty"`
+	CreatedAt   time.Time  `bson:"created_at"`
+	UpdatedAt   time.Time  `bson:"updated_at"`
+}
+
+// privacyRequestRepositoryMongo implements the PrivacyRequestRepository interface for MongoDB
+type privacyRequestRepositoryMongo struct {
+	db         *MongoDB
+	collection *mongo.Collection
+}
+
+// NewPrivacyRequestRepositoryMongo creates a new MongoDB privacy request repository
+func NewPrivacyRequestRepositoryMongo(db *MongoDB) repository.PrivacyRequestRepository {
+	collection := db.GetCollection("privacy_requests")
+
+	// Create indexes
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "updated_at", Value: 1}}},
+	})
+
+	return &privacyRequestRepositoryMongo{
+		db:         db,
+		collection: collection,
+	}
+}
+
+// toEntity converts MongoPrivacyRequest to entity.PrivacyRequest
+func (m *MongoPrivacyRequest) toEntity() *entity.PrivacyRequest {
+	return &entity.PrivacyRequest{
+		ID:          m.ID,
+		UserID:      m.UserID,
+		Type:        entity.PrivacyRequestType(m.Type),
+		Status:      entity.PrivacyRequestStatus(m.Status),
+		FilePath:    m.FilePath,
+		Error:       m.Error,
+		ExpiresAt:   m.ExpiresAt,
+		CompletedAt: m.CompletedAt,
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+	}
+}
+
+// fromPrivacyEntity converts entity.PrivacyRequest to MongoPrivacyRequest
+func fromPrivacyEntity(req *entity.PrivacyRequest) *MongoPrivacyRequest {
+	return &MongoPrivacyRequest{
+		ID:          req.ID,
+		UserID:      req.UserID,
+		Type:        string(req.Type),
+		Status:      string(req.Status),
+		FilePath:    req.FilePath,
+		Error:       req.Error,
+		ExpiresAt:   req.ExpiresAt,
+		CompletedAt: req.CompletedAt,
+		CreatedAt:   req.CreatedAt,
+		UpdatedAt:   req.UpdatedAt,
+	}
+}
+
+// Create creates a new privacy request
+func (r *privacyRequestRepositoryMongo) Create(ctx context.Context, req *entity.PrivacyRequest) error {
+	id, err := r.db.NextSequence(ctx, "privacy_requests")
+	if err != nil {
+		return err
+	}
+
+	req.ID = id
+	req.CreatedAt = time.Now()
+	req.UpdatedAt = req.CreatedAt
+
+	_, err = r.collection.InsertOne(ctx, fromPrivacyEntity(req))
+	return err
+}
+
+// GetByID retrieves a privacy request by ID
+func (r *privacyRequestRepositoryMongo) GetByID(ctx context.Context, id uint) (*entity.PrivacyRequest, error) {
+	var doc MongoPrivacyRequest
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc.toEntity(), nil
+}
+
+// ListByUser retrieves a user's privacy requests, newest first
+func (r *privacyRequestRepositoryMongo) ListByUser(ctx context.Context, userID uint) ([]*entity.PrivacyRequest, error) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}})
+	return r.find(ctx, bson.M{"user_id": userID}, findOptions)
+}
+
+// ListByStatus returns requests of reqType in status last updated before updatedBefore, oldest first
+func (r *privacyRequestRepositoryMongo) ListByStatus(ctx context.Context, reqType entity.PrivacyRequestType, status entity.PrivacyRequestStatus, updatedBefore time.Time, limit int) ([]*entity.PrivacyRequest, error) {
+	filter := bson.M{
+		"status":     string(status),
+		"updated_at": bson.M{"$lt": updatedBefore},
+	}
+	if reqType != "" {
+		filter["type"] = string(reqType)
+	}
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(int64(limit))
+	return r.find(ctx, filter, findOptions)
+}
+
+// Claim moves a request from one status to another
+func (r *privacyRequestRepositoryMongo) Claim(ctx context.Context, id uint, from, to entity.PrivacyRequestStatus) (bool, error) {
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id, "status": string(from)},
+		bson.M{"$set": bson.M{"status": string(to), "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+// Update updates a privacy request
+func (r *privacyRequestRepositoryMongo) Update(ctx context.Context, req *entity.PrivacyRequest) error {
+	req.UpdatedAt = time.Now()
+
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": req.ID}, fromPrivacyEntity(req))
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// find runs a privacy request query and converts the documents
+func (r *privacyRequestRepositoryMongo) find(ctx context.Context, filter bson.M, findOptions *options.FindOptions) ([]*entity.PrivacyRequest, error) {
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	reqs := []*entity.PrivacyRequest{}
+	for cursor.Next(ctx) {
+		var doc MongoPrivacyRequest
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		reqs = append(reqs, doc.toEntity())
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return reqs, nil
+}
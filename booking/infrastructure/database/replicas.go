@@ -0,0 +1,226 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"booking/infrastructure/logging"
+	"booking/infrastructure/metrics"
+	"booking/infrastructure/tracing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// Targets and reasons of routed reads, as reported in metrics
+const (
+	targetPrimary = "primary"
+	targetReplica = "replica"
+
+	reasonReplica        = "replica"
+	reasonReadYourWrites = "read_your_writes"
+	reasonNoReplicas     = "no_replicas"
+	reasonUnhealthy      = "replicas_unhealthy"
+)
+
+// replicaCheckTimeout bounds one health check of a replica
+const replicaCheckTimeout = 2 * time.Second
+
+// lagQuery returns how many seconds a replica is behind the primary
+// A replica that has replayed everything it received is not behind, even if
+// the primary has been idle since its last transaction.
+const lagQuery = `SELECT COALESCE(
+	CASE WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+	ELSE EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()) END, 0)`
+
+// replica is a read-only copy of the primary
+type replica struct {
+	name    string
+	db      *gorm.DB
+	healthy atomic.Bool
+	// checked is false until the first health check; only the checker touches it
+	checked bool
+}
+
+// replicaSet spreads reads over the healthy replicas and checks their health in the background
+type replicaSet struct {
+	replicas []*replica
+	next     atomic.Uint64
+	maxLag   time.Duration
+	metrics  *metrics.Metrics
+	logger   *slog.Logger
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// openReplicas connects to the replicas in config and starts checking them
+// Connections are opened lazily, so an unreachable replica does not stop
+// startup; it just gets no reads until a health check passes.
+func openReplicas(config *Config) (*replicaSet, error) {
+	set := &replicaSet{
+		maxLag:  config.ReplicaMaxLag,
+		metrics: config.Metrics,
+		logger:  logging.For("database"),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	for _, hostPort := range config.Replicas {
+		r, err := openReplica(config, hostPort)
+		if err != nil {
+			set.closeAll()
+			return nil, err
+		}
+		set.replicas = append(set.replicas, r)
+	}
+
+	// Route reads to replicas that are already up from the first request on
+	set.checkAll()
+	go set.run(config.ReplicaCheckInterval)
+	return set, nil
+}
+
+// openReplica creates the connection pool of one replica
+func openReplica(config *Config, hostPort string) (*replica, error) {
+	host, port, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		// No port given; replicas listen where the primary does
+		host, port = hostPort, config.Port
+	}
+
+	dsn := fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		host, port, config.User, config.Password, config.DBName, config.SSLMode,
+	)
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger:               logging.NewGormLogger(logging.For("database"), config.SlowQueryThreshold),
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("replica %s: %w", hostPort, err)
+	}
+
+	r := &replica{name: net.JoinHostPort(host, port), db: db}
+	if err := db.Use(tracing.NewGormPlugin()); err != nil {
+		return nil, r.closeWith(err)
+	}
+	if err := config.Metrics.InstrumentGorm(db, config.DBName+"@"+r.name); err != nil {
+		return nil, r.closeWith(err)
+	}
+	return r, nil
+}
+
+// closeWith closes a replica that could not be set up and returns err naming it
+func (r *replica) closeWith(err error) error {
+	if sqlDB, dbErr := r.db.DB(); dbErr == nil {
+		sqlDB.Close()
+	}
+	return fmt.Errorf("replica %s: %w", r.name, err)
+}
+
+// pick returns the next healthy replica round robin, or nil if none is healthy
+func (s *replicaSet) pick() *replica {
+	n := uint64(len(s.replicas))
+	start := s.next.Add(1)
+	for i := uint64(0); i < n; i++ {
+		if r := s.replicas[(start+i)%n]; r.healthy.Load() {
+			return r
+		}
+	}
+	return nil
+}
+
+// run checks the replicas every interval until close
+func (s *replicaSet) run(interval time.Duration) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.checkAll()
+		}
+	}
+}
+
+// checkAll checks every replica concurrently, so a hanging one does not delay the others
+func (s *replicaSet) checkAll() {
+	var wg sync.WaitGroup
+	for _, r := range s.replicas {
+		wg.Add(1)
+		go func(r *replica) {
+			defer wg.Done()
+			s.check(r)
+		}(r)
+	}
+	wg.Wait()
+}
+
+// check marks a replica healthy if it answers and is not lagging more than maxLag
+func (s *replicaSet) check(r *replica) {
+	ctx, cancel := context.WithTimeout(context.Background(), replicaCheckTimeout)
+	defer cancel()
+
+	var lag time.Duration
+	err := pingGorm(ctx, r.db)
+	if err == nil && s.maxLag > 0 {
+		var seconds float64
+		if err = r.db.WithContext(ctx).Raw(lagQuery).Scan(&seconds).Error; err == nil {
+			lag = time.Duration(seconds * float64(time.Second))
+			if lag > s.maxLag {
+				err = fmt.Errorf("lagging %s behind the primary, more than %s", lag.Round(time.Millisecond), s.maxLag)
+			}
+		}
+	}
+
+	healthy := err == nil
+	wasHealthy := r.healthy.Swap(healthy)
+	s.metrics.SetReplicaHealth(r.name, healthy, lag)
+
+	switch {
+	case !healthy && (wasHealthy || !r.checked):
+		s.logger.Warn("replica gets no reads until it recovers", slog.String("replica", r.name), slog.Any("error", err))
+	case healthy && !wasHealthy:
+		s.logger.Info("replica serves reads", slog.String("replica", r.name), slog.Duration("lag", lag))
+	}
+	r.checked = true
+}
+
+// close stops the health checks and closes the replica connections
+func (s *replicaSet) close() error {
+	close(s.stop)
+	<-s.done
+	return s.closeAll()
+}
+
+// closeAll closes the connections of every replica
+func (s *replicaSet) closeAll() error {
+	var errs []error
+	for _, r := range s.replicas {
+		sqlDB, err := r.db.DB()
+		if err == nil {
+			err = sqlDB.Close()
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// pingGorm checks that the database behind db is reachable
+func pingGorm(ctx context.Context, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
@@ -0,0 +1,170 @@
+package database
+
+import (
+	"booking/domain/entity"
+	"booking/domain/repository"
+	"booking/infrastructure/observer"
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// roleRepositoryImpl implements the RoleRepository interface with GORM
+type roleRepositoryImpl struct {
+	db      *gorm.DB
+	subject *observer.Subject
+}
+
+// NewRoleRepository creates a new role repository
+func NewRoleRepository(db *gorm.DB, subject *observer.Subject) repository.RoleRepository {
+	return &roleRepositoryImpl{
+		db:      db,
+		subject: subject,
+	}
+}
+
+// Upsert creates the role if missing and replaces its permission set
+func (r *roleRepositoryImpl) Upsert(ctx context.Context, role *entity.Role) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		permissions := make([]entity.Permission, 0, len(role.Permissions))
+		for _, p := range role.Permissions {
+			permission := entity.Permission{Name: p.Name}
+			if err := tx.Where(entity.Permission{Name: p.Name}).
+				Attrs(entity.Permission{Description: p.Description}).
+				FirstOrCreate(&permission).Error; err != nil {
+				return err
+			}
+			permissions = append(permissions, permission)
+		}
+
+		existing := entity.Role{}
+		if err := tx.Where(entity.Role{Name: role.Name}).
+			Attrs(entity.Role{Description: role.Description}).
+			FirstOrCreate(&existing).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&existing).Association("Permissions").Replace(permissions); err != nil {
+			return err
+		}
+
+		existing.Permissions = permissions
+		*role = existing
+		return nil
+	})
+}
+
+// GetByName retrieves a role and its permissions by name
+func (r *roleRepositoryImpl) GetByName(ctx context.Context, name string) (*entity.Role, error) {
+	var role entity.Role
+	if err := r.db.WithContext(ctx).Preload("Permissions").Where("name = ?", name).First(&role).Error; err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// List retrieves all roles with their permissions
+func (r *roleRepositoryImpl) List(ctx context.Context) ([]*entity.Role, error) {
+	var roles []*entity.Role
+	if err := r.db.WithContext(ctx).Preload("Permissions").Order("name").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// ListByUser retrieves the roles assigned to a user
+func (r *roleRepositoryImpl) ListByUser(ctx context.Context, userID uint) ([]*entity.Role, error) {
+	var roles []*entity.Role
+	err := r.db.WithContext(ctx).
+		Preload("Permissions").
+		Joins("JOIN user_roles ON user_roles.role_id = roles.id").
+		Where("user_roles.user_id = ?", userID).
+		Order("roles.name").
+		Find(&roles).Error
+	if err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// ListByUsers retrieves the roles of several users with two queries
+func (r *roleRepositoryImpl) ListByUsers(ctx context.Context, userIDs []uint) (map[uint][]*entity.Role, error) {
+	result := make(map[uint][]*entity.Role, len(userIDs))
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+
+	var assignments []entity.UserRole
+	if err := r.db.WithContext(ctx).Where("user_id IN ?", userIDs).Find(&assignments).Error; err != nil {
+		return nil, err
+	}
+	if len(assignments) == 0 {
+		return result, nil
+	}
+
+	roleIDs := make([]uint, 0, len(assignments))
+	for _, a := range assignments {
+		roleIDs = append(roleIDs, a.RoleID)
+	}
+	var roles []*entity.Role
+	if err := r.db.WithContext(ctx).Preload("Permissions").Where("id IN ?", roleIDs).Order("name").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+
+	// Roles are visited in name order so each user's list is sorted like ListByUser
+	for _, role := range roles {
+		for _, a := range assignments {
+			if a.RoleID == role.ID {
+				result[a.UserID] = append(result[a.UserID], role)
+			}
+		}
+	}
+	return result, nil
+}
+
+// AssignToUser grants a role to a user; granting an already held role is a no-op
+func (r *roleRepositoryImpl) AssignToUser(ctx context.Context, userID uint, roleName string, actorID uint) error {
+	role, err := r.GetByName(ctx, roleName)
+	if err != nil {
+		return err
+	}
+
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&entity.UserRole{UserID: userID, RoleID: role.ID, GrantedBy: actorID})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected > 0 {
+		r.subject.Notify(observer.NewEvent(ctx, observer.RoleGranted, &entity.RoleChange{
+			UserID: userID, Role: roleName, ActorID: actorID,
+		}))
+	}
+
+	return nil
+}
+
+// RevokeFromUser removes a role from a user; revoking a role not held is a no-op
+func (r *roleRepositoryImpl) RevokeFromUser(ctx context.Context, userID uint, roleName string, actorID uint) error {
+	role, err := r.GetByName(ctx, roleName)
+	if err != nil {
+		return err
+	}
+
+	result := r.db.WithContext(ctx).
+		Where("user_id = ? AND role_id = ?", userID, role.ID).
+		Delete(&entity.UserRole{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected > 0 {
+		r.subject.Notify(observer.NewEvent(ctx, observer.RoleRevoked, &entity.RoleChange{
+			UserID: userID, Role: roleName, ActorID: actorID,
+		}))
+	}
+
+	return nil
+}
@@ -0,0 +1,318 @@
+package database
+
+import (
+	"booking/domain/entity"
+	"booking/domain/repository"
+	"booking/infrastructure/observer"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoRole represents the role document in MongoDB
+// Permissions are embedded by name since they are never queried on their own.
+// RoleID comes from a counter; roles seeded together share an ObjectID timestamp.
+type MongoRole struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	RoleID      uint               `bson:"role_id,omitempty"`
+	Name        string             `bson:"name"`
+	Description string             `bson:"description"`
+	Permissions []string           `bson:"permissions"`
+	CreatedAt   time.Time          `bson:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at"`
+}
+
+// MongoUserRole represents a user role assignment document in MongoDB
+type MongoUserRole struct {
+	UserID    uint      `bson:"user_id"`
+	Role      string    `bson:"role"`
+	GrantedBy uint      `bson:"granted_by"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+// roleRepositoryMongo implements the RoleRepository interface for MongoDB
+type roleRepositoryMongo struct {
+	db        *MongoDB
+	roles     *mongo.Collection
+	userRoles *mongo.Collection
+	subject   *observer.Subject
+}
+
+// NewRoleRepositoryMongo creates a new MongoDB role repository
+func NewRoleRepositoryMongo(db *MongoDB, subject *observer.Subject) repository.RoleRepository {
+	roles := db.GetCollection("roles")
+	userRoles := db.GetCollection("user_roles")
+
+	// Create indexes
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	roles.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "name", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	roles.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "role_id", Value: 1}},
+		Options: options.Index().SetUnique(true).SetSparse(true),
+	})
+	userRoles.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "role", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+
+	return &roleRepositoryMongo{
+		db:        db,
+		roles:     roles,
+		userRoles: userRoles,
+		subject:   subject,
+	}
+}
+
+// toEntity converts MongoRole to entity.Role
+func (m *MongoRole) toEntity() *entity.Role {
+	permissions := make([]entity.Permission, 0, len(m.Permissions))
+	for _, name := range m.Permissions {
+		permissions = append(permissions, entity.Permission{Name: name})
+	}
+
+	return &entity.Role{
+		ID:          m.RoleID,
+		Name:        m.Name,
+		Description: m.Description,
+		Permissions: permissions,
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+	}
+}
+
+// Upsert creates the role if missing and replaces its permission set
+func (r *roleRepositoryMongo) Upsert(ctx context.Context, role *entity.Role) error {
+	names := make([]string, 0, len(role.Permissions))
+	for _, p := range role.Permissions {
+		names = append(names, p.Name)
+	}
+
+	now := time.Now()
+	filter := bson.M{"name": role.Name}
+	set := bson.M{
+		"permissions": names,
+		"updated_at":  now,
+	}
+
+	var mongoRole MongoRole
+	err := r.roles.FindOneAndUpdate(
+		ctx,
+		filter,
+		bson.M{"$set": set},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&mongoRole)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		// New role; the counter is only touched on insert so restarts leave no gaps
+		id, seqErr := r.db.NextSequence(ctx, "roles")
+		if seqErr != nil {
+			return seqErr
+		}
+		err = r.roles.FindOneAndUpdate(
+			ctx,
+			filter,
+			bson.M{
+				"$set": set,
+				"$setOnInsert": bson.M{
+					"role_id":     id,
+					"description": role.Description,
+					"created_at":  now,
+				},
+			},
+			options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+		).Decode(&mongoRole)
+	}
+	if err != nil {
+		return err
+	}
+
+	if mongoRole.RoleID == 0 {
+		// Seeded before roles had IDs of their own
+		if err := r.assignRoleID(ctx, &mongoRole); err != nil {
+			return err
+		}
+	}
+
+	*role = *mongoRole.toEntity()
+	return nil
+}
+
+// assignRoleID gives a role stored without role_id the next ID from the counter
+// If another instance got there first, its ID is kept.
+func (r *roleRepositoryMongo) assignRoleID(ctx context.Context, mongoRole *MongoRole) error {
+	id, err := r.db.NextSequence(ctx, "roles")
+	if err != nil {
+		return err
+	}
+
+	_, err = r.roles.UpdateOne(ctx,
+		bson.M{"_id": mongoRole.ID, "role_id": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"role_id": id}},
+	)
+	if err != nil {
+		return err
+	}
+	return r.roles.FindOne(ctx, bson.M{"_id": mongoRole.ID}).Decode(mongoRole)
+}
+
+// GetByName retrieves a role and its permissions by name
+func (r *roleRepositoryMongo) GetByName(ctx context.Context, name string) (*entity.Role, error) {
+	var mongoRole MongoRole
+	if err := r.roles.FindOne(ctx, bson.M{"name": name}).Decode(&mongoRole); err != nil {
+		return nil, err
+	}
+	return mongoRole.toEntity(), nil
+}
+
+// List retrieves all roles with their permissions
+func (r *roleRepositoryMongo) List(ctx context.Context) ([]*entity.Role, error) {
+	return r.find(ctx, bson.M{})
+}
+
+// ListByUser retrieves the roles assigned to a user
+func (r *roleRepositoryMongo) ListByUser(ctx context.Context, userID uint) ([]*entity.Role, error) {
+	cursor, err := r.userRoles.Find(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var assignments []MongoUserRole
+	if err := cursor.All(ctx, &assignments); err != nil {
+		return nil, err
+	}
+	if len(assignments) == 0 {
+		return []*entity.Role{}, nil
+	}
+
+	names := make([]string, 0, len(assignments))
+	for _, a := range assignments {
+		names = append(names, a.Role)
+	}
+
+	return r.find(ctx, bson.M{"name": bson.M{"$in": names}})
+}
+
+// ListByUsers retrieves the roles of several users with two queries
+func (r *roleRepositoryMongo) ListByUsers(ctx context.Context, userIDs []uint) (map[uint][]*entity.Role, error) {
+	result := make(map[uint][]*entity.Role, len(userIDs))
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+
+	cursor, err := r.userRoles.Find(ctx, bson.M{"user_id": bson.M{"$in": userIDs}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var assignments []MongoUserRole
+	if err := cursor.All(ctx, &assignments); err != nil {
+		return nil, err
+	}
+	if len(assignments) == 0 {
+		return result, nil
+	}
+
+	names := make([]string, 0, len(assignments))
+	for _, a := range assignments {
+		names = append(names, a.Role)
+	}
+	roles, err := r.find(ctx, bson.M{"name": bson.M{"$in": names}})
+	if err != nil {
+		return nil, err
+	}
+
+	// Roles are visited in name order so each user's list is sorted like ListByUser
+	for _, role := range roles {
+		for _, a := range assignments {
+			if a.Role == role.Name {
+				result[a.UserID] = append(result[a.UserID], role)
+			}
+		}
+	}
+	return result, nil
+}
+
+// AssignToUser grants a role to a user; granting an already held role is a no-op
+func (r *roleRepositoryMongo) AssignToUser(ctx context.Context, userID uint, roleName string, actorID uint) error {
+	if _, err := r.GetByName(ctx, roleName); err != nil {
+		return err
+	}
+
+	result, err := r.userRoles.UpdateOne(
+		ctx,
+		bson.M{"user_id": userID, "role": roleName},
+		bson.M{"$setOnInsert": MongoUserRole{
+			UserID:    userID,
+			Role:      roleName,
+			GrantedBy: actorID,
+			CreatedAt: time.Now(),
+		}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.UpsertedCount > 0 {
+		r.subject.Notify(observer.NewEvent(ctx, observer.RoleGranted, &entity.RoleChange{
+			UserID: userID, Role: roleName, ActorID: actorID,
+		}))
+	}
+
+	return nil
+}
+
+// RevokeFromUser removes a role from a user; revoking a role not held is a no-op
+func (r *roleRepositoryMongo) RevokeFromUser(ctx context.Context, userID uint, roleName string, actorID uint) error {
+	if _, err := r.GetByName(ctx, roleName); err != nil {
+		return err
+	}
+
+	result, err := r.userRoles.DeleteOne(ctx, bson.M{"user_id": userID, "role": roleName})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount > 0 {
+		r.subject.Notify(observer.NewEvent(ctx, observer.RoleRevoked, &entity.RoleChange{
+			UserID: userID, Role: roleName, ActorID: actorID,
+		}))
+	}
+
+	return nil
+}
+
+// find runs a role query sorted by name
+func (r *roleRepositoryMongo) find(ctx context.Context, filter bson.M) ([]*entity.Role, error) {
+	cursor, err := r.roles.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	roles := []*entity.Role{}
+	for cursor.Next(ctx) {
+		var mongoRole MongoRole
+		if err := cursor.Decode(&mongoRole); err != nil {
+			return nil, err
+		}
+		roles = append(roles, mongoRole.toEntity())
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
@@ -0,0 +1,40 @@
+package database
+
+import (
+	"errors"
+	"strings"
+
+	"booking/domain/repository"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// uniqueViolation is the PostgreSQL error code of a unique constraint violation
+const uniqueViolation = "23505"
+
+// userConflict maps a unique index violation on the users email or username
+// to ErrDuplicateEmail or ErrDuplicateUsername; other errors are returned as is
+// Soft-deleted users keep their email and username until purged, so the
+// indexes catch values the use case could not see when it checked them.
+func userConflict(err error) error {
+	var index string
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.As(err, &pgErr) && pgErr.Code == uniqueViolation:
+		index = pgErr.ConstraintName // idx_users_email
+	case mongo.IsDuplicateKeyError(err):
+		index = err.Error() // "... index: email_1 dup key ..."
+	default:
+		return err
+	}
+
+	switch {
+	case strings.Contains(index, "email"):
+		return repository.ErrDuplicateEmail
+	case strings.Contains(index, "username"):
+		return repository.ErrDuplicateUsername
+	default:
+		return err
+	}
+}
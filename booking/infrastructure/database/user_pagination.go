@@ -0,0 +1,32 @@
+package database
+
+import (
+	"time"
+
+	"booking/domain/entity"
+)
+
+// userSortValue returns the cursor form of a user's sort key
+func userSortValue(user *entity.User, sortBy string) string {
+	switch sortBy {
+	case entity.UserSortUsername:
+		return user.Username
+	case entity.UserSortEmail:
+		return user.Email
+	default:
+		return user.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+}
+
+// parseUserSortValue converts the cursor form of a sort key back to a query value
+func parseUserSortValue(sortBy, value string) (interface{}, error) {
+	if sortBy != entity.UserSortCreatedAt {
+		return value, nil
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return nil, entity.ErrInvalidCursor
+	}
+	return t, nil
+}
@@ -0,0 +1,116 @@
+package database
+
+import (
+	"context"
+
+	"booking/domain/entity"
+	"booking/infrastructure/encryption"
+)
+
+// reencryptBatchSize is how many users are loaded at a time when rotating keys
+const reencryptBatchSize = 200
+
+// Field names bound into the ciphertext and blind index of each PII column
+const (
+	piiFullName = "users.full_name"
+	piiPhone    = "users.phone"
+)
+
+// PIIRotator re-encrypts stored PII under the active master key
+type PIIRotator interface {
+	// ReencryptPII rewrites users not yet under the active key, or every user
+	// when all is set (e.g. after changing the blind index key), and returns
+	// how many were rewritten
+	ReencryptPII(ctx context.Context, all bool) (int, error)
+}
+
+// userPII encrypts the PII columns of users on their way to and from storage
+type userPII struct {
+	encryptor *encryption.FieldEncryptor
+}
+
+// sealedPII holds the at-rest form of a user's PII columns
+type sealedPII struct {
+	FullName      string
+	Phone         string
+	FullNameIndex string
+	PhoneIndex    string
+	KeyID         string
+}
+
+// seal encrypts the PII of user and computes its blind indexes
+func (p userPII) seal(user *entity.User) (*sealedPII, error) {
+	fullName, err := p.encryptor.Encrypt(piiFullName, user.FullName)
+	if err != nil {
+		return nil, err
+	}
+	phone, err := p.encryptor.Encrypt(piiPhone, user.Phone)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sealedPII{
+		FullName:      fullName,
+		Phone:         phone,
+		FullNameIndex: p.encryptor.BlindIndex(piiFullName, user.FullName),
+		PhoneIndex:    p.encryptor.BlindIndex(piiPhone, user.Phone),
+		KeyID:         p.encryptor.ActiveKeyID(),
+	}, nil
+}
+
+// sealInPlace swaps the PII of user for its at-rest form
+// The returned function puts the plaintext back once the user has been stored.
+func (p userPII) sealInPlace(user *entity.User) (func(), error) {
+	sealed, err := p.seal(user)
+	if err != nil {
+		return nil, err
+	}
+
+	fullName, phone := user.FullName, user.Phone
+	user.FullName = sealed.FullName
+	user.Phone = sealed.Phone
+	user.FullNameIndex = sealed.FullNameIndex
+	user.PhoneIndex = sealed.PhoneIndex
+	user.PIIKeyID = sealed.KeyID
+
+	return func() {
+		user.FullName = fullName
+		user.Phone = phone
+	}, nil
+}
+
+// open decrypts the PII of a user loaded from storage
+func (p userPII) open(user *entity.User) error {
+	fullName, err := p.encryptor.Decrypt(piiFullName, user.FullName)
+	if err != nil {
+		return err
+	}
+	phone, err := p.encryptor.Decrypt(piiPhone, user.Phone)
+	if err != nil {
+		return err
+	}
+
+	user.FullName = fullName
+	user.Phone = phone
+	return nil
+}
+
+// openAll decrypts the PII of every user in users
+func (p userPII) openAll(users []*entity.User) error {
+	for _, user := range users {
+		if err := p.open(user); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fullNameIndex returns the blind index to look up users by full name
+func (p userPII) fullNameIndex(fullName string) string {
+	return p.encryptor.BlindIndex(piiFullName, fullName)
+}
+
+// phoneIndex returns the blind index to look up users by phone
+func (p userPII) phoneIndex(phone string) string {
+	return p.encryptor.BlindIndex(piiPhone, phone)
+}
@@ -0,0 +1,164 @@
+package database
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"booking/domain/entity"
+	"booking/infrastructure/encryption"
+)
+
+// newPII creates a userPII whose active master key is active, among "k1" and "k2"
+func newPII(t *testing.T, active string) userPII {
+	t.Helper()
+	encryptor, err := encryption.NewFieldEncryptor(map[string][]byte{
+		"k1": bytes.Repeat([]byte{1}, 32),
+		"k2": bytes.Repeat([]byte{2}, 32),
+	}, active, bytes.Repeat([]byte{9}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return userPII{encryptor: encryptor}
+}
+
+func TestUserPIISealOpen(t *testing.T) {
+	pii := newPII(t, "k1")
+	user := &entity.User{ID: 7, FullName: "Nguyễn Văn An", Phone: "0901234567"}
+
+	sealed, err := pii.seal(user)
+	if err != nil {
+		t.Fatalf("seal() error = %v", err)
+	}
+	if sealed.KeyID != "k1" {
+		t.Errorf("key ID = %q, want k1", sealed.KeyID)
+	}
+	if strings.Contains(sealed.FullName, "An") || strings.Contains(sealed.Phone, "0901234567") {
+		t.Errorf("sealed PII holds plaintext: %+v", sealed)
+	}
+	if user.FullName != "Nguyễn Văn An" {
+		t.Error("seal() changed the user")
+	}
+
+	stored := &entity.User{ID: 7, FullName: sealed.FullName, Phone: sealed.Phone}
+	if err := pii.open(stored); err != nil {
+		t.Fatalf("open() error = %v", err)
+	}
+	if stored.FullName != user.FullName || stored.Phone != user.Phone {
+		t.Errorf("open() = %q, %q; want %q, %q", stored.FullName, stored.Phone, user.FullName, user.Phone)
+	}
+}
+
+func TestUserPIIColumnsAreBound(t *testing.T) {
+	pii := newPII(t, "k1")
+	sealed, err := pii.seal(&entity.User{FullName: "Nguyễn Văn An", Phone: "0901234567"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A full name copied into the phone column does not open
+	swapped := &entity.User{FullName: sealed.Phone, Phone: sealed.FullName}
+	if err := pii.open(swapped); err == nil {
+		t.Error("open() of swapped columns succeeded")
+	}
+}
+
+func TestUserPIISealInPlace(t *testing.T) {
+	pii := newPII(t, "k1")
+	user := &entity.User{FullName: "Nguyễn Văn An", Phone: "0901234567"}
+
+	restore, err := pii.sealInPlace(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(user.FullName, "enc:v1:k1:") || user.PIIKeyID != "k1" || user.FullNameIndex == "" || user.PhoneIndex == "" {
+		t.Errorf("sealInPlace() left %+v", user)
+	}
+
+	restore()
+	if user.FullName != "Nguyễn Văn An" || user.Phone != "0901234567" {
+		t.Errorf("restore() left %q, %q", user.FullName, user.Phone)
+	}
+	// The indexes and key ID stay, as the stored row has them
+	if user.PIIKeyID != "k1" || user.FullNameIndex == "" {
+		t.Errorf("restore() cleared the stored columns: %+v", user)
+	}
+}
+
+func TestUserPIIRotation(t *testing.T) {
+	user := &entity.User{FullName: "Nguyễn Văn An", Phone: "0901234567"}
+	before, err := newPII(t, "k1").seal(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotated := newPII(t, "k2")
+	stored := &entity.User{FullName: before.FullName, Phone: before.Phone}
+	if err := rotated.open(stored); err != nil || stored.FullName != user.FullName {
+		t.Fatalf("open() under the old key = %q, %v", stored.FullName, err)
+	}
+
+	// Re-encryption, as ReencryptPII does it, moves the user to the new key
+	after, err := rotated.seal(stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.KeyID != "k2" || !strings.HasPrefix(after.FullName, "enc:v1:k2:") {
+		t.Errorf("re-sealed PII = %+v, want it under k2", after)
+	}
+	if after.FullNameIndex != before.FullNameIndex || after.PhoneIndex != before.PhoneIndex {
+		t.Error("blind indexes changed with the master key")
+	}
+}
+
+func TestUserPIIUnknownKey(t *testing.T) {
+	sealed, err := newPII(t, "k1").seal(&entity.User{FullName: "An"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encryptor, err := encryption.NewFieldEncryptor(map[string][]byte{"k2": bytes.Repeat([]byte{2}, 32)}, "k2", bytes.Repeat([]byte{9}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = userPII{encryptor: encryptor}.openAll([]*entity.User{{FullName: sealed.FullName}})
+	if !errors.Is(err, encryption.ErrUnknownKey) {
+		t.Errorf("openAll() error = %v, want %v", err, encryption.ErrUnknownKey)
+	}
+}
+
+func TestUserPIILookupIndexes(t *testing.T) {
+	pii := newPII(t, "k1")
+	sealed, err := pii.seal(&entity.User{FullName: "Nguyễn Văn An", Phone: "0901 234 567"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Lookups find the stored index whatever the formatting of the search
+	if got := pii.fullNameIndex("nguyễn văn  AN"); got != sealed.FullNameIndex {
+		t.Errorf("fullNameIndex() = %q, want %q", got, sealed.FullNameIndex)
+	}
+	if got := pii.phoneIndex(" 0901 234 567 "); got != sealed.PhoneIndex {
+		t.Errorf("phoneIndex() = %q, want %q", got, sealed.PhoneIndex)
+	}
+	if pii.fullNameIndex("0901 234 567") == sealed.PhoneIndex {
+		t.Error("a full name lookup matches the phone index")
+	}
+}
+
+func TestUserPIIDisabled(t *testing.T) {
+	encryptor, err := encryption.NewFieldEncryptor(nil, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pii := userPII{encryptor: encryptor}
+
+	sealed, err := pii.seal(&entity.User{FullName: "An", Phone: "0901234567"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sealed.FullName != "An" || sealed.KeyID != "" {
+		t.Errorf("seal() without keys = %+v, want plaintext", sealed)
+	}
+}
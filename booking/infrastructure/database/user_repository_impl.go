@@ -2,8 +2,13 @@ package database
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
 	"booking/domain/entity"
 	"booking/domain/repository"
+	"booking/infrastructure/encryption"
 	"booking/infrastructure/observer"
 	
 	"gorm.io/gorm"
@@ -11,30 +16,74 @@ import (
 
 // userRepositoryImpl implements the UserRepository interface
 type userRepositoryImpl struct {
-	db      *gorm.DB
-	subject *observer.Subject
+	// db is the primary; database routes the reads that may go to a replica
+	db       *gorm.DB
+	database *Database
+	subject  *observer.Subject
+	pii      userPII
 }
 
 // NewUserRepository creates a new user repository
 // This is a Factory function
-func NewUserRepository(db *gorm.DB, subject *observer.Subject) repository.UserRepository {
+func NewUserRepository(db *Database, subject *observer.Subject, encryptor *encryption.FieldEncryptor) repository.UserRepository {
 	return &userRepositoryImpl{
-		db:      db,
-		subject: subject,
+		db:       db.DB,
+		database: db,
+		subject:  subject,
+		pii:      userPII{encryptor: encryptor},
 	}
 }
 
 // Create creates a new user
 func (r *userRepositoryImpl) Create(ctx context.Context, user *entity.User) error {
-	if err := r.db.WithContext(ctx).Create(user).Error; err != nil {
+	restore, err := r.pii.sealInPlace(user)
+	if err != nil {
 		return err
 	}
+	err = r.db.WithContext(ctx).Create(user).Error
+	restore()
+	if err != nil {
+		return userConflict(err)
+	}
 	
 	// Notify observers
-	r.subject.Notify(observer.Event{
-		Type: observer.UserCreated,
-		Data: user,
+	r.subject.Notify(observer.NewEvent(ctx, observer.UserCreated, user))
+	
+	return nil
+}
+
+// CreateBatch creates several users in one transaction
+func (r *userRepositoryImpl) CreateBatch(ctx context.Context, users []*entity.User) error {
+	if len(users) == 0 {
+		return nil
+	}
+	
+	restores := make([]func(), 0, len(users))
+	restoreAll := func() {
+		for _, restore := range restores {
+			restore()
+		}
+	}
+	for _, user := range users {
+		restore, err := r.pii.sealInPlace(user)
+		if err != nil {
+			restoreAll()
+			return err
+		}
+		restores = append(restores, restore)
+	}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return tx.Create(&users).Error
 	})
+	restoreAll()
+	if err != nil {
+		return userConflict(err)
+	}
+	
+	// Notify observers once the whole batch is committed
+	for _, user := range users {
+		r.subject.Notify(observer.NewEvent(ctx, observer.UserCreated, user))
+	}
 	
 	return nil
 }
@@ -42,16 +91,39 @@ func (r *userRepositoryImpl) Create(ctx context.Context, user *entity.User) erro
 // GetByID retrieves a user by ID
 func (r *userRepositoryImpl) GetByID(ctx context.Context, id uint) (*entity.User, error) {
 	var user entity.User
-	if err := r.db.WithContext(ctx).First(&user, id).Error; err != nil {
+	if err := r.database.Reader(ctx, "users.get_by_id").First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	if err := r.pii.open(&user); err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
+// GetByIDs retrieves several users in one query
+func (r *userRepositoryImpl) GetByIDs(ctx context.Context, ids []uint) ([]*entity.User, error) {
+	users := []*entity.User{}
+	if len(ids) == 0 {
+		return users, nil
+	}
+	if err := r.database.Reader(ctx, "users.get_by_ids").Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		if err := r.pii.open(user); err != nil {
+			return nil, err
+		}
+	}
+	return users, nil
+}
+
 // GetByEmail retrieves a user by email
 func (r *userRepositoryImpl) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
 	var user entity.User
-	if err := r.db.WithContext(ctx).Where("email = ?", email).First(&user).Error; err != nil {
+	if err := r.database.Reader(ctx, "users.get_by_email").Where("email = ?", email).First(&user).Error; err != nil {
+		return nil, err
+	}
+	if err := r.pii.open(&user); err != nil {
 		return nil, err
 	}
 	return &user, nil
@@ -60,68 +132,199 @@ func (r *userRepositoryImpl) GetByEmail(ctx context.Context, email string) (*ent
 // GetByUsername retrieves a user by username
 func (r *userRepositoryImpl) GetByUsername(ctx context.Context, username string) (*entity.User, error) {
 	var user entity.User
-	if err := r.db.WithContext(ctx).Where("username = ?", username).First(&user).Error; err != nil {
+	if err := r.database.Reader(ctx, "users.get_by_username").Where("username = ?", username).First(&user).Error; err != nil {
+		return nil, err
+	}
+	if err := r.pii.open(&user); err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
-// List retrieves users based on filter
-func (r *userRepositoryImpl) List(ctx context.Context, filter *entity.UserFilter) ([]*entity.User, error) {
+// List retrieves one page of users based on filter
+func (r *userRepositoryImpl) List(ctx context.Context, filter *entity.UserFilter) (*entity.UserPage, error) {
 	var users []*entity.User
-	query := r.db.WithContext(ctx)
+	query := r.applyFilter(r.database.Reader(ctx, "users.list"), filter)
 	
+	sortBy, desc := entity.UserSortCreatedAt, false
+	limit := 0
 	if filter != nil {
-		if filter.Email != nil {
-			query = query.Where("email = ?", *filter.Email)
-		}
-		if filter.Username != nil {
-			query = query.Where("username = ?", *filter.Username)
-		}
-		if filter.IsActive != nil {
-			query = query.Where("is_active = ?", *filter.IsActive)
+		if filter.SortBy != "" {
+			sortBy, desc = filter.SortBy, filter.SortDesc
 		}
-		if filter.Limit > 0 {
-			query = query.Limit(filter.Limit)
-		}
-		if filter.Offset > 0 {
-			query = query.Offset(filter.Offset)
+		limit = filter.Limit
+		
+		if filter.After != nil {
+			after, err := r.cursorCondition(sortBy, desc, filter.After)
+			if err != nil {
+				return nil, err
+			}
+			query = query.Where(after)
 		}
 	}
 	
+	direction := "ASC"
+	if desc {
+		direction = "DESC"
+	}
+	// sortBy comes from the whitelist, so it is safe to splice into ORDER BY
+	query = query.Order(sortBy + " " + direction).Order("id " + direction)
+	
+	// Fetch one extra row to learn whether another page follows
+	if limit > 0 {
+		query = query.Limit(limit + 1)
+	}
+	
 	if err := query.Find(&users).Error; err != nil {
 		return nil, err
 	}
 	
-	return users, nil
+	if err := r.pii.openAll(users); err != nil {
+		return nil, err
+	}
+	
+	page := &entity.UserPage{Users: users}
+	if limit > 0 && len(users) > limit {
+		page.Users = users[:limit]
+		page.HasMore = true
+		
+		last := page.Users[limit-1]
+		cursor := &entity.UserCursor{
+			SortBy:   sortBy,
+			SortDesc: desc,
+			Value:    userSortValue(last, sortBy),
+			ID:       strconv.FormatUint(uint64(last.ID), 10),
+		}
+		page.NextCursor = cursor.Encode()
+	}
+	
+	return page, nil
 }
 
 // Update updates a user
 func (r *userRepositoryImpl) Update(ctx context.Context, user *entity.User) error {
-	if err := r.db.WithContext(ctx).Save(user).Error; err != nil {
+	// Load the prior state so observers (e.g. the audit log) can see what changed
+	var before entity.User
+	if err := r.db.WithContext(ctx).First(&before, user.ID).Error; err != nil {
+		return err
+	}
+	if err := r.pii.open(&before); err != nil {
 		return err
 	}
 	
+	restore, err := r.pii.sealInPlace(user)
+	if err != nil {
+		return err
+	}
+	err = r.db.WithContext(ctx).Save(user).Error
+	restore()
+	if err != nil {
+		return userConflict(err)
+	}
+	
 	// Notify observers
-	r.subject.Notify(observer.Event{
-		Type: observer.UserUpdated,
-		Data: user,
-	})
+	event := observer.NewEvent(ctx, observer.UserUpdated, user)
+	event.Before = &before
+	r.subject.Notify(event)
 	
 	return nil
 }
 
-// Delete deletes a user by ID
+// Delete soft-deletes a user by ID
 func (r *userRepositoryImpl) Delete(ctx context.Context, id uint) error {
-	if err := r.db.WithContext(ctx).Delete(&entity.User{}, id).Error; err != nil {
+	var user entity.User
+	if err := r.db.WithContext(ctx).First(&user, id).Error; err != nil {
+		return err
+	}
+	
+	prior := user
+	if err := r.pii.open(&prior); err != nil {
+		return err
+	}
+	
+	// gorm.DeletedAt turns this into UPDATE users SET deleted_at = now()
+	if err := r.db.WithContext(ctx).Delete(&user).Error; err != nil {
+		return err
+	}
+	
+	// Notify observers with the full prior entity
+	r.subject.Notify(observer.NewEvent(ctx, observer.UserDeleted, &prior))
+	
+	return nil
+}
+
+// Restore clears the soft-delete mark of a user that has not been purged yet
+func (r *userRepositoryImpl) Restore(ctx context.Context, id uint) (*entity.User, error) {
+	result := r.db.WithContext(ctx).Unscoped().
+		Model(&entity.User{}).
+		Where("id = ? AND deleted_at IS NOT NULL AND purged_at IS NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	
+	user, err := r.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	
+	// Notify observers
+	r.subject.Notify(observer.NewEvent(ctx, observer.UserRestored, user))
+	
+	return user, nil
+}
+
+// ListDeletedBefore returns soft-deleted, not yet purged users deleted before cutoff
+func (r *userRepositoryImpl) ListDeletedBefore(ctx context.Context, cutoff time.Time, limit int) ([]*entity.User, error) {
+	var users []*entity.User
+	err := r.database.Reader(ctx, "users.list_deleted_before").Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ? AND purged_at IS NULL", cutoff).
+		Order("deleted_at").
+		Limit(limit).
+		Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+	if err := r.pii.openAll(users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// Purge stores an anonymized soft-deleted user
+func (r *userRepositoryImpl) Purge(ctx context.Context, user *entity.User) error {
+	sealed, err := r.pii.seal(user)
+	if err != nil {
 		return err
 	}
 	
+	result := r.db.WithContext(ctx).Unscoped().
+		Model(&entity.User{}).
+		Where("id = ? AND deleted_at IS NOT NULL", user.ID).
+		Updates(map[string]interface{}{
+			"email":     user.Email,
+			"username":  user.Username,
+			"password":  user.Password,
+			"full_name":       sealed.FullName,
+			"phone":           sealed.Phone,
+			"full_name_index": sealed.FullNameIndex,
+			"phone_index":     sealed.PhoneIndex,
+			"pii_key_id":      sealed.KeyID,
+			"is_active":       user.IsActive,
+			"purged_at":       user.PurgedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	
 	// Notify observers
-	r.subject.Notify(observer.Event{
-		Type: observer.UserDeleted,
-		Data: id,
-	})
+	r.subject.Notify(observer.NewEvent(ctx, observer.UserPurged, user))
 	
 	return nil
 }
@@ -129,19 +332,7 @@ func (r *userRepositoryImpl) Delete(ctx context.Context, id uint) error {
 // Count counts users based on filter
 func (r *userRepositoryImpl) Count(ctx context.Context, filter *entity.UserFilter) (int64, error) {
 	var count int64
-	query := r.db.WithContext(ctx).Model(&entity.User{})
-	
-	if filter != nil {
-		if filter.Email != nil {
-			query = query.Where("email = ?", *filter.Email)
-		}
-		if filter.Username != nil {
-			query = query.Where("username = ?", *filter.Username)
-		}
-		if filter.IsActive != nil {
-			query = query.Where("is_active = ?", *filter.IsActive)
-		}
-	}
+	query := r.applyFilter(r.database.Reader(ctx, "users.count").Model(&entity.User{}), filter)
 	
 	if err := query.Count(&count).Error; err != nil {
 		return 0, err
@@ -150,3 +341,121 @@ func (r *userRepositoryImpl) Count(ctx context.Context, filter *entity.UserFilte
 	return count, nil
 }
 
+// applyFilter adds the WHERE conditions of filter to query
+func (r *userRepositoryImpl) applyFilter(query *gorm.DB, filter *entity.UserFilter) *gorm.DB {
+	if filter == nil {
+		return query
+	}
+	
+	if filter.Email != nil {
+		query = query.Where("email = ?", *filter.Email)
+	}
+	if filter.Username != nil {
+		query = query.Where("username = ?", *filter.Username)
+	}
+	if filter.FullName != nil {
+		query = query.Where("full_name_index = ?", r.pii.fullNameIndex(*filter.FullName))
+	}
+	if filter.Phone != nil {
+		query = query.Where("phone_index = ?", r.pii.phoneIndex(*filter.Phone))
+	}
+	if filter.IsActive != nil {
+		query = query.Where("is_active = ?", *filter.IsActive)
+	}
+	if filter.Search != nil {
+		prefix := escapeLike(*filter.Search) + "%"
+		query = query.Where(
+			"(username ILIKE ? OR email ILIKE ? OR full_name_index = ?)",
+			prefix, prefix, r.pii.fullNameIndex(*filter.Search),
+		)
+	}
+	if filter.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		query = query.Where("created_at < ?", *filter.CreatedTo)
+	}
+	
+	return query
+}
+
+// cursorCondition builds the keyset condition selecting rows after cursor
+func (r *userRepositoryImpl) cursorCondition(sortBy string, desc bool, cursor *entity.UserCursor) (*gorm.DB, error) {
+	id, err := strconv.ParseUint(cursor.ID, 10, 64)
+	if err != nil {
+		return nil, entity.ErrInvalidCursor
+	}
+	
+	value, err := parseUserSortValue(sortBy, cursor.Value)
+	if err != nil {
+		return nil, err
+	}
+	
+	op := ">"
+	if desc {
+		op = "<"
+	}
+	
+	return r.db.Where(
+		fmt.Sprintf("%s %s ? OR (%s = ? AND id %s ?)", sortBy, op, sortBy, op),
+		value, value, id,
+	), nil
+}
+
+// escapeLike escapes the LIKE wildcards in a user supplied search term
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// ReencryptPII rewrites the PII columns of users under the active master key
+// Soft-deleted users are included so they stay readable if restored.
+func (r *userRepositoryImpl) ReencryptPII(ctx context.Context, all bool) (int, error) {
+	var lastID uint
+	rewritten := 0
+	
+	for {
+		query := r.db.WithContext(ctx).Unscoped().
+			Where("id > ?", lastID).
+			Order("id").
+			Limit(reencryptBatchSize)
+		if !all {
+			query = query.Where("(pii_key_id IS NULL OR pii_key_id <> ?)", r.pii.encryptor.ActiveKeyID())
+		}
+		
+		var users []*entity.User
+		if err := query.Find(&users).Error; err != nil {
+			return rewritten, err
+		}
+		if len(users) == 0 {
+			return rewritten, nil
+		}
+		
+		for _, user := range users {
+			lastID = user.ID
+			
+			if err := r.pii.open(user); err != nil {
+				return rewritten, fmt.Errorf("user %d: %w", user.ID, err)
+			}
+			sealed, err := r.pii.seal(user)
+			if err != nil {
+				return rewritten, fmt.Errorf("user %d: %w", user.ID, err)
+			}
+			
+			// UpdateColumns leaves updated_at alone; rotation is not a change to the user
+			err = r.db.WithContext(ctx).Unscoped().
+				Model(&entity.User{}).
+				Where("id = ?", user.ID).
+				UpdateColumns(map[string]interface{}{
+					"full_name":       sealed.FullName,
+					"phone":           sealed.Phone,
+					"full_name_index": sealed.FullNameIndex,
+					"phone_index":     sealed.PhoneIndex,
+					"pii_key_id":      sealed.KeyID,
+				}).Error
+			if err != nil {
+				return rewritten, err
+			}
+			rewritten++
+		}
+	}
+}
@@ -3,19 +3,25 @@ package database
 import (
 	"booking/domain/entity"
 	"booking/domain/repository"
+	"booking/infrastructure/encryption"
 	"booking/infrastructure/observer"
 	"context"
+	"fmt"
+	"regexp"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"gorm.io/gorm"
 )
 
 // MongoUser represents the user document in MongoDB
+// UserID comes from a counter; users created in the same second share an ObjectID timestamp.
 type MongoUser struct {
 	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	UserID    uint               `bson:"user_id,omitempty"`
 	Email     string             `bson:"email"`
 	Username  string             `bson:"username"`
 	Password  string             `bson:"password"`
@@ -24,22 +30,36 @@ type MongoUser struct {
 	IsActive  bool               `bson:"is_active"`
 	CreatedAt time.Time          `bson:"created_at"`
 	UpdatedAt time.Time          `bson:"updated_at"`
+	DeletedAt *time.Time         `bson:"deleted_at,omitempty"`
+	PurgedAt  *time.Time         `bson:"purged_at,omitempty"`
+	// PIIKeyID, FullNameIndex and PhoneIndex describe the encrypted PII columns
+	PIIKeyID      string `bson:"pii_key_id,omitempty"`
+	FullNameIndex string `bson:"full_name_index,omitempty"`
+	PhoneIndex    string `bson:"phone_index,omitempty"`
 }
 
 // userRepositoryMongo implements the UserRepository interface for MongoDB
 type userRepositoryMongo struct {
+	db         *MongoDB
 	collection *mongo.Collection
 	subject    *observer.Subject
+	pii        userPII
 }
 
 // NewUserRepositoryMongo creates a new MongoDB user repository
-func NewUserRepositoryMongo(db *MongoDB, subject *observer.Subject) repository.UserRepository {
+func NewUserRepositoryMongo(db *MongoDB, subject *observer.Subject, encryptor *encryption.FieldEncryptor) repository.UserRepository {
 	collection := db.GetCollection("users")
 
 	// Create indexes
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	// User ID index; sparse until AssignMongoUserIDs has numbered older documents
+	userIDIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "user_id", Value: 1}},
+		Options: options.Index().SetUnique(true).SetSparse(true),
+	}
+
 	// Email index
 	emailIndex := mongo.IndexModel{
 		Keys:    bson.D{{Key: "email", Value: 1}},
@@ -52,21 +72,27 @@ func NewUserRepositoryMongo(db *MongoDB, subject *observer.Subject) repository.U
 		Options: options.Index().SetUnique(true),
 	}
 
-	collection.Indexes().CreateMany(ctx, []mongo.IndexModel{emailIndex, usernameIndex})
+	// Blind index and key ID indexes for lookups and key rotation
+	piiIndexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "full_name_index", Value: 1}}},
+		{Keys: bson.D{{Key: "phone_index", Value: 1}}},
+		{Keys: bson.D{{Key: "pii_key_id", Value: 1}}},
+	}
+
+	collection.Indexes().CreateMany(ctx, append([]mongo.IndexModel{userIDIndex, emailIndex, usernameIndex}, piiIndexes...))
 
 	return &userRepositoryMongo{
+		db:         db,
 		collection: collection,
 		subject:    subject,
+		pii:        userPII{encryptor: encryptor},
 	}
 }
 
 // toEntity converts MongoUser to entity.User
 func (m *MongoUser) toEntity() *entity.User {
-	// Convert ObjectID to uint (using timestamp as ID for simplicity)
-	id := uint(m.ID.Timestamp().Unix())
-
-	return &entity.User{
-		ID:        id,
+	user := &entity.User{
+		ID:        m.UserID,
 		Email:     m.Email,
 		Username:  m.Username,
 		Password:  m.Password,
@@ -75,7 +101,14 @@ func (m *MongoUser) toEntity() *entity.User {
 		IsActive:  m.IsActive,
 		CreatedAt: m.CreatedAt,
 		UpdatedAt: m.UpdatedAt,
+		PurgedAt:  m.PurgedAt,
+		PIIKeyID:  m.PIIKeyID,
+	}
+	if m.DeletedAt != nil {
+		user.DeletedAt = gorm.DeletedAt{Time: *m.DeletedAt, Valid: true}
 	}
+
+	return user
 }
 
 // fromEntity converts entity.User to MongoUser
@@ -91,52 +124,106 @@ func fromEntity(user *entity.User) *MongoUser {
 		UpdatedAt: user.UpdatedAt,
 	}
 
-	// If ID exists, try to convert it to ObjectID
-	if user.ID > 0 {
-		// For existing users, we need to fetch the ObjectID from database
-		// For now, we'll create a new ObjectID
-		mongoUser.ID = primitive.NewObjectID()
-	}
-
 	return mongoUser
 }
 
 // Create creates a new user
 func (r *userRepositoryMongo) Create(ctx context.Context, user *entity.User) error {
+	sealed, err := r.pii.seal(user)
+	if err != nil {
+		return err
+	}
+
 	mongoUser := fromEntity(user)
+	mongoUser.FullName = sealed.FullName
+	mongoUser.Phone = sealed.Phone
+	mongoUser.FullNameIndex = sealed.FullNameIndex
+	mongoUser.PhoneIndex = sealed.PhoneIndex
+	mongoUser.PIIKeyID = sealed.KeyID
 	mongoUser.ID = primitive.NewObjectID()
 	mongoUser.CreatedAt = time.Now()
 	mongoUser.UpdatedAt = time.Now()
 
-	result, err := r.collection.InsertOne(ctx, mongoUser)
+	// A failed insert, e.g. a duplicate email, leaves a gap in the counter
+	mongoUser.UserID, err = r.db.NextSequence(ctx, "users")
 	if err != nil {
 		return err
 	}
 
-	// Update user ID
-	if oid, ok := result.InsertedID.(primitive.ObjectID); ok {
-		user.ID = uint(oid.Timestamp().Unix())
-		user.CreatedAt = mongoUser.CreatedAt
-		user.UpdatedAt = mongoUser.UpdatedAt
+	if _, err := r.collection.InsertOne(ctx, mongoUser); err != nil {
+		return userConflict(err)
 	}
 
+	user.ID = mongoUser.UserID
+	user.CreatedAt = mongoUser.CreatedAt
+	user.UpdatedAt = mongoUser.UpdatedAt
+
 	// Notify observers
-	r.subject.Notify(observer.Event{
-		Type: observer.UserCreated,
-		Data: user,
+	r.subject.Notify(observer.NewEvent(ctx, observer.UserCreated, user))
+
+	return nil
+}
+
+// CreateBatch creates several users in one transaction
+// MongoDB only supports transactions on a replica set or sharded cluster.
+func (r *userRepositoryMongo) CreateBatch(ctx context.Context, users []*entity.User) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	mongoUsers := make([]*MongoUser, 0, len(users))
+	documents := make([]interface{}, 0, len(users))
+	for _, user := range users {
+		sealed, err := r.pii.seal(user)
+		if err != nil {
+			return err
+		}
+		mongoUser := fromEntity(user)
+		mongoUser.FullName = sealed.FullName
+		mongoUser.Phone = sealed.Phone
+		mongoUser.FullNameIndex = sealed.FullNameIndex
+		mongoUser.PhoneIndex = sealed.PhoneIndex
+		mongoUser.PIIKeyID = sealed.KeyID
+		mongoUser.ID = primitive.NewObjectID()
+		mongoUser.CreatedAt = now
+		mongoUser.UpdatedAt = now
+		mongoUser.UserID, err = r.db.NextSequence(ctx, "users")
+		if err != nil {
+			return err
+		}
+		mongoUsers = append(mongoUsers, mongoUser)
+		documents = append(documents, mongoUser)
+	}
+
+	session, err := r.collection.Database().Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return r.collection.InsertMany(sc, documents)
 	})
+	if err != nil {
+		return userConflict(err)
+	}
+
+	// Notify observers once the whole batch is committed
+	for i, user := range users {
+		user.ID = mongoUsers[i].UserID
+		user.CreatedAt = now
+		user.UpdatedAt = now
+		r.subject.Notify(observer.NewEvent(ctx, observer.UserCreated, user))
+	}
 
 	return nil
 }
 
 // GetByID retrieves a user by ID
 func (r *userRepositoryMongo) GetByID(ctx context.Context, id uint) (*entity.User, error) {
-	// Note: This is a simplified approach. In production, you'd store the ObjectID mapping
 	var mongoUser MongoUser
-
-	// Find by timestamp-based ID (this is a limitation of the simple conversion)
-	// In production, you should maintain an ID mapping or use ObjectID directly
-	filter := bson.M{"_id": primitive.NewObjectIDFromTimestamp(time.Unix(int64(id), 0))}
+	filter := notDeleted(bson.M{"user_id": id})
 
 	err := r.collection.FindOne(ctx, filter).Decode(&mongoUser)
 	if err != nil {
@@ -146,13 +233,45 @@ func (r *userRepositoryMongo) GetByID(ctx context.Context, id uint) (*entity.Use
 		return nil, err
 	}
 
-	return mongoUser.toEntity(), nil
+	return r.open(&mongoUser)
+}
+
+// GetByIDs retrieves several users in one query
+func (r *userRepositoryMongo) GetByIDs(ctx context.Context, ids []uint) ([]*entity.User, error) {
+	users := []*entity.User{}
+	if len(ids) == 0 {
+		return users, nil
+	}
+
+	cursor, err := r.collection.Find(ctx, notDeleted(bson.M{"user_id": bson.M{"$in": ids}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var mongoUser MongoUser
+		if err := cursor.Decode(&mongoUser); err != nil {
+			return nil, err
+		}
+		user, err := r.open(&mongoUser)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
 }
 
 // GetByEmail retrieves a user by email
 func (r *userRepositoryMongo) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
 	var mongoUser MongoUser
-	filter := bson.M{"email": email}
+	filter := notDeleted(bson.M{"email": email})
 
 	err := r.collection.FindOne(ctx, filter).Decode(&mongoUser)
 	if err != nil {
@@ -162,13 +281,13 @@ func (r *userRepositoryMongo) GetByEmail(ctx context.Context, email string) (*en
 		return nil, err
 	}
 
-	return mongoUser.toEntity(), nil
+	return r.open(&mongoUser)
 }
 
 // GetByUsername retrieves a user by username
 func (r *userRepositoryMongo) GetByUsername(ctx context.Context, username string) (*entity.User, error) {
 	var mongoUser MongoUser
-	filter := bson.M{"username": username}
+	filter := notDeleted(bson.M{"username": username})
 
 	err := r.collection.FindOne(ctx, filter).Decode(&mongoUser)
 	if err != nil {
@@ -178,36 +297,201 @@ func (r *userRepositoryMongo) GetByUsername(ctx context.Context, username string
 		return nil, err
 	}
 
-	return mongoUser.toEntity(), nil
+	return r.open(&mongoUser)
 }
 
-// List retrieves users based on filter
-func (r *userRepositoryMongo) List(ctx context.Context, filter *entity.UserFilter) ([]*entity.User, error) {
-	mongoFilter := bson.M{}
+// List retrieves one page of users based on filter
+func (r *userRepositoryMongo) List(ctx context.Context, filter *entity.UserFilter) (*entity.UserPage, error) {
+	conditions := r.filterConditions(filter)
 
+	sortBy, desc := entity.UserSortCreatedAt, false
+	limit := 0
 	if filter != nil {
-		if filter.Email != nil {
-			mongoFilter["email"] = *filter.Email
-		}
-		if filter.Username != nil {
-			mongoFilter["username"] = *filter.Username
+		if filter.SortBy != "" {
+			sortBy, desc = filter.SortBy, filter.SortDesc
 		}
-		if filter.IsActive != nil {
-			mongoFilter["is_active"] = *filter.IsActive
+		limit = filter.Limit
+
+		if filter.After != nil {
+			after, err := cursorCondition(sortBy, desc, filter.After)
+			if err != nil {
+				return nil, err
+			}
+			conditions = append(conditions, after)
 		}
 	}
 
-	findOptions := options.Find()
-	if filter != nil {
-		if filter.Limit > 0 {
-			findOptions.SetLimit(int64(filter.Limit))
+	direction := 1
+	if desc {
+		direction = -1
+	}
+	findOptions := options.Find().SetSort(bson.D{{Key: sortBy, Value: direction}, {Key: "_id", Value: direction}})
+
+	// Fetch one extra document to learn whether another page follows
+	if limit > 0 {
+		findOptions.SetLimit(int64(limit + 1))
+	}
+
+	cursor, err := r.collection.Find(ctx, andConditions(conditions), findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []MongoUser
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	page := &entity.UserPage{Users: []*entity.User{}}
+	if limit > 0 && len(docs) > limit {
+		docs = docs[:limit]
+		page.HasMore = true
+	}
+
+	for i := range docs {
+		user, err := r.open(&docs[i])
+		if err != nil {
+			return nil, err
 		}
-		if filter.Offset > 0 {
-			findOptions.SetSkip(int64(filter.Offset))
+		page.Users = append(page.Users, user)
+	}
+
+	if page.HasMore {
+		// The ObjectID breaks ties, as in the sort
+		last := page.Users[len(page.Users)-1]
+		next := &entity.UserCursor{
+			SortBy:   sortBy,
+			SortDesc: desc,
+			Value:    userSortValue(last, sortBy),
+			ID:       docs[len(docs)-1].ID.Hex(),
 		}
+		page.NextCursor = next.Encode()
+	}
+
+	return page, nil
+}
+
+// Update updates a user
+func (r *userRepositoryMongo) Update(ctx context.Context, user *entity.User) error {
+	filter := notDeleted(bson.M{"user_id": user.ID})
+
+	sealed, err := r.pii.seal(user)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"username":        user.Username,
+			"password":        user.Password,
+			"full_name":       sealed.FullName,
+			"phone":           sealed.Phone,
+			"full_name_index": sealed.FullNameIndex,
+			"phone_index":     sealed.PhoneIndex,
+			"pii_key_id":      sealed.KeyID,
+			"is_active":       user.IsActive,
+			"updated_at":      time.Now(),
+		},
+	}
+
+	// Return the document as it was before the update so observers can see what changed
+	var before MongoUser
+	err = r.collection.FindOneAndUpdate(
+		ctx,
+		filter,
+		update,
+		options.FindOneAndUpdate().SetReturnDocument(options.Before),
+	).Decode(&before)
+	if err != nil {
+		return userConflict(err)
+	}
+
+	prior, err := r.open(&before)
+	if err != nil {
+		return err
+	}
+
+	user.UpdatedAt = time.Now()
+
+	// Notify observers
+	event := observer.NewEvent(ctx, observer.UserUpdated, user)
+	event.Before = prior
+	r.subject.Notify(event)
+
+	return nil
+}
+
+// Delete soft-deletes a user by ID
+func (r *userRepositoryMongo) Delete(ctx context.Context, id uint) error {
+	filter := notDeleted(bson.M{"user_id": id})
+	update := bson.M{"$set": bson.M{"deleted_at": time.Now()}}
+
+	// Return the document as it was before deletion for observers
+	var prior MongoUser
+	err := r.collection.FindOneAndUpdate(
+		ctx,
+		filter,
+		update,
+		options.FindOneAndUpdate().SetReturnDocument(options.Before),
+	).Decode(&prior)
+	if err != nil {
+		return err
 	}
 
-	cursor, err := r.collection.Find(ctx, mongoFilter, findOptions)
+	user, err := r.open(&prior)
+	if err != nil {
+		return err
+	}
+
+	// Notify observers with the full prior entity
+	r.subject.Notify(observer.NewEvent(ctx, observer.UserDeleted, user))
+
+	return nil
+}
+
+// Restore clears the soft-delete mark of a user that has not been purged yet
+func (r *userRepositoryMongo) Restore(ctx context.Context, id uint) (*entity.User, error) {
+	filter := bson.M{
+		"user_id":    id,
+		"deleted_at": bson.M{"$ne": nil},
+		"purged_at":  nil,
+	}
+	update := bson.M{"$unset": bson.M{"deleted_at": ""}}
+
+	var restored MongoUser
+	err := r.collection.FindOneAndUpdate(
+		ctx,
+		filter,
+		update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&restored)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := r.open(&restored)
+	if err != nil {
+		return nil, err
+	}
+
+	// Notify observers
+	r.subject.Notify(observer.NewEvent(ctx, observer.UserRestored, user))
+
+	return user, nil
+}
+
+// ListDeletedBefore returns soft-deleted, not yet purged users deleted before cutoff
+func (r *userRepositoryMongo) ListDeletedBefore(ctx context.Context, cutoff time.Time, limit int) ([]*entity.User, error) {
+	filter := bson.M{
+		"deleted_at": bson.M{"$ne": nil, "$lt": cutoff},
+		"purged_at":  nil,
+	}
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "deleted_at", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -219,7 +503,11 @@ func (r *userRepositoryMongo) List(ctx context.Context, filter *entity.UserFilte
 		if err := cursor.Decode(&mongoUser); err != nil {
 			return nil, err
 		}
-		users = append(users, mongoUser.toEntity())
+		user, err := r.open(&mongoUser)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
 	}
 
 	if err := cursor.Err(); err != nil {
@@ -229,18 +517,30 @@ func (r *userRepositoryMongo) List(ctx context.Context, filter *entity.UserFilte
 	return users, nil
 }
 
-// Update updates a user
-func (r *userRepositoryMongo) Update(ctx context.Context, user *entity.User) error {
-	filter := bson.M{"email": user.Email} // Use email as identifier since we can't reliably convert uint ID
+// Purge stores an anonymized soft-deleted user
+func (r *userRepositoryMongo) Purge(ctx context.Context, user *entity.User) error {
+	filter := bson.M{
+		"user_id":    user.ID,
+		"deleted_at": bson.M{"$ne": nil},
+	}
+
+	sealed, err := r.pii.seal(user)
+	if err != nil {
+		return err
+	}
 
 	update := bson.M{
 		"$set": bson.M{
-			"username":   user.Username,
-			"password":   user.Password,
-			"full_name":  user.FullName,
-			"phone":      user.Phone,
-			"is_active":  user.IsActive,
-			"updated_at": time.Now(),
+			"email":           user.Email,
+			"username":        user.Username,
+			"password":        user.Password,
+			"full_name":       sealed.FullName,
+			"phone":           sealed.Phone,
+			"full_name_index": sealed.FullNameIndex,
+			"phone_index":     sealed.PhoneIndex,
+			"pii_key_id":      sealed.KeyID,
+			"is_active":       user.IsActive,
+			"purged_at":       user.PurgedAt,
 		},
 	}
 
@@ -253,60 +553,204 @@ func (r *userRepositoryMongo) Update(ctx context.Context, user *entity.User) err
 		return mongo.ErrNoDocuments
 	}
 
-	user.UpdatedAt = time.Now()
-
 	// Notify observers
-	r.subject.Notify(observer.Event{
-		Type: observer.UserUpdated,
-		Data: user,
-	})
+	r.subject.Notify(observer.NewEvent(ctx, observer.UserPurged, user))
 
 	return nil
 }
 
-// Delete deletes a user by ID
-func (r *userRepositoryMongo) Delete(ctx context.Context, id uint) error {
-	// Find user first to get the actual ObjectID
-	filter := bson.M{"_id": primitive.NewObjectIDFromTimestamp(time.Unix(int64(id), 0))}
+// Count counts users based on filter
+func (r *userRepositoryMongo) Count(ctx context.Context, filter *entity.UserFilter) (int64, error) {
+	count, err := r.collection.CountDocuments(ctx, andConditions(r.filterConditions(filter)))
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// filterConditions converts a UserFilter to MongoDB query conditions
+func (r *userRepositoryMongo) filterConditions(filter *entity.UserFilter) []bson.M {
+	mongoFilter := bson.M{}
+	conditions := []bson.M{notDeleted(mongoFilter)}
+	if filter == nil {
+		return conditions
+	}
+
+	if filter.Email != nil {
+		mongoFilter["email"] = *filter.Email
+	}
+	if filter.Username != nil {
+		mongoFilter["username"] = *filter.Username
+	}
+	if filter.FullName != nil {
+		mongoFilter["full_name_index"] = r.pii.fullNameIndex(*filter.FullName)
+	}
+	if filter.Phone != nil {
+		mongoFilter["phone_index"] = r.pii.phoneIndex(*filter.Phone)
+	}
+	if filter.IsActive != nil {
+		mongoFilter["is_active"] = *filter.IsActive
+	}
+
+	createdAt := bson.M{}
+	if filter.CreatedFrom != nil {
+		createdAt["$gte"] = *filter.CreatedFrom
+	}
+	if filter.CreatedTo != nil {
+		createdAt["$lt"] = *filter.CreatedTo
+	}
+	if len(createdAt) > 0 {
+		mongoFilter["created_at"] = createdAt
+	}
+
+	if filter.Search != nil {
+		prefix := primitive.Regex{Pattern: "^" + regexp.QuoteMeta(*filter.Search), Options: "i"}
+		conditions = append(conditions, bson.M{"$or": []bson.M{
+			{"username": prefix},
+			{"email": prefix},
+			{"full_name_index": r.pii.fullNameIndex(*filter.Search)},
+		}})
+	}
 
-	result, err := r.collection.DeleteOne(ctx, filter)
+	return conditions
+}
+
+// cursorCondition builds the keyset condition selecting documents after cursor
+func cursorCondition(sortBy string, desc bool, cursor *entity.UserCursor) (bson.M, error) {
+	id, err := primitive.ObjectIDFromHex(cursor.ID)
 	if err != nil {
-		return err
+		return nil, entity.ErrInvalidCursor
 	}
 
-	if result.DeletedCount == 0 {
-		return mongo.ErrNoDocuments
+	value, err := parseUserSortValue(sortBy, cursor.Value)
+	if err != nil {
+		return nil, err
 	}
 
-	// Notify observers
-	r.subject.Notify(observer.Event{
-		Type: observer.UserDeleted,
-		Data: id,
-	})
+	op := "$gt"
+	if desc {
+		op = "$lt"
+	}
 
-	return nil
+	return bson.M{"$or": []bson.M{
+		{sortBy: bson.M{op: value}},
+		{sortBy: value, "_id": bson.M{op: id}},
+	}}, nil
 }
 
-// Count counts users based on filter
-func (r *userRepositoryMongo) Count(ctx context.Context, filter *entity.UserFilter) (int64, error) {
-	mongoFilter := bson.M{}
+// andConditions combines query conditions
+func andConditions(conditions []bson.M) bson.M {
+	if len(conditions) == 1 {
+		return conditions[0]
+	}
+	return bson.M{"$and": conditions}
+}
 
-	if filter != nil {
-		if filter.Email != nil {
-			mongoFilter["email"] = *filter.Email
+// ReencryptPII rewrites the PII fields of users under the active master key
+// Soft-deleted users are included so they stay readable if restored.
+func (r *userRepositoryMongo) ReencryptPII(ctx context.Context, all bool) (int, error) {
+	filter := bson.M{}
+	if !all {
+		filter["pii_key_id"] = bson.M{"$ne": r.pii.encryptor.ActiveKeyID()}
+	}
+
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetBatchSize(reencryptBatchSize))
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	rewritten := 0
+	for cursor.Next(ctx) {
+		var mongoUser MongoUser
+		if err := cursor.Decode(&mongoUser); err != nil {
+			return rewritten, err
 		}
-		if filter.Username != nil {
-			mongoFilter["username"] = *filter.Username
+
+		user, err := r.open(&mongoUser)
+		if err != nil {
+			return rewritten, fmt.Errorf("user %s: %w", mongoUser.ID.Hex(), err)
 		}
-		if filter.IsActive != nil {
-			mongoFilter["is_active"] = *filter.IsActive
+		sealed, err := r.pii.seal(user)
+		if err != nil {
+			return rewritten, fmt.Errorf("user %s: %w", mongoUser.ID.Hex(), err)
 		}
+
+		// updated_at is left alone; rotation is not a change to the user
+		_, err = r.collection.UpdateOne(ctx, bson.M{"_id": mongoUser.ID}, bson.M{
+			"$set": bson.M{
+				"full_name":       sealed.FullName,
+				"phone":           sealed.Phone,
+				"full_name_index": sealed.FullNameIndex,
+				"phone_index":     sealed.PhoneIndex,
+				"pii_key_id":      sealed.KeyID,
+			},
+		})
+		if err != nil {
+			return rewritten, err
+		}
+		rewritten++
+	}
+
+	if err := cursor.Err(); err != nil {
+		return rewritten, err
 	}
 
-	count, err := r.collection.CountDocuments(ctx, mongoFilter)
+	return rewritten, nil
+}
+
+// open converts a stored document to an entity with decrypted PII
+func (r *userRepositoryMongo) open(m *MongoUser) (*entity.User, error) {
+	user := m.toEntity()
+	if err := r.pii.open(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// notDeleted restricts a query to users that have not been soft-deleted
+func notDeleted(filter bson.M) bson.M {
+	filter["deleted_at"] = nil
+	return filter
+}
+
+// AssignMongoUserIDs gives users stored without user_id the next IDs from the counter, oldest first
+// Their IDs used to be ObjectID timestamps, so roles granted under those IDs have to be granted again.
+func AssignMongoUserIDs(ctx context.Context, db *MongoDB) (int, error) {
+	collection := db.GetCollection("users")
+	cursor, err := collection.Find(ctx,
+		bson.M{"user_id": bson.M{"$exists": false}},
+		options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetProjection(bson.M{"_id": 1}),
+	)
 	if err != nil {
 		return 0, err
 	}
+	defer cursor.Close(ctx)
 
-	return count, nil
+	assigned := 0
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return assigned, err
+		}
+		id, err := db.NextSequence(ctx, "users")
+		if err != nil {
+			return assigned, err
+		}
+
+		// If another instance got there first, its ID is kept
+		result, err := collection.UpdateOne(ctx,
+			bson.M{"_id": doc.ID, "user_id": bson.M{"$exists": false}},
+			bson.M{"$set": bson.M{"user_id": id}},
+		)
+		if err != nil {
+			return assigned, err
+		}
+		assigned += int(result.ModifiedCount)
+	}
+
+	return assigned, cursor.Err()
 }
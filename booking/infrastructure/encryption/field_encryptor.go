@@ -0,0 +1,217 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// envelopePrefix marks a value produced by Encrypt; anything else is legacy plaintext
+const envelopePrefix = "enc:v1:"
+
+// keySize is the length of master, data and blind index keys (AES-256)
+const keySize = 32
+
+var (
+	// ErrUnknownKey is returned when a value was encrypted under a master key that is not loaded
+	ErrUnknownKey = errors.New("encryption: unknown master key id")
+	// ErrMalformedEnvelope is returned when an encrypted value cannot be parsed
+	ErrMalformedEnvelope = errors.New("encryption: malformed envelope")
+)
+
+// FieldEncryptor performs envelope encryption of individual field values
+// Every value gets a fresh AES-256-GCM data key, which is itself wrapped by
+// the active master key. The envelope records the master key ID so values
+// stay readable while keys are rotated:
+//
+//	enc:v1:<key id>:<base64 wrapped data key>:<base64 nonce+ciphertext>
+//
+// A FieldEncryptor without master keys is disabled and passes values through.
+type FieldEncryptor struct {
+	masterKeys  map[string][]byte
+	activeKeyID string
+	indexKey    []byte
+}
+
+// NewFieldEncryptor creates a field encryptor
+// masterKeys maps key IDs to 32-byte keys; activeKeyID selects the key that
+// wraps new data keys. indexKey is the HMAC key for blind indexes.
+func NewFieldEncryptor(masterKeys map[string][]byte, activeKeyID string, indexKey []byte) (*FieldEncryptor, error) {
+	for id, key := range masterKeys {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("encryption: invalid master key id %q", id)
+		}
+		if len(key) != keySize {
+			return nil, fmt.Errorf("encryption: master key %q must be %d bytes, got %d", id, keySize, len(key))
+		}
+	}
+
+	if len(masterKeys) > 0 {
+		if _, ok := masterKeys[activeKeyID]; !ok {
+			return nil, fmt.Errorf("encryption: active key %q is not among the loaded master keys", activeKeyID)
+		}
+		if len(indexKey) != keySize {
+			return nil, fmt.Errorf("encryption: blind index key must be %d bytes, got %d", keySize, len(indexKey))
+		}
+	} else {
+		activeKeyID = ""
+	}
+
+	return &FieldEncryptor{
+		masterKeys:  masterKeys,
+		activeKeyID: activeKeyID,
+		indexKey:    indexKey,
+	}, nil
+}
+
+// Enabled reports whether master keys are loaded
+func (e *FieldEncryptor) Enabled() bool {
+	return len(e.masterKeys) > 0
+}
+
+// ActiveKeyID returns the ID of the master key used for new values, or "" when disabled
+func (e *FieldEncryptor) ActiveKeyID() string {
+	return e.activeKeyID
+}
+
+// Encrypt seals a field value under a fresh data key
+// The field name is bound as additional data, so a ciphertext copied into
+// another column fails to decrypt. Empty values stay empty.
+func (e *FieldEncryptor) Encrypt(field, plaintext string) (string, error) {
+	if plaintext == "" || !e.Enabled() {
+		return plaintext, nil
+	}
+
+	dataKey := make([]byte, keySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+
+	wrappedKey, err := seal(e.masterKeys[e.activeKeyID], dataKey, []byte(e.activeKeyID))
+	if err != nil {
+		return "", err
+	}
+
+	ciphertext, err := seal(dataKey, []byte(plaintext), []byte(field))
+	if err != nil {
+		return "", err
+	}
+
+	return envelopePrefix + e.activeKeyID + ":" +
+		base64.RawStdEncoding.EncodeToString(wrappedKey) + ":" +
+		base64.RawStdEncoding.EncodeToString(ciphertext), nil
+}
+
+// Decrypt opens a value produced by Encrypt
+// Values without the envelope prefix are legacy plaintext and returned as-is.
+func (e *FieldEncryptor) Decrypt(field, value string) (string, error) {
+	if !strings.HasPrefix(value, envelopePrefix) {
+		return value, nil
+	}
+
+	parts := strings.Split(strings.TrimPrefix(value, envelopePrefix), ":")
+	if len(parts) != 3 {
+		return "", ErrMalformedEnvelope
+	}
+	keyID := parts[0]
+
+	masterKey, ok := e.masterKeys[keyID]
+	if !ok {
+		return "", fmt.Errorf("%w %q", ErrUnknownKey, keyID)
+	}
+
+	wrappedKey, err := base64.RawStdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", ErrMalformedEnvelope
+	}
+	ciphertext, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", ErrMalformedEnvelope
+	}
+
+	dataKey, err := open(masterKey, wrappedKey, []byte(keyID))
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := open(dataKey, ciphertext, []byte(field))
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
+
+// BlindIndex returns a deterministic keyed hash of a normalized value
+// Equal inputs give equal indexes, so equality lookups can run against the
+// index column without decrypting. Empty values have an empty index.
+func (e *FieldEncryptor) BlindIndex(field, value string) string {
+	value = Normalize(value)
+	if value == "" {
+		return ""
+	}
+
+	mac := hmac.New(sha256.New, e.indexKey)
+	mac.Write([]byte(field))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+
+	// 128 bits is plenty to avoid collisions while leaking less than the full MAC
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// Normalize trims, lowercases and collapses whitespace so that lookups are
+// not defeated by formatting differences
+func Normalize(value string) string {
+	return strings.ToLower(strings.Join(strings.Fields(value), " "))
+}
+
+// seal encrypts plaintext with AES-GCM, prefixing the random nonce
+func seal(key, plaintext, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// open decrypts a nonce-prefixed AES-GCM ciphertext
+func open(key, ciphertext, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, ErrMalformedEnvelope
+	}
+
+	nonce, sealed := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, sealed, additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("encryption: authentication failed: %w", err)
+	}
+	return plaintext, nil
+}
+
+// newGCM creates an AES-GCM AEAD for key
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
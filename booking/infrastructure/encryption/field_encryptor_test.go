@@ -0,0 +1,206 @@
+package encryption
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// key returns a 32-byte key filled with b
+func key(b byte) []byte {
+	return bytes.Repeat([]byte{b}, keySize)
+}
+
+// newEncryptor creates an encryptor with master keys "k1" and "k2", k1 active
+func newEncryptor(t *testing.T, active string) *FieldEncryptor {
+	t.Helper()
+	e, err := NewFieldEncryptor(map[string][]byte{"k1": key(1), "k2": key(2)}, active, key(9))
+	if err != nil {
+		t.Fatalf("NewFieldEncryptor() error = %v", err)
+	}
+	return e
+}
+
+func TestEncryptRoundTrip(t *testing.T) {
+	e := newEncryptor(t, "k1")
+	for _, plaintext := range []string{"Nguyễn Văn An", "0901 234 567", "a:b:c", " "} {
+		sealed, err := e.Encrypt("users.full_name", plaintext)
+		if err != nil {
+			t.Fatalf("Encrypt(%q) error = %v", plaintext, err)
+		}
+		if !strings.HasPrefix(sealed, envelopePrefix+"k1:") {
+			t.Errorf("Encrypt(%q) = %q, want an envelope under k1", plaintext, sealed)
+		}
+		opened, err := e.Decrypt("users.full_name", sealed)
+		if err != nil || opened != plaintext {
+			t.Errorf("Decrypt(Encrypt(%q)) = %q, %v", plaintext, opened, err)
+		}
+	}
+
+	// Every value gets its own data key and nonce
+	a, _ := e.Encrypt("users.phone", "0901234567")
+	b, _ := e.Encrypt("users.phone", "0901234567")
+	if a == b {
+		t.Error("two encryptions of a value are equal")
+	}
+}
+
+func TestEncryptPassesThrough(t *testing.T) {
+	e := newEncryptor(t, "k1")
+	if sealed, err := e.Encrypt("users.phone", ""); err != nil || sealed != "" {
+		t.Errorf("Encrypt(\"\") = %q, %v; want it empty", sealed, err)
+	}
+	if opened, err := e.Decrypt("users.phone", "legacy plaintext"); err != nil || opened != "legacy plaintext" {
+		t.Errorf("Decrypt(legacy) = %q, %v; want it unchanged", opened, err)
+	}
+
+	disabled, err := NewFieldEncryptor(nil, "ignored", nil)
+	if err != nil {
+		t.Fatalf("NewFieldEncryptor(nil) error = %v", err)
+	}
+	if disabled.Enabled() || disabled.ActiveKeyID() != "" {
+		t.Error("an encryptor without keys is enabled")
+	}
+	if sealed, _ := disabled.Encrypt("users.phone", "0901234567"); sealed != "0901234567" {
+		t.Errorf("disabled Encrypt() = %q, want the plaintext", sealed)
+	}
+}
+
+func TestDecryptWrongField(t *testing.T) {
+	e := newEncryptor(t, "k1")
+	sealed, err := e.Encrypt("users.full_name", "An")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The field is bound as additional data, so a value moved to another column does not open
+	if _, err := e.Decrypt("users.phone", sealed); err == nil {
+		t.Error("Decrypt() with another field succeeded")
+	}
+}
+
+func TestDecryptTampered(t *testing.T) {
+	e := newEncryptor(t, "k1")
+	sealed, err := e.Encrypt("users.full_name", "An")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(strings.TrimPrefix(sealed, envelopePrefix), ":")
+
+	tests := []struct {
+		name  string
+		value string
+		want  error
+	}{
+		{name: "unknown key", value: envelopePrefix + "k9:" + parts[1] + ":" + parts[2], want: ErrUnknownKey},
+		{name: "too few parts", value: envelopePrefix + "k1:" + parts[1], want: ErrMalformedEnvelope},
+		{name: "bad base64", value: envelopePrefix + "k1:!!:" + parts[2], want: ErrMalformedEnvelope},
+		{name: "short ciphertext", value: envelopePrefix + "k1:" + parts[1] + ":AAAA", want: ErrMalformedEnvelope},
+		// The wrapped data key is bound to its key ID
+		{name: "key ID swapped", value: envelopePrefix + "k2:" + parts[1] + ":" + parts[2]},
+		{name: "ciphertext flipped", value: envelopePrefix + "k1:" + parts[1] + ":" + flip(parts[2])},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := e.Decrypt("users.full_name", tt.value)
+			if err == nil {
+				t.Fatal("Decrypt() succeeded")
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("Decrypt() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// flip changes the last base64 character of value
+func flip(value string) string {
+	last := value[len(value)-1]
+	replacement := byte('A')
+	if last == 'A' {
+		replacement = 'B'
+	}
+	return value[:len(value)-1] + string(replacement)
+}
+
+func TestRotation(t *testing.T) {
+	old := newEncryptor(t, "k1")
+	sealed, err := old.Encrypt("users.phone", "0901234567")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// After rotation, values under the old key stay readable and new ones use the new key
+	rotated := newEncryptor(t, "k2")
+	if opened, err := rotated.Decrypt("users.phone", sealed); err != nil || opened != "0901234567" {
+		t.Errorf("Decrypt() after rotation = %q, %v", opened, err)
+	}
+	resealed, err := rotated.Encrypt("users.phone", "0901234567")
+	if err != nil || !strings.HasPrefix(resealed, envelopePrefix+"k2:") {
+		t.Errorf("Encrypt() after rotation = %q, %v; want an envelope under k2", resealed, err)
+	}
+
+	// Once the old key is removed, its values cannot be read
+	retired, err := NewFieldEncryptor(map[string][]byte{"k2": key(2)}, "k2", key(9))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := retired.Decrypt("users.phone", sealed); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Decrypt() with the old key removed: error = %v, want %v", err, ErrUnknownKey)
+	}
+	if opened, err := retired.Decrypt("users.phone", resealed); err != nil || opened != "0901234567" {
+		t.Errorf("Decrypt() of a re-encrypted value = %q, %v", opened, err)
+	}
+}
+
+func TestBlindIndex(t *testing.T) {
+	e := newEncryptor(t, "k1")
+	index := e.BlindIndex("users.full_name", "Nguyễn Văn An")
+
+	if got := e.BlindIndex("users.full_name", "  nguyễn   VĂN an "); got != index {
+		t.Errorf("index of a reformatted value = %q, want %q", got, index)
+	}
+	if got := newEncryptor(t, "k2").BlindIndex("users.full_name", "Nguyễn Văn An"); got != index {
+		t.Error("the index depends on the active master key")
+	}
+	if got := e.BlindIndex("users.phone", "Nguyễn Văn An"); got == index {
+		t.Error("two fields share an index")
+	}
+	if got := e.BlindIndex("users.full_name", "Nguyễn Văn Anh"); got == index {
+		t.Error("two values share an index")
+	}
+	if got := e.BlindIndex("users.full_name", "   "); got != "" {
+		t.Errorf("index of a blank value = %q, want it empty", got)
+	}
+
+	other, err := NewFieldEncryptor(map[string][]byte{"k1": key(1)}, "k1", key(8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.BlindIndex("users.full_name", "Nguyễn Văn An") == index {
+		t.Error("the index does not depend on the blind index key")
+	}
+}
+
+func TestNewFieldEncryptorValidates(t *testing.T) {
+	tests := []struct {
+		name   string
+		keys   map[string][]byte
+		active string
+		index  []byte
+	}{
+		{name: "short master key", keys: map[string][]byte{"k1": key(1)[:16]}, active: "k1", index: key(9)},
+		{name: "colon in key ID", keys: map[string][]byte{"k:1": key(1)}, active: "k:1", index: key(9)},
+		{name: "empty key ID", keys: map[string][]byte{"": key(1)}, active: "", index: key(9)},
+		{name: "active key missing", keys: map[string][]byte{"k1": key(1)}, active: "k2", index: key(9)},
+		{name: "short index key", keys: map[string][]byte{"k1": key(1)}, active: "k1", index: key(9)[:8]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewFieldEncryptor(tt.keys, tt.active, tt.index); err == nil {
+				t.Error("NewFieldEncryptor() succeeded")
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package encryption
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+
+	"booking/config"
+)
+
+// NewFieldEncryptorFromConfig loads master keys from config and the optional key file
+// Keys are "id:base64key" pairs, comma separated in config and one per line
+// in the key file (blank lines and # comments are ignored). When no master
+// key is configured the returned encryptor is disabled.
+func NewFieldEncryptorFromConfig(cfg config.EncryptionConfig) (*FieldEncryptor, error) {
+	masterKeys := map[string][]byte{}
+
+	if err := parseKeys(masterKeys, strings.Split(cfg.MasterKeys, ",")); err != nil {
+		return nil, err
+	}
+
+	if cfg.MasterKeyFile != "" {
+		lines, err := readLines(cfg.MasterKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("encryption: failed to read key file: %w", err)
+		}
+		if err := parseKeys(masterKeys, lines); err != nil {
+			return nil, err
+		}
+	}
+
+	var indexKey []byte
+	if cfg.BlindIndexKey != "" {
+		key, err := base64.StdEncoding.DecodeString(cfg.BlindIndexKey)
+		if err != nil {
+			return nil, fmt.Errorf("encryption: blind index key is not valid base64: %w", err)
+		}
+		indexKey = key
+	}
+
+	return NewFieldEncryptor(masterKeys, cfg.ActiveKeyID, indexKey)
+}
+
+// parseKeys decodes "id:base64key" entries into keys
+func parseKeys(keys map[string][]byte, entries []string) error {
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+
+		id, encoded, ok := strings.Cut(entry, ":")
+		if !ok {
+			return fmt.Errorf("encryption: master key entry must be id:base64key")
+		}
+
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return fmt.Errorf("encryption: master key %q is not valid base64: %w", id, err)
+		}
+		keys[strings.TrimSpace(id)] = key
+	}
+	return nil
+}
+
+// readLines returns the lines of a file
+func readLines(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
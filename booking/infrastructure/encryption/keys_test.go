@@ -0,0 +1,79 @@
+package encryption
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"booking/config"
+)
+
+func TestNewFieldEncryptorFromConfig(t *testing.T) {
+	k1 := base64.StdEncoding.EncodeToString(key(1))
+	k2 := base64.StdEncoding.EncodeToString(key(2))
+	index := base64.StdEncoding.EncodeToString(key(9))
+
+	keyFile := filepath.Join(t.TempDir(), "keys")
+	if err := os.WriteFile(keyFile, []byte("# rotated in 2026\n\nk2:"+k2+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		cfg        config.EncryptionConfig
+		wantErr    bool
+		wantActive string
+	}{
+		{name: "disabled", cfg: config.EncryptionConfig{}},
+		{name: "config keys", cfg: config.EncryptionConfig{MasterKeys: " k1:" + k1 + " , k2:" + k2, ActiveKeyID: "k2", BlindIndexKey: index}, wantActive: "k2"},
+		{name: "key file", cfg: config.EncryptionConfig{MasterKeys: "k1:" + k1, MasterKeyFile: keyFile, ActiveKeyID: "k2", BlindIndexKey: index}, wantActive: "k2"},
+		{name: "missing key file", cfg: config.EncryptionConfig{MasterKeyFile: filepath.Join(t.TempDir(), "none")}, wantErr: true},
+		{name: "entry without ID", cfg: config.EncryptionConfig{MasterKeys: k1, ActiveKeyID: "k1", BlindIndexKey: index}, wantErr: true},
+		{name: "key not base64", cfg: config.EncryptionConfig{MasterKeys: "k1:%%%", ActiveKeyID: "k1", BlindIndexKey: index}, wantErr: true},
+		{name: "index key not base64", cfg: config.EncryptionConfig{MasterKeys: "k1:" + k1, ActiveKeyID: "k1", BlindIndexKey: "%%%"}, wantErr: true},
+		{name: "active key unknown", cfg: config.EncryptionConfig{MasterKeys: "k1:" + k1, ActiveKeyID: "k3", BlindIndexKey: index}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := NewFieldEncryptorFromConfig(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewFieldEncryptorFromConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if e.ActiveKeyID() != tt.wantActive || e.Enabled() != (tt.wantActive != "") {
+				t.Errorf("active key = %q, enabled = %v; want %q", e.ActiveKeyID(), e.Enabled(), tt.wantActive)
+			}
+		})
+	}
+}
+
+func TestKeyFileKeysDecryptConfigValues(t *testing.T) {
+	// A value written under a key from config stays readable once that key moves to the key file
+	fromConfig, err := NewFieldEncryptor(map[string][]byte{"k1": key(1)}, "k1", key(9))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := fromConfig.Encrypt("users.phone", "0901234567")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyFile := filepath.Join(t.TempDir(), "keys")
+	if err := os.WriteFile(keyFile, []byte("k1:"+base64.StdEncoding.EncodeToString(key(1))+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	fromFile, err := NewFieldEncryptorFromConfig(config.EncryptionConfig{
+		MasterKeyFile: keyFile,
+		ActiveKeyID:   "k1",
+		BlindIndexKey: base64.StdEncoding.EncodeToString(key(9)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opened, err := fromFile.Decrypt("users.phone", sealed); err != nil || opened != "0901234567" {
+		t.Errorf("Decrypt() = %q, %v", opened, err)
+	}
+}
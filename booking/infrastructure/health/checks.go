@@ -0,0 +1,65 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// QueueDepth reports how many items are waiting to be processed
+type QueueDepth interface {
+	Pending() int
+}
+
+// BacklogCheck fails when more than max items are waiting in queue
+func BacklogCheck(queue QueueDepth, max int) CheckFunc {
+	return func(context.Context) error {
+		if pending := queue.Pending(); pending > max {
+			return fmt.Errorf("%d items pending, limit %d", pending, max)
+		}
+		return nil
+	}
+}
+
+// QueueLag reports how long the oldest waiting item has been waiting
+type QueueLag interface {
+	OldestPending() time.Duration
+}
+
+// LagCheck fails when the oldest item in queue has waited longer than max
+// A few stuck items fail it even while the backlog is short.
+func LagCheck(queue QueueLag, max time.Duration) CheckFunc {
+	return func(context.Context) error {
+		if lag := queue.OldestPending(); lag > max {
+			return fmt.Errorf("oldest item pending for %s, limit %s", lag.Round(time.Millisecond), max)
+		}
+		return nil
+	}
+}
+
+// DiskCheck fails when dir is not writable or has less than minFreeBytes available
+func DiskCheck(dir string, minFreeBytes uint64) CheckFunc {
+	return func(context.Context) error {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return err
+		}
+
+		probe, err := os.CreateTemp(dir, ".health-*")
+		if err != nil {
+			return fmt.Errorf("not writable: %w", err)
+		}
+		probe.Close()
+		os.Remove(probe.Name())
+
+		free, err := freeBytes(dir)
+		if err != nil {
+			return err
+		}
+		if free < minFreeBytes {
+			return fmt.Errorf("%s has %d MB free, need %d MB", filepath.Clean(dir), free>>20, minFreeBytes>>20)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,52 @@
+package health
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// queue reports a fixed backlog and lag
+type queue struct {
+	pending int
+	oldest  time.Duration
+}
+
+func (q queue) Pending() int                 { return q.pending }
+func (q queue) OldestPending() time.Duration { return q.oldest }
+
+func TestQueueChecks(t *testing.T) {
+	tests := []struct {
+		name    string
+		check   CheckFunc
+		wantErr bool
+	}{
+		{name: "empty backlog", check: BacklogCheck(queue{}, 10)},
+		{name: "backlog at limit", check: BacklogCheck(queue{pending: 10}, 10)},
+		{name: "backlog over limit", check: BacklogCheck(queue{pending: 11}, 10), wantErr: true},
+		{name: "nothing pending", check: LagCheck(queue{}, time.Second)},
+		{name: "lag at limit", check: LagCheck(queue{oldest: time.Second}, time.Second)},
+		{name: "lag over limit", check: LagCheck(queue{pending: 1, oldest: time.Minute}, time.Second), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.check(context.Background()); (err != nil) != tt.wantErr {
+				t.Errorf("check() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReadinessFailsOnOutboxLag(t *testing.T) {
+	checker := New(time.Second, 0)
+	checker.MarkStarted()
+	checker.Register("outbox_lag", LagCheck(queue{pending: 1, oldest: time.Minute}, 30*time.Second), 0)
+
+	report := checker.Readiness(context.Background())
+	if report.Status == StatusOK {
+		t.Fatalf("status = %q, want a failure", report.Status)
+	}
+	if report.Checks["outbox_lag"].Error == "" {
+		t.Errorf("checks = %+v, want the lag reported", report.Checks)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package health
+
+import "math"
+
+// freeBytes is not measured on this platform; only writability is checked
+func freeBytes(string) (uint64, error) {
+	return math.MaxUint64, nil
+}
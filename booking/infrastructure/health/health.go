@@ -0,0 +1,168 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Statuses reported for checks and for the whole report
+const (
+	StatusOK       = "ok"
+	StatusFail     = "fail"
+	StatusStarting = "starting"
+)
+
+// ErrNotStarted is reported while the startup gate is closed
+var ErrNotStarted = errors.New("startup not complete")
+
+// CheckFunc reports an unhealthy dependency by returning an error
+type CheckFunc func(ctx context.Context) error
+
+// CheckResult is the outcome of one check
+type CheckResult struct {
+	Status     string  `json:"status"`
+	Error      string  `json:"error,omitempty"`
+	DurationMs float64 `json:"duration_ms"`
+}
+
+// Report is the outcome of a readiness evaluation
+type Report struct {
+	Status    string                 `json:"status"`
+	Checks    map[string]CheckResult `json:"checks"`
+	CheckedAt time.Time              `json:"checked_at"`
+}
+
+// Ready reports whether every check passed and startup is complete
+func (r *Report) Ready() bool {
+	return r.Status == StatusOK
+}
+
+// check is a registered named check
+type check struct {
+	name    string
+	fn      CheckFunc
+	timeout time.Duration
+}
+
+// Health evaluates the readiness of the service
+// Checks run concurrently, each under its own timeout, and the report is
+// cached for cacheTTL so that frequent probes don't hammer the dependencies.
+// Readiness stays false until MarkStarted is called.
+type Health struct {
+	defaultTimeout time.Duration
+	cacheTTL       time.Duration
+	started        atomic.Bool
+
+	mu     sync.Mutex
+	checks []check
+	cached *Report
+}
+
+// New creates a health evaluator
+func New(defaultTimeout, cacheTTL time.Duration) *Health {
+	return &Health{
+		defaultTimeout: defaultTimeout,
+		cacheTTL:       cacheTTL,
+	}
+}
+
+// Register adds a readiness check; a zero timeout uses the default
+func (h *Health) Register(name string, fn CheckFunc, timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = h.defaultTimeout
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks = append(h.checks, check{name: name, fn: fn, timeout: timeout})
+	h.cached = nil
+}
+
+// MarkStarted opens the startup gate, e.g. once migrations are verified
+func (h *Health) MarkStarted() {
+	h.started.Store(true)
+}
+
+// Readiness runs the checks, or returns the cached report while it is fresh
+func (h *Health) Readiness(ctx context.Context) *Report {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.cached != nil && time.Since(h.cached.CheckedAt) < h.cacheTTL {
+		return h.cached
+	}
+
+	report := h.run(ctx, h.checks)
+	// Don't cache the gate: it should open on the very next probe
+	if h.started.Load() {
+		h.cached = report
+	}
+	return report
+}
+
+// run evaluates checks concurrently
+func (h *Health) run(ctx context.Context, checks []check) *Report {
+	report := &Report{
+		Status:    StatusOK,
+		Checks:    make(map[string]CheckResult, len(checks)+1),
+		CheckedAt: time.Now(),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, c := range checks {
+		wg.Add(1)
+		go func(c check) {
+			defer wg.Done()
+			result := runCheck(ctx, c)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[c.name] = result
+			if result.Status != StatusOK {
+				report.Status = StatusFail
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	if !h.started.Load() {
+		report.Status = StatusStarting
+		report.Checks["startup"] = CheckResult{Status: StatusFail, Error: ErrNotStarted.Error()}
+	}
+	return report
+}
+
+// runCheck runs one check under its timeout, turning panics into failures
+func runCheck(ctx context.Context, c check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				done <- fmt.Errorf("panic: %v", recovered)
+			}
+		}()
+		done <- c.fn(ctx)
+	}()
+
+	// A check that ignores its context still can't hold up the probe
+	result := CheckResult{Status: StatusOK}
+	select {
+	case err := <-done:
+		if err != nil {
+			result = CheckResult{Status: StatusFail, Error: err.Error()}
+		}
+	case <-ctx.Done():
+		result = CheckResult{Status: StatusFail, Error: fmt.Sprintf("timed out after %s", c.timeout)}
+	}
+	result.DurationMs = float64(time.Since(start).Microseconds()) / 1000
+	return result
+}
@@ -0,0 +1,204 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// hook is a named shutdown step
+type hook struct {
+	name string
+	stop func(ctx context.Context) error
+}
+
+// listener is an additional server, such as gRPC, served next to HTTP
+type listener struct {
+	name  string
+	serve func() error
+	drain func(ctx context.Context) error
+}
+
+// Lifecycle owns the servers, background workers and shutdown hooks of the application
+// Run serves until SIGINT/SIGTERM, then shuts down in order: drain in-flight
+// requests, stop workers, then run hooks in reverse registration order. Register
+// hooks as resources are created so that dependents stop before their
+// dependencies (e.g. observers before the database they write to).
+type Lifecycle struct {
+	server          *http.Server
+	listeners       []listener
+	shutdownTimeout time.Duration
+	logger          *slog.Logger
+
+	workerCtx    context.Context
+	stopWorkers  context.CancelFunc
+	workers      sync.WaitGroup
+	hooks        []hook
+	shutdownOnce sync.Once
+}
+
+// New creates a lifecycle; shutdown as a whole is bounded by shutdownTimeout
+func New(shutdownTimeout time.Duration, logger *slog.Logger) *Lifecycle {
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	return &Lifecycle{
+		shutdownTimeout: shutdownTimeout,
+		logger:          logger,
+		workerCtx:       workerCtx,
+		stopWorkers:     stopWorkers,
+	}
+}
+
+// Go runs a background worker until shutdown cancels its context
+func (l *Lifecycle) Go(name string, run func(ctx context.Context)) {
+	l.workers.Add(1)
+	go func() {
+		defer l.workers.Done()
+		run(l.workerCtx)
+		l.logger.Debug("worker stopped", slog.String("worker", name))
+	}()
+}
+
+// Serve registers a server that Run starts next to the HTTP server
+// serve blocks until the server stops; drain stops it gracefully and is
+// called during shutdown, together with draining the HTTP server. A serve
+// error triggers shutdown like an HTTP server failure.
+func (l *Lifecycle) Serve(name string, serve func() error, drain func(ctx context.Context) error) {
+	l.listeners = append(l.listeners, listener{name: name, serve: serve, drain: drain})
+}
+
+// OnStop registers a hook run at shutdown, after the workers have stopped
+// Hooks run in reverse registration order.
+func (l *Lifecycle) OnStop(name string, stop func(ctx context.Context) error) {
+	l.hooks = append(l.hooks, hook{name: name, stop: stop})
+}
+
+// Run serves HTTP with server, and the servers registered with Serve, until
+// the process is signalled or a server fails, then shuts down
+// It returns the server error, if any, joined with shutdown errors.
+func (l *Lifecycle) Run(server *http.Server) error {
+	l.server = server
+
+	signalCtx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	// Only the first failure is reported; it is enough to trigger shutdown
+	serveErr := make(chan error, 1+len(l.listeners))
+	go func() {
+		l.logger.Info("server starting", slog.String("addr", l.server.Addr))
+		if err := l.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- err
+		}
+	}()
+	for _, s := range l.listeners {
+		go func() {
+			l.logger.Info("server starting", slog.String("server", s.name))
+			if err := s.serve(); err != nil {
+				serveErr <- fmt.Errorf("%s server: %w", s.name, err)
+			}
+		}()
+	}
+
+	var err error
+	select {
+	case <-signalCtx.Done():
+		l.logger.Info("shutdown signal received")
+	case err = <-serveErr:
+		l.logger.Error("server failed", slog.Any("error", err))
+	}
+
+	// A second signal during shutdown kills the process the default way
+	stopSignals()
+
+	return errors.Join(err, l.Shutdown())
+}
+
+// Shutdown drains the server, stops workers and runs the hooks; later calls do nothing
+// Call it directly when startup fails after resources have been registered.
+func (l *Lifecycle) Shutdown() error {
+	var err error
+	l.shutdownOnce.Do(func() {
+		err = l.shutdown()
+	})
+	return err
+}
+
+// shutdown performs the ordered shutdown within the timeout
+func (l *Lifecycle) shutdown() error {
+	ctx, cancel := context.WithTimeout(context.Background(), l.shutdownTimeout)
+	defer cancel()
+
+	var errs []error
+
+	// Stop accepting connections and wait for in-flight requests, on all servers at once
+	var drained sync.WaitGroup
+	drainErrs := make([]error, len(l.listeners)+1)
+	if l.server != nil {
+		drained.Add(1)
+		go func() {
+			defer drained.Done()
+			if err := l.server.Shutdown(ctx); err != nil {
+				l.logger.Error("HTTP server did not drain", slog.Any("error", err))
+				drainErrs[0] = err
+				return
+			}
+			l.logger.Info("HTTP server drained")
+		}()
+	}
+	for i, s := range l.listeners {
+		drained.Add(1)
+		go func() {
+			defer drained.Done()
+			if err := s.drain(ctx); err != nil {
+				l.logger.Error("server did not drain", slog.String("server", s.name), slog.Any("error", err))
+				drainErrs[i+1] = err
+				return
+			}
+			l.logger.Info("server drained", slog.String("server", s.name))
+		}()
+	}
+	drained.Wait()
+	errs = append(errs, errors.Join(drainErrs...))
+
+	l.stopWorkers()
+	if err := wait(ctx, &l.workers); err != nil {
+		l.logger.Error("workers did not stop", slog.Any("error", err))
+		errs = append(errs, err)
+	} else {
+		l.logger.Info("workers stopped")
+	}
+
+	for i := len(l.hooks) - 1; i >= 0; i-- {
+		h := l.hooks[i]
+		if err := h.stop(ctx); err != nil {
+			l.logger.Error("shutdown step failed", slog.String("step", h.name), slog.Any("error", err))
+			errs = append(errs, err)
+			continue
+		}
+		l.logger.Info("shutdown step done", slog.String("step", h.name))
+	}
+
+	return errors.Join(errs...)
+}
+
+// wait waits for wg until ctx is done
+func wait(ctx context.Context, wg *sync.WaitGroup) error {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
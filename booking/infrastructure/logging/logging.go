@@ -0,0 +1,174 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"booking/config"
+	"booking/domain/identity"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ComponentKey is the attribute naming the package a log line comes from
+const ComponentKey = "component"
+
+// redacted replaces the value of sensitive attributes
+const redacted = "[REDACTED]"
+
+// Setup installs the process-wide slog logger described by cfg
+// Output is JSON on stdout unless LOG_FORMAT=text. Every record logged with
+// a request context carries its request_id, actor_id and trace_id.
+func Setup(cfg config.LoggingConfig) (*slog.Logger, error) {
+	return SetupWriter(os.Stdout, cfg)
+}
+
+// SetupWriter is Setup with output to w, e.g. stderr for commands whose
+// stdout is their result
+func SetupWriter(w io.Writer, cfg config.LoggingConfig) (*slog.Logger, error) {
+	levels, err := parseLevels(cfg.Level, cfg.Levels)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := slog.New(newHandler(w, cfg.Format, levels))
+	slog.SetDefault(logger)
+	return logger, nil
+}
+
+// For returns the logger of a component (package); its level can be set in LOG_LEVELS
+// Call it after Setup, e.g. in constructors, not in package-level variables.
+func For(component string) *slog.Logger {
+	return slog.Default().With(ComponentKey, component)
+}
+
+// levels holds the default log level and per-component overrides
+type levels struct {
+	defaultLevel slog.Level
+	components   map[string]slog.Level
+}
+
+// forComponent returns the minimum level of a component
+func (l *levels) forComponent(component string) slog.Level {
+	if level, ok := l.components[component]; ok {
+		return level
+	}
+	return l.defaultLevel
+}
+
+// parseLevels parses the default level and "component=level,..." overrides
+func parseLevels(defaultLevel, overrides string) (*levels, error) {
+	l := &levels{components: map[string]slog.Level{}}
+	if err := l.defaultLevel.UnmarshalText([]byte(defaultLevel)); err != nil {
+		return nil, fmt.Errorf("invalid LOG_LEVEL %q: %w", defaultLevel, err)
+	}
+
+	for _, entry := range strings.Split(overrides, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		component, level, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid LOG_LEVELS entry %q, expected component=level", entry)
+		}
+
+		var parsed slog.Level
+		if err := parsed.UnmarshalText([]byte(strings.TrimSpace(level))); err != nil {
+			return nil, fmt.Errorf("invalid level for %q: %w", component, err)
+		}
+		l.components[strings.TrimSpace(component)] = parsed
+	}
+
+	return l, nil
+}
+
+// handler filters records by component level and adds request context attributes
+type handler struct {
+	next     slog.Handler
+	levels   *levels
+	minLevel slog.Level
+}
+
+// newHandler creates the root handler writing to w
+func newHandler(w io.Writer, format string, levels *levels) *handler {
+	options := &slog.HandlerOptions{
+		// Filtering happens in handler.Enabled, so let everything through here
+		Level:       slog.LevelDebug - 4,
+		ReplaceAttr: redact,
+	}
+
+	var next slog.Handler
+	if format == "text" {
+		next = slog.NewTextHandler(w, options)
+	} else {
+		next = slog.NewJSONHandler(w, options)
+	}
+
+	return &handler{next: next, levels: levels, minLevel: levels.defaultLevel}
+}
+
+// Enabled implements slog.Handler
+func (h *handler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.minLevel
+}
+
+// Handle implements slog.Handler
+func (h *handler) Handle(ctx context.Context, record slog.Record) error {
+	if ctx != nil {
+		if requestID := identity.RequestIDFromContext(ctx); requestID != "" {
+			record.AddAttrs(slog.String("request_id", requestID))
+		}
+		if actorID, ok := identity.ActorFromContext(ctx); ok {
+			record.AddAttrs(slog.Uint64("actor_id", uint64(actorID)))
+		}
+		if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+			record.AddAttrs(
+				slog.String("trace_id", spanContext.TraceID().String()),
+				slog.String("span_id", spanContext.SpanID().String()),
+			)
+		}
+	}
+	return h.next.Handle(ctx, record)
+}
+
+// WithAttrs implements slog.Handler; a component attribute selects the level
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	minLevel := h.minLevel
+	for _, attr := range attrs {
+		if attr.Key == ComponentKey {
+			minLevel = h.levels.forComponent(attr.Value.String())
+		}
+	}
+	return &handler{next: h.next.WithAttrs(attrs), levels: h.levels, minLevel: minLevel}
+}
+
+// WithGroup implements slog.Handler
+func (h *handler) WithGroup(name string) slog.Handler {
+	return &handler{next: h.next.WithGroup(name), levels: h.levels, minLevel: h.minLevel}
+}
+
+// redact hides the values of sensitive attributes wherever they appear
+func redact(_ []string, attr slog.Attr) slog.Attr {
+	if attr.Value.Kind() != slog.KindGroup && isSensitive(attr.Key) {
+		return slog.String(attr.Key, redacted)
+	}
+	return attr
+}
+
+// isSensitive reports whether an attribute key names a secret
+func isSensitive(key string) bool {
+	key = strings.ToLower(key)
+	switch key {
+	case "authorization", "cookie", "set-cookie", "api_key", "apikey":
+		return true
+	}
+	return strings.Contains(key, "password") ||
+		strings.Contains(key, "token") ||
+		strings.Contains(key, "secret")
+}
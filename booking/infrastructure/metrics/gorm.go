@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"gorm.io/gorm"
+)
+
+// gormStartKey stores the start time of a statement on its *gorm.DB instance
+const gormStartKey = "metrics:start"
+
+// InstrumentGorm records the duration of every GORM statement and exports
+// the connection pool stats of its database/sql pool (go_sql_* metrics)
+func (m *Metrics) InstrumentGorm(db *gorm.DB, dbName string) error {
+	if m == nil {
+		return nil
+	}
+
+	if err := db.Use(&gormPlugin{metrics: m}); err != nil {
+		return err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return m.Register(collectors.NewDBStatsCollector(sqlDB, dbName))
+}
+
+// gormPlugin times statements through GORM callbacks
+type gormPlugin struct {
+	metrics *Metrics
+}
+
+// Name implements gorm.Plugin
+func (p *gormPlugin) Name() string {
+	return "metrics"
+}
+
+// Initialize implements gorm.Plugin
+func (p *gormPlugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	registrations := []struct {
+		operation string
+		before    func(name string, fn func(*gorm.DB)) error
+		after     func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", callbacks.Create().Before("gorm:create").Register, callbacks.Create().After("gorm:create").Register},
+		{"query", callbacks.Query().Before("gorm:query").Register, callbacks.Query().After("gorm:query").Register},
+		{"update", callbacks.Update().Before("gorm:update").Register, callbacks.Update().After("gorm:update").Register},
+		{"delete", callbacks.Delete().Before("gorm:delete").Register, callbacks.Delete().After("gorm:delete").Register},
+		{"row", callbacks.Row().Before("gorm:row").Register, callbacks.Row().After("gorm:row").Register},
+		{"raw", callbacks.Raw().Before("gorm:raw").Register, callbacks.Raw().After("gorm:raw").Register},
+	}
+
+	for _, r := range registrations {
+		if err := r.before("metrics:before_"+r.operation, p.before); err != nil {
+			return err
+		}
+		if err := r.after("metrics:after_"+r.operation, p.after(r.operation)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// before stamps the start time of a statement
+func (p *gormPlugin) before(db *gorm.DB) {
+	db.InstanceSet(gormStartKey, time.Now())
+}
+
+// after observes the duration of a statement; record-not-found is not a failure
+func (p *gormPlugin) after(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(gormStartKey)
+		if !ok {
+			return
+		}
+		start, ok := value.(time.Time)
+		if !ok {
+			return
+		}
+
+		failed := db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound)
+		p.metrics.ObserveQuery("postgres", operation, failed, time.Since(start))
+	}
+}
@@ -0,0 +1,309 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes every metric of the service
+const namespace = "booking"
+
+// Registry is where collectors are registered and gathered from
+// *prometheus.Registry satisfies it; tests pass a fresh one per case.
+type Registry interface {
+	prometheus.Registerer
+	prometheus.Gatherer
+}
+
+// NewRegistry returns a registry with the Go runtime and process collectors
+func NewRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return registry
+}
+
+// Metrics holds the collectors of the service
+// All methods are safe to call on a nil *Metrics, so instrumented code does
+// not need to check whether metrics are enabled.
+type Metrics struct {
+	registry Registry
+
+	httpRequests        *prometheus.CounterVec
+	httpRequestDuration *prometheus.HistogramVec
+	httpInFlight        prometheus.Gauge
+
+	dbQueryDuration *prometheus.HistogramVec
+	dbPoolOpen      *prometheus.GaugeVec
+	dbPoolInUse     *prometheus.GaugeVec
+	dbRoutedQueries *prometheus.CounterVec
+	dbReplicaUp     *prometheus.GaugeVec
+	dbReplicaLag    *prometheus.GaugeVec
+
+	cacheLookups *prometheus.CounterVec
+
+	usersRegistered prometheus.Counter
+	runsSubmitted   prometheus.Counter
+	coinsMinted     prometheus.Counter
+	itemsPurchased  prometheus.Counter
+}
+
+// New creates the service metrics and registers them with registry
+func New(registry Registry) *Metrics {
+	m := &Metrics{
+		registry: registry,
+
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "HTTP requests by method, route template and status code.",
+		}, []string{"method", "route", "status"}),
+		httpRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latency by method, route template and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		httpInFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_in_flight",
+			Help:      "HTTP requests currently being served.",
+		}),
+
+		dbQueryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "db",
+			Name:      "query_duration_seconds",
+			Help:      "Database query latency by driver, operation and outcome.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"driver", "operation", "status"}),
+		dbPoolOpen: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "db",
+			Name:      "pool_open_connections",
+			Help:      "Open connections in the database pool.",
+		}, []string{"driver"}),
+		dbPoolInUse: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "db",
+			Name:      "pool_in_use_connections",
+			Help:      "Connections currently checked out of the database pool.",
+		}, []string{"driver"}),
+		dbRoutedQueries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "db",
+			Name:      "routed_queries_total",
+			Help:      "Read queries by query type, the connection they were routed to (primary, replica) and why.",
+		}, []string{"query", "target", "reason"}),
+		dbReplicaUp: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "db",
+			Name:      "replica_up",
+			Help:      "Whether a read replica passed its last health check (1) or not (0).",
+		}, []string{"replica"}),
+		dbReplicaLag: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "db",
+			Name:      "replica_lag_seconds",
+			Help:      "Replication lag of a read replica at its last health check.",
+		}, []string{"replica"}),
+
+		cacheLookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "cache",
+			Name:      "lookups_total",
+			Help:      "Cache lookups by cache, lookup kind and result (hit, negative_hit, miss, bypass, error).",
+		}, []string{"cache", "lookup", "result"}),
+
+		usersRegistered: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "users_registered_total",
+			Help:      "Users registered.",
+		}),
+		runsSubmitted: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "runs_submitted_total",
+			Help:      "Runs submitted.",
+		}),
+		coinsMinted: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "coins_minted_total",
+			Help:      "Coins minted.",
+		}),
+		itemsPurchased: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "items_purchased_total",
+			Help:      "Items purchased.",
+		}),
+	}
+
+	registry.MustRegister(
+		m.httpRequests,
+		m.httpRequestDuration,
+		m.httpInFlight,
+		m.dbQueryDuration,
+		m.dbPoolOpen,
+		m.dbPoolInUse,
+		m.dbRoutedQueries,
+		m.dbReplicaUp,
+		m.dbReplicaLag,
+		m.cacheLookups,
+		m.usersRegistered,
+		m.runsSubmitted,
+		m.coinsMinted,
+		m.itemsPurchased,
+	)
+	return m
+}
+
+// Handler serves the registry in the Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	if m == nil {
+		return http.NotFoundHandler()
+	}
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Register adds extra collectors, e.g. the database/sql pool stats
+func (m *Metrics) Register(collector prometheus.Collector) error {
+	if m == nil {
+		return nil
+	}
+	return m.registry.Register(collector)
+}
+
+// RequestStarted tracks an HTTP request in flight; call the returned function when it is done
+func (m *Metrics) RequestStarted() func() {
+	if m == nil {
+		return func() {}
+	}
+	m.httpInFlight.Inc()
+	return m.httpInFlight.Dec
+}
+
+// ObserveRequest records a served HTTP request
+// route must be the route template (e.g. /api/v1/users/:id), never the raw path.
+func (m *Metrics) ObserveRequest(method, route string, status int, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	code := strconv.Itoa(status)
+	m.httpRequests.WithLabelValues(method, route, code).Inc()
+	m.httpRequestDuration.WithLabelValues(method, route, code).Observe(duration.Seconds())
+}
+
+// ObserveQuery records a database query
+func (m *Metrics) ObserveQuery(driver, operation string, failed bool, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	status := "ok"
+	if failed {
+		status = "error"
+	}
+	m.dbQueryDuration.WithLabelValues(driver, operation, status).Observe(duration.Seconds())
+}
+
+// AddPoolConnections adjusts the open and in-use connection gauges of a pool
+func (m *Metrics) AddPoolConnections(driver string, open, inUse float64) {
+	if m == nil {
+		return
+	}
+	if open != 0 {
+		m.dbPoolOpen.WithLabelValues(driver).Add(open)
+	}
+	if inUse != 0 {
+		m.dbPoolInUse.WithLabelValues(driver).Add(inUse)
+	}
+}
+
+// ObserveRoute records which connection a read query was routed to
+func (m *Metrics) ObserveRoute(query, target, reason string) {
+	if m == nil {
+		return
+	}
+	m.dbRoutedQueries.WithLabelValues(query, target, reason).Inc()
+}
+
+// SetReplicaHealth records the outcome of a replica health check
+func (m *Metrics) SetReplicaHealth(replica string, up bool, lag time.Duration) {
+	if m == nil {
+		return
+	}
+	value := 0.0
+	if up {
+		value = 1
+	}
+	m.dbReplicaUp.WithLabelValues(replica).Set(value)
+	m.dbReplicaLag.WithLabelValues(replica).Set(lag.Seconds())
+}
+
+// ObserveCacheLookup records a cache lookup and whether it was answered from the cache
+func (m *Metrics) ObserveCacheLookup(cache, lookup, result string) {
+	if m == nil {
+		return
+	}
+	m.cacheLookups.WithLabelValues(cache, lookup, result).Inc()
+}
+
+// QueueDepth reports how many items are waiting to be processed
+type QueueDepth interface {
+	Pending() int
+}
+
+// ObserveQueue exports the depth of a queue as a gauge sampled on every scrape
+func (m *Metrics) ObserveQueue(name string, queue QueueDepth) error {
+	if m == nil {
+		return nil
+	}
+	return m.registry.Register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   namespace,
+		Name:        "queue_depth",
+		Help:        "Items waiting to be processed, by queue.",
+		ConstLabels: prometheus.Labels{"queue": name},
+	}, func() float64 {
+		return float64(queue.Pending())
+	}))
+}
+
+// UserRegistered counts a registration
+func (m *Metrics) UserRegistered() {
+	if m == nil {
+		return
+	}
+	m.usersRegistered.Inc()
+}
+
+// RunSubmitted counts a submitted run
+func (m *Metrics) RunSubmitted() {
+	if m == nil {
+		return
+	}
+	m.runsSubmitted.Inc()
+}
+
+// CoinsMinted counts minted coins
+func (m *Metrics) CoinsMinted(amount int64) {
+	if m == nil || amount <= 0 {
+		return
+	}
+	m.coinsMinted.Add(float64(amount))
+}
+
+// ItemPurchased counts a purchased item
+func (m *Metrics) ItemPurchased() {
+	if m == nil {
+		return
+	}
+	m.itemsPurchased.Inc()
+}
@@ -0,0 +1,154 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// queue is a fixed QueueDepth
+type queue int
+
+func (q queue) Pending() int {
+	return int(q)
+}
+
+func TestMetricsRecordToRegistry(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	m := New(registry)
+
+	m.ObserveRequest("GET", "/api/v1/users/:id", http.StatusOK, 20*time.Millisecond)
+	m.ObserveRequest("GET", "/api/v1/users/:id", http.StatusOK, 30*time.Millisecond)
+	m.ObserveRequest("POST", "/api/v1/users", http.StatusConflict, time.Millisecond)
+	m.ObserveQuery("postgres", "query", false, time.Millisecond)
+	m.ObserveQuery("postgres", "query", true, time.Millisecond)
+	m.ObserveRoute("GetByID", "replica", "default")
+	m.SetReplicaHealth("replica-0", true, 1500*time.Millisecond)
+	m.ObserveCacheLookup("users", "id", "hit")
+	m.ObserveCacheLookup("users", "id", "hit")
+	m.ObserveCacheLookup("users", "email", "miss")
+	m.UserRegistered()
+	m.RunSubmitted()
+	m.RunSubmitted()
+	m.CoinsMinted(25)
+	m.CoinsMinted(-5)
+	m.ItemPurchased()
+
+	done := m.RequestStarted()
+	if got := testutil.ToFloat64(m.httpInFlight); got != 1 {
+		t.Errorf("requests_in_flight = %v while serving, want 1", got)
+	}
+	done()
+
+	for name, tc := range map[string]struct {
+		collector prometheus.Collector
+		want      float64
+	}{
+		"requests GET 200":      {m.httpRequests.WithLabelValues("GET", "/api/v1/users/:id", "200"), 2},
+		"requests POST 409":     {m.httpRequests.WithLabelValues("POST", "/api/v1/users", "409"), 1},
+		"requests in flight":    {m.httpInFlight, 0},
+		"routed queries":        {m.dbRoutedQueries.WithLabelValues("GetByID", "replica", "default"), 1},
+		"replica up":            {m.dbReplicaUp.WithLabelValues("replica-0"), 1},
+		"replica lag":           {m.dbReplicaLag.WithLabelValues("replica-0"), 1.5},
+		"cache hits":            {m.cacheLookups.WithLabelValues("users", "id", "hit"), 2},
+		"cache misses":          {m.cacheLookups.WithLabelValues("users", "email", "miss"), 1},
+		"users registered":      {m.usersRegistered, 1},
+		"runs submitted":        {m.runsSubmitted, 2},
+		"coins minted (no neg)": {m.coinsMinted, 25},
+		"items purchased":       {m.itemsPurchased, 1},
+	} {
+		if got := testutil.ToFloat64(tc.collector); got != tc.want {
+			t.Errorf("%s = %v, want %v", name, got, tc.want)
+		}
+	}
+
+	// Histograms only show up through the registry
+	if n := testutil.CollectAndCount(m.dbQueryDuration); n != 2 {
+		t.Errorf("query_duration_seconds series = %d, want 2 (ok and error)", n)
+	}
+	if n, err := testutil.GatherAndCount(registry, "booking_http_request_duration_seconds"); err != nil || n != 2 {
+		t.Errorf("request_duration_seconds series = %d, %v, want 2", n, err)
+	}
+}
+
+func TestObserveQueueSamplesOnScrape(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	m := New(registry)
+
+	if err := m.ObserveQueue("audit", queue(7)); err != nil {
+		t.Fatal(err)
+	}
+	// A queue name is registered once
+	if err := m.ObserveQueue("audit", queue(1)); err == nil {
+		t.Error("ObserveQueue registered the same queue twice")
+	}
+
+	expected := `
+# HELP booking_queue_depth Items waiting to be processed, by queue.
+# TYPE booking_queue_depth gauge
+booking_queue_depth{queue="audit"} 7
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "booking_queue_depth"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHandlerServesExpositionFormat(t *testing.T) {
+	m := New(NewRegistry())
+	m.ObserveRequest("GET", "/health", http.StatusOK, time.Millisecond)
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /metrics = %d, want 200", rec.Code)
+	}
+
+	body, _ := io.ReadAll(rec.Body)
+	for _, want := range []string{
+		`booking_http_requests_total{method="GET",route="/health",status="200"} 1`,
+		"booking_http_requests_in_flight 0",
+		// Business counters are exported before anything increments them
+		"booking_runs_submitted_total 0",
+		"booking_coins_minted_total 0",
+		"booking_items_purchased_total 0",
+		"go_goroutines",
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("exposition is missing %q", want)
+		}
+	}
+}
+
+func TestNilMetricsAreNoOps(t *testing.T) {
+	var m *Metrics
+
+	m.RequestStarted()()
+	m.ObserveRequest("GET", "/health", http.StatusOK, time.Millisecond)
+	m.ObserveQuery("postgres", "query", false, time.Millisecond)
+	m.AddPoolConnections("mongo", 1, 1)
+	m.ObserveRoute("GetByID", "primary", "read_your_writes")
+	m.SetReplicaHealth("replica-0", false, 0)
+	m.ObserveCacheLookup("users", "id", "miss")
+	m.UserRegistered()
+	m.RunSubmitted()
+	m.CoinsMinted(10)
+	m.ItemPurchased()
+	if err := m.ObserveQueue("audit", queue(1)); err != nil {
+		t.Errorf("ObserveQueue on nil metrics = %v", err)
+	}
+	if err := m.Register(prometheus.NewCounter(prometheus.CounterOpts{Name: "x"})); err != nil {
+		t.Errorf("Register on nil metrics = %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("GET /metrics with metrics disabled = %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// MongoCommandMonitor records the duration of every MongoDB command
+func (m *Metrics) MongoCommandMonitor() *event.CommandMonitor {
+	if m == nil {
+		return nil
+	}
+	return &event.CommandMonitor{
+		Succeeded: func(_ context.Context, evt *event.CommandSucceededEvent) {
+			m.ObserveQuery("mongo", evt.CommandName, false, evt.Duration)
+		},
+		Failed: func(_ context.Context, evt *event.CommandFailedEvent) {
+			m.ObserveQuery("mongo", evt.CommandName, true, evt.Duration)
+		},
+	}
+}
+
+// MongoPoolMonitor tracks open and checked-out connections of the MongoDB pool
+func (m *Metrics) MongoPoolMonitor() *event.PoolMonitor {
+	if m == nil {
+		return nil
+	}
+	return &event.PoolMonitor{
+		Event: func(evt *event.PoolEvent) {
+			switch evt.Type {
+			case event.ConnectionCreated:
+				m.AddPoolConnections("mongo", 1, 0)
+			case event.ConnectionClosed:
+				m.AddPoolConnections("mongo", -1, 0)
+			case event.GetSucceeded:
+				m.AddPoolConnections("mongo", 0, 1)
+			case event.ConnectionReturned:
+				m.AddPoolConnections("mongo", 0, -1)
+			}
+		},
+	}
+}
@@ -0,0 +1,79 @@
+package observer
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"booking/domain/entity"
+	"booking/domain/repository"
+	"booking/infrastructure/logging"
+)
+
+// auditAppendTimeout bounds how long an observer goroutine may wait on the audit store
+const auditAppendTimeout = 10 * time.Second
+
+// AuditObserver is a concrete observer that appends every state-changing event to the audit log
+type AuditObserver struct {
+	auditRepo repository.AuditRepository
+	logger    *slog.Logger
+}
+
+// NewAuditObserver creates a new AuditObserver
+func NewAuditObserver(auditRepo repository.AuditRepository) *AuditObserver {
+	return &AuditObserver{auditRepo: auditRepo, logger: logging.For("audit")}
+}
+
+// Update implements the Observer interface
+func (a *AuditObserver) Update(event Event) {
+	targetType, targetID, ok := auditTarget(event)
+	if !ok {
+		return
+	}
+
+	before, after := event.Before, event.Data
+	switch event.Type {
+	case UserDeleted:
+		// Data is the user as it was just before deletion
+		before, after = event.Data, nil
+	case UserPurged:
+		// Only the fact of the purge is kept; snapshots would defeat anonymization
+		before, after = nil, nil
+	}
+
+	entry, err := entity.NewAuditEntry(
+		event.ActorID,
+		string(event.Type),
+		targetType,
+		targetID,
+		event.RequestID,
+		before,
+		after,
+	)
+	if err != nil {
+		a.logger.LogAttrs(context.Background(), slog.LevelError, "failed to build audit entry",
+			append(eventAttrs(event), slog.Any("error", err))...)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), auditAppendTimeout)
+	defer cancel()
+
+	if err := a.auditRepo.Append(ctx, entry); err != nil {
+		a.logger.LogAttrs(ctx, slog.LevelError, "failed to append audit entry",
+			append(eventAttrs(event), slog.Any("error", err))...)
+	}
+}
+
+// auditTarget identifies the entity an event is about
+func auditTarget(event Event) (string, string, bool) {
+	switch data := event.Data.(type) {
+	case *entity.User:
+		return "user", strconv.FormatUint(uint64(data.ID), 10), true
+	case *entity.RoleChange:
+		return "user", strconv.FormatUint(uint64(data.UserID), 10), true
+	default:
+		return "", "", false
+	}
+}
@@ -1,23 +1,52 @@
 package observer
 
 import (
+	"context"
+	"fmt"
+	"log/slog"
 	"sync"
+	"sync/atomic"
+	"time"
 	"booking/domain/entity"
+	"booking/domain/identity"
+	"booking/infrastructure/logging"
 )
 
 // EventType represents different types of events
 type EventType string
 
 const (
-	UserCreated EventType = "user.created"
-	UserUpdated EventType = "user.updated"
-	UserDeleted EventType = "user.deleted"
+	UserCreated  EventType = "user.created"
+	UserUpdated  EventType = "user.updated"
+	UserDeleted  EventType = "user.deleted"
+	UserRestored EventType = "user.restored"
+	UserPurged   EventType = "user.purged"
+	RoleGranted  EventType = "role.granted"
+	RoleRevoked  EventType = "role.revoked"
 )
 
 // Event represents an event in the system
 type Event struct {
 	Type EventType
 	Data interface{}
+	// Before holds the state prior to the change, when the publisher knows it
+	Before interface{}
+	// ActorID and RequestID identify who triggered the change (0/"" for system jobs)
+	ActorID   uint
+	RequestID string
+}
+
+// NewEvent creates an event stamped with the actor and request ID carried by ctx
+// Observers run asynchronously without the request context, so this is how
+// they learn who triggered the change.
+func NewEvent(ctx context.Context, eventType EventType, data interface{}) Event {
+	actorID, _ := identity.ActorFromContext(ctx)
+	return Event{
+		Type:      eventType,
+		Data:      data,
+		ActorID:   actorID,
+		RequestID: identity.RequestIDFromContext(ctx),
+	}
 }
 
 // Observer defines the interface for event observers
@@ -30,12 +59,22 @@ type Observer interface {
 type Subject struct {
 	observers []Observer
 	mu        sync.RWMutex
+	pending   atomic.Int64
+	inFlight  sync.WaitGroup
+	// queued holds when each notification still in flight was sent, by sequence number
+	queued    map[uint64]time.Time
+	queuedMu  sync.Mutex
+	seq       uint64
+	closed    bool
+	logger    *slog.Logger
 }
 
 // NewSubject creates a new Subject
 func NewSubject() *Subject {
 	return &Subject{
 		observers: make([]Observer, 0),
+		queued:    make(map[uint64]time.Time),
+		logger:    logging.For("events"),
 	}
 }
 
@@ -64,56 +103,152 @@ func (s *Subject) Notify(event Event) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	
+	if s.closed {
+		s.logger.LogAttrs(context.Background(), slog.LevelWarn, "event dropped after shutdown", eventAttrs(event)...)
+		return
+	}
+	
 	for _, observer := range s.observers {
-		go observer.Update(event) // Async notification
+		s.pending.Add(1)
+		s.inFlight.Add(1)
+		seq := s.enqueue()
+		go func(observer Observer) { // Async notification
+			defer s.inFlight.Done()
+			defer s.pending.Add(-1)
+			defer s.dequeue(seq)
+			observer.Update(event)
+		}(observer)
+	}
+}
+
+// Close stops accepting events and waits for observers to finish the ones in flight
+// It gives up when ctx is done; call it after the producers of events have stopped.
+func (s *Subject) Close(ctx context.Context) error {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	
+	done := make(chan struct{})
+	go func() {
+		s.inFlight.Wait()
+		close(done)
+	}()
+	
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("observers still busy with %d events: %w", s.Pending(), ctx.Err())
+	}
+}
+
+// Pending returns how many observer notifications are still being processed
+func (s *Subject) Pending() int {
+	return int(s.pending.Load())
+}
+
+// OldestPending returns how long the oldest notification still being processed has waited
+// It is 0 when nothing is pending.
+func (s *Subject) OldestPending() time.Duration {
+	s.queuedMu.Lock()
+	defer s.queuedMu.Unlock()
+	
+	var oldest time.Time
+	for _, sent := range s.queued {
+		if oldest.IsZero() || sent.Before(oldest) {
+			oldest = sent
+		}
 	}
+	if oldest.IsZero() {
+		return 0
+	}
+	return time.Since(oldest)
+}
+
+// enqueue records when a notification is sent and returns its sequence number
+func (s *Subject) enqueue() uint64 {
+	s.queuedMu.Lock()
+	defer s.queuedMu.Unlock()
+	s.seq++
+	s.queued[s.seq] = time.Now()
+	return s.seq
+}
+
+// dequeue forgets a notification once its observer is done
+func (s *Subject) dequeue(seq uint64) {
+	s.queuedMu.Lock()
+	defer s.queuedMu.Unlock()
+	delete(s.queued, seq)
 }
 
 // UserEventLogger is a concrete observer that logs user events
-type UserEventLogger struct{}
+type UserEventLogger struct {
+	logger *slog.Logger
+}
 
 // NewUserEventLogger creates a new UserEventLogger
 func NewUserEventLogger() *UserEventLogger {
-	return &UserEventLogger{}
+	return &UserEventLogger{logger: logging.For("events")}
 }
 
 // Update implements the Observer interface
+// Only IDs are logged; names and emails stay out of the logs.
 func (l *UserEventLogger) Update(event Event) {
-	switch event.Type {
-	case UserCreated:
-		if user, ok := event.Data.(*entity.User); ok {
-			println("📝 [LOG] User created:", user.Username, "- Email:", user.Email)
-		}
-	case UserUpdated:
-		if user, ok := event.Data.(*entity.User); ok {
-			println("📝 [LOG] User updated:", user.Username)
-		}
-	case UserDeleted:
-		if id, ok := event.Data.(uint); ok {
-			println("📝 [LOG] User deleted: ID", id)
-		}
+	attrs := eventAttrs(event)
+	
+	switch data := event.Data.(type) {
+	case *entity.User:
+		attrs = append(attrs, slog.Uint64("user_id", uint64(data.ID)))
+	case *entity.RoleChange:
+		attrs = append(attrs,
+			slog.Uint64("user_id", uint64(data.UserID)),
+			slog.String("role", data.Role),
+		)
+	default:
+		return
 	}
+	
+	l.logger.LogAttrs(context.Background(), slog.LevelInfo, "user event", attrs...)
 }
 
 // UserEventNotifier is another concrete observer for notifications
-type UserEventNotifier struct{}
+type UserEventNotifier struct {
+	logger *slog.Logger
+}
 
 // NewUserEventNotifier creates a new UserEventNotifier
 func NewUserEventNotifier() *UserEventNotifier {
-	return &UserEventNotifier{}
+	return &UserEventNotifier{logger: logging.For("notify")}
 }
 
 // Update implements the Observer interface
 func (n *UserEventNotifier) Update(event Event) {
+	var msg string
 	switch event.Type {
 	case UserCreated:
-		if user, ok := event.Data.(*entity.User); ok {
-			println("📧 [NOTIFY] Welcome email sent to:", user.Email)
-		}
+		msg = "welcome email sent"
 	case UserUpdated:
-		if user, ok := event.Data.(*entity.User); ok {
-			println("📧 [NOTIFY] Update notification sent to:", user.Email)
-		}
+		msg = "update notification sent"
+	default:
+		return
+	}
+	
+	if user, ok := event.Data.(*entity.User); ok {
+		attrs := append(eventAttrs(event), slog.Uint64("user_id", uint64(user.ID)))
+		n.logger.LogAttrs(context.Background(), slog.LevelInfo, msg, attrs...)
 	}
 }
 
+// eventAttrs returns the log attributes identifying an event and its origin
+// Observers run on their own goroutine, so the request ID and actor are taken
+// from the event rather than from a context.
+func eventAttrs(event Event) []slog.Attr {
+	attrs := []slog.Attr{slog.String("event_type", string(event.Type))}
+	if event.RequestID != "" {
+		attrs = append(attrs, slog.String("request_id", event.RequestID))
+	}
+	if event.ActorID != 0 {
+		attrs = append(attrs, slog.Uint64("actor_id", uint64(event.ActorID)))
+	}
+	return attrs
+}
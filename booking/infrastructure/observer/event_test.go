@@ -0,0 +1,46 @@
+package observer
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// blockingObserver holds each event until release is closed
+type blockingObserver struct {
+	release chan struct{}
+}
+
+func (o blockingObserver) Update(Event) {
+	<-o.release
+}
+
+func TestOldestPending(t *testing.T) {
+	s := NewSubject()
+	obs := blockingObserver{release: make(chan struct{})}
+	s.Attach(obs)
+
+	if lag := s.OldestPending(); lag != 0 {
+		t.Fatalf("OldestPending() = %s with nothing sent, want 0", lag)
+	}
+
+	s.Notify(Event{Type: UserCreated})
+	time.Sleep(20 * time.Millisecond)
+	s.Notify(Event{Type: UserUpdated})
+	if lag := s.OldestPending(); lag < 20*time.Millisecond {
+		t.Errorf("OldestPending() = %s, want the age of the first event", lag)
+	}
+	if got := s.Pending(); got != 2 {
+		t.Errorf("Pending() = %d, want 2", got)
+	}
+
+	close(obs.release)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if lag := s.OldestPending(); lag != 0 {
+		t.Errorf("OldestPending() = %s after delivery, want 0", lag)
+	}
+}
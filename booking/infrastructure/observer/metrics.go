@@ -0,0 +1,24 @@
+package observer
+
+import (
+	"booking/infrastructure/metrics"
+)
+
+// MetricsObserver is a concrete observer that counts business events
+type MetricsObserver struct {
+	metrics *metrics.Metrics
+}
+
+// NewMetricsObserver creates a new MetricsObserver
+func NewMetricsObserver(m *metrics.Metrics) *MetricsObserver {
+	return &MetricsObserver{metrics: m}
+}
+
+// Update implements the Observer interface
+// Runs, coins and items have no events yet; their use cases record them directly.
+func (o *MetricsObserver) Update(event Event) {
+	switch event.Type {
+	case UserCreated:
+		o.metrics.UserRegistered()
+	}
+}
@@ -0,0 +1,102 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often full (idle) buckets are dropped from memory
+const sweepInterval = time.Minute
+
+// bucket is the state of one token bucket
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	policy  Policy
+}
+
+// MemoryStore keeps token buckets in process memory
+// Limits are per instance, so with N instances a client gets up to N times
+// the configured rate; use RedisStore to share buckets.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+	stop    chan struct{}
+	once    sync.Once
+}
+
+// NewMemoryStore creates an in-memory store and starts its sweeper
+func NewMemoryStore() *MemoryStore {
+	s := &MemoryStore{
+		buckets: map[string]*bucket{},
+		now:     time.Now,
+		stop:    make(chan struct{}),
+	}
+	go s.sweep()
+	return s
+}
+
+// Take removes one token from the bucket of key under policy
+func (s *MemoryStore) Take(ctx context.Context, key string, policy Policy) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	key = policy.Name + ":" + key
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(policy.Limit), updated: now, policy: policy}
+		s.buckets[key] = b
+	}
+	b.refill(now)
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+
+	return newResult(policy, allowed, b.tokens), nil
+}
+
+// Close stops the sweeper
+func (s *MemoryStore) Close() error {
+	s.once.Do(func() { close(s.stop) })
+	return nil
+}
+
+// sweep periodically drops buckets that have refilled completely
+// A full bucket is indistinguishable from a missing one, so this loses nothing.
+func (s *MemoryStore) sweep() {
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		}
+
+		s.mu.Lock()
+		now := s.now()
+		for key, b := range s.buckets {
+			b.refill(now)
+			if b.tokens >= float64(b.policy.Limit) {
+				delete(s.buckets, key)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+// refill adds the tokens earned since the last update
+func (b *bucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.updated).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(float64(b.policy.Limit), b.tokens+elapsed*b.policy.ratePerSecond())
+		b.updated = now
+	}
+}
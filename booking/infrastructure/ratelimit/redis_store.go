@@ -0,0 +1,111 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"booking/config"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// keyPrefix namespaces the bucket keys in Redis
+const keyPrefix = "ratelimit:"
+
+// takeScript refills and takes from a bucket in one atomic step
+// KEYS[1] bucket hash; ARGV: limit, period and now, both in milliseconds.
+// Returns {allowed, tokens left}; tokens are a string because Redis truncates
+// Lua numbers to integers.
+var takeScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local rate = limit / tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+  tokens = limit
+  ts = now
+end
+
+if now > ts then
+  tokens = math.min(limit, tokens + (now - ts) * rate)
+  ts = now
+end
+
+local allowed = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(ts))
+redis.call('PEXPIRE', KEYS[1], math.ceil((limit - tokens) / rate) + 1000)
+
+return {allowed, tostring(tokens)}
+`)
+
+// RedisStore keeps token buckets in Redis, shared by every instance
+// It only needs HMGET, HSET, PEXPIRE and EVALSHA/EVAL, so any server speaking
+// the Redis protocol with Lua scripting (e.g. miniredis in tests) works.
+type RedisStore struct {
+	client redis.UniversalClient
+	now    func() time.Time
+}
+
+// NewRedisStore creates a store on an existing Redis client
+func NewRedisStore(client redis.UniversalClient) *RedisStore {
+	return &RedisStore{client: client, now: time.Now}
+}
+
+// NewRedisStoreFromConfig connects to the Redis server in config
+func NewRedisStoreFromConfig(cfg config.RateLimitConfig) (*RedisStore, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.RedisAddr,
+		Password: cfg.RedisPassword,
+		DB:       cfg.RedisDB,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to Redis at %s: %w", cfg.RedisAddr, err)
+	}
+
+	return NewRedisStore(client), nil
+}
+
+// Take removes one token from the bucket of key under policy
+func (s *RedisStore) Take(ctx context.Context, key string, policy Policy) (Result, error) {
+	now := s.now().UnixMilli()
+
+	reply, err := takeScript.Run(ctx, s.client,
+		[]string{keyPrefix + policy.Name + ":" + key},
+		policy.Limit, policy.Period.Milliseconds(), now,
+	).Slice()
+	if err != nil {
+		return Result{}, err
+	}
+	if len(reply) != 2 {
+		return Result{}, fmt.Errorf("unexpected rate limit script reply: %v", reply)
+	}
+
+	allowed, _ := reply[0].(int64)
+	tokensText, _ := reply[1].(string)
+	tokens, err := strconv.ParseFloat(tokensText, 64)
+	if err != nil {
+		return Result{}, fmt.Errorf("unexpected rate limit script reply: %v", reply)
+	}
+
+	return newResult(policy, allowed == 1, tokens), nil
+}
+
+// Close closes the Redis client
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
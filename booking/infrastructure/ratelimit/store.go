@@ -0,0 +1,114 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"booking/config"
+)
+
+// StoreType represents the backend keeping the token buckets
+type StoreType string
+
+const (
+	MemoryStoreType StoreType = "memory"
+	RedisStoreType  StoreType = "redis"
+)
+
+// Policy is a token bucket: Limit tokens, refilled evenly over Period
+// A client may burst up to Limit requests and then sustain Limit per Period.
+type Policy struct {
+	Name   string
+	Limit  int
+	Period time.Duration
+}
+
+// ParsePolicy parses a "<limit>/<period>" spec such as "120/1m"
+// An empty or "off" spec returns a zero Policy, which disables limiting.
+func ParsePolicy(name, spec string) (Policy, error) {
+	if spec == "" || spec == "off" {
+		return Policy{}, nil
+	}
+
+	limit, period, ok := strings.Cut(spec, "/")
+	if !ok {
+		return Policy{}, fmt.Errorf("rate limit %q: expected <limit>/<period>, got %q", name, spec)
+	}
+
+	n, err := strconv.Atoi(limit)
+	if err != nil || n <= 0 {
+		return Policy{}, fmt.Errorf("rate limit %q: invalid limit %q", name, limit)
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return Policy{}, fmt.Errorf("rate limit %q: invalid period %q", name, period)
+	}
+
+	return Policy{Name: name, Limit: n, Period: d}, nil
+}
+
+// Enabled reports whether the policy limits anything
+func (p Policy) Enabled() bool {
+	return p.Limit > 0
+}
+
+// ratePerSecond returns how many tokens are refilled per second
+func (p Policy) ratePerSecond() float64 {
+	return float64(p.Limit) / p.Period.Seconds()
+}
+
+// Result is the outcome of taking a token
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter is how long until a token is available again (zero when allowed)
+	RetryAfter time.Duration
+	// ResetAfter is how long until the bucket is full again
+	ResetAfter time.Duration
+}
+
+// newResult derives a Result from the tokens left in a bucket
+func newResult(policy Policy, allowed bool, tokens float64) Result {
+	rate := policy.ratePerSecond()
+	result := Result{
+		Allowed:    allowed,
+		Limit:      policy.Limit,
+		Remaining:  int(math.Floor(tokens)),
+		ResetAfter: secondsToDuration((float64(policy.Limit) - tokens) / rate),
+	}
+	if !allowed {
+		result.RetryAfter = secondsToDuration((1 - tokens) / rate)
+	}
+	return result
+}
+
+// secondsToDuration converts fractional seconds to a duration
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// Store keeps token buckets
+// Implementations must take tokens atomically so that concurrent requests
+// (and, for shared stores, other instances) cannot overspend a bucket.
+type Store interface {
+	// Take removes one token from the bucket of key under policy
+	Take(ctx context.Context, key string, policy Policy) (Result, error)
+	Close() error
+}
+
+// NewStore creates the store selected in config
+func NewStore(cfg config.RateLimitConfig) (Store, error) {
+	switch StoreType(cfg.Store) {
+	case MemoryStoreType:
+		return NewMemoryStore(), nil
+	case RedisStoreType:
+		return NewRedisStoreFromConfig(cfg)
+	default:
+		return nil, fmt.Errorf("unsupported rate limit store: %s", cfg.Store)
+	}
+}
@@ -0,0 +1,149 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// clock is a manually advanced time source
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+// storeCase builds a store whose time is read from clk
+type storeCase struct {
+	name string
+	new  func(t *testing.T, clk *clock) Store
+}
+
+func storeCases() []storeCase {
+	return []storeCase{
+		{name: "memory", new: func(t *testing.T, clk *clock) Store {
+			s := NewMemoryStore()
+			s.now = clk.Now
+			t.Cleanup(func() { s.Close() })
+			return s
+		}},
+		{name: "redis", new: func(t *testing.T, clk *clock) Store {
+			mr := miniredis.RunT(t)
+			s := NewRedisStore(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+			s.now = clk.Now
+			t.Cleanup(func() { s.Close() })
+			return s
+		}},
+	}
+}
+
+func TestStoreTakesUntilEmpty(t *testing.T) {
+	policy := Policy{Name: "api", Limit: 3, Period: 3 * time.Second}
+
+	for _, tc := range storeCases() {
+		t.Run(tc.name, func(t *testing.T) {
+			clk := &clock{now: time.Unix(1_700_000_000, 0)}
+			store := tc.new(t, clk)
+			ctx := context.Background()
+
+			for i := 0; i < policy.Limit; i++ {
+				res, err := store.Take(ctx, "client", policy)
+				if err != nil {
+					t.Fatalf("Take #%d: %v", i+1, err)
+				}
+				if !res.Allowed || res.Remaining != policy.Limit-i-1 || res.Limit != policy.Limit {
+					t.Fatalf("Take #%d = %+v, want allowed with %d remaining", i+1, res, policy.Limit-i-1)
+				}
+			}
+
+			res, err := store.Take(ctx, "client", policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Allowed || res.Remaining != 0 {
+				t.Fatalf("Take on an empty bucket = %+v, want denied", res)
+			}
+			if res.RetryAfter != time.Second || res.ResetAfter != policy.Period {
+				t.Errorf("RetryAfter, ResetAfter = %v, %v, want 1s, %v", res.RetryAfter, res.ResetAfter, policy.Period)
+			}
+
+			// Other keys and other policies have buckets of their own
+			if res, _ := store.Take(ctx, "other", policy); !res.Allowed {
+				t.Error("another key shares the bucket")
+			}
+			if res, _ := store.Take(ctx, "client", Policy{Name: "login", Limit: 1, Period: time.Minute}); !res.Allowed {
+				t.Error("another policy shares the bucket")
+			}
+		})
+	}
+}
+
+func TestStoreRefillsOverPeriod(t *testing.T) {
+	policy := Policy{Name: "api", Limit: 2, Period: 2 * time.Second}
+
+	for _, tc := range storeCases() {
+		t.Run(tc.name, func(t *testing.T) {
+			clk := &clock{now: time.Unix(1_700_000_000, 0)}
+			store := tc.new(t, clk)
+			ctx := context.Background()
+
+			for i := 0; i < policy.Limit; i++ {
+				store.Take(ctx, "client", policy)
+			}
+			if res, _ := store.Take(ctx, "client", policy); res.Allowed {
+				t.Fatal("bucket not empty after Limit takes")
+			}
+
+			// One token is earned per Period/Limit
+			clk.Advance(500 * time.Millisecond)
+			if res, _ := store.Take(ctx, "client", policy); res.Allowed {
+				t.Fatal("allowed before a token was earned")
+			}
+			clk.Advance(500 * time.Millisecond)
+			res, err := store.Take(ctx, "client", policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !res.Allowed || res.Remaining != 0 {
+				t.Fatalf("Take after one refill = %+v, want allowed with 0 remaining", res)
+			}
+
+			// A long idle period never fills the bucket past Limit
+			clk.Advance(time.Hour)
+			res, _ = store.Take(ctx, "client", policy)
+			if res.Remaining != policy.Limit-1 {
+				t.Errorf("Remaining after idling = %d, want %d", res.Remaining, policy.Limit-1)
+			}
+		})
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	got, err := ParsePolicy("api", "120/1m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Policy{Name: "api", Limit: 120, Period: time.Minute}); got != want {
+		t.Errorf("ParsePolicy = %+v, want %+v", got, want)
+	}
+
+	for _, spec := range []string{"", "off"} {
+		if p, err := ParsePolicy("api", spec); err != nil || p.Enabled() {
+			t.Errorf("ParsePolicy(%q) = %+v, %v, want a disabled policy", spec, p, err)
+		}
+	}
+	for _, spec := range []string{"120", "0/1m", "x/1m", "10/0s", "10/soon"} {
+		if _, err := ParsePolicy("api", spec); err == nil {
+			t.Errorf("ParsePolicy(%q) accepted an invalid spec", spec)
+		}
+	}
+}
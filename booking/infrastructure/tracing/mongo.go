@@ -0,0 +1,46 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/event"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// NewMongoCommandMonitor creates a client span for every MongoDB command
+// Only the command name and collection are recorded; command documents carry user data.
+func NewMongoCommandMonitor() *event.CommandMonitor {
+	tracer := Tracer("database")
+	var spans sync.Map // request ID -> trace.Span
+
+	finish := func(requestID int64, err error) {
+		if value, ok := spans.LoadAndDelete(requestID); ok {
+			Finish(value.(trace.Span), err)
+		}
+	}
+
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, evt *event.CommandStartedEvent) {
+			collection, _ := evt.Command.Lookup(evt.CommandName).StringValueOK()
+			_, span := tracer.Start(ctx, "mongo."+evt.CommandName,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					semconv.DBSystemMongoDB,
+					semconv.DBOperationName(evt.CommandName),
+					semconv.DBCollectionName(collection),
+					semconv.DBNamespace(evt.DatabaseName),
+				),
+			)
+			spans.Store(evt.RequestID, span)
+		},
+		Succeeded: func(_ context.Context, evt *event.CommandSucceededEvent) {
+			finish(evt.RequestID, nil)
+		},
+		Failed: func(_ context.Context, evt *event.CommandFailedEvent) {
+			finish(evt.RequestID, errors.New(evt.Failure))
+		},
+	}
+}
@@ -0,0 +1,100 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"booking/config"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Exporters selectable with TRACING_EXPORTER
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// instrumentationName identifies the tracers of this service
+const instrumentationName = "booking"
+
+// Setup installs the global tracer provider and W3C trace context propagation
+// The OTLP exporter is configured through the standard OTEL_EXPORTER_OTLP_*
+// variables. The returned function flushes pending spans and must be called
+// before exit.
+func Setup(ctx context.Context, cfg config.TracingConfig) (func(context.Context) error, error) {
+	// Propagate incoming trace context even when this service exports nothing
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var exporterOption sdktrace.TracerProviderOption
+	switch cfg.Exporter {
+	case "", ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+		if err != nil {
+			return nil, err
+		}
+		exporterOption = sdktrace.WithSyncer(exporter)
+	case ExporterOTLP:
+		exporter, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, err
+		}
+		exporterOption = sdktrace.WithBatcher(exporter)
+	default:
+		return nil, fmt.Errorf("unsupported tracing exporter: %s", cfg.Exporter)
+	}
+
+	provider, err := NewTracerProvider(cfg.ServiceName, cfg.SampleRatio, exporterOption)
+	if err != nil {
+		return nil, err
+	}
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// NewTracerProvider creates a tracer provider for the service
+// Child spans follow their parent's sampling decision; root spans are sampled
+// at sampleRatio. Tests pass sdktrace.WithSyncer(tracetest.NewInMemoryExporter()).
+func NewTracerProvider(serviceName string, sampleRatio float64, opts ...sdktrace.TracerProviderOption) (*sdktrace.TracerProvider, error) {
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	opts = append([]sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+	}, opts...)
+	return sdktrace.NewTracerProvider(opts...), nil
+}
+
+// Tracer returns the tracer of a layer from the global provider
+func Tracer(layer string) trace.Tracer {
+	return otel.Tracer(instrumentationName + "/" + layer)
+}
+
+// Finish marks span as failed when err is set and ends it
+func Finish(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,152 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// newTestProvider installs a global provider sampling every root span into an in-memory exporter
+func newTestProvider(t *testing.T, sampleRatio float64) *tracetest.InMemoryExporter {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider, err := NewTracerProvider("booking-test", sampleRatio, sdktrace.WithSyncer(exporter))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		provider.Shutdown(context.Background())
+	})
+	return exporter
+}
+
+// attributes indexes the attributes of a span by key
+func attributes(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	values := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes {
+		values[kv.Key] = kv.Value
+	}
+	return values
+}
+
+func TestFinishRecordsErrors(t *testing.T) {
+	exporter := newTestProvider(t, 1)
+	tracer := Tracer("usecase/test")
+
+	_, ok := tracer.Start(context.Background(), "ok")
+	Finish(ok, nil)
+	_, failed := tracer.Start(context.Background(), "failed")
+	Finish(failed, errors.New("boom"))
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("exported %d spans, want 2", len(spans))
+	}
+	if spans[0].Status.Code != codes.Unset || len(spans[0].Events) != 0 {
+		t.Errorf("successful span has status %v and %d events", spans[0].Status.Code, len(spans[0].Events))
+	}
+	if spans[1].Status.Code != codes.Error || spans[1].Status.Description != "boom" {
+		t.Errorf("failed span status = %+v, want Error boom", spans[1].Status)
+	}
+	if len(spans[1].Events) != 1 || spans[1].Events[0].Name != "exception" {
+		t.Errorf("failed span events = %+v, want one exception", spans[1].Events)
+	}
+	if got := spans[1].InstrumentationScope.Name; got != "booking/usecase/test" {
+		t.Errorf("instrumentation scope = %q, want booking/usecase/test", got)
+	}
+}
+
+func TestChildSpansFollowParentSampling(t *testing.T) {
+	exporter := newTestProvider(t, 0)
+	tracer := Tracer("usecase/test")
+
+	// A root span is dropped at ratio 0...
+	_, root := tracer.Start(context.Background(), "root")
+	root.End()
+	if n := len(exporter.GetSpans()); n != 0 {
+		t.Fatalf("exported %d root spans at ratio 0", n)
+	}
+
+	// ...but a caller that sampled its trace is always followed
+	parent := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})
+	_, child := tracer.Start(trace.ContextWithRemoteSpanContext(context.Background(), parent), "child")
+	child.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 || spans[0].SpanContext.TraceID() != parent.TraceID() {
+		t.Fatalf("exported %+v, want the child in the caller's trace", spans)
+	}
+}
+
+// tracedRow is a throwaway model for the GORM plugin test
+type tracedRow struct {
+	ID    uint
+	Email string
+}
+
+func TestGormPluginSpansStatements(t *testing.T) {
+	exporter := newTestProvider(t, 1)
+
+	// DryRun builds statements without a server, so every callback still runs;
+	// the default transaction is skipped because beginning one would connect
+	db, err := gorm.Open(postgres.Open("host=localhost dbname=test"), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(NewGormPlugin()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, parent := Tracer("usecase/test").Start(context.Background(), "UserUseCase.GetUserByEmail")
+	var row tracedRow
+	db.WithContext(ctx).Where("email = ?", "secret@example.com").First(&row)
+	db.WithContext(ctx).Create(&tracedRow{Email: "secret@example.com"})
+	parent.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 3 {
+		t.Fatalf("exported %d spans, want 2 statements and the parent", len(spans))
+	}
+
+	for i, operation := range []string{"query", "create"} {
+		span := spans[i]
+		if span.Name != "gorm."+operation || span.SpanKind != trace.SpanKindClient {
+			t.Errorf("span %d = %s (%v), want gorm.%s client span", i, span.Name, span.SpanKind, operation)
+		}
+		if span.Parent.SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("gorm.%s is not a child of the use case span", operation)
+		}
+
+		attrs := attributes(span)
+		if got := attrs["db.collection.name"].AsString(); got != "traced_rows" {
+			t.Errorf("gorm.%s db.collection.name = %q, want traced_rows", operation, got)
+		}
+		if got := attrs["db.query.text"].AsString(); got == "" || strings.Contains(got, "secret@example.com") {
+			t.Errorf("gorm.%s db.query.text = %q, want the statement without bound values", operation, got)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+
+	"booking/domain/entity"
+	"booking/domain/identity"
+	"booking/domain/repository"
+)
+
+// verifyBatchSize is how many entries are loaded at a time when verifying the chain
+const verifyBatchSize = 500
+
+// AuditUseCase defines the interface for audit log business logic
+type AuditUseCase interface {
+	// Record appends an entry on behalf of the actor and request carried by ctx
+	Record(ctx context.Context, action, targetType, targetID string, before, after interface{}) error
+	ListEntries(ctx context.Context, filter *entity.AuditFilter) ([]*entity.AuditEntry, error)
+	CountEntries(ctx context.Context, filter *entity.AuditFilter) (int64, error)
+	VerifyChain(ctx context.Context) (*entity.AuditChainReport, error)
+}
+
+// auditUseCase implements AuditUseCase
+type auditUseCase struct {
+	auditRepo repository.AuditRepository
+}
+
+// NewAuditUseCase creates a new audit use case
+func NewAuditUseCase(auditRepo repository.AuditRepository) AuditUseCase {
+	return &auditUseCase{auditRepo: auditRepo}
+}
+
+// Record appends an entry on behalf of the actor and request carried by ctx
+func (uc *auditUseCase) Record(ctx context.Context, action, targetType, targetID string, before, after interface{}) error {
+	actorID, _ := identity.ActorFromContext(ctx)
+
+	entry, err := entity.NewAuditEntry(actorID, action, targetType, targetID, identity.RequestIDFromContext(ctx), before, after)
+	if err != nil {
+		return err
+	}
+
+	return uc.auditRepo.Append(ctx, entry)
+}
+
+// ListEntries retrieves audit entries based on filter
+func (uc *auditUseCase) ListEntries(ctx context.Context, filter *entity.AuditFilter) ([]*entity.AuditEntry, error) {
+	return uc.auditRepo.List(ctx, filter)
+}
+
+// CountEntries counts audit entries based on filter
+func (uc *auditUseCase) CountEntries(ctx context.Context, filter *entity.AuditFilter) (int64, error) {
+	return uc.auditRepo.Count(ctx, filter)
+}
+
+// VerifyChain walks the whole chain oldest first and checks every link and hash
+func (uc *auditUseCase) VerifyChain(ctx context.Context) (*entity.AuditChainReport, error) {
+	report := &entity.AuditChainReport{Valid: true}
+
+	var prev *entity.AuditEntry
+	for {
+		var after uint64
+		if prev != nil {
+			after = prev.Sequence
+		}
+
+		entries, err := uc.auditRepo.ListAfter(ctx, after, verifyBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(entries) == 0 {
+			return report, nil
+		}
+
+		for _, entry := range entries {
+			if reason := checkLink(prev, entry); reason != "" {
+				report.Valid = false
+				report.FirstBroken = entry.Sequence
+				report.Reason = reason
+				return report, nil
+			}
+			report.EntriesChecked++
+			prev = entry
+		}
+	}
+}
+
+// checkLink returns why entry does not correctly follow prev, or "" if it does
+func checkLink(prev, entry *entity.AuditEntry) string {
+	expectedSequence, expectedPrevHash := uint64(1), ""
+	if prev != nil {
+		expectedSequence, expectedPrevHash = prev.Sequence+1, prev.Hash
+	}
+
+	switch {
+	case entry.Sequence != expectedSequence:
+		return fmt.Sprintf("expected sequence %d, found %d", expectedSequence, entry.Sequence)
+	case entry.PrevHash != expectedPrevHash:
+		return "prev_hash does not match the previous entry"
+	case entry.ComputeHash() != entry.Hash:
+		return "entry hash does not match its contents"
+	case !entry.Redacted && entry.ComputePayloadHash() != entry.PayloadHash:
+		return "payload does not match payload_hash"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,182 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"booking/domain/entity"
+	"booking/domain/identity"
+	"booking/domain/repository"
+)
+
+// memoryRepo keeps the chain in a slice, sealing entries like the real repositories
+type memoryRepo struct {
+	repository.AuditRepository
+	entries []*entity.AuditEntry
+}
+
+func (r *memoryRepo) Append(ctx context.Context, entry *entity.AuditEntry) error {
+	var prev *entity.AuditEntry
+	if len(r.entries) > 0 {
+		prev = r.entries[len(r.entries)-1]
+	}
+	entry.Seal(prev)
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func (r *memoryRepo) ListAfter(ctx context.Context, afterSequence uint64, limit int) ([]*entity.AuditEntry, error) {
+	var page []*entity.AuditEntry
+	for _, entry := range r.entries {
+		if entry.Sequence > afterSequence && len(page) < limit {
+			page = append(page, entry)
+		}
+	}
+	return page, nil
+}
+
+func (r *memoryRepo) RedactTarget(ctx context.Context, targetType, targetID string) (int64, error) {
+	var redacted int64
+	for _, entry := range r.entries {
+		if entry.TargetType == targetType && entry.TargetID == targetID {
+			entry.Before, entry.After, entry.Changes = nil, nil, nil
+			entry.Redacted = true
+			redacted++
+		}
+	}
+	return redacted, nil
+}
+
+// newChain records n user updates, more than one verification batch when n is large
+func newChain(t *testing.T, n int) (AuditUseCase, *memoryRepo) {
+	t.Helper()
+	repo := &memoryRepo{}
+	uc := NewAuditUseCase(repo)
+	ctx := identity.WithActor(context.Background(), 1)
+	for i := 1; i <= n; i++ {
+		before := &entity.User{ID: uint(i), Username: "before"}
+		after := &entity.User{ID: uint(i), Username: "after"}
+		if err := uc.Record(ctx, "user.updated", "user", fmt.Sprint(i), before, after); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return uc, repo
+}
+
+func TestVerifyChain(t *testing.T) {
+	n := 2*verifyBatchSize + 10
+	tests := []struct {
+		name        string
+		tamper      func(r *memoryRepo)
+		wantBroken  uint64
+		wantChecked int64
+	}{
+		{name: "intact", wantChecked: int64(n)},
+		{
+			name:        "payload edited",
+			tamper:      func(r *memoryRepo) { r.entries[9].After = entity.JSON(`{"username":"forged"}`) },
+			wantBroken:  10,
+			wantChecked: 9,
+		},
+		{
+			name:        "metadata edited",
+			tamper:      func(r *memoryRepo) { r.entries[verifyBatchSize+4].ActorID = 2 },
+			wantBroken:  verifyBatchSize + 5,
+			wantChecked: verifyBatchSize + 4,
+		},
+		{
+			name: "edit with a recomputed hash",
+			tamper: func(r *memoryRepo) {
+				entry := r.entries[19]
+				entry.Action = "user.created"
+				entry.Hash = entry.ComputeHash()
+			},
+			// The next entry still links to the old hash
+			wantBroken:  21,
+			wantChecked: 20,
+		},
+		{
+			name:        "link deleted",
+			tamper:      func(r *memoryRepo) { r.entries = append(r.entries[:29], r.entries[30:]...) },
+			wantBroken:  31,
+			wantChecked: 29,
+		},
+		{
+			name:        "first entry deleted",
+			tamper:      func(r *memoryRepo) { r.entries = r.entries[1:] },
+			wantBroken:  2,
+			wantChecked: 0,
+		},
+		{
+			name: "deleted and renumbered",
+			tamper: func(r *memoryRepo) {
+				r.entries = append(r.entries[:39], r.entries[40:]...)
+				for _, entry := range r.entries[39:] {
+					entry.Sequence--
+				}
+			},
+			wantBroken:  40,
+			wantChecked: 39,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, repo := newChain(t, n)
+			if tt.tamper != nil {
+				tt.tamper(repo)
+			}
+
+			report, err := uc.VerifyChain(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if report.Valid != (tt.wantBroken == 0) || report.FirstBroken != tt.wantBroken || report.EntriesChecked != tt.wantChecked {
+				t.Errorf("report = %+v, want first broken %d after %d entries", report, tt.wantBroken, tt.wantChecked)
+			}
+			if !report.Valid && report.Reason == "" {
+				t.Error("a broken chain has no reason")
+			}
+		})
+	}
+}
+
+func TestVerifyChainAfterRedaction(t *testing.T) {
+	uc, repo := newChain(t, 5)
+	if _, err := repo.RedactTarget(context.Background(), "user", "3"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Erasing a payload keeps the chain valid, as the payload hash is what is chained
+	report, err := uc.VerifyChain(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Valid || report.EntriesChecked != 5 {
+		t.Errorf("report = %+v, want a valid chain of 5", report)
+	}
+
+	// A forged payload hash on the redacted entry is still caught
+	repo.entries[2].PayloadHash = "forged"
+	report, err = uc.VerifyChain(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Valid || report.FirstBroken != 3 {
+		t.Errorf("report = %+v, want entry 3 broken", report)
+	}
+}
+
+func TestRecordCarriesActorAndRequest(t *testing.T) {
+	repo := &memoryRepo{}
+	uc := NewAuditUseCase(repo)
+	ctx := identity.WithRequestID(identity.WithActor(context.Background(), 4), "req-9")
+
+	if err := uc.Record(ctx, "user.deleted", "user", "7", &entity.User{ID: 7}, nil); err != nil {
+		t.Fatal(err)
+	}
+	entry := repo.entries[0]
+	if entry.ActorID != 4 || entry.RequestID != "req-9" || entry.Sequence != 1 || entry.Hash == "" {
+		t.Errorf("entry = %+v", entry)
+	}
+}
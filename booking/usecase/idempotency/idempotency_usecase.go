@@ -0,0 +1,160 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"booking/domain/entity"
+	"booking/domain/repository"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"gorm.io/gorm"
+)
+
+// maxBeginAttempts bounds retries when a competing request frees or takes over a key
+const maxBeginAttempts = 3
+
+var (
+	ErrKeyReused         = errors.New("idempotency key was already used for a different request")
+	ErrRequestInProgress = errors.New("a request with this idempotency key is still in progress")
+)
+
+// IdempotencyUseCase defines the interface for idempotent request handling
+type IdempotencyUseCase interface {
+	// Begin claims a key for a request. It returns a new in-progress record to
+	// complete or release, or the completed record of an earlier identical request.
+	Begin(ctx context.Context, scope, key, requestHash string) (*entity.IdempotencyRecord, error)
+	// Complete stores the response so retries replay it
+	Complete(ctx context.Context, record *entity.IdempotencyRecord, status int, contentType string, body []byte) error
+	// Release frees the key so the request can be retried, e.g. after a server error
+	Release(ctx context.Context, record *entity.IdempotencyRecord) error
+	// PurgeExpired removes expired records
+	PurgeExpired(ctx context.Context) (int64, error)
+}
+
+// UseCaseOptions holds optional configuration for the idempotency use case
+type UseCaseOptions struct {
+	// TTL is how long a stored response is replayed
+	TTL time.Duration
+	// LockTimeout is after how long an unfinished request (e.g. from a crashed
+	// instance) stops blocking retries with the same key
+	LockTimeout time.Duration
+}
+
+// UseCaseOption is a function that configures UseCaseOptions
+type UseCaseOption func(*UseCaseOptions)
+
+// WithTTL sets how long a stored response is replayed
+func WithTTL(ttl time.Duration) UseCaseOption {
+	return func(o *UseCaseOptions) {
+		o.TTL = ttl
+	}
+}
+
+// WithLockTimeout sets after how long an unfinished request stops blocking retries
+func WithLockTimeout(timeout time.Duration) UseCaseOption {
+	return func(o *UseCaseOptions) {
+		o.LockTimeout = timeout
+	}
+}
+
+// defaultOptions returns default use case options
+func defaultOptions() *UseCaseOptions {
+	return &UseCaseOptions{
+		TTL:         24 * time.Hour,
+		LockTimeout: time.Minute,
+	}
+}
+
+// idempotencyUseCase implements IdempotencyUseCase
+type idempotencyUseCase struct {
+	idempotencyRepo repository.IdempotencyRepository
+	options         *UseCaseOptions
+}
+
+// NewIdempotencyUseCase creates a new idempotency use case
+func NewIdempotencyUseCase(idempotencyRepo repository.IdempotencyRepository, opts ...UseCaseOption) IdempotencyUseCase {
+	options := defaultOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return &idempotencyUseCase{
+		idempotencyRepo: idempotencyRepo,
+		options:         options,
+	}
+}
+
+// Begin claims a key for a request or returns the stored outcome of an earlier one
+func (uc *idempotencyUseCase) Begin(ctx context.Context, scope, key, requestHash string) (*entity.IdempotencyRecord, error) {
+	for attempt := 0; attempt < maxBeginAttempts; attempt++ {
+		now := time.Now()
+		record := &entity.IdempotencyRecord{
+			Scope:       scope,
+			Key:         key,
+			RequestHash: requestHash,
+			Status:      entity.IdempotencyInProgress,
+			ExpiresAt:   now.Add(uc.options.TTL),
+		}
+
+		// The unique (scope, key) index makes exactly one concurrent duplicate win
+		reserved, err := uc.idempotencyRepo.Reserve(ctx, record)
+		if err != nil {
+			return nil, err
+		}
+		if reserved {
+			return record, nil
+		}
+
+		existing, err := uc.idempotencyRepo.Get(ctx, scope, key)
+		if err != nil {
+			if isNotFound(err) {
+				// Released between our insert and read; try again
+				continue
+			}
+			return nil, err
+		}
+
+		switch {
+		case existing.ExpiresAt.Before(now),
+			existing.Status == entity.IdempotencyInProgress && existing.UpdatedAt.Before(now.Add(-uc.options.LockTimeout)):
+			// Expired or abandoned: free the key and compete for it again
+			if err := uc.idempotencyRepo.Delete(ctx, existing.ID); err != nil {
+				return nil, err
+			}
+		case existing.RequestHash != requestHash:
+			return nil, ErrKeyReused
+		case existing.Status == entity.IdempotencyInProgress:
+			return nil, ErrRequestInProgress
+		default:
+			return existing, nil
+		}
+	}
+
+	return nil, ErrRequestInProgress
+}
+
+// Complete stores the response so retries replay it
+func (uc *idempotencyUseCase) Complete(ctx context.Context, record *entity.IdempotencyRecord, status int, contentType string, body []byte) error {
+	record.Status = entity.IdempotencyCompleted
+	record.ResponseStatus = status
+	record.ContentType = contentType
+	record.ResponseBody = body
+	return uc.idempotencyRepo.Complete(ctx, record)
+}
+
+// Release frees the key so the request can be retried
+func (uc *idempotencyUseCase) Release(ctx context.Context, record *entity.IdempotencyRecord) error {
+	return uc.idempotencyRepo.Delete(ctx, record.ID)
+}
+
+// PurgeExpired removes expired records
+func (uc *idempotencyUseCase) PurgeExpired(ctx context.Context) (int64, error) {
+	return uc.idempotencyRepo.DeleteExpired(ctx, time.Now())
+}
+
+// isNotFound reports whether err is a not-found error from either database backend
+func isNotFound(err error) bool {
+	return errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, mongo.ErrNoDocuments)
+}
@@ -0,0 +1,46 @@
+package idempotency
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"booking/infrastructure/logging"
+)
+
+// CleanupWorker periodically removes expired idempotency records
+type CleanupWorker struct {
+	idempotencyUseCase IdempotencyUseCase
+	interval           time.Duration
+	logger             *slog.Logger
+}
+
+// NewCleanupWorker creates a new cleanup worker
+func NewCleanupWorker(idempotencyUseCase IdempotencyUseCase, interval time.Duration) *CleanupWorker {
+	return &CleanupWorker{
+		idempotencyUseCase: idempotencyUseCase,
+		interval:           interval,
+		logger:             logging.For("idempotency"),
+	}
+}
+
+// Run removes expired records immediately and then on every interval until ctx is cancelled
+func (w *CleanupWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		removed, err := w.idempotencyUseCase.PurgeExpired(ctx)
+		if err != nil {
+			w.logger.ErrorContext(ctx, "idempotency cleanup failed", slog.Any("error", err))
+		} else if removed > 0 {
+			w.logger.InfoContext(ctx, "removed expired idempotency keys", slog.Int64("removed", removed))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
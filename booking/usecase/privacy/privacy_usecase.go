@@ -0,0 +1,463 @@
+package privacy
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"booking/domain/entity"
+	"booking/domain/identity"
+	"booking/domain/repository"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"gorm.io/gorm"
+)
+
+// workBatchSize is how many requests one worker pass handles per status
+const workBatchSize = 20
+
+var (
+	ErrUnauthenticated = errors.New("authentication required")
+	ErrRequestNotFound = errors.New("privacy request not found")
+	ErrUserNotFound    = errors.New("user not found")
+	ErrExportNotReady  = errors.New("export is not ready yet")
+	ErrExportExpired   = errors.New("export has expired")
+	ErrNotAnExport     = errors.New("privacy request is not an export")
+)
+
+// PrivacyUseCase defines the interface for GDPR export and erasure
+// Every method acts on the authenticated user carried by ctx.
+type PrivacyUseCase interface {
+	RequestExport(ctx context.Context) (*entity.PrivacyRequest, error)
+	RequestErasure(ctx context.Context) (*entity.PrivacyRequest, error)
+	ListRequests(ctx context.Context) ([]*entity.PrivacyRequest, error)
+	GetRequest(ctx context.Context, id uint) (*entity.PrivacyRequest, error)
+	// ExportFile returns the path of a finished export ZIP
+	ExportFile(ctx context.Context, id uint) (string, error)
+	// ProcessRequests runs one pass of the background worker
+	ProcessRequests(ctx context.Context) error
+}
+
+// UseCaseOptions holds optional configuration for the privacy use case
+type UseCaseOptions struct {
+	ExportDir string
+	ExportTTL time.Duration
+	// ErasureSettleDelay must exceed the time observers need to record the
+	// erasure's own events, so the audit redaction that follows catches them
+	ErasureSettleDelay time.Duration
+	// StuckAfter re-queues requests left in processing by a crashed worker
+	StuckAfter time.Duration
+	Sections   []ExportSection
+	Erasers    []Eraser
+}
+
+// UseCaseOption is a function that configures UseCaseOptions
+type UseCaseOption func(*UseCaseOptions)
+
+// WithExportDir sets where export ZIPs are written
+func WithExportDir(dir string) UseCaseOption {
+	return func(o *UseCaseOptions) {
+		o.ExportDir = dir
+	}
+}
+
+// WithExportTTL sets how long a finished export can be downloaded
+func WithExportTTL(ttl time.Duration) UseCaseOption {
+	return func(o *UseCaseOptions) {
+		o.ExportTTL = ttl
+	}
+}
+
+// WithErasureSettleDelay sets the wait between anonymizing records and redacting the audit log
+func WithErasureSettleDelay(delay time.Duration) UseCaseOption {
+	return func(o *UseCaseOptions) {
+		o.ErasureSettleDelay = delay
+	}
+}
+
+// WithExportSections adds sections to every export
+func WithExportSections(sections ...ExportSection) UseCaseOption {
+	return func(o *UseCaseOptions) {
+		o.Sections = append(o.Sections, sections...)
+	}
+}
+
+// WithErasers adds erasers run for every erasure request, before the user record is anonymized
+func WithErasers(erasers ...Eraser) UseCaseOption {
+	return func(o *UseCaseOptions) {
+		o.Erasers = append(o.Erasers, erasers...)
+	}
+}
+
+// defaultOptions returns default use case options
+func defaultOptions() *UseCaseOptions {
+	return &UseCaseOptions{
+		ExportDir:          "exports",
+		ExportTTL:          7 * 24 * time.Hour,
+		ErasureSettleDelay: time.Minute,
+		StuckAfter:         30 * time.Minute,
+	}
+}
+
+// privacyUseCase implements PrivacyUseCase
+type privacyUseCase struct {
+	privacyRepo repository.PrivacyRequestRepository
+	userRepo    repository.UserRepository
+	auditRepo   repository.AuditRepository
+	sections    []ExportSection
+	erasers     []Eraser
+	options     *UseCaseOptions
+}
+
+// NewPrivacyUseCase creates a new privacy use case
+// Profile, roles and audit entries are always exported; other modules add
+// their data with WithExportSections and WithErasers.
+func NewPrivacyUseCase(
+	privacyRepo repository.PrivacyRequestRepository,
+	userRepo repository.UserRepository,
+	roleRepo repository.RoleRepository,
+	auditRepo repository.AuditRepository,
+	opts ...UseCaseOption,
+) PrivacyUseCase {
+	options := defaultOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	sections := []ExportSection{
+		&profileSection{userRepo: userRepo},
+		&rolesSection{roleRepo: roleRepo},
+		&auditSection{auditRepo: auditRepo},
+	}
+	sections = append(sections, options.Sections...)
+
+	// The user record goes last so other erasers can still look the user up
+	erasers := append([]Eraser{&roleEraser{roleRepo: roleRepo}}, options.Erasers...)
+	erasers = append(erasers, &userEraser{userRepo: userRepo})
+
+	return &privacyUseCase{
+		privacyRepo: privacyRepo,
+		userRepo:    userRepo,
+		auditRepo:   auditRepo,
+		sections:    sections,
+		erasers:     erasers,
+		options:     options,
+	}
+}
+
+// RequestExport queues an export of everything stored about the user
+func (uc *privacyUseCase) RequestExport(ctx context.Context) (*entity.PrivacyRequest, error) {
+	return uc.createRequest(ctx, entity.PrivacyExport)
+}
+
+// RequestErasure queues anonymization of the user's personal data
+// A second request while one is still in progress returns the existing one.
+func (uc *privacyUseCase) RequestErasure(ctx context.Context) (*entity.PrivacyRequest, error) {
+	userID, ok := identity.ActorFromContext(ctx)
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+
+	existing, err := uc.privacyRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, req := range existing {
+		if req.Type == entity.PrivacyErasure && req.Status != entity.PrivacyFailed {
+			return req, nil
+		}
+	}
+
+	return uc.createRequest(ctx, entity.PrivacyErasure)
+}
+
+// ListRequests lists the user's privacy requests
+func (uc *privacyUseCase) ListRequests(ctx context.Context) ([]*entity.PrivacyRequest, error) {
+	userID, ok := identity.ActorFromContext(ctx)
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+	return uc.privacyRepo.ListByUser(ctx, userID)
+}
+
+// GetRequest retrieves one of the user's privacy requests
+func (uc *privacyUseCase) GetRequest(ctx context.Context, id uint) (*entity.PrivacyRequest, error) {
+	userID, ok := identity.ActorFromContext(ctx)
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+
+	req, err := uc.privacyRepo.GetByID(ctx, id)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, ErrRequestNotFound
+		}
+		return nil, err
+	}
+
+	// Someone else's request looks exactly like a missing one
+	if req.UserID != userID {
+		return nil, ErrRequestNotFound
+	}
+
+	return req, nil
+}
+
+// ExportFile returns the path of a finished export ZIP
+func (uc *privacyUseCase) ExportFile(ctx context.Context, id uint) (string, error) {
+	req, err := uc.GetRequest(ctx, id)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case req.Type != entity.PrivacyExport:
+		return "", ErrNotAnExport
+	case req.Status == entity.PrivacyExpired:
+		return "", ErrExportExpired
+	case req.Status != entity.PrivacyCompleted:
+		return "", ErrExportNotReady
+	case req.ExpiresAt != nil && time.Now().After(*req.ExpiresAt):
+		return "", ErrExportExpired
+	}
+
+	return req.FilePath, nil
+}
+
+// ProcessRequests runs one pass of the background worker
+func (uc *privacyUseCase) ProcessRequests(ctx context.Context) error {
+	now := time.Now()
+
+	// Re-queue work abandoned by a crashed worker
+	stuck, err := uc.privacyRepo.ListByStatus(ctx, "", entity.PrivacyProcessing, now.Add(-uc.options.StuckAfter), workBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, req := range stuck {
+		if _, err := uc.privacyRepo.Claim(ctx, req.ID, entity.PrivacyProcessing, entity.PrivacyPending); err != nil {
+			return err
+		}
+	}
+
+	pending, err := uc.privacyRepo.ListByStatus(ctx, "", entity.PrivacyPending, now, workBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, req := range pending {
+		claimed, err := uc.privacyRepo.Claim(ctx, req.ID, entity.PrivacyPending, entity.PrivacyProcessing)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+
+		req.Status = entity.PrivacyProcessing
+		if req.Type == entity.PrivacyExport {
+			err = uc.buildExport(ctx, req)
+		} else {
+			err = uc.anonymize(ctx, req)
+		}
+		if err != nil {
+			req.Status = entity.PrivacyFailed
+			req.Error = err.Error()
+			if err := uc.privacyRepo.Update(ctx, req); err != nil {
+				return err
+			}
+		}
+	}
+
+	settled, err := uc.privacyRepo.ListByStatus(ctx, entity.PrivacyErasure, entity.PrivacyAnonymized, now.Add(-uc.options.ErasureSettleDelay), workBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, req := range settled {
+		if err := uc.redactAudit(ctx, req); err != nil {
+			return err
+		}
+	}
+
+	return uc.expireExports(ctx, now)
+}
+
+// createRequest stores a new pending request for the authenticated user
+func (uc *privacyUseCase) createRequest(ctx context.Context, requestType entity.PrivacyRequestType) (*entity.PrivacyRequest, error) {
+	userID, ok := identity.ActorFromContext(ctx)
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+
+	if _, err := uc.userRepo.GetByID(repository.WithReadYourWrites(ctx), userID); err != nil {
+		if isNotFound(err) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	req := &entity.PrivacyRequest{
+		UserID: userID,
+		Type:   requestType,
+		Status: entity.PrivacyPending,
+	}
+	if err := uc.privacyRepo.Create(ctx, req); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// buildExport writes every section to a ZIP of JSON files and completes the request
+func (uc *privacyUseCase) buildExport(ctx context.Context, req *entity.PrivacyRequest) error {
+	if err := os.MkdirAll(uc.options.ExportDir, 0o700); err != nil {
+		return err
+	}
+
+	// The random suffix keeps file names unguessable even if the directory is exposed
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+	path := filepath.Join(uc.options.ExportDir, fmt.Sprintf("export-%d-%s.zip", req.ID, hex.EncodeToString(suffix)))
+
+	if err := uc.writeZip(ctx, req.UserID, path); err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(uc.options.ExportTTL)
+	req.Status = entity.PrivacyCompleted
+	req.FilePath = path
+	req.CompletedAt = &now
+	req.ExpiresAt = &expiresAt
+
+	return uc.privacyRepo.Update(ctx, req)
+}
+
+// writeZip writes a manifest and one <section>.json per export section
+func (uc *privacyUseCase) writeZip(ctx context.Context, userID uint, path string) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	archive := zip.NewWriter(file)
+
+	names := make([]string, 0, len(uc.sections))
+	for _, section := range uc.sections {
+		data, err := section.Export(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("export section %s: %w", section.Name(), err)
+		}
+		if err := writeJSON(archive, section.Name()+".json", data); err != nil {
+			return err
+		}
+		names = append(names, section.Name())
+	}
+
+	manifest := map[string]interface{}{
+		"user_id":      userID,
+		"generated_at": time.Now().UTC(),
+		"sections":     names,
+	}
+	if err := writeJSON(archive, "manifest.json", manifest); err != nil {
+		return err
+	}
+
+	if err := archive.Close(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// anonymize runs every eraser and removes the user's exports
+func (uc *privacyUseCase) anonymize(ctx context.Context, req *entity.PrivacyRequest) error {
+	// Changes made on the user's behalf are attributed to them in the audit log
+	ctx = identity.WithActor(ctx, req.UserID)
+	// Erasers read what they overwrite, which must be the latest state
+	ctx = repository.WithReadYourWrites(ctx)
+
+	for _, eraser := range uc.erasers {
+		if err := eraser.Erase(ctx, req.UserID); err != nil {
+			return fmt.Errorf("erase %s: %w", eraser.Name(), err)
+		}
+	}
+
+	others, err := uc.privacyRepo.ListByUser(ctx, req.UserID)
+	if err != nil {
+		return err
+	}
+	for _, other := range others {
+		if other.Type == entity.PrivacyExport && other.FilePath != "" {
+			if err := uc.expire(ctx, other); err != nil {
+				return err
+			}
+		}
+	}
+
+	req.Status = entity.PrivacyAnonymized
+	return uc.privacyRepo.Update(ctx, req)
+}
+
+// redactAudit erases audit payloads about the user, keeping the hash chain intact
+func (uc *privacyUseCase) redactAudit(ctx context.Context, req *entity.PrivacyRequest) error {
+	targetID := strconv.FormatUint(uint64(req.UserID), 10)
+	if _, err := uc.auditRepo.RedactTarget(ctx, "user", targetID); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	req.Status = entity.PrivacyCompleted
+	req.CompletedAt = &now
+	return uc.privacyRepo.Update(ctx, req)
+}
+
+// expireExports deletes export files whose download window has passed
+func (uc *privacyUseCase) expireExports(ctx context.Context, now time.Time) error {
+	// Completed erasures keep their status forever, so only exports are listed
+	completed, err := uc.privacyRepo.ListByStatus(ctx, entity.PrivacyExport, entity.PrivacyCompleted, now.Add(-uc.options.ExportTTL), workBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, req := range completed {
+		if err := uc.expire(ctx, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expire deletes an export file and marks the request expired
+func (uc *privacyUseCase) expire(ctx context.Context, req *entity.PrivacyRequest) error {
+	if err := os.Remove(req.FilePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	req.FilePath = ""
+	req.Status = entity.PrivacyExpired
+	return uc.privacyRepo.Update(ctx, req)
+}
+
+// writeJSON adds an indented JSON file to the archive
+func writeJSON(archive *zip.Writer, name string, data interface{}) error {
+	w, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(data)
+}
+
+// isNotFound reports whether err is a not-found error from either database backend
+func isNotFound(err error) bool {
+	return errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, mongo.ErrNoDocuments)
+}
@@ -0,0 +1,501 @@
+package privacy
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"booking/domain/entity"
+	"booking/domain/identity"
+	"booking/domain/repository"
+
+	"gorm.io/gorm"
+)
+
+// fakeRequests stores privacy requests in memory, handing out copies like a database
+type fakeRequests struct {
+	repository.PrivacyRequestRepository
+	requests map[uint]*entity.PrivacyRequest
+	nextID   uint
+	// lost lists requests another worker claims first
+	lost map[uint]bool
+}
+
+func newFakeRequests() *fakeRequests {
+	return &fakeRequests{requests: map[uint]*entity.PrivacyRequest{}, lost: map[uint]bool{}}
+}
+
+func (r *fakeRequests) Create(ctx context.Context, req *entity.PrivacyRequest) error {
+	r.nextID++
+	req.ID = r.nextID
+	req.CreatedAt = time.Now()
+	req.UpdatedAt = req.CreatedAt
+	stored := *req
+	r.requests[req.ID] = &stored
+	return nil
+}
+
+func (r *fakeRequests) GetByID(ctx context.Context, id uint) (*entity.PrivacyRequest, error) {
+	req, ok := r.requests[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	found := *req
+	return &found, nil
+}
+
+func (r *fakeRequests) ListByUser(ctx context.Context, userID uint) ([]*entity.PrivacyRequest, error) {
+	return r.list(func(req *entity.PrivacyRequest) bool { return req.UserID == userID }), nil
+}
+
+func (r *fakeRequests) ListByStatus(ctx context.Context, reqType entity.PrivacyRequestType, status entity.PrivacyRequestStatus, updatedBefore time.Time, limit int) ([]*entity.PrivacyRequest, error) {
+	list := r.list(func(req *entity.PrivacyRequest) bool {
+		return (reqType == "" || req.Type == reqType) && req.Status == status && req.UpdatedAt.Before(updatedBefore)
+	})
+	if len(list) > limit {
+		list = list[:limit]
+	}
+	return list, nil
+}
+
+func (r *fakeRequests) Claim(ctx context.Context, id uint, from, to entity.PrivacyRequestStatus) (bool, error) {
+	req, ok := r.requests[id]
+	if !ok || req.Status != from {
+		return false, nil
+	}
+	if r.lost[id] {
+		req.Status = to
+		return false, nil
+	}
+	req.Status = to
+	req.UpdatedAt = time.Now()
+	return true, nil
+}
+
+func (r *fakeRequests) Update(ctx context.Context, req *entity.PrivacyRequest) error {
+	req.UpdatedAt = time.Now()
+	stored := *req
+	r.requests[req.ID] = &stored
+	return nil
+}
+
+// list returns copies of the matching requests, oldest first
+func (r *fakeRequests) list(match func(req *entity.PrivacyRequest) bool) []*entity.PrivacyRequest {
+	list := []*entity.PrivacyRequest{}
+	for _, req := range r.requests {
+		if match(req) {
+			found := *req
+			list = append(list, &found)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// backdate moves the last update of a request into the past
+func (r *fakeRequests) backdate(id uint, by time.Duration) {
+	r.requests[id].UpdatedAt = r.requests[id].UpdatedAt.Add(-by)
+}
+
+// fakeUsers stores users in memory with soft deletion
+type fakeUsers struct {
+	repository.UserRepository
+	users   map[uint]*entity.User
+	deleted map[uint]bool
+}
+
+func (r *fakeUsers) GetByID(ctx context.Context, id uint) (*entity.User, error) {
+	u, ok := r.users[id]
+	if !ok || r.deleted[id] {
+		return nil, gorm.ErrRecordNotFound
+	}
+	found := *u
+	return &found, nil
+}
+
+func (r *fakeUsers) Delete(ctx context.Context, id uint) error {
+	if _, ok := r.users[id]; !ok || r.deleted[id] {
+		return gorm.ErrRecordNotFound
+	}
+	r.deleted[id] = true
+	return nil
+}
+
+func (r *fakeUsers) Purge(ctx context.Context, user *entity.User) error {
+	if _, ok := r.users[user.ID]; !ok || !r.deleted[user.ID] {
+		return gorm.ErrRecordNotFound
+	}
+	purged := *user
+	r.users[user.ID] = &purged
+	return nil
+}
+
+// fakeRoles holds role names per user
+type fakeRoles struct {
+	repository.RoleRepository
+	roles map[uint][]string
+}
+
+func (r *fakeRoles) ListByUser(ctx context.Context, userID uint) ([]*entity.Role, error) {
+	roles := []*entity.Role{}
+	for _, name := range r.roles[userID] {
+		roles = append(roles, &entity.Role{Name: name})
+	}
+	return roles, nil
+}
+
+func (r *fakeRoles) RevokeFromUser(ctx context.Context, userID uint, roleName string, actorID uint) error {
+	kept := r.roles[userID][:0]
+	for _, name := range r.roles[userID] {
+		if name != roleName {
+			kept = append(kept, name)
+		}
+	}
+	r.roles[userID] = kept
+	return nil
+}
+
+// fakeAudit returns no entries and records redactions
+type fakeAudit struct {
+	repository.AuditRepository
+	redacted []string
+}
+
+func (r *fakeAudit) List(ctx context.Context, filter *entity.AuditFilter) ([]*entity.AuditEntry, error) {
+	return []*entity.AuditEntry{}, nil
+}
+
+func (r *fakeAudit) RedactTarget(ctx context.Context, targetType, targetID string) (int64, error) {
+	r.redacted = append(r.redacted, targetType+"/"+targetID)
+	return 1, nil
+}
+
+// fixture is a privacy use case over fakes holding user 7, a runner
+type fixture struct {
+	uc       PrivacyUseCase
+	requests *fakeRequests
+	users    *fakeUsers
+	roles    *fakeRoles
+	audit    *fakeAudit
+	dir      string
+}
+
+func newFixture(t *testing.T, opts ...UseCaseOption) *fixture {
+	t.Helper()
+	f := &fixture{
+		requests: newFakeRequests(),
+		users: &fakeUsers{
+			users:   map[uint]*entity.User{7: {ID: 7, Email: "an@example.com", Username: "an", FullName: "Nguyễn Văn An", IsActive: true}},
+			deleted: map[uint]bool{},
+		},
+		roles: &fakeRoles{roles: map[uint][]string{7: {entity.RoleRunner}}},
+		audit: &fakeAudit{},
+		dir:   filepath.Join(t.TempDir(), "exports"),
+	}
+	opts = append([]UseCaseOption{WithExportDir(f.dir), WithExportTTL(time.Hour), WithErasureSettleDelay(0)}, opts...)
+	f.uc = NewPrivacyUseCase(f.requests, f.users, f.roles, f.audit, opts...)
+	return f
+}
+
+// as returns a context authenticated as userID
+func as(userID uint) context.Context {
+	return identity.WithActor(context.Background(), userID)
+}
+
+// process runs one worker pass
+func (f *fixture) process(t *testing.T) {
+	t.Helper()
+	if err := f.uc.ProcessRequests(context.Background()); err != nil {
+		t.Fatalf("ProcessRequests() error = %v", err)
+	}
+}
+
+// status returns the stored status of a request
+func (f *fixture) status(id uint) entity.PrivacyRequestStatus {
+	return f.requests.requests[id].Status
+}
+
+func TestRequestsNeedAnExistingUser(t *testing.T) {
+	f := newFixture(t)
+
+	if _, err := f.uc.RequestExport(context.Background()); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("anonymous RequestExport() error = %v, want %v", err, ErrUnauthenticated)
+	}
+	if _, err := f.uc.RequestErasure(context.Background()); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("anonymous RequestErasure() error = %v, want %v", err, ErrUnauthenticated)
+	}
+	if _, err := f.uc.RequestErasure(as(9)); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("RequestErasure() of a missing user error = %v, want %v", err, ErrUserNotFound)
+	}
+	if len(f.requests.requests) != 0 {
+		t.Errorf("stored %d requests, want none", len(f.requests.requests))
+	}
+}
+
+func TestExport(t *testing.T) {
+	f := newFixture(t)
+	req, err := f.uc.RequestExport(as(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Status != entity.PrivacyPending {
+		t.Fatalf("status = %s, want pending", req.Status)
+	}
+	if _, err := f.uc.ExportFile(as(7), req.ID); !errors.Is(err, ErrExportNotReady) {
+		t.Errorf("ExportFile() before the worker error = %v, want %v", err, ErrExportNotReady)
+	}
+
+	f.process(t)
+	if got := f.status(req.ID); got != entity.PrivacyCompleted {
+		t.Fatalf("status = %s, want completed (error %q)", got, f.requests.requests[req.ID].Error)
+	}
+
+	path, err := f.uc.ExportFile(as(7), req.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(path) != f.dir {
+		t.Errorf("export written to %s, want in %s", path, f.dir)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("export permissions = %o, want 600", perm)
+	}
+	if info, err := os.Stat(f.dir); err != nil || info.Mode().Perm() != 0o700 {
+		t.Errorf("export directory = %v, %v; want permissions 700", info, err)
+	}
+
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+	files := map[string]*zip.File{}
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+	for _, name := range []string{"manifest.json", "profile.json", "roles.json", "audit_entries.json"} {
+		if files[name] == nil {
+			t.Errorf("export is missing %s", name)
+		}
+	}
+	var profile entity.User
+	readJSON(t, files["profile.json"], &profile)
+	if profile.Email != "an@example.com" || profile.FullName != "Nguyễn Văn An" {
+		t.Errorf("profile = %+v", profile)
+	}
+
+	// Someone else's export looks missing
+	if _, err := f.uc.ExportFile(as(8), req.ID); !errors.Is(err, ErrRequestNotFound) {
+		t.Errorf("ExportFile() by another user error = %v, want %v", err, ErrRequestNotFound)
+	}
+}
+
+// readJSON decodes a file of a ZIP archive
+func readJSON(t *testing.T, file *zip.File, v interface{}) {
+	t.Helper()
+	if file == nil {
+		return
+	}
+	r, err := file.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if err := json.NewDecoder(r).Decode(v); err != nil {
+		t.Fatalf("decode %s: %v", file.Name, err)
+	}
+}
+
+func TestExportExpires(t *testing.T) {
+	f := newFixture(t)
+	req, err := f.uc.RequestExport(as(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.process(t)
+	path := f.requests.requests[req.ID].FilePath
+
+	// Past expires_at the file is refused even before the worker removes it
+	past := time.Now().Add(-time.Minute)
+	f.requests.requests[req.ID].ExpiresAt = &past
+	if _, err := f.uc.ExportFile(as(7), req.ID); !errors.Is(err, ErrExportExpired) {
+		t.Errorf("ExportFile() past expires_at error = %v, want %v", err, ErrExportExpired)
+	}
+
+	f.requests.backdate(req.ID, 2*time.Hour)
+	f.process(t)
+	if got := f.status(req.ID); got != entity.PrivacyExpired {
+		t.Errorf("status = %s, want expired", got)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expired export still on disk: %v", err)
+	}
+	if _, err := f.uc.ExportFile(as(7), req.ID); !errors.Is(err, ErrExportExpired) {
+		t.Errorf("ExportFile() after expiry error = %v, want %v", err, ErrExportExpired)
+	}
+}
+
+func TestErasure(t *testing.T) {
+	f := newFixture(t)
+	export, err := f.uc.RequestExport(as(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.process(t)
+	exportPath := f.requests.requests[export.ID].FilePath
+
+	req, err := f.uc.RequestErasure(as(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := f.uc.RequestErasure(as(7))
+	if err != nil || again.ID != req.ID {
+		t.Errorf("second RequestErasure() = %+v, %v; want request %d again", again, err, req.ID)
+	}
+	if _, err := f.uc.ExportFile(as(7), req.ID); !errors.Is(err, ErrNotAnExport) {
+		t.Errorf("ExportFile() of an erasure error = %v, want %v", err, ErrNotAnExport)
+	}
+
+	// The first pass anonymizes; audit redaction waits for the next one
+	f.process(t)
+	if got := f.status(req.ID); got != entity.PrivacyAnonymized {
+		t.Fatalf("status = %s, want anonymized (error %q)", got, f.requests.requests[req.ID].Error)
+	}
+	u := f.users.users[7]
+	if !f.users.deleted[7] || u.PurgedAt == nil || u.Email != "purged-7@invalid.local" || u.FullName != "" {
+		t.Errorf("user = %+v, want deleted and anonymized", u)
+	}
+	if len(f.roles.roles[7]) != 0 {
+		t.Errorf("roles = %v, want none", f.roles.roles[7])
+	}
+	if f.status(export.ID) != entity.PrivacyExpired {
+		t.Error("the user's export was not expired")
+	}
+	if _, err := os.Stat(exportPath); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("the user's export is still on disk: %v", err)
+	}
+	if len(f.audit.redacted) != 0 {
+		t.Errorf("audit redacted before settling: %v", f.audit.redacted)
+	}
+
+	// The settle worker redacts the audit log
+	f.process(t)
+	if got := f.status(req.ID); got != entity.PrivacyCompleted {
+		t.Errorf("status = %s, want completed", got)
+	}
+	if len(f.audit.redacted) != 1 || f.audit.redacted[0] != "user/7" {
+		t.Errorf("redacted = %v, want user/7", f.audit.redacted)
+	}
+}
+
+func TestErasureWaitsForSettleDelay(t *testing.T) {
+	f := newFixture(t, WithErasureSettleDelay(time.Hour))
+	req, err := f.uc.RequestErasure(as(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f.process(t)
+	f.process(t)
+	if got := f.status(req.ID); got != entity.PrivacyAnonymized || len(f.audit.redacted) != 0 {
+		t.Fatalf("status = %s, redacted = %v; want anonymized and nothing redacted", got, f.audit.redacted)
+	}
+
+	f.requests.backdate(req.ID, 2*time.Hour)
+	f.process(t)
+	if got := f.status(req.ID); got != entity.PrivacyCompleted || len(f.audit.redacted) != 1 {
+		t.Errorf("status = %s, redacted = %v; want completed after the delay", got, f.audit.redacted)
+	}
+}
+
+func TestErasureOfMissingUserFails(t *testing.T) {
+	f := newFixture(t)
+	req, err := f.uc.RequestErasure(as(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The user row is gone by the time the worker runs
+	delete(f.users.users, 7)
+
+	f.process(t)
+	stored := f.requests.requests[req.ID]
+	if stored.Status != entity.PrivacyFailed || !strings.Contains(stored.Error, ErrUserNotFound.Error()) {
+		t.Errorf("request = %+v, want failed with %q", stored, ErrUserNotFound)
+	}
+
+	// A failed erasure can be requested again
+	f.users.users[7] = &entity.User{ID: 7}
+	retry, err := f.uc.RequestErasure(as(7))
+	if err != nil || retry.ID == req.ID {
+		t.Errorf("RequestErasure() after a failure = %+v, %v; want a new request", retry, err)
+	}
+}
+
+func TestErasureOfAnonymizedUserSucceeds(t *testing.T) {
+	f := newFixture(t)
+	first, err := f.uc.RequestErasure(as(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.process(t)
+	f.process(t)
+
+	// A crashed worker may run the erasers again on a user already soft-deleted and anonymized
+	f.requests.requests[first.ID].Status = entity.PrivacyPending
+	f.requests.backdate(first.ID, time.Minute)
+	f.process(t)
+	if got := f.status(first.ID); got != entity.PrivacyAnonymized {
+		t.Errorf("status = %s, want anonymized (error %q)", got, f.requests.requests[first.ID].Error)
+	}
+}
+
+func TestClaimLostToAnotherWorker(t *testing.T) {
+	f := newFixture(t)
+	req, err := f.uc.RequestExport(as(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.requests.lost[req.ID] = true
+
+	f.process(t)
+	if stored := f.requests.requests[req.ID]; stored.FilePath != "" || stored.Status != entity.PrivacyProcessing {
+		t.Errorf("request = %+v, want it left to the other worker", stored)
+	}
+	if entries, _ := os.ReadDir(f.dir); len(entries) != 0 {
+		t.Errorf("exports written: %v", entries)
+	}
+}
+
+func TestStuckRequestsAreRequeued(t *testing.T) {
+	f := newFixture(t)
+	req, err := f.uc.RequestExport(as(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A worker crashed after claiming the request
+	f.requests.requests[req.ID].Status = entity.PrivacyProcessing
+	f.requests.backdate(req.ID, time.Minute)
+
+	f.process(t)
+	if got := f.status(req.ID); got != entity.PrivacyProcessing {
+		t.Fatalf("status = %s, want it left alone before StuckAfter", got)
+	}
+
+	f.requests.backdate(req.ID, time.Hour)
+	f.process(t)
+	f.process(t)
+	if got := f.status(req.ID); got != entity.PrivacyCompleted {
+		t.Errorf("status = %s, want completed once re-queued", got)
+	}
+}
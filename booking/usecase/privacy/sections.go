@@ -0,0 +1,150 @@
+package privacy
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"booking/domain/entity"
+	"booking/domain/repository"
+)
+
+// auditExportLimit caps how many audit entries of each kind go into one export
+const auditExportLimit = 10000
+
+// ExportSection contributes one JSON file to a user's data export
+// Modules that store personal data (runs, wallet, inventory, ...) register a
+// section with WithExportSections so the export stays complete as they are added.
+type ExportSection interface {
+	// Name is used as the file name inside the ZIP (<name>.json)
+	Name() string
+	Export(ctx context.Context, userID uint) (interface{}, error)
+}
+
+// Eraser removes or anonymizes one repository's personal data for a user
+// Erasers must keep ledgers balanced: anonymize references, never delete postings.
+type Eraser interface {
+	Name() string
+	Erase(ctx context.Context, userID uint) error
+}
+
+// profileSection exports the user record
+type profileSection struct {
+	userRepo repository.UserRepository
+}
+
+// Name implements ExportSection
+func (s *profileSection) Name() string {
+	return "profile"
+}
+
+// Export implements ExportSection
+func (s *profileSection) Export(ctx context.Context, userID uint) (interface{}, error) {
+	return s.userRepo.GetByID(ctx, userID)
+}
+
+// rolesSection exports the roles assigned to the user
+type rolesSection struct {
+	roleRepo repository.RoleRepository
+}
+
+// Name implements ExportSection
+func (s *rolesSection) Name() string {
+	return "roles"
+}
+
+// Export implements ExportSection
+func (s *rolesSection) Export(ctx context.Context, userID uint) (interface{}, error) {
+	return s.roleRepo.ListByUser(ctx, userID)
+}
+
+// auditSection exports audit entries about the user and actions the user performed
+type auditSection struct {
+	auditRepo repository.AuditRepository
+}
+
+// Name implements ExportSection
+func (s *auditSection) Name() string {
+	return "audit_entries"
+}
+
+// Export implements ExportSection
+func (s *auditSection) Export(ctx context.Context, userID uint) (interface{}, error) {
+	targetType, targetID := "user", strconv.FormatUint(uint64(userID), 10)
+	about, err := s.auditRepo.List(ctx, &entity.AuditFilter{
+		TargetType: &targetType,
+		TargetID:   &targetID,
+		Limit:      auditExportLimit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	performed, err := s.auditRepo.List(ctx, &entity.AuditFilter{
+		ActorID: &userID,
+		Limit:   auditExportLimit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"about_you":        about,
+		"performed_by_you": performed,
+	}, nil
+}
+
+// roleEraser revokes every role held by the user
+type roleEraser struct {
+	roleRepo repository.RoleRepository
+}
+
+// Name implements Eraser
+func (e *roleEraser) Name() string {
+	return "roles"
+}
+
+// Erase implements Eraser
+func (e *roleEraser) Erase(ctx context.Context, userID uint) error {
+	roles, err := e.roleRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, r := range roles {
+		if err := e.roleRepo.RevokeFromUser(ctx, userID, r.Name, userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// userEraser soft-deletes the user and immediately anonymizes the record
+// The ID survives so ledgers and history keep referencing a valid, anonymous user.
+type userEraser struct {
+	userRepo repository.UserRepository
+}
+
+// Name implements Eraser
+func (e *userEraser) Name() string {
+	return "profile"
+}
+
+// Erase implements Eraser
+func (e *userEraser) Erase(ctx context.Context, userID uint) error {
+	// A user already soft-deleted is fine; Purge works on deleted records
+	if err := e.userRepo.Delete(ctx, userID); err != nil && !isNotFound(err) {
+		return err
+	}
+
+	// Purge matches every deleted record, anonymized ones included, so
+	// not-found here means the user never existed and nothing was erased
+	anonymous := &entity.User{ID: userID}
+	anonymous.Anonymize(time.Now())
+	if err := e.userRepo.Purge(ctx, anonymous); err != nil {
+		if isNotFound(err) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	return nil
+}
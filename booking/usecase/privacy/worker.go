@@ -0,0 +1,43 @@
+package privacy
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"booking/infrastructure/logging"
+)
+
+// Worker generates exports and carries out erasures in the background
+type Worker struct {
+	privacyUseCase PrivacyUseCase
+	interval       time.Duration
+	logger         *slog.Logger
+}
+
+// NewWorker creates a new privacy worker
+func NewWorker(privacyUseCase PrivacyUseCase, interval time.Duration) *Worker {
+	return &Worker{
+		privacyUseCase: privacyUseCase,
+		interval:       interval,
+		logger:         logging.For("privacy"),
+	}
+}
+
+// Run processes requests on every interval until ctx is cancelled
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.privacyUseCase.ProcessRequests(ctx); err != nil {
+			w.logger.ErrorContext(ctx, "privacy worker pass failed", slog.Any("error", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package role
+
+import (
+	"booking/domain/entity"
+	"booking/domain/identity"
+	"booking/domain/repository"
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUnauthenticated = errors.New("authentication required")
+	ErrForbidden       = errors.New("permission denied")
+	ErrRoleNotFound    = errors.New("role not found")
+	ErrUserNotFound    = errors.New("user not found")
+	ErrSelfRevokeAdmin = errors.New("cannot revoke the admin role from yourself")
+)
+
+// PermissionChecker answers whether a user holds a permission
+// It is the only dependency the HTTP middleware and other use cases need
+type PermissionChecker interface {
+	HasPermission(ctx context.Context, userID uint, permission string) (bool, error)
+}
+
+// Require checks that the actor carried by ctx holds the permission
+func Require(ctx context.Context, checker PermissionChecker, permission string) error {
+	actorID, ok := identity.ActorFromContext(ctx)
+	if !ok {
+		return ErrUnauthenticated
+	}
+
+	allowed, err := checker.HasPermission(ctx, actorID, permission)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return ErrForbidden
+	}
+
+	return nil
+}
+
+// RoleUseCase defines the interface for role and permission business logic
+type RoleUseCase interface {
+	PermissionChecker
+	SeedDefaultRoles(ctx context.Context) error
+	BootstrapAdmin(ctx context.Context, email string) error
+	GrantDefaultRole(ctx context.Context, userID uint) error
+	ListRoles(ctx context.Context) ([]*entity.Role, error)
+	GetUserRoles(ctx context.Context, userID uint) ([]*entity.Role, error)
+	GetRolesForUsers(ctx context.Context, userIDs []uint) (map[uint][]*entity.Role, error)
+	GrantRole(ctx context.Context, userID uint, roleName string) error
+	RevokeRole(ctx context.Context, userID uint, roleName string) error
+}
+
+// roleUseCase implements RoleUseCase
+type roleUseCase struct {
+	roleRepo repository.RoleRepository
+	userRepo repository.UserRepository
+}
+
+// NewRoleUseCase creates a new role use case
+func NewRoleUseCase(roleRepo repository.RoleRepository, userRepo repository.UserRepository) RoleUseCase {
+	return &roleUseCase{
+		roleRepo: roleRepo,
+		userRepo: userRepo,
+	}
+}
+
+// SeedDefaultRoles makes sure the built-in roles exist with their current permissions
+func (uc *roleUseCase) SeedDefaultRoles(ctx context.Context) error {
+	for _, r := range entity.DefaultRoles() {
+		if err := uc.roleRepo.Upsert(ctx, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BootstrapAdmin grants the admin role to the user with the given email
+// It runs as the system (actor 0) so a fresh deployment can get its first admin
+func (uc *roleUseCase) BootstrapAdmin(ctx context.Context, email string) error {
+	ctx = repository.WithReadYourWrites(ctx)
+	user, err := uc.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		if isNotFound(err) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	return uc.roleRepo.AssignToUser(ctx, user.ID, entity.RoleAdmin, 0)
+}
+
+// GrantDefaultRole grants the runner role to a user who registered themselves
+// It runs as the system (actor 0), since the new user holds no roles:manage.
+func (uc *roleUseCase) GrantDefaultRole(ctx context.Context, userID uint) error {
+	return uc.roleRepo.AssignToUser(ctx, userID, entity.RoleRunner, 0)
+}
+
+// HasPermission reports whether any of the user's roles grants the permission
+func (uc *roleUseCase) HasPermission(ctx context.Context, userID uint, permission string) (bool, error) {
+	roles, err := uc.roleRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, r := range roles {
+		if r.HasPermission(permission) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// ListRoles retrieves all roles
+func (uc *roleUseCase) ListRoles(ctx context.Context) ([]*entity.Role, error) {
+	return uc.roleRepo.List(ctx)
+}
+
+// GetUserRoles retrieves the roles assigned to a user
+func (uc *roleUseCase) GetUserRoles(ctx context.Context, userID uint) ([]*entity.Role, error) {
+	if err := uc.ensureUserExists(ctx, userID); err != nil {
+		return nil, err
+	}
+	return uc.roleRepo.ListByUser(ctx, userID)
+}
+
+// GetRolesForUsers retrieves the roles of several users at once, keyed by user ID
+// Users without roles, or that don't exist, are missing from the map.
+func (uc *roleUseCase) GetRolesForUsers(ctx context.Context, userIDs []uint) (map[uint][]*entity.Role, error) {
+	return uc.roleRepo.ListByUsers(ctx, userIDs)
+}
+
+// GrantRole grants a role to a user on behalf of the actor in ctx
+func (uc *roleUseCase) GrantRole(ctx context.Context, userID uint, roleName string) error {
+	if err := Require(ctx, uc, entity.PermissionRolesManage); err != nil {
+		return err
+	}
+	if err := uc.ensureUserExists(ctx, userID); err != nil {
+		return err
+	}
+
+	actorID, _ := identity.ActorFromContext(ctx)
+	if err := uc.roleRepo.AssignToUser(ctx, userID, roleName, actorID); err != nil {
+		if isNotFound(err) {
+			return ErrRoleNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// RevokeRole removes a role from a user on behalf of the actor in ctx
+func (uc *roleUseCase) RevokeRole(ctx context.Context, userID uint, roleName string) error {
+	if err := Require(ctx, uc, entity.PermissionRolesManage); err != nil {
+		return err
+	}
+	if err := uc.ensureUserExists(ctx, userID); err != nil {
+		return err
+	}
+
+	// Prevent an admin from locking everyone out by accident
+	actorID, _ := identity.ActorFromContext(ctx)
+	if actorID == userID && roleName == entity.RoleAdmin {
+		return ErrSelfRevokeAdmin
+	}
+
+	if err := uc.roleRepo.RevokeFromUser(ctx, userID, roleName, actorID); err != nil {
+		if isNotFound(err) {
+			return ErrRoleNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// ensureUserExists maps a missing user to ErrUserNotFound
+// It reads from the primary, so a role can be granted right after the user is created.
+func (uc *roleUseCase) ensureUserExists(ctx context.Context, userID uint) error {
+	if _, err := uc.userRepo.GetByID(repository.WithReadYourWrites(ctx), userID); err != nil {
+		if isNotFound(err) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// isNotFound reports whether err is a not-found error from either database backend
+func isNotFound(err error) bool {
+	return errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, mongo.ErrNoDocuments)
+}
@@ -0,0 +1,224 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"sync"
+
+	"booking/domain/entity"
+	"booking/domain/repository"
+)
+
+// Defaults of the import limits; see WithImportLimits
+const (
+	defaultImportBatchSize = 100
+	defaultImportMaxRows   = 1000
+)
+
+// ErrImportTooLarge is returned when an import has more rows than allowed; nothing is imported
+var ErrImportTooLarge = errors.New("import has too many rows")
+
+// UserSource yields the rows of an import in order
+// Next returns io.EOF after the last row. A *ValidationError only concerns
+// the current row, which is reported and skipped; any other error stops the
+// import.
+type UserSource interface {
+	Next() (*entity.User, error)
+}
+
+// ImportRowError explains why one row was not imported
+type ImportRowError struct {
+	// Row is the 1-based position of the row, not counting a CSV header
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+// ImportReport is the outcome of an import
+type ImportReport struct {
+	DryRun bool `json:"dry_run"`
+	// Rows were read, Valid of them passed validation and Created were inserted
+	Rows    int `json:"rows"`
+	Valid   int `json:"valid"`
+	Created int `json:"created"`
+	Failed  int `json:"failed"`
+	// Errors lists every failed row in row order
+	Errors []ImportRowError `json:"errors"`
+}
+
+// fail records why a row was not imported
+func (r *ImportReport) fail(row int, reason string) {
+	r.Failed++
+	r.Errors = append(r.Errors, ImportRowError{Row: row, Error: reason})
+}
+
+// importRow is a validated row waiting to be inserted
+type importRow struct {
+	row  int
+	user *entity.User
+}
+
+// ImportUsers creates the users read from source
+// Every row is read and validated like CreateUser before anything is written,
+// so an import over the row limit changes nothing. Invalid rows are reported
+// and the valid ones are inserted in batches, one transaction per batch; when
+// a batch fails its rows are reported and the next batch is tried. A dry run
+// stops after validation.
+func (uc *userUseCase) ImportUsers(ctx context.Context, source UserSource, dryRun bool) (*ImportReport, error) {
+	// Rows are checked against users created up to this moment
+	ctx = repository.WithReadYourWrites(ctx)
+	report := &ImportReport{DryRun: dryRun, Errors: []ImportRowError{}}
+
+	var valid []importRow
+	emails := map[string]int{}
+	usernames := map[string]int{}
+	for {
+		u, err := source.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		report.Rows++
+		row := report.Rows
+		if uc.options.ImportMaxRows > 0 && row > uc.options.ImportMaxRows {
+			return nil, ErrImportTooLarge
+		}
+
+		if err == nil {
+			err = uc.checkImportRow(ctx, u, emails, usernames)
+		}
+		if err != nil {
+			if !isRowError(err) {
+				return nil, err
+			}
+			report.fail(row, err.Error())
+			continue
+		}
+
+		emails[u.Email] = row
+		usernames[u.Username] = row
+		valid = append(valid, importRow{row: row, user: u})
+	}
+	report.Valid = len(valid)
+	if dryRun {
+		return report, nil
+	}
+
+	batchSize := uc.options.ImportBatchSize
+	for start := 0; start < len(valid); start += batchSize {
+		batch := valid[start:min(start+batchSize, len(valid))]
+		if err := uc.insertBatch(ctx, batch); err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			reason := fmt.Sprintf("batch of rows %d-%d was not inserted: %v", batch[0].row, batch[len(batch)-1].row, err)
+			for _, r := range batch {
+				report.fail(r.row, reason)
+			}
+			continue
+		}
+		report.Created += len(batch)
+	}
+
+	sort.SliceStable(report.Errors, func(i, j int) bool { return report.Errors[i].Row < report.Errors[j].Row })
+	return report, nil
+}
+
+// checkImportRow validates a row and checks its email and username are free
+// emails and usernames map the values of earlier valid rows to their row.
+func (uc *userUseCase) checkImportRow(ctx context.Context, u *entity.User, emails, usernames map[string]int) error {
+	if err := uc.validateUser(u); err != nil {
+		return err
+	}
+
+	if row, ok := emails[u.Email]; ok {
+		return invalid(fmt.Sprintf("email is already used by row %d", row))
+	}
+	if row, ok := usernames[u.Username]; ok {
+		return invalid(fmt.Sprintf("username is already used by row %d", row))
+	}
+
+	if _, err := uc.userRepo.GetByEmail(ctx, u.Email); err == nil {
+		return ErrEmailTaken
+	} else if !isNotFound(err) {
+		return err
+	}
+	if _, err := uc.userRepo.GetByUsername(ctx, u.Username); err == nil {
+		return ErrUsernameTaken
+	} else if !isNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// insertBatch hashes the passwords of a batch and creates its users in one transaction
+// bcrypt dominates the cost of an import, so passwords are hashed in parallel.
+func (uc *userUseCase) insertBatch(ctx context.Context, batch []importRow) error {
+	users := make([]*entity.User, len(batch))
+	errs := make([]error, len(batch))
+
+	var wg sync.WaitGroup
+	next := make(chan int)
+	for range min(runtime.GOMAXPROCS(0), len(batch)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				users[i] = batch[i].user
+				users[i].Password, errs[i] = uc.hashPassword(ctx, users[i].Password)
+			}
+		}()
+	}
+	for i := range batch {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	return takenError(uc.userRepo.CreateBatch(ctx, users))
+}
+
+// ExportUsers calls fn with every user matching filter, in filter order
+// Users are read one page at a time, so only a page is held in memory. It
+// returns how many users were passed to fn; an error from fn stops the export.
+func (uc *userUseCase) ExportUsers(ctx context.Context, filter *entity.UserFilter, fn func(*entity.User) error) (int, error) {
+	var pageFilter entity.UserFilter
+	if filter != nil {
+		pageFilter = *filter
+	}
+	pageFilter.Limit = maxUserPageSize
+
+	exported := 0
+	for {
+		page, err := uc.ListUsers(ctx, &pageFilter)
+		if err != nil {
+			return exported, err
+		}
+		for _, u := range page.Users {
+			if err := fn(u); err != nil {
+				return exported, err
+			}
+			exported++
+		}
+		if !page.HasMore {
+			return exported, nil
+		}
+
+		after, err := entity.DecodeUserCursor(page.NextCursor)
+		if err != nil {
+			return exported, err
+		}
+		pageFilter.After = after
+	}
+}
+
+// isRowError reports whether err only concerns the current import row
+func isRowError(err error) bool {
+	var invalidRow *ValidationError
+	return errors.As(err, &invalidRow) || errors.Is(err, ErrEmailTaken) || errors.Is(err, ErrUsernameTaken)
+}
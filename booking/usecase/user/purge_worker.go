@@ -0,0 +1,55 @@
+package user
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"booking/infrastructure/logging"
+)
+
+// PurgeWorker periodically anonymizes users whose deletion grace period has expired
+type PurgeWorker struct {
+	userUseCase UserUseCase
+	gracePeriod time.Duration
+	interval    time.Duration
+	logger      *slog.Logger
+}
+
+// NewPurgeWorker creates a new purge worker
+func NewPurgeWorker(userUseCase UserUseCase, gracePeriod, interval time.Duration) *PurgeWorker {
+	return &PurgeWorker{
+		userUseCase: userUseCase,
+		gracePeriod: gracePeriod,
+		interval:    interval,
+		logger:      logging.For("purge"),
+	}
+}
+
+// Run purges expired users immediately and then on every interval until ctx is cancelled
+func (w *PurgeWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.purgeOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// purgeOnce runs a single purge pass
+func (w *PurgeWorker) purgeOnce(ctx context.Context) {
+	purged, err := w.userUseCase.PurgeDeletedUsers(ctx, time.Now().Add(-w.gracePeriod))
+	if err != nil {
+		w.logger.ErrorContext(ctx, "purge failed", slog.Int("purged", purged), slog.Any("error", err))
+		return
+	}
+	if purged > 0 {
+		w.logger.InfoContext(ctx, "anonymized deleted users", slog.Int("purged", purged))
+	}
+}
@@ -0,0 +1,164 @@
+package user
+
+import (
+	"context"
+	"time"
+
+	"booking/domain/entity"
+	"booking/infrastructure/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedUserUseCase wraps a UserUseCase with one span per call
+// Decorator Pattern: tracing stays out of the business logic. Only IDs are
+// recorded as attributes; emails and names stay out of traces.
+type tracedUserUseCase struct {
+	next   UserUseCase
+	tracer trace.Tracer
+}
+
+// newTracedUserUseCase decorates next with spans from tracer
+func newTracedUserUseCase(next UserUseCase, tracer trace.Tracer) UserUseCase {
+	return &tracedUserUseCase{next: next, tracer: tracer}
+}
+
+// CreateUser implements UserUseCase
+func (t *tracedUserUseCase) CreateUser(ctx context.Context, user *entity.User) error {
+	ctx, span := t.tracer.Start(ctx, "UserUseCase.CreateUser")
+	err := t.next.CreateUser(ctx, user)
+	if err == nil {
+		span.SetAttributes(userIDAttr(user.ID))
+	}
+	tracing.Finish(span, err)
+	return err
+}
+
+// RegisterUser implements UserUseCase
+func (t *tracedUserUseCase) RegisterUser(ctx context.Context, user *entity.User) error {
+	ctx, span := t.tracer.Start(ctx, "UserUseCase.RegisterUser")
+	err := t.next.RegisterUser(ctx, user)
+	if user.ID != 0 {
+		span.SetAttributes(userIDAttr(user.ID))
+	}
+	tracing.Finish(span, err)
+	return err
+}
+
+// GetUserByID implements UserUseCase
+func (t *tracedUserUseCase) GetUserByID(ctx context.Context, id uint) (*entity.User, error) {
+	ctx, span := t.tracer.Start(ctx, "UserUseCase.GetUserByID", trace.WithAttributes(userIDAttr(id)))
+	user, err := t.next.GetUserByID(ctx, id)
+	tracing.Finish(span, err)
+	return user, err
+}
+
+// GetUsersByIDs implements UserUseCase
+func (t *tracedUserUseCase) GetUsersByIDs(ctx context.Context, ids []uint) ([]*entity.User, error) {
+	ctx, span := t.tracer.Start(ctx, "UserUseCase.GetUsersByIDs", trace.WithAttributes(attribute.Int("user.count", len(ids))))
+	users, err := t.next.GetUsersByIDs(ctx, ids)
+	tracing.Finish(span, err)
+	return users, err
+}
+
+// GetUserByEmail implements UserUseCase
+func (t *tracedUserUseCase) GetUserByEmail(ctx context.Context, email string) (*entity.User, error) {
+	ctx, span := t.tracer.Start(ctx, "UserUseCase.GetUserByEmail")
+	user, err := t.next.GetUserByEmail(ctx, email)
+	tracing.Finish(span, err)
+	return user, err
+}
+
+// GetUserByUsername implements UserUseCase
+func (t *tracedUserUseCase) GetUserByUsername(ctx context.Context, username string) (*entity.User, error) {
+	ctx, span := t.tracer.Start(ctx, "UserUseCase.GetUserByUsername")
+	user, err := t.next.GetUserByUsername(ctx, username)
+	tracing.Finish(span, err)
+	return user, err
+}
+
+// ListUsers implements UserUseCase
+func (t *tracedUserUseCase) ListUsers(ctx context.Context, filter *entity.UserFilter) (*entity.UserPage, error) {
+	ctx, span := t.tracer.Start(ctx, "UserUseCase.ListUsers")
+	page, err := t.next.ListUsers(ctx, filter)
+	if err == nil {
+		span.SetAttributes(
+			attribute.String("users.sort_by", filter.SortBy),
+			attribute.Int("users.limit", filter.Limit),
+			attribute.Int("users.returned", len(page.Users)),
+		)
+	}
+	tracing.Finish(span, err)
+	return page, err
+}
+
+// UpdateUser implements UserUseCase
+func (t *tracedUserUseCase) UpdateUser(ctx context.Context, user *entity.User) error {
+	ctx, span := t.tracer.Start(ctx, "UserUseCase.UpdateUser", trace.WithAttributes(userIDAttr(user.ID)))
+	err := t.next.UpdateUser(ctx, user)
+	tracing.Finish(span, err)
+	return err
+}
+
+// DeleteUser implements UserUseCase
+func (t *tracedUserUseCase) DeleteUser(ctx context.Context, id uint) error {
+	ctx, span := t.tracer.Start(ctx, "UserUseCase.DeleteUser", trace.WithAttributes(userIDAttr(id)))
+	err := t.next.DeleteUser(ctx, id)
+	tracing.Finish(span, err)
+	return err
+}
+
+// CountUsers implements UserUseCase
+func (t *tracedUserUseCase) CountUsers(ctx context.Context, filter *entity.UserFilter) (int64, error) {
+	ctx, span := t.tracer.Start(ctx, "UserUseCase.CountUsers")
+	count, err := t.next.CountUsers(ctx, filter)
+	tracing.Finish(span, err)
+	return count, err
+}
+
+// RestoreUser implements UserUseCase
+func (t *tracedUserUseCase) RestoreUser(ctx context.Context, id uint) (*entity.User, error) {
+	ctx, span := t.tracer.Start(ctx, "UserUseCase.RestoreUser", trace.WithAttributes(userIDAttr(id)))
+	user, err := t.next.RestoreUser(ctx, id)
+	tracing.Finish(span, err)
+	return user, err
+}
+
+// PurgeDeletedUsers implements UserUseCase
+func (t *tracedUserUseCase) PurgeDeletedUsers(ctx context.Context, deletedBefore time.Time) (int, error) {
+	ctx, span := t.tracer.Start(ctx, "UserUseCase.PurgeDeletedUsers")
+	purged, err := t.next.PurgeDeletedUsers(ctx, deletedBefore)
+	span.SetAttributes(attribute.Int("users.purged", purged))
+	tracing.Finish(span, err)
+	return purged, err
+}
+
+// ImportUsers implements UserUseCase
+func (t *tracedUserUseCase) ImportUsers(ctx context.Context, source UserSource, dryRun bool) (*ImportReport, error) {
+	ctx, span := t.tracer.Start(ctx, "UserUseCase.ImportUsers", trace.WithAttributes(attribute.Bool("users.import.dry_run", dryRun)))
+	report, err := t.next.ImportUsers(ctx, source, dryRun)
+	if err == nil {
+		span.SetAttributes(
+			attribute.Int("users.import.rows", report.Rows),
+			attribute.Int("users.import.created", report.Created),
+			attribute.Int("users.import.failed", report.Failed),
+		)
+	}
+	tracing.Finish(span, err)
+	return report, err
+}
+
+// ExportUsers implements UserUseCase
+func (t *tracedUserUseCase) ExportUsers(ctx context.Context, filter *entity.UserFilter, fn func(*entity.User) error) (int, error) {
+	ctx, span := t.tracer.Start(ctx, "UserUseCase.ExportUsers")
+	exported, err := t.next.ExportUsers(ctx, filter, fn)
+	span.SetAttributes(attribute.Int("users.exported", exported))
+	tracing.Finish(span, err)
+	return exported, err
+}
+
+// userIDAttr is the span attribute identifying a user
+func userIDAttr(id uint) attribute.KeyValue {
+	return attribute.Int64("user.id", int64(id))
+}
@@ -3,22 +3,52 @@ package user
 import (
 	"context"
 	"errors"
+	"strconv"
+	"time"
 	"booking/domain/entity"
 	"booking/domain/repository"
+	"booking/infrastructure/tracing"
 	
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
 )
 
+// purgeBatchSize is how many expired users are anonymized per repository call
+const purgeBatchSize = 100
+
+// defaultUserPageSize and maxUserPageSize bound the user listing page size
+const (
+	defaultUserPageSize = 20
+	maxUserPageSize     = 100
+)
+
+var (
+	// ErrUserNotFound is returned when the user does not exist (or is soft-deleted)
+	ErrUserNotFound = errors.New("user not found")
+	// ErrInvalidSort is returned when a listing is sorted by a field off the whitelist
+	ErrInvalidSort = errors.New("invalid sort field")
+	// ErrEmailTaken and ErrUsernameTaken are returned when another user already has the email or username
+	ErrEmailTaken    = errors.New("user with this email already exists")
+	ErrUsernameTaken = errors.New("user with this username already exists")
+)
+
 // UserUseCase defines the interface for user business logic
 type UserUseCase interface {
 	CreateUser(ctx context.Context, user *entity.User) error
+	RegisterUser(ctx context.Context, user *entity.User) error
 	GetUserByID(ctx context.Context, id uint) (*entity.User, error)
+	GetUsersByIDs(ctx context.Context, ids []uint) ([]*entity.User, error)
 	GetUserByEmail(ctx context.Context, email string) (*entity.User, error)
 	GetUserByUsername(ctx context.Context, username string) (*entity.User, error)
-	ListUsers(ctx context.Context, filter *entity.UserFilter) ([]*entity.User, error)
+	ListUsers(ctx context.Context, filter *entity.UserFilter) (*entity.UserPage, error)
 	UpdateUser(ctx context.Context, user *entity.User) error
 	DeleteUser(ctx context.Context, id uint) error
 	CountUsers(ctx context.Context, filter *entity.UserFilter) (int64, error)
+	RestoreUser(ctx context.Context, id uint) (*entity.User, error)
+	PurgeDeletedUsers(ctx context.Context, deletedBefore time.Time) (int, error)
+	ImportUsers(ctx context.Context, source UserSource, dryRun bool) (*ImportReport, error)
+	ExportUsers(ctx context.Context, filter *entity.UserFilter, fn func(*entity.User) error) (int, error)
 }
 
 // userUseCase implements UserUseCase
@@ -26,6 +56,7 @@ type userUseCase struct {
 	userRepo       repository.UserRepository
 	passwordHasher PasswordHasher
 	options        *UseCaseOptions
+	tracer         trace.Tracer
 }
 
 // UseCaseOptions holds optional configuration for the use case
@@ -35,6 +66,21 @@ type UseCaseOptions struct {
 	ValidatePassword bool
 	MinPasswordLen   int
 	MaxPasswordLen   int
+	AuditRecorder    AuditRecorder
+	RoleGranter      RoleGranter
+	// ImportBatchSize users are inserted per transaction; ImportMaxRows bounds an import, 0 means no limit
+	ImportBatchSize int
+	ImportMaxRows   int
+}
+
+// AuditRecorder records security-relevant actions that repository events don't reveal
+type AuditRecorder interface {
+	Record(ctx context.Context, action, targetType, targetID string, before, after interface{}) error
+}
+
+// RoleGranter gives users who register themselves their default role
+type RoleGranter interface {
+	GrantDefaultRole(ctx context.Context, userID uint) error
 }
 
 // UseCaseOption is a function that configures UseCaseOptions
@@ -62,6 +108,28 @@ func WithPasswordLength(min, max int) UseCaseOption {
 	}
 }
 
+// WithAuditRecorder records password changes to the audit log
+func WithAuditRecorder(recorder AuditRecorder) UseCaseOption {
+	return func(o *UseCaseOptions) {
+		o.AuditRecorder = recorder
+	}
+}
+
+// WithRoleGranter grants registered users their default role
+func WithRoleGranter(granter RoleGranter) UseCaseOption {
+	return func(o *UseCaseOptions) {
+		o.RoleGranter = granter
+	}
+}
+
+// WithImportLimits sets the batch size and row limit of ImportUsers
+func WithImportLimits(batchSize, maxRows int) UseCaseOption {
+	return func(o *UseCaseOptions) {
+		o.ImportBatchSize = batchSize
+		o.ImportMaxRows = maxRows
+	}
+}
+
 // defaultOptions returns default use case options
 func defaultOptions() *UseCaseOptions {
 	return &UseCaseOptions{
@@ -69,11 +137,14 @@ func defaultOptions() *UseCaseOptions {
 		ValidatePassword: true,
 		MinPasswordLen:   8,
 		MaxPasswordLen:   72,
+		ImportBatchSize:  defaultImportBatchSize,
+		ImportMaxRows:    defaultImportMaxRows,
 	}
 }
 
 // NewUserUseCase creates a new user use case with functional options
 // Functional Options Pattern implementation
+// Every call is traced through the global OpenTelemetry tracer provider.
 func NewUserUseCase(
 	userRepo repository.UserRepository,
 	passwordHasher PasswordHasher,
@@ -85,12 +156,17 @@ func NewUserUseCase(
 	for _, opt := range opts {
 		opt(options)
 	}
+	if options.ImportBatchSize <= 0 {
+		options.ImportBatchSize = defaultImportBatchSize
+	}
 	
-	return &userUseCase{
+	tracer := tracing.Tracer("usecase/user")
+	return newTracedUserUseCase(&userUseCase{
 		userRepo:       userRepo,
 		passwordHasher: passwordHasher,
 		options:        options,
-	}
+		tracer:         tracer,
+	}, tracer)
 }
 
 // CreateUser creates a new user
@@ -100,31 +176,57 @@ func (uc *userUseCase) CreateUser(ctx context.Context, user *entity.User) error
 		return err
 	}
 	
-	// Check if user already exists
+	// Check if user already exists; a lagging replica could miss a user created a moment ago
+	ctx = repository.WithReadYourWrites(ctx)
 	existingUser, err := uc.userRepo.GetByEmail(ctx, user.Email)
 	if err == nil && existingUser != nil {
-		return errors.New("user with this email already exists")
+		return ErrEmailTaken
 	}
 	
 	existingUser, err = uc.userRepo.GetByUsername(ctx, user.Username)
 	if err == nil && existingUser != nil {
-		return errors.New("user with this username already exists")
+		return ErrUsernameTaken
 	}
 	
 	// Hash password
-	hashedPassword, err := uc.passwordHasher.Hash(user.Password)
+	hashedPassword, err := uc.hashPassword(ctx, user.Password)
 	if err != nil {
 		return err
 	}
 	user.Password = hashedPassword
 	
-	// Create user
-	return uc.userRepo.Create(ctx, user)
+	// Create user; the unique indexes also catch soft-deleted users the checks above skip
+	return takenError(uc.userRepo.Create(ctx, user))
+}
+
+// RegisterUser creates an active user on its own behalf and grants the default role
+// If the grant fails the user exists without roles; an admin can grant one.
+func (uc *userUseCase) RegisterUser(ctx context.Context, user *entity.User) error {
+	user.IsActive = true
+	if err := uc.CreateUser(ctx, user); err != nil {
+		return err
+	}
+	if uc.options.RoleGranter == nil {
+		return nil
+	}
+	return uc.options.RoleGranter.GrantDefaultRole(ctx, user.ID)
 }
 
 // GetUserByID retrieves a user by ID
 func (uc *userUseCase) GetUserByID(ctx context.Context, id uint) (*entity.User, error) {
-	return uc.userRepo.GetByID(ctx, id)
+	user, err := uc.userRepo.GetByID(ctx, id)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// GetUsersByIDs retrieves several users at once; IDs without a user are skipped
+func (uc *userUseCase) GetUsersByIDs(ctx context.Context, ids []uint) ([]*entity.User, error) {
+	return uc.userRepo.GetByIDs(ctx, ids)
 }
 
 // GetUserByEmail retrieves a user by email
@@ -137,43 +239,156 @@ func (uc *userUseCase) GetUserByUsername(ctx context.Context, username string) (
 	return uc.userRepo.GetByUsername(ctx, username)
 }
 
-// ListUsers retrieves users based on filter
-func (uc *userUseCase) ListUsers(ctx context.Context, filter *entity.UserFilter) ([]*entity.User, error) {
+// ListUsers retrieves one page of users based on filter; a nil filter lists every user
+func (uc *userUseCase) ListUsers(ctx context.Context, filter *entity.UserFilter) (*entity.UserPage, error) {
+	if filter == nil {
+		filter = &entity.UserFilter{}
+	}
+	if filter.SortBy == "" {
+		filter.SortBy = entity.UserSortCreatedAt
+	}
+	if !entity.IsUserSortField(filter.SortBy) {
+		return nil, ErrInvalidSort
+	}
+	
+	if filter.Limit <= 0 {
+		filter.Limit = defaultUserPageSize
+	}
+	filter.Limit = min(filter.Limit, maxUserPageSize)
+	
+	// A cursor only marks a position within the order it was issued for
+	if filter.After != nil && (filter.After.SortBy != filter.SortBy || filter.After.SortDesc != filter.SortDesc) {
+		return nil, entity.ErrInvalidCursor
+	}
+	
 	return uc.userRepo.List(ctx, filter)
 }
 
 // UpdateUser updates a user
 func (uc *userUseCase) UpdateUser(ctx context.Context, user *entity.User) error {
-	// Check if user exists
+	// Check if user exists; the profile fields are taken as given, while the
+	// timestamps and an unchanged password hash come from the latest state
+	// Read-your-writes also skips the user cache, which keeps no password hash
+	ctx = repository.WithReadYourWrites(ctx)
 	existingUser, err := uc.userRepo.GetByID(ctx, user.ID)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("user not found")
+		if isNotFound(err) {
+			return ErrUserNotFound
 		}
 		return err
 	}
 	
+	// Callers build the user from a request, so these would otherwise be zero and Save would write them
+	user.CreatedAt = existingUser.CreatedAt
+	user.DeletedAt = existingUser.DeletedAt
+	user.PurgedAt = existingUser.PurgedAt
+	
 	// If password is being updated, hash it
+	passwordChanged := false
 	if user.Password != "" && user.Password != existingUser.Password {
-		hashedPassword, err := uc.passwordHasher.Hash(user.Password)
+		hashedPassword, err := uc.hashPassword(ctx, user.Password)
 		if err != nil {
 			return err
 		}
 		user.Password = hashedPassword
+		passwordChanged = true
 	} else {
 		user.Password = existingUser.Password
 	}
 	
-	return uc.userRepo.Update(ctx, user)
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return takenError(err)
+	}
+	
+	// Password hashes are hidden from JSON, so the user.updated audit entry can't show this
+	if passwordChanged && uc.options.AuditRecorder != nil {
+		targetID := strconv.FormatUint(uint64(user.ID), 10)
+		if err := uc.options.AuditRecorder.Record(ctx, "user.password_changed", "user", targetID, nil, nil); err != nil {
+			return err
+		}
+	}
+	
+	return nil
 }
 
-// DeleteUser deletes a user
+// DeleteUser soft-deletes a user; it can be restored until the grace period ends
 func (uc *userUseCase) DeleteUser(ctx context.Context, id uint) error {
-	return uc.userRepo.Delete(ctx, id)
+	if err := uc.userRepo.Delete(ctx, id); err != nil {
+		if isNotFound(err) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// RestoreUser restores a soft-deleted user that has not been purged yet
+func (uc *userUseCase) RestoreUser(ctx context.Context, id uint) (*entity.User, error) {
+	user, err := uc.userRepo.Restore(ctx, id)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// PurgeDeletedUsers anonymizes every user soft-deleted before the cutoff
+// It returns how many users were purged.
+func (uc *userUseCase) PurgeDeletedUsers(ctx context.Context, deletedBefore time.Time) (int, error) {
+	// Users purged by the previous batch must not be listed again
+	ctx = repository.WithReadYourWrites(ctx)
+	purged := 0
+	for {
+		users, err := uc.userRepo.ListDeletedBefore(ctx, deletedBefore, purgeBatchSize)
+		if err != nil {
+			return purged, err
+		}
+		
+		for _, u := range users {
+			u.Anonymize(time.Now())
+			if err := uc.userRepo.Purge(ctx, u); err != nil {
+				return purged, err
+			}
+			purged++
+		}
+		
+		if len(users) < purgeBatchSize {
+			return purged, nil
+		}
+	}
 }
 
 // CountUsers counts users based on filter
 func (uc *userUseCase) CountUsers(ctx context.Context, filter *entity.UserFilter) (int64, error) {
+	if filter == nil {
+		filter = &entity.UserFilter{}
+	}
 	return uc.userRepo.Count(ctx, filter)
 }
 
+// hashPassword hashes a password in its own span; bcrypt is often the slowest step of a request
+func (uc *userUseCase) hashPassword(ctx context.Context, password string) (string, error) {
+	_, span := uc.tracer.Start(ctx, "PasswordHasher.Hash")
+	hashed, err := uc.passwordHasher.Hash(password)
+	tracing.Finish(span, err)
+	return hashed, err
+}
+
+// takenError turns the repository's duplicate email or username errors into ErrEmailTaken or ErrUsernameTaken
+func takenError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrDuplicateEmail):
+		return ErrEmailTaken
+	case errors.Is(err, repository.ErrDuplicateUsername):
+		return ErrUsernameTaken
+	default:
+		return err
+	}
+}
+
+// isNotFound reports whether err is a not-found error from either database backend
+func isNotFound(err error) bool {
+	return errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, mongo.ErrNoDocuments)
+}
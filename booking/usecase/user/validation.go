@@ -1,39 +1,54 @@
 package user
 
 import (
-	"errors"
 	"regexp"
 	"booking/domain/entity"
 )
 
+// ValidationError is returned when user data breaks a validation rule
+// Transports report it as a client error rather than a server failure.
+type ValidationError struct {
+	Reason string
+}
+
+// Error implements error
+func (e *ValidationError) Error() string {
+	return e.Reason
+}
+
+// invalid returns a ValidationError with the reason
+func invalid(reason string) error {
+	return &ValidationError{Reason: reason}
+}
+
 var emailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`)
 
 // validateUser validates user data
 func (uc *userUseCase) validateUser(user *entity.User) error {
 	if user.Email == "" {
-		return errors.New("email is required")
+		return invalid("email is required")
 	}
 	
 	if user.Username == "" {
-		return errors.New("username is required")
+		return invalid("username is required")
 	}
 	
 	if user.Password == "" {
-		return errors.New("password is required")
+		return invalid("password is required")
 	}
 	
 	// Email validation
 	if uc.options.ValidateEmail && !emailRegex.MatchString(user.Email) {
-		return errors.New("invalid email format")
+		return invalid("invalid email format")
 	}
 	
 	// Password validation
 	if uc.options.ValidatePassword {
 		if len(user.Password) < uc.options.MinPasswordLen {
-			return errors.New("password is too short")
+			return invalid("password is too short")
 		}
 		if len(user.Password) > uc.options.MaxPasswordLen {
-			return errors.New("password is too long")
+			return invalid("password is too long")
 		}
 	}
 	